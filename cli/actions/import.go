@@ -0,0 +1,112 @@
+package actions
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/yaml"
+	"github.com/pkg/errors"
+)
+
+// Import is the command description for `sous import`.
+type Import struct {
+	Importer sous.Importer
+	Cluster  *sous.Cluster
+	// RequestIDs are the requests to import. If empty, every request Sous
+	// does not manage is offered.
+	RequestIDs  []string
+	State       *sous.State
+	StateWriter sous.StateWriter
+	User        sous.User
+	// Yes imports without asking for confirmation.
+	Yes bool
+	In  io.Reader
+	Out io.Writer
+}
+
+// Do shows the manifest proposed for each unmanaged request, and for each
+// one confirmed, adds the deployment to its manifest, and then relabels the
+// request so that Sous manages it. If the request can't be relabelled, the
+// manifest is put back as it was.
+func (im *Import) Do() error {
+	uds, err := im.Importer.Unmanaged(im.Cluster, im.RequestIDs...)
+	if err != nil {
+		return err
+	}
+	if len(uds) == 0 {
+		fmt.Fprintf(im.Out, "No unmanaged requests found in %s.\n", im.Cluster.Name)
+		return nil
+	}
+	in := bufio.NewReader(im.In)
+	for _, ud := range uds {
+		m, err := im.proposedManifest(ud.Deployment)
+		if err != nil {
+			fmt.Fprintf(im.Out, "Skipping request %s: %s\n", ud.RequestID, err)
+			continue
+		}
+		b, err := yaml.Marshal(m)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(im.Out, "Request %s would be managed by this manifest:\n%s\n", ud.RequestID, b)
+		if !im.Yes && !confirm(in, im.Out, fmt.Sprintf("Import request %s as %s?", ud.RequestID, m.ID())) {
+			continue
+		}
+		prior, existed := im.State.Manifests.Get(m.ID())
+		im.State.Manifests.Set(m.ID(), m)
+		if err := im.StateWriter.WriteState(im.State, im.User); err != nil {
+			return errors.Wrapf(err, "writing manifest %s", m.ID())
+		}
+		if err := im.Importer.Adopt(ud); err != nil {
+			if existed {
+				im.State.Manifests.Set(m.ID(), prior)
+			} else {
+				im.State.Manifests.Remove(m.ID())
+			}
+			if werr := im.StateWriter.WriteState(im.State, im.User); werr != nil {
+				return errors.Wrapf(err, "relabelling request %s (and putting back manifest %s: %s)", ud.RequestID, m.ID(), werr)
+			}
+			return errors.Wrapf(err, "relabelling request %s", ud.RequestID)
+		}
+		fmt.Fprintf(im.Out, "Imported request %s as %s.\n", ud.RequestID, m.ID())
+	}
+	return nil
+}
+
+// proposedManifest returns the manifest for d: its existing manifest, if it
+// has one, with d added.
+func (im *Import) proposedManifest(d *sous.Deployment) (*sous.Manifest, error) {
+	mid := d.ManifestID()
+	ms, err := sous.NewDeployments(d).PutbackManifests(im.State.Defs, im.State.Manifests)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := ms.Get(mid)
+	if !ok {
+		return nil, errors.Errorf("no manifest made for %s", mid)
+	}
+	existing, ok := im.State.Manifests.Get(mid)
+	if !ok {
+		return m, nil
+	}
+	if _, deployed := existing.Deployments[d.ClusterName]; deployed {
+		return nil, errors.Errorf("%s is already deployed to %s", mid, d.ClusterName)
+	}
+	merged := existing.Clone()
+	merged.Deployments[d.ClusterName] = m.Deployments[d.ClusterName]
+	return merged, nil
+}
+
+// confirm asks question, and returns true if the answer is yes.
+func confirm(in *bufio.Reader, out io.Writer, question string) bool {
+	fmt.Fprintf(out, "%s [y/N] ", question)
+	answer, _ := in.ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package actions
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	sous "github.com/opentable/sous/lib"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeImporter struct {
+	unmanaged []sous.UnmanagedDeployment
+	adopted   []string
+	// sm, if set, must have written the manifest before each adoption.
+	sm *sous.DummyStateManager
+	// fail is a request which can't be adopted.
+	fail string
+}
+
+func (fi *fakeImporter) Unmanaged(*sous.Cluster, ...string) ([]sous.UnmanagedDeployment, error) {
+	return fi.unmanaged, nil
+}
+
+func (fi *fakeImporter) Adopt(u sous.UnmanagedDeployment) error {
+	if fi.sm != nil {
+		if _, written := fi.sm.State.Manifests.Get(u.Deployment.ManifestID()); !written {
+			return errors.Errorf("%s adopted before its manifest was written", u.RequestID)
+		}
+	}
+	if u.RequestID == fi.fail {
+		return errors.Errorf("cannot adopt %s", u.RequestID)
+	}
+	fi.adopted = append(fi.adopted, u.RequestID)
+	return nil
+}
+
+func TestImport(t *testing.T) {
+	left := &sous.Cluster{Name: "left"}
+	right := &sous.Cluster{Name: "right"}
+	unmanaged := func(reqID, repo string, cluster *sous.Cluster) sous.UnmanagedDeployment {
+		return sous.UnmanagedDeployment{
+			RequestID: reqID,
+			Deployment: &sous.Deployment{
+				SourceID:     sous.MustNewSourceID(repo, "", "1.0.0"),
+				ClusterName:  cluster.Name,
+				Cluster:      cluster,
+				Kind:         sous.ManifestKindService,
+				DeployConfig: sous.DeployConfig{NumInstances: 2},
+			},
+			Artifact: &sous.BuildArtifact{Name: reqID + ":1.0.0", Type: "docker"},
+		}
+	}
+
+	state := sous.NewState()
+	state.Defs.Clusters = sous.Clusters{"left": left, "right": right}
+	existing := &sous.Manifest{
+		Source:      sous.MustParseSourceLocation("github.com/example/existing"),
+		Kind:        sous.ManifestKindService,
+		Deployments: sous.DeploySpecs{"left": sous.DeploySpec{}},
+	}
+	state.Manifests.Add(existing)
+
+	importer := &fakeImporter{unmanaged: []sous.UnmanagedDeployment{
+		unmanaged("declined", "github.com/example/declined", left),
+		unmanaged("legacy", "github.com/example/legacy", left),
+		unmanaged("existing-right", "github.com/example/existing", right),
+		unmanaged("existing-left", "github.com/example/existing", left),
+	}}
+	sm := &sous.DummyStateManager{State: state}
+	importer.sm = sm
+	out := &bytes.Buffer{}
+	im := &Import{
+		Importer:    importer,
+		Cluster:     left,
+		State:       state,
+		StateWriter: sm,
+		In:          strings.NewReader("n\ny\nyes\n"),
+		Out:         out,
+	}
+	require.NoError(t, im.Do())
+
+	assert.Equal(t, []string{"legacy", "existing-right"}, importer.adopted)
+	_, declined := state.Manifests.Get(sous.MustParseManifestID("github.com/example/declined"))
+	assert.False(t, declined)
+	legacy, ok := state.Manifests.Get(sous.MustParseManifestID("github.com/example/legacy"))
+	if assert.True(t, ok) {
+		assert.Equal(t, 2, legacy.Deployments["left"].NumInstances)
+	}
+	merged, _ := state.Manifests.Get(existing.ID())
+	assert.Len(t, merged.Deployments, 2, "an import into an existing manifest adds to it")
+	assert.Contains(t, out.String(), "Skipping request existing-left")
+	assert.Equal(t, 2, sm.WriteCount)
+}
+
+func TestImport_adoptFails(t *testing.T) {
+	left := &sous.Cluster{Name: "left"}
+	state := sous.NewState()
+	state.Defs.Clusters = sous.Clusters{"left": left}
+	sm := &sous.DummyStateManager{State: state}
+	importer := &fakeImporter{sm: sm, fail: "legacy", unmanaged: []sous.UnmanagedDeployment{{
+		RequestID: "legacy",
+		Deployment: &sous.Deployment{
+			SourceID:    sous.MustNewSourceID("github.com/example/legacy", "", "1.0.0"),
+			ClusterName: "left",
+			Cluster:     left,
+			Kind:        sous.ManifestKindService,
+		},
+		Artifact: &sous.BuildArtifact{Name: "legacy:1.0.0", Type: "docker"},
+	}}}
+	im := &Import{
+		Importer:    importer,
+		Cluster:     left,
+		State:       state,
+		StateWriter: sm,
+		Yes:         true,
+		Out:         &bytes.Buffer{},
+	}
+	assert.Error(t, im.Do())
+	_, ok := state.Manifests.Get(sous.MustParseManifestID("github.com/example/legacy"))
+	assert.False(t, ok, "the manifest of a request which wasn't adopted is removed")
+	assert.Equal(t, 2, sm.WriteCount)
+}
+
+func TestImport_none(t *testing.T) {
+	out := &bytes.Buffer{}
+	im := &Import{Importer: &fakeImporter{}, Cluster: &sous.Cluster{Name: "left"}, Out: out}
+	require.NoError(t, im.Do())
+	assert.Contains(t, out.String(), "No unmanaged requests")
+}
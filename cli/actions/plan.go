@@ -0,0 +1,90 @@
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/restful"
+	"github.com/pkg/errors"
+)
+
+// Plan is the command description for `sous plan`.
+type Plan struct {
+	// Client, if not nil, gets the plan from the server.
+	Client restful.HTTPClient
+	// Resolver and State make the plan locally, if Client is nil.
+	Resolver      *sous.Resolver
+	State         *sous.State
+	ResolveFilter *sous.ResolveFilter
+	// JSON selects JSON output rather than a human readable summary.
+	JSON bool
+	Out  io.Writer
+}
+
+// Do prints the changes a rectification would make.
+func (p *Plan) Do() error {
+	plan, err := p.plan()
+	if err != nil {
+		return err
+	}
+	if p.JSON {
+		enc := json.NewEncoder(p.Out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(plan)
+	}
+	writePlan(p.Out, plan)
+	return nil
+}
+
+func (p *Plan) plan() (*sous.Plan, error) {
+	if p.Client != nil {
+		plan := &sous.Plan{}
+		if _, err := p.Client.Retrieve("./plan", p.ResolveFilter.QueryMap(), plan, nil); err != nil {
+			return nil, errors.Wrapf(err, "getting plan from server")
+		}
+		return plan, nil
+	}
+	gdm, err := p.State.Deployments()
+	if err != nil {
+		return nil, err
+	}
+	return p.Resolver.Plan(gdm, p.State.Defs.Clusters)
+}
+
+// writePlan writes a summary of plan to w: per cluster, "+" for each
+// deployment which would be created, "~" with its differences for each which
+// would be modified, and "-" for each which would be deleted.
+func writePlan(w io.Writer, plan *sous.Plan) {
+	for _, cp := range plan.Clusters {
+		fmt.Fprintf(w, "%s:\n", cp.Cluster)
+		for _, c := range cp.Changes {
+			mid := c.DeploymentID.ManifestID
+			switch c.Action {
+			case sous.PlanCreate:
+				fmt.Fprintf(w, "  + %s %s\n", mid, c.Post)
+			case sous.PlanDelete:
+				fmt.Fprintf(w, "  - %s %s\n", mid, c.Prior)
+			default:
+				version := c.Post
+				if c.Prior != c.Post {
+					version = c.Prior + " -> " + c.Post
+				}
+				fmt.Fprintf(w, "  ~ %s %s\n", mid, version)
+				for _, d := range c.Diffs {
+					fmt.Fprintf(w, "      %s\n", d)
+				}
+			}
+		}
+		if cp.Unchanged != 0 {
+			fmt.Fprintf(w, "  (%d unchanged)\n", cp.Unchanged)
+		}
+	}
+	if plan.Empty() {
+		fmt.Fprintln(w, "No changes.")
+		return
+	}
+	fmt.Fprintf(w, "Plan: %d to create, %d to modify, %d to delete.\n",
+		plan.Count(sous.PlanCreate), plan.Count(sous.PlanModify), plan.Count(sous.PlanDelete))
+}
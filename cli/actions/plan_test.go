@@ -0,0 +1,52 @@
+package actions
+
+import (
+	"bytes"
+	"testing"
+
+	sous "github.com/opentable/sous/lib"
+)
+
+func TestWritePlan(t *testing.T) {
+	did := func(mid, cluster string) sous.DeploymentID {
+		return sous.DeploymentID{ManifestID: sous.MustParseManifestID(mid), Cluster: cluster}
+	}
+	plan := &sous.Plan{Clusters: []sous.ClusterPlan{
+		{
+			Cluster: "ci",
+			Changes: []sous.PlannedChange{
+				{DeploymentID: did("github.com/ot/new", "ci"), Action: sous.PlanCreate, Post: "1.0.0"},
+				{DeploymentID: did("github.com/ot/bump", "ci"), Action: sous.PlanModify, Prior: "1.0.0", Post: "1.1.0",
+					Diffs: sous.Differences{"number of instances; this: 1; other: 2"}},
+			},
+			Unchanged: 3,
+		},
+		{
+			Cluster: "prod",
+			Changes: []sous.PlannedChange{
+				{DeploymentID: did("github.com/ot/gone", "prod"), Action: sous.PlanDelete, Prior: "0.9.0"},
+			},
+		},
+	}}
+
+	out := &bytes.Buffer{}
+	writePlan(out, plan)
+	want := `ci:
+  + github.com/ot/new 1.0.0
+  ~ github.com/ot/bump 1.0.0 -> 1.1.0
+      number of instances; this: 1; other: 2
+  (3 unchanged)
+prod:
+  - github.com/ot/gone 0.9.0
+Plan: 1 to create, 1 to modify, 1 to delete.
+`
+	if out.String() != want {
+		t.Errorf("got plan:\n%s\nwant:\n%s", out, want)
+	}
+
+	out.Reset()
+	writePlan(out, &sous.Plan{Clusters: []sous.ClusterPlan{{Cluster: "ci", Unchanged: 2}}})
+	if want := "ci:\n  (2 unchanged)\nNo changes.\n"; out.String() != want {
+		t.Errorf("got plan %q; want %q", out, want)
+	}
+}
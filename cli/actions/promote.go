@@ -0,0 +1,129 @@
+package actions
+
+import (
+	"time"
+
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/restful"
+	"github.com/pkg/errors"
+)
+
+// Promote is the command description for `sous promote`
+type Promote struct {
+	Manifest      *sous.Manifest
+	Defs          sous.Defs
+	GDM           sous.Deployments
+	Client        restful.HTTPClient
+	Registry      sous.Registry
+	ResolveFilter *sous.ResolveFilter
+	User          sous.User
+	Log           logging.LogSink
+
+	// clusterClient returns a client for the server of the named cluster.
+	// If it is nil, the server is found in the main server's /servers list.
+	clusterClient func(cluster string) (restful.HTTPClient, error)
+}
+
+// copied from server - avoiding coupling to server implemention
+type serverListData struct {
+	Servers []struct {
+		ClusterName string
+		URL         string
+	}
+}
+
+// Do performs the promotion, returning nil on success.
+func (p *Promote) Do() error {
+	mid := p.Manifest.ID()
+	did, err := p.ResolveFilter.DeploymentID(mid)
+	if err != nil {
+		return err
+	}
+	promotion, ok := p.Defs.Promotions.Upstream(did.Cluster)
+	if !ok {
+		return errors.Errorf("no cluster promotes to %s", did.Cluster)
+	}
+	upDID := sous.DeploymentID{ManifestID: mid, Cluster: promotion.From}
+
+	upstream, ok := p.GDM.Get(upDID)
+	if !ok {
+		return errors.Errorf("%s is not deployed to %s", mid, promotion.From)
+	}
+	current, ok := p.GDM.Get(did)
+	if !ok {
+		return errors.Errorf("%s is not deployed to %s", mid, did.Cluster)
+	}
+
+	active, err := p.activeVersion(upDID)
+	if err != nil {
+		return err
+	}
+	version := upstream.SourceID.Version
+	if !active.Version.Equals(version) {
+		return errors.Errorf("version %s is not yet stable in %s", version, promotion.From)
+	}
+	if version.Equals(current.SourceID.Version) {
+		return errors.Errorf("%s is already at version %s in %s", mid, version, did.Cluster)
+	}
+	if err := promotion.Check(did.Cluster, upstream, active, time.Now()); err != nil {
+		return err
+	}
+
+	target := current.Clone()
+	target.SourceID.Version = version
+	if _, err := sous.GuardImage(p.Registry, target); err != nil {
+		return errors.Wrapf(err, "refusing to promote %s to %s", version, did.Cluster)
+	}
+
+	rf := *p.ResolveFilter
+	if err := rf.SetTag(version.String()); err != nil {
+		return err
+	}
+	update := &Update{
+		Manifest:      p.Manifest,
+		GDM:           p.GDM,
+		Client:        p.Client,
+		ResolveFilter: &rf,
+		User:          p.User,
+		Log:           p.Log,
+	}
+	return update.Do()
+}
+
+// activeVersion asks the server for did's cluster which version of did is
+// active there, and since when.
+func (p *Promote) activeVersion(did sous.DeploymentID) (sous.ActiveVersion, error) {
+	clusterClient := p.clusterClient
+	if clusterClient == nil {
+		clusterClient = p.serverFor
+	}
+	cl, err := clusterClient(did.Cluster)
+	if err != nil {
+		return sous.ActiveVersion{}, err
+	}
+	query := map[string]string{
+		"repo":    did.ManifestID.Source.Repo,
+		"offset":  did.ManifestID.Source.Dir,
+		"flavor":  did.ManifestID.Flavor,
+		"cluster": did.Cluster,
+	}
+	active := sous.ActiveVersion{}
+	if _, err := cl.Retrieve("./active", query, &active, p.User.HTTPHeaders()); err != nil {
+		return active, errors.Wrapf(err, "no version of %s is known to be active in %s", did.ManifestID, did.Cluster)
+	}
+	return active, nil
+}
+
+func (p *Promote) serverFor(cluster string) (restful.HTTPClient, error) {
+	servers := &serverListData{}
+	if _, err := p.Client.Retrieve("./servers", nil, servers, p.User.HTTPHeaders()); err != nil {
+		return nil, err
+	}
+	for _, s := range servers.Servers {
+		if s.ClusterName == cluster {
+			return restful.NewClient(s.URL, p.Log.Child("http"))
+		}
+	}
+	return nil, errors.Errorf("no server known for cluster %s", cluster)
+}
@@ -0,0 +1,64 @@
+package actions
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/restful"
+	"github.com/opentable/sous/util/restful/restfultest"
+	"github.com/samsalisbury/semv"
+)
+
+func TestPromoteRefusals(t *testing.T) {
+	mid := sous.MustParseManifestID("github.com/user/project")
+	deployment := func(cluster, version string) *sous.Deployment {
+		return &sous.Deployment{
+			SourceID:     mid.Source.SourceID(semv.MustParse(version)),
+			ClusterName:  cluster,
+			Cluster:      &sous.Cluster{Name: cluster},
+			DeployConfig: sous.DeployConfig{NumInstances: 1},
+		}
+	}
+	defs := sous.Defs{Promotions: sous.Promotions{{
+		From:  "staging",
+		To:    []string{"prod"},
+		Gates: sous.PromotionGates{SoakTime: "1h"},
+	}}}
+
+	promote := func(cluster string, gdm sous.Deployments, active sous.ActiveVersion) error {
+		cl, control := restfultest.NewHTTPClientSpy()
+		control.Any("Retrieve", active, restfultest.DummyUpdater(), nil)
+		p := &Promote{
+			Manifest:      &sous.Manifest{Source: mid.Source},
+			Defs:          defs,
+			GDM:           gdm,
+			Registry:      sous.NewDummyRegistry(),
+			ResolveFilter: &sous.ResolveFilter{Cluster: sous.NewResolveFieldMatcher(cluster)},
+			clusterClient: func(string) (restful.HTTPClient, error) { return cl, nil },
+		}
+		return p.Do()
+	}
+
+	expectError := func(err error, want string) {
+		t.Helper()
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("got error %v; want one containing %q", err, want)
+		}
+	}
+
+	gdm := sous.NewDeployments(deployment("staging", "2.0.0"), deployment("prod", "1.0.0"))
+	longAgo := time.Now().Add(-2 * time.Hour)
+
+	expectError(promote("staging", gdm, sous.ActiveVersion{}), "no cluster promotes to staging")
+	expectError(promote("prod", sous.NewDeployments(deployment("prod", "1.0.0")), sous.ActiveVersion{}),
+		"is not deployed to staging")
+	expectError(promote("prod", gdm, sous.ActiveVersion{Version: semv.MustParse("1.0.0"), Since: longAgo}),
+		"version 2.0.0 is not yet stable in staging")
+	expectError(promote("prod", gdm, sous.ActiveVersion{Version: semv.MustParse("2.0.0"), Since: time.Now()}),
+		"less than soak time 1h0m0s")
+	expectError(promote("prod", sous.NewDeployments(deployment("staging", "2.0.0"), deployment("prod", "2.0.0")),
+		sous.ActiveVersion{Version: semv.MustParse("2.0.0"), Since: longAgo}),
+		"already at version 2.0.0")
+}
@@ -0,0 +1,71 @@
+package actions
+
+import (
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/restful"
+	"github.com/pkg/errors"
+)
+
+// ResolveNow asks the server which resolves a deployment's cluster to
+// resolve it straight away, rather than in its next cycle.
+type ResolveNow struct {
+	// Client talks to the main server, which lists the server of each
+	// cluster.
+	Client       restful.HTTPClient
+	Auth         restful.AuthConfig
+	DeploymentID sous.DeploymentID
+	User         sous.User
+	Log          logging.LogSink
+}
+
+// Do starts the resolve. It doesn't wait for it to finish.
+func (r *ResolveNow) Do() error {
+	cl, err := clusterServerClient(r.Client, r.Auth, r.DeploymentID.Cluster, r.User, r.Log)
+	if err != nil {
+		return err
+	}
+	return errors.Wrapf(cl.Post("./resolve", deploymentQuery(r.DeploymentID), nil, r.User.HTTPHeaders()), "resolving %s", r.DeploymentID)
+}
+
+// clusterServerClient returns a client of the server which resolves
+// cluster, found by asking the server client talks to.
+func clusterServerClient(client restful.HTTPClient, auth restful.AuthConfig, cluster string, user sous.User, log logging.LogSink) (*restful.LiveHTTPClient, error) {
+	servers := struct {
+		Servers []struct {
+			ClusterName, URL string
+		}
+	}{}
+	if _, err := client.Retrieve("./servers", nil, &servers, user.HTTPHeaders()); err != nil {
+		return nil, errors.Wrapf(err, "listing servers")
+	}
+	url := ""
+	for _, s := range servers.Servers {
+		if s.ClusterName == cluster {
+			url = s.URL
+		}
+	}
+	if url == "" {
+		return nil, errors.Errorf("no server resolves %s", cluster)
+	}
+
+	cl, err := restful.NewClient(url, log.Child("http"))
+	if err != nil {
+		return nil, err
+	}
+	if err := cl.SetAuth(auth); err != nil {
+		return nil, err
+	}
+	return cl, nil
+}
+
+// deploymentQuery returns the query parameters which select did.
+func deploymentQuery(did sous.DeploymentID) map[string]string {
+	mid := did.ManifestID
+	return map[string]string{
+		"repo":    mid.Source.Repo,
+		"offset":  mid.Source.Dir,
+		"flavor":  mid.Flavor,
+		"cluster": did.Cluster,
+	}
+}
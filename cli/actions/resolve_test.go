@@ -0,0 +1,55 @@
+package actions
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/restful"
+)
+
+func TestResolveNow(t *testing.T) {
+	resolves := make(chan string, 1)
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		default:
+			http.NotFound(rw, r)
+		case "/servers":
+			json.NewEncoder(rw).Encode(map[string]interface{}{"Servers": []map[string]string{
+				{"ClusterName": "left", "URL": srv.URL + "/left/"},
+			}})
+		case "/left/resolve":
+			if r.Method != "POST" {
+				t.Errorf("got %s /left/resolve; want POST", r.Method)
+			}
+			resolves <- r.URL.Query().Encode()
+			rw.WriteHeader(http.StatusAccepted)
+			rw.Write([]byte("{}"))
+		}
+	}))
+	defer srv.Close()
+
+	ls := logging.SilentLogSet()
+	client, err := restful.NewClient(srv.URL, ls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	did := sous.DeploymentID{ManifestID: sous.MustParseManifestID("github.com/ot/one,api~canary"), Cluster: "left"}
+	rn := &ResolveNow{Client: client, DeploymentID: did, Log: ls}
+	if err := rn.Do(); err != nil {
+		t.Fatal(err)
+	}
+	want := "cluster=left&flavor=canary&offset=api&repo=github.com%2Fot%2Fone"
+	if got := <-resolves; got != want {
+		t.Errorf("got query %q; want %q", got, want)
+	}
+
+	rn.DeploymentID.Cluster = "right"
+	if err := rn.Do(); err == nil {
+		t.Error("want an error for a cluster no server resolves")
+	}
+}
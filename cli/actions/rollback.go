@@ -0,0 +1,104 @@
+package actions
+
+import (
+	"fmt"
+
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/restful"
+	"github.com/pkg/errors"
+	"github.com/samsalisbury/semv"
+)
+
+// Rollback is the command description for `sous rollback`
+type Rollback struct {
+	Manifest      *sous.Manifest
+	GDM           sous.Deployments
+	Client        restful.HTTPClient
+	Registry      sous.Registry
+	ResolveFilter *sous.ResolveFilter
+	// To is the version to roll back to. If it is empty, the deployment is
+	// rolled back to the version it had before its current one.
+	To         string
+	WaitStable bool
+	User       sous.User
+	Log        logging.LogSink
+}
+
+// Do performs the rollback, returning nil on success.
+func (r *Rollback) Do() error {
+	mid := r.Manifest.ID()
+	did, err := r.ResolveFilter.DeploymentID(mid)
+	if err != nil {
+		return err
+	}
+	current, ok := r.GDM.Get(did)
+	if !ok {
+		return errors.Errorf("%s is not deployed to %s", mid, did.Cluster)
+	}
+
+	version, err := r.targetVersion(did, current.SourceID.Version)
+	if err != nil {
+		return err
+	}
+	if version.Equals(current.SourceID.Version) {
+		return errors.Errorf("%s is already at version %s in %s", mid, version, did.Cluster)
+	}
+
+	target := current.Clone()
+	target.SourceID.Version = version
+	if _, err := sous.GuardImage(r.Registry, target); err != nil {
+		return errors.Wrapf(err, "refusing to roll back to %s", version)
+	}
+
+	rf := *r.ResolveFilter
+	if err := rf.SetTag(version.String()); err != nil {
+		return err
+	}
+
+	update := &Update{
+		Manifest:      r.Manifest,
+		GDM:           r.GDM,
+		Client:        r.Client,
+		ResolveFilter: &rf,
+		User:          r.User,
+		Log:           r.Log,
+	}
+	if err := update.Do(); err != nil {
+		return err
+	}
+	if !r.WaitStable {
+		return nil
+	}
+	poll := &PollStatus{StatusPoller: sous.NewStatusPoller(r.Client, &rf, r.User, r.Log)}
+	return poll.Do()
+}
+
+func (r *Rollback) targetVersion(did sous.DeploymentID, current semv.Version) (semv.Version, error) {
+	if r.To != "" {
+		return semv.Parse(r.To)
+	}
+	query := map[string]string{
+		"repo":    did.ManifestID.Source.Repo,
+		"offset":  did.ManifestID.Source.Dir,
+		"flavor":  did.ManifestID.Flavor,
+		"cluster": did.Cluster,
+	}
+	history := sous.DeploymentHistory{}
+	if _, err := r.Client.Retrieve("./history", query, &history, nil); err != nil {
+		return semv.Version{}, errors.Wrapf(err, "reading history of %s (use -to to give a version)", did)
+	}
+	return previousVersion(history, current)
+}
+
+// previousVersion returns the most recent version in history before current.
+func previousVersion(history sous.DeploymentHistory, current semv.Version) (semv.Version, error) {
+	for i := len(history) - 1; i >= 0; i-- {
+		change := history[i]
+		if change.Deleted || change.Version.Equals(current) {
+			continue
+		}
+		return change.Version, nil
+	}
+	return semv.Version{}, fmt.Errorf("no version before %s in history (use -to to give a version)", current)
+}
@@ -0,0 +1,74 @@
+package actions
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	sous "github.com/opentable/sous/lib"
+	"github.com/samsalisbury/semv"
+)
+
+func TestPreviousVersion(t *testing.T) {
+	change := func(version string, deleted bool) sous.DeploymentChange {
+		return sous.DeploymentChange{Version: semv.MustParse(version), Deleted: deleted}
+	}
+	history := sous.DeploymentHistory{
+		change("1.0.0", false),
+		change("1.1.0", false),
+		change("1.1.0", true),
+		change("1.1.0", false),
+		change("1.2.0", false),
+		change("1.2.0", false),
+	}
+
+	v, err := previousVersion(history, semv.MustParse("1.2.0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.String() != "1.1.0" {
+		t.Errorf("got previous version %s; want 1.1.0", v)
+	}
+
+	if _, err := previousVersion(history[:1], semv.MustParse("1.0.0")); err == nil {
+		t.Errorf("got no error when there was no previous version")
+	}
+}
+
+func TestRollbackRefusals(t *testing.T) {
+	mid := sous.MustParseManifestID("github.com/user/project")
+	cluster := &sous.Cluster{Name: "blah", AllowedAdvisories: []string{"ok"}}
+	gdm := sous.NewDeployments(&sous.Deployment{
+		SourceID:     mid.Source.SourceID(semv.MustParse("2.0.0")),
+		ClusterName:  "blah",
+		Cluster:      cluster,
+		DeployConfig: sous.DeployConfig{NumInstances: 1},
+	})
+
+	rollback := func(to string, artifact *sous.BuildArtifact, artifactErr error) error {
+		reg := sous.NewDummyRegistry()
+		reg.FeedArtifact(artifact, artifactErr)
+		r := &Rollback{
+			Manifest:      &sous.Manifest{Source: mid.Source},
+			GDM:           gdm,
+			Registry:      reg,
+			ResolveFilter: &sous.ResolveFilter{Cluster: sous.NewResolveFieldMatcher("blah")},
+			To:            to,
+		}
+		return r.Do()
+	}
+
+	expectError := func(err error, want string) {
+		t.Helper()
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("got error %v; want one containing %q", err, want)
+		}
+	}
+
+	expectError(rollback("2.0.0", nil, nil), "already at version")
+	expectError(rollback("1.0.0", nil, fmt.Errorf("no such image")), "refusing to roll back to 1.0.0")
+	expectError(rollback("1.0.0", &sous.BuildArtifact{
+		Name:      "docker.example.com/project:1.0.0",
+		Qualities: []sous.Quality{{Name: "dirty", Kind: "advisory"}},
+	}, nil), "refusing to roll back to 1.0.0")
+}
@@ -0,0 +1,135 @@
+package actions
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/restful"
+	"github.com/pborman/uuid"
+	"github.com/pkg/errors"
+)
+
+// DefaultRunTimeout is how long Run waits for a run to finish, unless told
+// otherwise.
+const DefaultRunTimeout = time.Hour
+
+type (
+	// Run asks the server which resolves a deployment's cluster to launch a
+	// run of it, then waits for the run to finish.
+	Run struct {
+		// Client talks to the main server, which lists the server of each
+		// cluster.
+		Client       restful.HTTPClient
+		Auth         restful.AuthConfig
+		DeploymentID sous.DeploymentID
+		Args         []string
+		// Stream copies the run's stdout and stderr to Out and Err while it
+		// runs.
+		Stream       bool
+		Out, Err     io.Writer
+		PollInterval time.Duration
+		// Timeout is how long to wait for the run to finish; if zero,
+		// DefaultRunTimeout. The run itself isn't stopped when it expires.
+		Timeout time.Duration
+		User    sous.User
+		Log     logging.LogSink
+	}
+
+	// RunFailedError reports that a run didn't succeed.
+	RunFailedError struct {
+		Status sous.RunStatus
+	}
+)
+
+// Do launches the run, and returns a *RunFailedError if it fails.
+func (r *Run) Do() error {
+	cl, err := clusterServerClient(r.Client, r.Auth, r.DeploymentID.Cluster, r.User, r.Log)
+	if err != nil {
+		return err
+	}
+	runID := uuid.New()
+	query := deploymentQuery(r.DeploymentID)
+	req := sous.RunRequest{RunID: runID, Args: r.Args}
+	if err := cl.Post("./run", query, req, r.User.HTTPHeaders()); err != nil {
+		return errors.Wrapf(err, "running %s", r.DeploymentID)
+	}
+	fmt.Fprintf(r.Err, "Started run %s of %s\n", runID, r.DeploymentID)
+
+	timeout := r.Timeout
+	if timeout == 0 {
+		timeout = DefaultRunTimeout
+	}
+	deadline := time.Now().Add(timeout)
+
+	query["run"] = runID
+	offsets := map[string]int64{}
+	for {
+		var status sous.RunStatus
+		if _, err := cl.Retrieve("./run", query, &status, r.User.HTTPHeaders()); err != nil {
+			return errors.Wrapf(err, "getting status of run %s", runID)
+		}
+		if r.Stream {
+			r.copyOutput(cl, query, offsets, status.State.Finished())
+		}
+		if status.State.Finished() {
+			if status.State == sous.RunSucceeded {
+				return nil
+			}
+			return &RunFailedError{Status: status}
+		}
+		if time.Now().After(deadline) {
+			return errors.Errorf("run %s of %s didn't finish within %s; it may still be running", runID, r.DeploymentID, timeout)
+		}
+		time.Sleep(r.PollInterval)
+	}
+}
+
+// copyOutput copies the output of the run selected by query to Out and Err,
+// from offsets on, and updates offsets. If all is true, it copies all the
+// output there is, rather than one chunk of each stream. Errors getting
+// output are logged rather than returned, since the run goes on without it.
+func (r *Run) copyOutput(cl restful.HTTPClient, query map[string]string, offsets map[string]int64, all bool) {
+	for _, stream := range []string{"stdout", "stderr"} {
+		w := r.Out
+		if stream == "stderr" {
+			w = r.Err
+		}
+		for {
+			q := map[string]string{"stream": stream}
+			for k, v := range query {
+				q[k] = v
+			}
+			q["from"] = strconv.FormatInt(offsets[stream], 10)
+			var out sous.RunOutput
+			if _, err := cl.Retrieve("./run/output", q, &out, r.User.HTTPHeaders()); err != nil {
+				logging.ReportMsg(r.Log, logging.WarningLevel, fmt.Sprintf("getting %s of run %s: %s", stream, query["run"], err))
+				break
+			}
+			io.WriteString(w, out.Data)
+			offsets[stream] = out.NextOffset
+			if !all || out.Data == "" {
+				break
+			}
+		}
+	}
+}
+
+func (e *RunFailedError) Error() string {
+	if e.Status.Message == "" {
+		return e.Status.String()
+	}
+	return fmt.Sprintf("%s: %s", e.Status.String(), e.Status.Message)
+}
+
+// ExitCode returns the exit status of the failed run, or 255 if it isn't
+// known.
+func (e *RunFailedError) ExitCode() int {
+	if e.Status.ExitCode <= 0 || e.Status.ExitCode > 255 {
+		return 255
+	}
+	return e.Status.ExitCode
+}
@@ -0,0 +1,131 @@
+package actions
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/restful"
+)
+
+// runTestServer serves a run which writes stdout and stderr over a few
+// polls, then finishes with exitCode.
+func runTestServer(t *testing.T, exitCode int) *httptest.Server {
+	var srv *httptest.Server
+	var mu sync.Mutex
+	polls := 0
+	srv = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		default:
+			http.NotFound(rw, r)
+		case "/servers":
+			json.NewEncoder(rw).Encode(map[string]interface{}{"Servers": []map[string]string{
+				{"ClusterName": "left", "URL": srv.URL + "/left/"},
+			}})
+		case "/left/run":
+			if r.Method == "POST" {
+				var rr sous.RunRequest
+				json.NewDecoder(r.Body).Decode(&rr)
+				if rr.RunID == "" || len(rr.Args) != 1 || rr.Args[0] != "migrate" {
+					t.Errorf("got run request %+v", rr)
+				}
+				rw.WriteHeader(http.StatusCreated)
+				rw.Write([]byte("{}"))
+				return
+			}
+			polls++
+			status := sous.RunStatus{RunID: r.URL.Query().Get("run"), State: sous.RunRunning}
+			if polls > 2 {
+				status.State = sous.RunSucceeded
+				if exitCode != 0 {
+					status.State = sous.RunFailed
+				}
+				status.ExitCode = exitCode
+			}
+			json.NewEncoder(rw).Encode(status)
+		case "/left/run/output":
+			if r.URL.Query().Get("offset") != "api" {
+				t.Errorf("lost the offset of the source: %s", r.URL.RawQuery)
+			}
+			from, _ := strconv.Atoi(r.URL.Query().Get("from"))
+			data := r.URL.Query().Get("stream") + "\n"
+			if from >= polls*len(data) {
+				data = ""
+			}
+			json.NewEncoder(rw).Encode(sous.RunOutput{Data: data, NextOffset: int64(from + len(data))})
+		}
+	}))
+	return srv
+}
+
+func runTestAction(t *testing.T, srv *httptest.Server, stream bool) (*Run, *bytes.Buffer, *bytes.Buffer) {
+	ls := logging.SilentLogSet()
+	client, err := restful.NewClient(srv.URL, ls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	return &Run{
+		Client:       client,
+		DeploymentID: sous.DeploymentID{ManifestID: sous.MustParseManifestID("github.com/ot/one,api"), Cluster: "left"},
+		Args:         []string{"migrate"},
+		Stream:       stream,
+		Out:          out,
+		Err:          errOut,
+		Log:          ls,
+	}, out, errOut
+}
+
+func TestRun(t *testing.T) {
+	srv := runTestServer(t, 0)
+	defer srv.Close()
+	run, out, errOut := runTestAction(t, srv, true)
+	if err := run.Do(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := out.String(), "stdout\nstdout\nstdout\n"; got != want {
+		t.Errorf("got stdout %q; want %q", got, want)
+	}
+	if !bytes.HasSuffix(errOut.Bytes(), []byte("stderr\nstderr\nstderr\n")) {
+		t.Errorf("got stderr %q", errOut)
+	}
+}
+
+func TestRun_timeout(t *testing.T) {
+	srv := runTestServer(t, 0)
+	defer srv.Close()
+	run, _, _ := runTestAction(t, srv, false)
+	run.Timeout = time.Nanosecond
+	run.PollInterval = time.Millisecond
+	err := run.Do()
+	if err == nil || !strings.Contains(err.Error(), "didn't finish within") {
+		t.Errorf("got %v; want the run to time out", err)
+	}
+}
+
+func TestRun_failed(t *testing.T) {
+	srv := runTestServer(t, 3)
+	defer srv.Close()
+	run, out, _ := runTestAction(t, srv, false)
+	err := run.Do()
+	failed, ok := err.(*RunFailedError)
+	if !ok {
+		t.Fatalf("got %v; want a *RunFailedError", err)
+	}
+	if failed.ExitCode() != 3 {
+		t.Errorf("got exit code %d; want 3", failed.ExitCode())
+	}
+	if out.Len() != 0 {
+		t.Errorf("output was streamed without -stream: %q", out)
+	}
+}
@@ -0,0 +1,150 @@
+package actions
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/restful"
+	"github.com/pkg/errors"
+)
+
+type (
+	// SecretsSet is the command description for `sous secrets set`.
+	SecretsSet struct {
+		Store        sous.SecretStore
+		StoreName    string
+		DeploymentID sous.DeploymentID
+		Key, Value   string
+		// In is read for the value if Value is empty.
+		In  io.Reader
+		Out io.Writer
+	}
+
+	// SecretsList is the command description for `sous secrets list`.
+	SecretsList struct {
+		Store        sous.SecretStore
+		StoreName    string
+		DeploymentID sous.DeploymentID
+		Out          io.Writer
+	}
+
+	// SecretsRotate is the command description for `sous secrets rotate`.
+	SecretsRotate struct {
+		Store        sous.SecretStore
+		StoreName    string
+		DeploymentID sous.DeploymentID
+		Key, Value   string
+		// In is read for the value if Value is empty.
+		In     io.Reader
+		Client restful.HTTPClient
+		User   sous.User
+		Out    io.Writer
+	}
+)
+
+// Do sets the secret, and prints the env var referring to it.
+func (s *SecretsSet) Do() error {
+	value, err := secretValue(s.Value, s.In)
+	if err != nil {
+		return err
+	}
+	ref := secretRef(s.StoreName, s.DeploymentID, s.Key)
+	if err := s.Store.SetSecret(ref.Path, ref.Key, value); err != nil {
+		return errors.Wrapf(err, "setting %s", ref)
+	}
+	fmt.Fprintf(s.Out, "%s=%s\n", s.Key, ref)
+	return nil
+}
+
+// Do prints the env vars referring to each of the deployment's secrets. It
+// never prints their values.
+func (s *SecretsList) Do() error {
+	path := sous.SecretPath(s.DeploymentID)
+	keys, err := s.Store.SecretKeys(path)
+	if err != nil {
+		return errors.Wrapf(err, "listing secrets of %s", s.DeploymentID)
+	}
+	for _, k := range keys {
+		fmt.Fprintf(s.Out, "%s=%s\n", k, secretRef(s.StoreName, s.DeploymentID, k))
+	}
+	return nil
+}
+
+// Do changes the value of an existing secret, and bumps the SecretsVersion
+// of the deployment so that the server redeploys it with the new value.
+func (s *SecretsRotate) Do() error {
+	value, err := secretValue(s.Value, s.In)
+	if err != nil {
+		return err
+	}
+	ref := secretRef(s.StoreName, s.DeploymentID, s.Key)
+	keys, err := s.Store.SecretKeys(ref.Path)
+	if err != nil {
+		return errors.Wrapf(err, "listing secrets of %s", s.DeploymentID)
+	}
+	if !containsString(keys, s.Key) {
+		return errors.Errorf("%s has no secret %s to rotate (use sous secrets set)", s.DeploymentID, s.Key)
+	}
+
+	mid := s.DeploymentID.ManifestID
+	query := map[string]string{
+		"repo":   mid.Source.Repo,
+		"offset": mid.Source.Dir,
+		"flavor": mid.Flavor,
+	}
+	mani := sous.Manifest{}
+	up, err := s.Client.Retrieve("/manifest", query, &mani, nil)
+	if err != nil {
+		return errors.Wrapf(err, "reading manifest %s", mid)
+	}
+	spec, ok := mani.Deployments[s.DeploymentID.Cluster]
+	if !ok {
+		return errors.Errorf("%s is not deployed to %s", mid, s.DeploymentID.Cluster)
+	}
+
+	if err := s.Store.SetSecret(ref.Path, ref.Key, value); err != nil {
+		return errors.Wrapf(err, "setting %s", ref)
+	}
+
+	spec.SecretsVersion++
+	mani.Deployments[s.DeploymentID.Cluster] = spec
+	if err := up.Update(&mani, s.User.HTTPHeaders()); err != nil {
+		return errors.Wrapf(err, "%s was rotated, but %s will not be redeployed", ref, s.DeploymentID)
+	}
+	fmt.Fprintf(s.Out, "Rotated %s; %s will be redeployed with secrets version %d.\n",
+		s.Key, s.DeploymentID, spec.SecretsVersion)
+	return nil
+}
+
+// secretValue returns value, or if it is empty, what is read from in, less
+// its trailing newline, so that secrets need not appear in shell history.
+func secretValue(value string, in io.Reader) (string, error) {
+	if value != "" || in == nil {
+		return value, nil
+	}
+	b, err := ioutil.ReadAll(in)
+	if err != nil {
+		return "", errors.Wrapf(err, "reading secret value")
+	}
+	value = strings.TrimSuffix(strings.TrimSuffix(string(b), "\n"), "\r")
+	if value == "" {
+		return "", errors.New("no secret value given")
+	}
+	return value, nil
+}
+
+func secretRef(store string, did sous.DeploymentID, key string) sous.SecretRef {
+	return sous.SecretRef{Store: store, Path: sous.SecretPath(did), Key: key}
+}
+
+func containsString(ss []string, s string) bool {
+	for _, x := range ss {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
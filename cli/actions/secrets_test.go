@@ -0,0 +1,106 @@
+package actions
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/opentable/sous/ext/secrets"
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/restful/restfultest"
+	"github.com/samsalisbury/semv"
+)
+
+func secretsTestStore(t *testing.T) (*secrets.FileStore, func()) {
+	dir, err := ioutil.TempDir("", "sous-secrets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return secrets.NewFileStore(dir), func() { os.RemoveAll(dir) }
+}
+
+func TestSecretsSetAndList(t *testing.T) {
+	store, cleanup := secretsTestStore(t)
+	defer cleanup()
+	did := sous.DeploymentID{ManifestID: sous.MustParseManifestID("github.com/user/project"), Cluster: "ci"}
+
+	out := &bytes.Buffer{}
+	set := &SecretsSet{Store: store, StoreName: "file", DeploymentID: did, Key: "TOKEN", In: strings.NewReader("hunter2\n"), Out: out}
+	if err := set.Do(); err != nil {
+		t.Fatal(err)
+	}
+	want := "TOKEN=secret://file/sous/github.com/user/project/ci#TOKEN\n"
+	if out.String() != want {
+		t.Errorf("set printed %q; want %q", out.String(), want)
+	}
+	v, err := store.ResolveSecret(sous.SecretRef{Store: "file", Path: sous.SecretPath(did), Key: "TOKEN"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "hunter2" {
+		t.Errorf("got secret %q; want %q (read from stdin)", v, "hunter2")
+	}
+
+	out.Reset()
+	list := &SecretsList{Store: store, StoreName: "file", DeploymentID: did, Out: out}
+	if err := list.Do(); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != want {
+		t.Errorf("list printed %q; want %q", out.String(), want)
+	}
+	if strings.Contains(out.String(), "hunter2") {
+		t.Errorf("list printed a secret value")
+	}
+}
+
+func TestSecretsRotate(t *testing.T) {
+	store, cleanup := secretsTestStore(t)
+	defer cleanup()
+	mid := sous.MustParseManifestID("github.com/user/project")
+	did := sous.DeploymentID{ManifestID: mid, Cluster: "ci"}
+
+	client, control := restfultest.NewHTTPClientSpy()
+	updater, upControl := restfultest.NewUpdateSpy()
+	upControl.Any("Update", nil)
+	control.Any("Retrieve", &sous.Manifest{
+		Source: mid.Source,
+		Deployments: sous.DeploySpecs{
+			"ci": {Version: semv.MustParse("1.0.0"), DeployConfig: sous.DeployConfig{NumInstances: 1, SecretsVersion: 2}},
+		},
+	}, updater, nil)
+
+	rotate := &SecretsRotate{Store: store, StoreName: "file", DeploymentID: did, Key: "TOKEN", Value: "new", Client: client, Out: ioutil.Discard}
+	if err := rotate.Do(); err == nil {
+		t.Errorf("rotated a secret which was never set")
+	}
+
+	if err := store.SetSecret(sous.SecretPath(did), "TOKEN", "old"); err != nil {
+		t.Fatal(err)
+	}
+	if err := rotate.Do(); err != nil {
+		t.Fatal(err)
+	}
+	v, _ := store.ResolveSecret(sous.SecretRef{Store: "file", Path: sous.SecretPath(did), Key: "TOKEN"})
+	if v != "new" {
+		t.Errorf("got secret %q; want %q", v, "new")
+	}
+	updates := upControl.CallsTo("Update")
+	if len(updates) != 1 {
+		t.Fatalf("got %d manifest updates; want 1", len(updates))
+	}
+	mani := updates[0].PassedArgs().Get(0).(*sous.Manifest)
+	if got := mani.Deployments["ci"].SecretsVersion; got != 3 {
+		t.Errorf("got SecretsVersion %d; want 3", got)
+	}
+
+	rotate.DeploymentID.Cluster = "prod"
+	if err := store.SetSecret(sous.SecretPath(rotate.DeploymentID), "TOKEN", "old"); err != nil {
+		t.Fatal(err)
+	}
+	if err := rotate.Do(); err == nil {
+		t.Errorf("rotated a secret of a deployment not in the manifest")
+	}
+}
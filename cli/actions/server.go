@@ -28,6 +28,9 @@ type Server struct {
 	*config.Config
 	ServerHandler http.Handler
 	*sous.AutoResolver
+	// Leadership, if not nil, runs the AutoResolver only while this server
+	// leads its cluster.
+	Leadership *sous.Leadership
 }
 
 // Do runs the server.
@@ -38,10 +41,21 @@ func (ss *Server) Do() error {
 
 	reportServerMessage("Starting scheduled GDM resolution.  Filtering the GDM to resolve on this server", ss.DeployFilterFlags, ss.ListenAddr, ss.Log)
 
-	ss.AutoResolver.Kickoff()
+	if ss.Leadership != nil {
+		go ss.Leadership.Run(ss.AutoResolver, nil)
+	} else {
+		ss.AutoResolver.Kickoff()
+	}
 
 	reportServerMessage("Sous Server Running", ss.DeployFilterFlags, ss.ListenAddr, ss.Log)
 
+	tc, err := ss.Config.Auth.ServerTLSConfig()
+	if err != nil {
+		return err
+	}
+	if tc != nil {
+		return server.RunTLS(ss.ListenAddr, ss.ServerHandler, tc)
+	}
 	return server.Run(ss.ListenAddr, ss.ServerHandler)
 }
 
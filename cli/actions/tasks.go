@@ -0,0 +1,109 @@
+package actions
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	sous "github.com/opentable/sous/lib"
+	"github.com/pkg/errors"
+)
+
+type (
+	// Tasks lists the running tasks of a deployment.
+	Tasks struct {
+		Inspector  sous.TaskInspector
+		Deployment *sous.Deployment
+		Out        io.Writer
+	}
+
+	// Logs copies a file from the sandbox of a running task of a deployment.
+	Logs struct {
+		Inspector  sous.TaskInspector
+		Deployment *sous.Deployment
+		// TaskID is the task to read from. If empty, the deployment must
+		// have exactly one running task, which is read from.
+		TaskID string
+		// File is the path of the file in the task's sandbox.
+		File string
+		// Follow keeps copying what is written to the file, until reading it
+		// fails.
+		Follow       bool
+		PollInterval time.Duration
+		Out          io.Writer
+	}
+)
+
+// Do prints a line for each running task.
+func (t *Tasks) Do() error {
+	tasks, err := t.Inspector.Tasks(t.Deployment)
+	if err != nil {
+		return err
+	}
+	if len(tasks) == 0 {
+		fmt.Fprintf(t.Out, "No tasks of %s are running.\n", t.Deployment.ID())
+		return nil
+	}
+	w := &tabwriter.Writer{}
+	w.Init(t.Out, 2, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "TASK\tHOST\tPORTS\tSTARTED\tHEALTH")
+	for _, task := range tasks {
+		ports := make([]string, len(task.Ports))
+		for i, p := range task.Ports {
+			ports[i] = strconv.Itoa(p)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", task.TaskID, task.Host,
+			strings.Join(ports, ","), task.Started.Format(time.RFC3339), task.Health)
+	}
+	return w.Flush()
+}
+
+// Do copies the file to Out.
+func (l *Logs) Do() error {
+	taskID, err := l.task()
+	if err != nil {
+		return err
+	}
+	var offset int64
+	for {
+		chunk, err := l.Inspector.ReadTaskFile(l.Deployment, taskID, l.File, offset)
+		if err != nil {
+			return err
+		}
+		io.WriteString(l.Out, chunk.Data)
+		offset = chunk.NextOffset
+		if chunk.Data != "" {
+			continue
+		}
+		if !l.Follow {
+			return nil
+		}
+		time.Sleep(l.PollInterval)
+	}
+}
+
+// task returns the ID of the task to read from.
+func (l *Logs) task() (string, error) {
+	if l.TaskID != "" {
+		return l.TaskID, nil
+	}
+	tasks, err := l.Inspector.Tasks(l.Deployment)
+	if err != nil {
+		return "", err
+	}
+	switch len(tasks) {
+	case 0:
+		return "", errors.Errorf("no tasks of %s are running", l.Deployment.ID())
+	case 1:
+		return tasks[0].TaskID, nil
+	}
+	ids := make([]string, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.TaskID
+	}
+	return "", errors.Errorf("%d tasks of %s are running, pick one with -task: %s",
+		len(tasks), l.Deployment.ID(), strings.Join(ids, ", "))
+}
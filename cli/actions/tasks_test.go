@@ -0,0 +1,115 @@
+package actions
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	sous "github.com/opentable/sous/lib"
+)
+
+// fakeInspector serves the file of each of its tasks in chunks of one line,
+// and fails once its lines run out and more than one empty read has been
+// made.
+type fakeInspector struct {
+	tasks      []sous.TaskInfo
+	lines      []string
+	emptyReads int
+}
+
+func (fi *fakeInspector) Tasks(d *sous.Deployment) ([]sous.TaskInfo, error) {
+	return fi.tasks, nil
+}
+
+func (fi *fakeInspector) ReadTaskFile(d *sous.Deployment, taskID, path string, offset int64) (*sous.FileChunk, error) {
+	if taskID != "task-1" || path != "stdout" {
+		return nil, errors.New("no such file")
+	}
+	i := int(offset)
+	if i >= len(fi.lines) {
+		fi.emptyReads++
+		if fi.emptyReads > 1 {
+			return nil, errors.New("task ended")
+		}
+		return &sous.FileChunk{NextOffset: offset}, nil
+	}
+	return &sous.FileChunk{Data: fi.lines[i], NextOffset: offset + 1}, nil
+}
+
+func tasksTestDeployment() *sous.Deployment {
+	return &sous.Deployment{
+		ClusterName: "left",
+		SourceID:    sous.MustNewSourceID("github.com/ot/app", "", "1.0.0"),
+	}
+}
+
+func TestTasks(t *testing.T) {
+	out := &bytes.Buffer{}
+	tasks := &Tasks{
+		Inspector: &fakeInspector{tasks: []sous.TaskInfo{{
+			TaskAddress: sous.TaskAddress{TaskID: "task-1", Host: "host-1", Ports: []int{31000, 31001}},
+			Started:     time.Date(2017, 6, 1, 12, 0, 0, 0, time.UTC),
+			Health:      sous.TaskHealthy,
+		}}},
+		Deployment: tasksTestDeployment(),
+		Out:        out,
+	}
+	if err := tasks.Do(); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %q; want a header and one task", out)
+	}
+	if got := strings.Fields(lines[1]); strings.Join(got, " ") != "task-1 host-1 31000,31001 2017-06-01T12:00:00Z healthy" {
+		t.Errorf("got task line %q", lines[1])
+	}
+}
+
+func TestLogs(t *testing.T) {
+	out := &bytes.Buffer{}
+	logs := &Logs{
+		Inspector: &fakeInspector{
+			tasks: []sous.TaskInfo{{TaskAddress: sous.TaskAddress{TaskID: "task-1"}}},
+			lines: []string{"one\n", "two\n"},
+		},
+		Deployment: tasksTestDeployment(),
+		File:       "stdout",
+		Out:        out,
+	}
+	if err := logs.Do(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := out.String(), "one\ntwo\n"; got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+
+	out.Reset()
+	logs.Inspector = &fakeInspector{lines: []string{"one\n"}}
+	logs.TaskID = "task-1"
+	logs.Follow = true
+	if err := logs.Do(); err == nil || err.Error() != "task ended" {
+		t.Errorf("got %v following a task until it ended", err)
+	}
+	if got, want := out.String(), "one\n"; got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+func TestLogs_severalTasks(t *testing.T) {
+	logs := &Logs{
+		Inspector: &fakeInspector{tasks: []sous.TaskInfo{
+			{TaskAddress: sous.TaskAddress{TaskID: "task-1"}},
+			{TaskAddress: sous.TaskAddress{TaskID: "task-2"}},
+		}},
+		Deployment: tasksTestDeployment(),
+		File:       "stdout",
+		Out:        &bytes.Buffer{},
+	}
+	err := logs.Do()
+	if err == nil || !strings.Contains(err.Error(), "task-1, task-2") {
+		t.Errorf("got %v; want an error listing the tasks", err)
+	}
+}
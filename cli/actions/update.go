@@ -18,6 +18,9 @@ type Update struct {
 	ResolveFilter *sous.ResolveFilter
 	User          sous.User
 	Log           logging.LogSink
+	// FreezeOverride, if not empty, is the reason to update the deployment
+	// despite a freeze of its cluster.
+	FreezeOverride string
 }
 
 // Do performs the appropriate update, returning nil on success.
@@ -33,7 +36,7 @@ func (u *Update) Do() error {
 		return err
 	}
 
-	gdm, err := updateRetryLoop(u.Log, u.Client, sid, did, u.User)
+	gdm, err := updateRetryLoop(u.Log, u.Client, sid, did, u.User, u.FreezeOverride)
 	if err != nil {
 		return err
 	}
@@ -55,8 +58,10 @@ func updateRetryLoop(ls logging.LogSink,
 	cl restful.HTTPClient,
 	sid sous.SourceID,
 	did sous.DeploymentID,
-	user sous.User) (sous.Deployments, error) {
+	user sous.User,
+	freezeOverride string) (sous.Deployments, error) {
 	sm := sous.NewHTTPStateManager(cl)
+	sm.FreezeOverride = freezeOverride
 
 	tryLimit := 2
 
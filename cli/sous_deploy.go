@@ -19,6 +19,7 @@ type SousDeploy struct {
 	OTPLFlags         config.OTPLFlags         `inject:"optional"`
 	dryrunOption      string
 	waitStable        bool
+	freezeOverride    string
 }
 
 func init() { TopLevelCommands["deploy"] = &SousDeploy{} }
@@ -44,12 +45,14 @@ func (sd *SousDeploy) AddFlags(fs *flag.FlagSet) {
 	fs.StringVar(&sd.dryrunOption, "dry-run", "none",
 		"prevent rectify from actually changing things - "+
 			"values are none,scheduler,registry,both")
+	fs.StringVar(&sd.freezeOverride, "override-freeze", "",
+		"the reason to deploy despite a freeze of the cluster (only for the freeze's overriders)")
 }
 
 // Execute fulfills the cmdr.Executor interface.
 func (sd *SousDeploy) Execute(args []string) cmdr.Result {
 	//func GetUpdate(di injector, dff config.DeployFilterFlags, otpl config.OTPLFlags) Action {
-	update, err := sd.SousGraph.GetUpdate(sd.DeployFilterFlags, sd.OTPLFlags)
+	update, err := sd.SousGraph.GetUpdate(sd.DeployFilterFlags, sd.OTPLFlags, sd.freezeOverride)
 	if err != nil {
 		return cmdr.EnsureErrorResult(err)
 	}
@@ -69,6 +72,16 @@ func (sd *SousDeploy) Execute(args []string) cmdr.Result {
 		return cmdr.Success("Successfully rectified")
 	}
 
+	// Ask for the deployment to be made now, rather than in the server's
+	// next cycle. Older servers don't support this, and will make it then.
+	resolve, err := sd.SousGraph.GetResolveNow(sd.DeployFilterFlags)
+	if err == nil {
+		err = resolve.Do()
+	}
+	if err != nil {
+		fmt.Fprintf(sd.CLI.Err, "Deploying in the server's next cycle: %s\n", err)
+	}
+
 	if sd.waitStable {
 		fmt.Fprintf(sd.CLI.Out, "Waiting for server to report that deploy has stabilized...\n")
 
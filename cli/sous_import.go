@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"flag"
+	"strings"
+
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/util/cmdr"
+)
+
+// SousImport is the command description for `sous import`.
+type SousImport struct {
+	SousGraph  *graph.SousGraph
+	cluster    string
+	requestIDs stringsFlag
+	yes        bool
+}
+
+// stringsFlag is a flag which may be given more than once.
+type stringsFlag []string
+
+func (sf *stringsFlag) String() string { return strings.Join(*sf, ",") }
+
+func (sf *stringsFlag) Set(v string) error {
+	*sf = append(*sf, v)
+	return nil
+}
+
+func init() { TopLevelCommands["import"] = &SousImport{} }
+
+const sousImportHelp = `adopts Singularity requests not deployed by Sous
+
+usage: sous import -cluster <name> [-request-id <id>]... [-yes]
+
+sous import finds the requests in the named cluster which Sous does not
+manage, or just those given by -request-id, and works out the manifest which
+would describe each one. It shows you each manifest, and if you confirm it,
+writes the manifest, and redeploys the request with Sous's labels so that Sous
+manages it from then on. The redeploy runs the same image and configuration,
+but Singularity replaces the request's tasks to make it.
+`
+
+// Help returns the help string for this command.
+func (si *SousImport) Help() string { return sousImportHelp }
+
+// AddFlags adds the flags for sous import.
+func (si *SousImport) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&si.cluster, "cluster", "", "the cluster to import requests from")
+	fs.Var(&si.requestIDs, "request-id", "a request to import (may be given more than once; defaults to all unmanaged requests)")
+	fs.BoolVar(&si.yes, "yes", false, "import without asking for confirmation")
+}
+
+// Execute fulfills the cmdr.Executor interface.
+func (si *SousImport) Execute(args []string) cmdr.Result {
+	if si.cluster == "" {
+		return cmdr.UsageErrorf("-cluster is required")
+	}
+	imp, err := si.SousGraph.GetImport(si.cluster, si.requestIDs, si.yes)
+	if err != nil {
+		return cmdr.EnsureErrorResult(err)
+	}
+	if err := imp.Do(); err != nil {
+		return cmdr.EnsureErrorResult(err)
+	}
+	return cmdr.Success()
+}
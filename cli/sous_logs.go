@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"flag"
+
+	"github.com/opentable/sous/config"
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/util/cmdr"
+)
+
+// SousLogs is the command description for `sous logs`.
+type SousLogs struct {
+	SousGraph         *graph.SousGraph
+	DeployFilterFlags config.DeployFilterFlags `inject:"optional"`
+	task, file        string
+	follow            bool
+}
+
+func init() { TopLevelCommands["logs"] = &SousLogs{} }
+
+const sousLogsHelp = `prints the logs of a running task of a deployment
+
+usage: sous logs -cluster <name> [-task <id>] [-file <path>] [-follow]
+
+sous logs prints a file from the sandbox of a running task of the current
+deployment of this application in the named cluster: stdout, unless -file
+names another. If more than one task is running, pick one with -task; sous
+tasks lists them. With -follow, sous logs keeps printing what the task
+writes to the file until it is interrupted.
+`
+
+// Help returns the help string for this command.
+func (sl *SousLogs) Help() string { return sousLogsHelp }
+
+// AddFlags adds the flags for sous logs.
+func (sl *SousLogs) AddFlags(fs *flag.FlagSet) {
+	MustAddFlags(fs, &sl.DeployFilterFlags, DeployFilterFlagsHelp)
+	fs.StringVar(&sl.task, "task", "", "the task to print the logs of (needed if more than one is running)")
+	fs.StringVar(&sl.file, "file", "stdout", "the file in the task's sandbox to print")
+	fs.BoolVar(&sl.follow, "follow", false, "keep printing what is written to the file")
+}
+
+// Execute fulfills the cmdr.Executor interface.
+func (sl *SousLogs) Execute(args []string) cmdr.Result {
+	if sl.DeployFilterFlags.Cluster == "" {
+		return cmdr.UsageErrorf("-cluster is required")
+	}
+	logs, err := sl.SousGraph.GetLogs(sl.DeployFilterFlags, sl.task, sl.file, sl.follow)
+	if err != nil {
+		return cmdr.EnsureErrorResult(err)
+	}
+	if err := logs.Do(); err != nil {
+		return cmdr.EnsureErrorResult(err)
+	}
+	return cmdr.Success()
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"flag"
+
+	"github.com/opentable/sous/config"
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/util/cmdr"
+)
+
+// SousPlan is the command description for `sous plan`.
+type SousPlan struct {
+	SousGraph         *graph.SousGraph
+	DeployFilterFlags config.DeployFilterFlags `inject:"optional"`
+	json              bool
+}
+
+func init() { TopLevelCommands["plan"] = &SousPlan{} }
+
+const sousPlanHelp = `preview the changes a rectification would make
+
+usage: sous plan [-repo <repo>] [-offset <offset>] [-flavor <flavor>] [-cluster <name>] [-json]
+
+sous plan compares the deployments running in each cluster with the GDM, and
+lists, per cluster, each deployment that would be created (+), modified (~),
+with the differences, or deleted (-). Nothing is changed.
+
+Without filters, every deployment is considered. If a server is configured,
+it makes the plan; otherwise the clusters are queried directly.
+`
+
+// Help returns the help string for this command.
+func (*SousPlan) Help() string { return sousPlanHelp }
+
+// AddFlags adds the flags for sous plan.
+func (sp *SousPlan) AddFlags(fs *flag.FlagSet) {
+	MustAddFlags(fs, &sp.DeployFilterFlags, RectifyFilterFlagsHelp,
+		map[string]interface{}{"offset": "*", "flavor": "*"})
+
+	fs.BoolVar(&sp.json, "json", false, "print the plan as JSON")
+}
+
+// Execute fulfills the cmdr.Executor interface.
+func (sp *SousPlan) Execute(args []string) cmdr.Result {
+	plan, err := sp.SousGraph.GetPlan(sp.DeployFilterFlags, sp.json)
+	if err != nil {
+		return cmdr.EnsureErrorResult(err)
+	}
+	if err := plan.Do(); err != nil {
+		return cmdr.EnsureErrorResult(err)
+	}
+	return cmdr.Success()
+}
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/opentable/sous/graph"
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/cmdr"
+	"github.com/pkg/errors"
+)
+
+// SousPlumbingDeletionBrake is the description of the `sous plumbing
+// deletion-brake` command.
+type SousPlumbingDeletionBrake struct {
+	graph.HTTPClient
+	User sous.User
+	ack  string
+}
+
+func init() { PlumbingSubcommands["deletion-brake"] = &SousPlumbingDeletionBrake{} }
+
+// Help prints the help
+func (*SousPlumbingDeletionBrake) Help() string {
+	return `Shows, and acknowledges, deletions held by the server's deletion brake.
+
+usage: sous plumbing deletion-brake [-ack <id>]
+
+When a resolve cycle would delete, or scale to zero, more deployments than
+the server's DeletionBrake config allows, the server halts the cycle and holds
+the deletions. Check that they are intended, then acknowledge them by their
+ID with -ack, and the next cycle will make them.
+`
+}
+
+// AddFlags adds the flags for sous plumbing deletion-brake.
+func (spb *SousPlumbingDeletionBrake) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&spb.ack, "ack", "", "the ID of the held deletions to acknowledge")
+}
+
+// Execute defines the behavior of `sous plumbing deletion-brake`
+func (spb *SousPlumbingDeletionBrake) Execute(args []string) cmdr.Result {
+	if spb.ack != "" {
+		up, err := spb.HTTPClient.Retrieve("./deletion-brake", map[string]string{"id": spb.ack}, &sous.DeletionBrakeStatus{}, nil)
+		if err != nil {
+			return EnsureErrorResult(err)
+		}
+		if err := up.Delete(spb.User.HTTPHeaders()); err != nil {
+			return EnsureErrorResult(errors.Wrapf(err, "acknowledging deletions %s", spb.ack))
+		}
+		return cmdr.Successf("Acknowledged deletions %s; they will be made in the next resolve cycle.", spb.ack)
+	}
+
+	status := sous.DeletionBrakeStatus{}
+	if _, err := spb.HTTPClient.Retrieve("./deletion-brake", nil, &status, nil); err != nil {
+		return EnsureErrorResult(err)
+	}
+	out := &bytes.Buffer{}
+	if status.Held == nil {
+		fmt.Fprintln(out, "No deletions are held.")
+		return cmdr.SuccessData(out.Bytes())
+	}
+	held := status.Held
+	fmt.Fprintf(out, "Deletions %s held since %s: %s\n", held.ID, held.Since.Format(time.RFC3339), held.Reason)
+	for _, d := range held.Deletions {
+		what := "delete"
+		if d.Action == sous.PlanModify {
+			what = "scale to zero"
+		}
+		fmt.Fprintf(out, "  %s %s\n", what, d.DeploymentID)
+	}
+	fmt.Fprintf(out, "Acknowledge them with: sous plumbing deletion-brake -ack %s\n", held.ID)
+	return cmdr.SuccessData(out.Bytes())
+}
@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"flag"
+
+	"github.com/opentable/sous/config"
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/util/cmdr"
+)
+
+// SousPromote is the command description for `sous promote`.
+type SousPromote struct {
+	SousGraph         *graph.SousGraph
+	DeployFilterFlags config.DeployFilterFlags `inject:"optional"`
+}
+
+func init() { TopLevelCommands["promote"] = &SousPromote{} }
+
+const sousPromoteHelp = `promotes the version stable upstream into a particular cluster
+
+usage: sous promote -cluster <name>
+
+sous promote finds the cluster which promotes to the named cluster, according
+to the Promotions in the GDM's defs, and deploys the version which is stable
+there into the named cluster. It refuses unless the version has been active
+upstream for the promotion's soak time, and the upstream deployment has all
+of the promotion's required metadata.
+`
+
+// Help returns the help string for this command.
+func (sp *SousPromote) Help() string { return sousPromoteHelp }
+
+// AddFlags adds the flags for sous promote.
+func (sp *SousPromote) AddFlags(fs *flag.FlagSet) {
+	MustAddFlags(fs, &sp.DeployFilterFlags, MetadataFilterFlagsHelp)
+}
+
+// Execute fulfills the cmdr.Executor interface.
+func (sp *SousPromote) Execute(args []string) cmdr.Result {
+	promote, err := sp.SousGraph.GetPromote(sp.DeployFilterFlags)
+	if err != nil {
+		return cmdr.EnsureErrorResult(err)
+	}
+	if err := promote.Do(); err != nil {
+		return cmdr.EnsureErrorResult(err)
+	}
+	return cmdr.Success("Promoted.")
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/opentable/sous/config"
+	"github.com/opentable/sous/graph"
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/cmdr"
+	"github.com/pkg/errors"
+)
+
+// SousQueryHistory is the description of the `sous query history` command
+type SousQueryHistory struct {
+	config.DeployFilterFlags `inject:"optional"`
+	graph.TargetManifestID
+	graph.HTTPClient
+}
+
+func init() { QuerySubcommands["history"] = &SousQueryHistory{} }
+
+const sousQueryHistoryHelp = `The history of changes to a deployment in one cluster.
+
+Lists each version the deployment was set to, who changed it and when, and
+how its configuration changed. The -cluster flag is required.
+`
+
+// Help prints the help
+func (*SousQueryHistory) Help() string { return sousQueryHistoryHelp }
+
+// AddFlags adds the flags for sous query history.
+func (sqh *SousQueryHistory) AddFlags(fs *flag.FlagSet) {
+	MustAddFlags(fs, &sqh.DeployFilterFlags, MetadataFilterFlagsHelp)
+}
+
+// RegisterOn adds the filter flags to the psyringe, to select the deployment.
+func (sqh *SousQueryHistory) RegisterOn(psy Addable) {
+	psy.Add(graph.DryrunNeither)
+	psy.Add(&sqh.DeployFilterFlags)
+}
+
+// Execute defines the behavior of `sous query history`
+func (sqh *SousQueryHistory) Execute(args []string) cmdr.Result {
+	if sqh.DeployFilterFlags.Cluster == "" {
+		return cmdr.UsageErrorf("-cluster is required")
+	}
+	query := sqh.TargetManifestID.QueryMap()
+	query["cluster"] = sqh.DeployFilterFlags.Cluster
+
+	history := sous.DeploymentHistory{}
+	if _, err := sqh.HTTPClient.Retrieve("./history", query, &history, nil); err != nil {
+		return EnsureErrorResult(errors.Wrapf(err, "no history for %s in %s", sous.ManifestID(sqh.TargetManifestID), sqh.DeployFilterFlags.Cluster))
+	}
+
+	out := &bytes.Buffer{}
+	w := &tabwriter.Writer{}
+	w.Init(out, 2, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tUSER\tVERSION\tCHANGES")
+	for _, change := range history {
+		changes := "created"
+		switch {
+		case change.Deleted:
+			changes = "deleted"
+		case len(change.Diffs) != 0:
+			changes = strings.Join(change.Diffs, "; ")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", change.Time.Format(time.RFC3339), change.User, change.Version, changes)
+	}
+	w.Flush()
+
+	return cmdr.SuccessData(out.Bytes())
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"flag"
+
+	"github.com/opentable/sous/config"
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/util/cmdr"
+)
+
+// SousRollback is the command description for `sous rollback`.
+type SousRollback struct {
+	SousGraph         *graph.SousGraph
+	DeployFilterFlags config.DeployFilterFlags `inject:"optional"`
+	to                string
+	waitStable        bool
+}
+
+func init() { TopLevelCommands["rollback"] = &SousRollback{} }
+
+const sousRollbackHelp = `rolls a deployment in a particular cluster back to an earlier version
+
+usage: sous rollback -cluster <name> [-to <semver>]
+
+sous rollback will deploy the version this application had in the named
+cluster before its current one, or the version given by -to. It refuses to
+roll back to a version with no known artifact, or whose artifact has
+advisories the cluster doesn't allow.
+`
+
+// Help returns the help string for this command.
+func (sr *SousRollback) Help() string { return sousRollbackHelp }
+
+// AddFlags adds the flags for sous rollback.
+func (sr *SousRollback) AddFlags(fs *flag.FlagSet) {
+	MustAddFlags(fs, &sr.DeployFilterFlags, MetadataFilterFlagsHelp)
+
+	fs.StringVar(&sr.to, "to", "",
+		"the version to roll back to (defaults to the version before the current one)")
+	fs.BoolVar(&sr.waitStable, "wait-stable", true,
+		"wait for the rollback to complete before returning (otherwise, use --wait-stable=false)")
+}
+
+// Execute fulfills the cmdr.Executor interface.
+func (sr *SousRollback) Execute(args []string) cmdr.Result {
+	rollback, err := sr.SousGraph.GetRollback(sr.DeployFilterFlags, sr.to, sr.waitStable)
+	if err != nil {
+		return cmdr.EnsureErrorResult(err)
+	}
+	if err := rollback.Do(); err != nil {
+		return cmdr.EnsureErrorResult(err)
+	}
+	if !sr.waitStable {
+		return cmdr.Successf("Rollback in process.")
+	}
+	return cmdr.Success("Rolled back.")
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"flag"
+	"time"
+
+	"github.com/opentable/sous/cli/actions"
+	"github.com/opentable/sous/config"
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/util/cmdr"
+	"github.com/pkg/errors"
+)
+
+// SousRun is the command description for `sous run`.
+type SousRun struct {
+	SousGraph         *graph.SousGraph
+	DeployFilterFlags config.DeployFilterFlags `inject:"optional"`
+	stream            bool
+	timeout           time.Duration
+}
+
+func init() { TopLevelCommands["run"] = &SousRun{} }
+
+const sousRunHelp = `runs an on-demand or one-off deployment
+
+usage: sous run -cluster <name> [-stream] [-timeout <duration>] [-- args...]
+
+sous run asks the named cluster to launch a run of the current deployment of
+this application, which must be of kind on-demand or once, passing it any
+arguments given after --. It waits for the run to finish, and exits with the
+run's exit status. With -stream, the run's stdout and stderr are copied to
+sous's own while it runs. If the run hasn't finished within -timeout (an hour
+by default), sous run gives up waiting for it, but the run goes on.
+`
+
+// Help returns the help string for this command.
+func (sr *SousRun) Help() string { return sousRunHelp }
+
+// AddFlags adds the flags for sous run.
+func (sr *SousRun) AddFlags(fs *flag.FlagSet) {
+	MustAddFlags(fs, &sr.DeployFilterFlags, DeployFilterFlagsHelp)
+	fs.BoolVar(&sr.stream, "stream", false, "copy the run's stdout and stderr while it runs")
+	fs.DurationVar(&sr.timeout, "timeout", actions.DefaultRunTimeout, "how long to wait for the run to finish")
+}
+
+// Execute fulfills the cmdr.Executor interface.
+func (sr *SousRun) Execute(args []string) cmdr.Result {
+	if sr.DeployFilterFlags.Cluster == "" {
+		return cmdr.UsageErrorf("-cluster is required")
+	}
+	run, err := sr.SousGraph.GetRun(sr.DeployFilterFlags, args, sr.stream, sr.timeout)
+	if err != nil {
+		return cmdr.EnsureErrorResult(err)
+	}
+	if err := run.Do(); err != nil {
+		if failed, ok := errors.Cause(err).(*actions.RunFailedError); ok {
+			return cmdr.ExitErrorf(failed.ExitCode(), "%s", failed)
+		}
+		return cmdr.EnsureErrorResult(err)
+	}
+	return cmdr.Success("Run succeeded")
+}
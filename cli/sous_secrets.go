@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/util/cmdr"
+)
+
+// SousSecrets describes the `sous secrets` command.
+type SousSecrets struct{}
+
+// SecretsSubcommands holds the subcommands of `sous secrets`.
+var SecretsSubcommands = cmdr.Commands{}
+
+func init() { TopLevelCommands["secrets"] = &SousSecrets{} }
+
+const sousSecretsHelp = `manage the secrets of a deployment
+
+The "sous secrets" command keeps the secrets of a deployment in the
+configured secret store (Secrets.VaultAddr or Secrets.Dir), and prints the
+env vars which refer to them, like
+
+    DB_PASSWORD=secret://vault/sous/github.com/user/project/ci-sf#DB_PASSWORD
+
+Put those in the deployment's Env, with the var defined as Type Secret in
+defs: Sous resolves them at deploy time, and never stores or logs their
+values. Secret values are read from stdin if they are not given as
+arguments, to keep them out of shell history.
+`
+
+// Subcommands implements Subcommander on SousSecrets.
+func (SousSecrets) Subcommands() cmdr.Commands {
+	return SecretsSubcommands
+}
+
+// RegisterOn implements Registrant on SousSecrets
+func (SousSecrets) RegisterOn(psy Addable) {
+	psy.Add(graph.DryrunNeither)
+}
+
+// Help implements Command on SousSecrets.
+func (*SousSecrets) Help() string { return sousSecretsHelp }
+
+// Execute implements Executor on SousSecrets.
+func (ss *SousSecrets) Execute(args []string) cmdr.Result {
+	err := cmdr.UsageErrorf("usage: sous secrets [options] <command>")
+	err.Tip = "try `sous help secrets` for a list of commands"
+	return err
+}
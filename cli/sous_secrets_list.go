@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"flag"
+
+	"github.com/opentable/sous/config"
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/util/cmdr"
+)
+
+// SousSecretsList describes the `sous secrets list` command.
+type SousSecretsList struct {
+	SousGraph         *graph.SousGraph
+	DeployFilterFlags config.DeployFilterFlags `inject:"optional"`
+}
+
+func init() { SecretsSubcommands["list"] = &SousSecretsList{} }
+
+const sousSecretsListHelp = `list the secrets of a deployment
+
+usage: sous secrets list -cluster <name>
+
+Prints the env vars referring to each secret of the deployment in the named
+cluster. Secret values are never printed.
+`
+
+// Help implements Command on SousSecretsList.
+func (*SousSecretsList) Help() string { return sousSecretsListHelp }
+
+// AddFlags implements AddFlagser on SousSecretsList.
+func (sl *SousSecretsList) AddFlags(fs *flag.FlagSet) {
+	MustAddFlags(fs, &sl.DeployFilterFlags, MetadataFilterFlagsHelp)
+}
+
+// RegisterOn implements Registrant on SousSecretsList.
+func (sl *SousSecretsList) RegisterOn(psy Addable) {
+	psy.Add(graph.DryrunNeither)
+	psy.Add(&sl.DeployFilterFlags)
+}
+
+// Execute implements Executor on SousSecretsList.
+func (sl *SousSecretsList) Execute(args []string) cmdr.Result {
+	list, err := sl.SousGraph.GetSecretsList(sl.DeployFilterFlags)
+	if err != nil {
+		return cmdr.EnsureErrorResult(err)
+	}
+	if err := list.Do(); err != nil {
+		return cmdr.EnsureErrorResult(err)
+	}
+	return cmdr.Success()
+}
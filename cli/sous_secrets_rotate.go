@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"flag"
+
+	"github.com/opentable/sous/config"
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/util/cmdr"
+)
+
+// SousSecretsRotate describes the `sous secrets rotate` command.
+type SousSecretsRotate struct {
+	SousGraph         *graph.SousGraph
+	DeployFilterFlags config.DeployFilterFlags `inject:"optional"`
+}
+
+func init() { SecretsSubcommands["rotate"] = &SousSecretsRotate{} }
+
+const sousSecretsRotateHelp = `rotate a secret of a deployment
+
+usage: sous secrets rotate -cluster <name> <KEY> [<value>]
+
+Changes the value of the existing secret KEY of the deployment in the named
+cluster, and bumps the deployment's SecretsVersion so that the server
+redeploys it with the new value. If value is omitted, it is read from stdin.
+`
+
+// Help implements Command on SousSecretsRotate.
+func (*SousSecretsRotate) Help() string { return sousSecretsRotateHelp }
+
+// AddFlags implements AddFlagser on SousSecretsRotate.
+func (sr *SousSecretsRotate) AddFlags(fs *flag.FlagSet) {
+	MustAddFlags(fs, &sr.DeployFilterFlags, MetadataFilterFlagsHelp)
+}
+
+// RegisterOn implements Registrant on SousSecretsRotate.
+func (sr *SousSecretsRotate) RegisterOn(psy Addable) {
+	psy.Add(graph.DryrunNeither)
+	psy.Add(&sr.DeployFilterFlags)
+}
+
+// Execute implements Executor on SousSecretsRotate.
+func (sr *SousSecretsRotate) Execute(args []string) cmdr.Result {
+	key, value, res := secretArgs("rotate", args)
+	if res != nil {
+		return res
+	}
+	rotate, err := sr.SousGraph.GetSecretsRotate(sr.DeployFilterFlags, key, value)
+	if err != nil {
+		return cmdr.EnsureErrorResult(err)
+	}
+	if err := rotate.Do(); err != nil {
+		return cmdr.EnsureErrorResult(err)
+	}
+	return cmdr.Success()
+}
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"flag"
+
+	"github.com/opentable/sous/config"
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/util/cmdr"
+)
+
+// SousSecretsSet describes the `sous secrets set` command.
+type SousSecretsSet struct {
+	SousGraph         *graph.SousGraph
+	DeployFilterFlags config.DeployFilterFlags `inject:"optional"`
+}
+
+func init() { SecretsSubcommands["set"] = &SousSecretsSet{} }
+
+const sousSecretsSetHelp = `set a secret of a deployment
+
+usage: sous secrets set -cluster <name> <KEY> [<value>]
+
+Sets the secret KEY of the deployment in the named cluster, and prints the
+env var which refers to it. If value is omitted, it is read from stdin.
+`
+
+// Help implements Command on SousSecretsSet.
+func (*SousSecretsSet) Help() string { return sousSecretsSetHelp }
+
+// AddFlags implements AddFlagser on SousSecretsSet.
+func (ss *SousSecretsSet) AddFlags(fs *flag.FlagSet) {
+	MustAddFlags(fs, &ss.DeployFilterFlags, MetadataFilterFlagsHelp)
+}
+
+// RegisterOn implements Registrant on SousSecretsSet.
+func (ss *SousSecretsSet) RegisterOn(psy Addable) {
+	psy.Add(graph.DryrunNeither)
+	psy.Add(&ss.DeployFilterFlags)
+}
+
+// Execute implements Executor on SousSecretsSet.
+func (ss *SousSecretsSet) Execute(args []string) cmdr.Result {
+	key, value, res := secretArgs("set", args)
+	if res != nil {
+		return res
+	}
+	set, err := ss.SousGraph.GetSecretsSet(ss.DeployFilterFlags, key, value)
+	if err != nil {
+		return cmdr.EnsureErrorResult(err)
+	}
+	if err := set.Do(); err != nil {
+		return cmdr.EnsureErrorResult(err)
+	}
+	return cmdr.Success()
+}
+
+// secretArgs returns the key and optional value of `sous secrets <cmd>`.
+func secretArgs(cmd string, args []string) (string, string, cmdr.Result) {
+	if len(args) < 1 || len(args) > 2 {
+		return "", "", cmdr.UsageErrorf("usage: sous secrets %s -cluster <name> <KEY> [<value>]", cmd)
+	}
+	if len(args) == 1 {
+		return args[0], "", nil
+	}
+	return args[0], args[1], nil
+}
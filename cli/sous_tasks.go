@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"flag"
+
+	"github.com/opentable/sous/config"
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/util/cmdr"
+)
+
+// SousTasks is the command description for `sous tasks`.
+type SousTasks struct {
+	SousGraph         *graph.SousGraph
+	DeployFilterFlags config.DeployFilterFlags `inject:"optional"`
+}
+
+func init() { TopLevelCommands["tasks"] = &SousTasks{} }
+
+const sousTasksHelp = `lists the running tasks of a deployment
+
+usage: sous tasks -cluster <name>
+
+sous tasks lists the running tasks of the current deployment of this
+application in the named cluster, with the host and ports of each, when it
+started, and the outcome of its latest health check.
+`
+
+// Help returns the help string for this command.
+func (st *SousTasks) Help() string { return sousTasksHelp }
+
+// AddFlags adds the flags for sous tasks.
+func (st *SousTasks) AddFlags(fs *flag.FlagSet) {
+	MustAddFlags(fs, &st.DeployFilterFlags, DeployFilterFlagsHelp)
+}
+
+// Execute fulfills the cmdr.Executor interface.
+func (st *SousTasks) Execute(args []string) cmdr.Result {
+	if st.DeployFilterFlags.Cluster == "" {
+		return cmdr.UsageErrorf("-cluster is required")
+	}
+	tasks, err := st.SousGraph.GetTasks(st.DeployFilterFlags)
+	if err != nil {
+		return cmdr.EnsureErrorResult(err)
+	}
+	if err := tasks.Do(); err != nil {
+		return cmdr.EnsureErrorResult(err)
+	}
+	return cmdr.Success()
+}
@@ -13,6 +13,7 @@ type SousUpdate struct {
 	DeployFilterFlags config.DeployFilterFlags `inject:"optional"`
 	OTPLFlags         config.OTPLFlags         `inject:"optional"`
 	SousGraph         *graph.SousGraph
+	freezeOverride    string
 }
 
 func init() { TopLevelCommands["update"] = &SousUpdate{} }
@@ -31,11 +32,14 @@ func (su *SousUpdate) Help() string { return sousUpdateHelp }
 // AddFlags adds the flags for sous init.
 func (su *SousUpdate) AddFlags(fs *flag.FlagSet) {
 	MustAddFlags(fs, &su.DeployFilterFlags, DeployFilterFlagsHelp)
+
+	fs.StringVar(&su.freezeOverride, "override-freeze", "",
+		"the reason to update despite a freeze of the cluster (only for the freeze's overriders)")
 }
 
 // Execute fulfills the cmdr.Executor interface.
 func (su *SousUpdate) Execute(args []string) cmdr.Result {
-	update, err := su.SousGraph.GetUpdate(su.DeployFilterFlags, su.OTPLFlags)
+	update, err := su.SousGraph.GetUpdate(su.DeployFilterFlags, su.OTPLFlags, su.freezeOverride)
 	if err != nil {
 		return cmdr.EnsureErrorResult(err)
 	}
@@ -8,10 +8,13 @@ import (
 	"path"
 
 	"github.com/opentable/sous/ext/docker"
+	"github.com/opentable/sous/ext/kubernetes"
+	"github.com/opentable/sous/ext/secrets"
 	"github.com/opentable/sous/ext/storage"
 	"github.com/opentable/sous/lib"
 	"github.com/opentable/sous/util/firsterr"
 	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/restful"
 	"github.com/pkg/errors"
 )
 
@@ -45,6 +48,32 @@ type (
 		// MaxHTTPConcurrencySingularity is the maximum number of concurrent
 		// requests that can be made to a single Singularity instance.
 		MaxHTTPConcurrencySingularity int `env:"MAX_HTTP_CONCURRENCY_SINGULARITY"`
+		// SingularityFullPollSeconds is how often, in seconds, the deploy
+		// states cached from Singularity are dropped, and every request
+		// assembled afresh. If it is zero, every poll is a full poll.
+		SingularityFullPollSeconds int `env:"SINGULARITY_FULL_POLL_SECONDS"`
+		// Kubernetes configures access to clusters of kind "kubernetes".
+		Kubernetes kubernetes.Config
+		// Auth configures how the server authenticates its clients, and how
+		// clients authenticate to the server.
+		Auth restful.AuthConfig
+		// Secrets configures the stores which secret references in env vars
+		// are resolved from.
+		Secrets secrets.Config
+		// AdminGroups are the groups whose members may change any manifest.
+		// Other users may only change manifests they own. Only enforced when
+		// the server authenticates its clients.
+		AdminGroups []string
+		// DeletionBrake limits how many deployments the server deletes, or
+		// scales to zero, in one resolve cycle without an operator
+		// acknowledging them.
+		DeletionBrake sous.DeletionBrakeConfig
+		// Webhooks are the URLs the server POSTs deployment lifecycle events
+		// to.
+		Webhooks []sous.Webhook
+		// Leadership configures leader election, so that of several servers
+		// for the same cluster, only one resolves it.
+		Leadership sous.LeadershipConfig
 	}
 )
 
@@ -71,6 +100,16 @@ func (c Config) Validate() error {
 			return errors.Wrapf(err, "Config.SiblingURLs[%s]", n)
 		}
 	}
+	for i, h := range c.Webhooks {
+		if err := checkURL(h.URL); err != nil {
+			return errors.Wrapf(err, "Config.Webhooks[%d]", i)
+		}
+	}
+	if c.Leadership.Enabled {
+		if err := checkURL(c.Leadership.URL); err != nil {
+			return errors.Wrapf(err, "Config.Leadership.URL")
+		}
+	}
 	if err := c.Logging.Validate(); err != nil {
 		return errors.Wrapf(err, "Config.Logging")
 	}
@@ -82,6 +121,7 @@ func DefaultConfig() Config {
 	return Config{
 		Docker: docker.DefaultConfig(),
 		MaxHTTPConcurrencySingularity: 10,
+		SingularityFullPollSeconds: 600,
 	}
 }
 
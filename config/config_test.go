@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/opentable/sous/ext/docker"
+	"github.com/opentable/sous/lib"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -76,6 +77,12 @@ func TestConfig_Validate(t *testing.T) {
 
 	cfg.Server = ""
 	checkValid()
+
+	cfg.Webhooks = []sous.Webhook{{Name: "chat", URL: "chat.example.com"}}
+	checkNotValid()
+
+	cfg.Webhooks[0].URL = "https://chat.example.com/hooks"
+	checkValid()
 }
 
 func TestConfig_Equals(t *testing.T) {
@@ -0,0 +1,502 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/opentable/sous/ext/docker"
+	"github.com/opentable/sous/lib"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ManagedLabel marks Kubernetes objects which are controlled by Sous.
+	ManagedLabel = "com.opentable.sous.managed"
+	// DeploymentLabel carries a digest of the sous.DeploymentID an object
+	// belongs to, and is used to select the pods of a deployment.
+	DeploymentLabel = "com.opentable.sous.deployment"
+
+	kindAnnotation     = "com.opentable.sous.kind"
+	ownersAnnotation   = "com.opentable.sous.owners"
+	metadataAnnotation = "com.opentable.sous.metadata"
+	startupAnnotation  = "com.opentable.sous.startup"
+	// schedulingAnnotation records the scheduling options Kubernetes objects
+	// have no equivalent for.
+	schedulingAnnotation = "com.opentable.sous.scheduling"
+
+	// basePort is the container port assigned to PORT0; further ports are
+	// numbered consecutively from there.
+	basePort = 8080
+	// maxNameLen is the longest name Kubernetes accepts for a Service.
+	maxNameLen = 63
+)
+
+var illegalNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// unmappedStartup holds the Startup fields which have no equivalent in a
+// Kubernetes probe, so that they can be stored in an annotation and survive
+// the round trip.
+type unmappedStartup struct {
+	Timeout, ConnectInterval  int
+	CheckReadyFailureStatuses []int
+}
+
+// unmappedScheduling holds the DeployConfig scheduling options which are
+// only acted on by Singularity, so that they survive the round trip.
+type unmappedScheduling struct {
+	ScheduleTimeZone     string `json:",omitempty"`
+	TaskTimeLimitSeconds int    `json:",omitempty"`
+	KillGraceSeconds     int    `json:",omitempty"`
+	Placement            sous.Placement
+}
+
+// ObjectName computes the name of the Kubernetes objects for a
+// sous.DeploymentID. It is a valid DNS label, and unique per DeploymentID.
+func ObjectName(depID sous.DeploymentID) (string, error) {
+	sn, err := depID.ManifestID.Source.ShortName()
+	if err != nil {
+		return "", err
+	}
+	parts := []string{}
+	for _, p := range []string{sn, depID.ManifestID.Source.Dir, depID.ManifestID.Flavor, depID.Cluster} {
+		p = strings.Trim(illegalNameChars.ReplaceAllString(strings.ToLower(p), "-"), "-")
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	digest := fmt.Sprintf("%x", depID.Digest())[:8]
+	base := strings.Join(parts, "-")
+	if max := maxNameLen - len(digest) - 2; len(base) > max {
+		base = strings.TrimRight(base[:max], "-")
+	}
+	return "s" + base + "-" + digest, nil
+}
+
+func selectorLabels(depID sous.DeploymentID) map[string]string {
+	return map[string]string{
+		ManagedLabel:    "true",
+		DeploymentLabel: fmt.Sprintf("%x", depID.Digest()),
+	}
+}
+
+func objectMeta(d *sous.Deployment, name string) (ObjectMeta, error) {
+	labels := selectorLabels(d.ID())
+	labels[sous.ClusterNameLabel] = d.ClusterName
+
+	annotations := docker.Labels(d.SourceID)
+	annotations[sous.ClusterNameLabel] = d.ClusterName
+	annotations[sous.FlavorLabel] = d.Flavor
+	annotations[kindAnnotation] = string(d.Kind)
+	owners := d.Owners.Slice()
+	sort.Strings(owners)
+	annotations[ownersAnnotation] = strings.Join(owners, ",")
+
+	md, err := json.Marshal(d.DeployConfig.Metadata)
+	if err != nil {
+		return ObjectMeta{}, err
+	}
+	annotations[metadataAnnotation] = string(md)
+
+	su, err := json.Marshal(unmappedStartup{
+		Timeout:                   d.Startup.Timeout,
+		ConnectInterval:           d.Startup.ConnectInterval,
+		CheckReadyFailureStatuses: d.Startup.CheckReadyFailureStatuses,
+	})
+	if err != nil {
+		return ObjectMeta{}, err
+	}
+	annotations[startupAnnotation] = string(su)
+	// Env vars read from the deployment's Secret are recorded by their
+	// references, so that the deployment read back has them in place of the
+	// values.
+	for n, v := range d.Env {
+		if sous.IsSecretRef(v) {
+			annotations[sous.SecretRefLabelPrefix+n] = v
+		}
+	}
+	if d.SecretsVersion != 0 {
+		annotations[sous.SecretsVersionLabel] = strconv.Itoa(d.SecretsVersion)
+	}
+	us := unmappedScheduling{
+		ScheduleTimeZone:     d.ScheduleTimeZone,
+		TaskTimeLimitSeconds: d.TaskTimeLimitSeconds,
+		KillGraceSeconds:     d.KillGraceSeconds,
+		Placement:            d.Placement,
+	}
+	if us.ScheduleTimeZone != "" || us.TaskTimeLimitSeconds != 0 || us.KillGraceSeconds != 0 || !us.Placement.IsZero() {
+		sc, err := json.Marshal(us)
+		if err != nil {
+			return ObjectMeta{}, err
+		}
+		annotations[schedulingAnnotation] = string(sc)
+	}
+
+	return ObjectMeta{Name: name, Labels: labels, Annotations: annotations}, nil
+}
+
+func portName(index int) string {
+	return fmt.Sprintf("port%d", index)
+}
+
+// mapResources produces the requests and limits for a container. Sous
+// resources are hard allocations, so requests and limits are identical.
+func mapResources(r sous.Resources) ResourceRequirements {
+	q := map[string]string{
+		"cpu":    strconv.FormatFloat(r.Cpus(), 'f', -1, 64),
+		"memory": strconv.FormatFloat(r.Memory(), 'f', -1, 64) + "Mi",
+	}
+	limits := map[string]string{}
+	for k, v := range q {
+		limits[k] = v
+	}
+	return ResourceRequirements{Requests: q, Limits: limits}
+}
+
+// MapStartupIntoProbe returns the readiness probe described by startup, or
+// nil if checks are skipped.
+func MapStartupIntoProbe(startup sous.Startup) *Probe {
+	if startup.SkipCheck {
+		return nil
+	}
+	p := probeHandler(startup)
+	p.InitialDelaySeconds = int32(startup.ConnectDelay)
+	p.TimeoutSeconds = int32(startup.CheckReadyURITimeout)
+	p.PeriodSeconds = int32(startup.CheckReadyInterval)
+	p.FailureThreshold = int32(startup.CheckReadyRetries)
+	return p
+}
+
+// MapStartupIntoLivenessProbe returns the liveness probe described by
+// startup, or nil if tasks aren't checked once they are ready. It runs the
+// same check as the readiness probe, starting once the startup timeout has
+// passed.
+func MapStartupIntoLivenessProbe(startup sous.Startup) *Probe {
+	if startup.SkipCheck || startup.LivenessInterval == 0 {
+		return nil
+	}
+	p := probeHandler(startup)
+	p.InitialDelaySeconds = int32(startup.Timeout)
+	p.TimeoutSeconds = int32(startup.CheckReadyURITimeout)
+	p.PeriodSeconds = int32(startup.LivenessInterval)
+	p.FailureThreshold = int32(startup.LivenessFailureThreshold)
+	return p
+}
+
+// probeHandler returns a Probe with only the check set.
+func probeHandler(startup sous.Startup) *Probe {
+	switch protocol := strings.ToUpper(startup.CheckReadyProtocol); protocol {
+	default:
+		return &Probe{HTTPGet: &HTTPGetAction{
+			Path:   startup.CheckReadyURIPath,
+			Port:   portName(startup.CheckReadyPortIndex),
+			Scheme: protocol,
+		}}
+	case "TCP":
+		return &Probe{TCPSocket: &TCPSocketAction{Port: portName(startup.CheckReadyPortIndex)}}
+	case "COMMAND":
+		return &Probe{Exec: &ExecAction{Command: startup.CheckReadyCommand}}
+	}
+}
+
+// unmapProbeHandler sets the kind of check in startup from p, and returns
+// false if p has no check.
+func unmapProbeHandler(p *Probe, startup *sous.Startup) bool {
+	switch {
+	default:
+		return false
+	case p.HTTPGet != nil:
+		startup.CheckReadyProtocol = p.HTTPGet.Scheme
+		startup.CheckReadyURIPath = p.HTTPGet.Path
+		startup.CheckReadyPortIndex = portIndex(p.HTTPGet.Port)
+	case p.TCPSocket != nil:
+		startup.CheckReadyProtocol = "TCP"
+		startup.CheckReadyPortIndex = portIndex(p.TCPSocket.Port)
+	case p.Exec != nil:
+		startup.CheckReadyProtocol = "COMMAND"
+		startup.CheckReadyCommand = p.Exec.Command
+	}
+	return true
+}
+
+func portIndex(name string) int {
+	i, _ := strconv.Atoi(strings.TrimPrefix(name, "port"))
+	return i
+}
+
+func podTemplate(d sous.Deployable, meta ObjectMeta) PodTemplateSpec {
+	dep := d.Deployment
+	ports := int(dep.Resources.Ports())
+
+	env := []EnvVar{}
+	for i := 0; i < ports; i++ {
+		env = append(env, EnvVar{Name: fmt.Sprintf("PORT%d", i), Value: strconv.Itoa(basePort + i)})
+	}
+	names := make([]string, 0, len(dep.Env))
+	for n := range dep.Env {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		v := dep.Env[n]
+		if !sous.IsSecretRef(v) {
+			env = append(env, EnvVar{Name: n, Value: v})
+			continue
+		}
+		env = append(env, EnvVar{Name: n, ValueFrom: &EnvVarSource{
+			SecretKeyRef: &SecretKeySelector{Name: meta.Name, Key: n},
+		}})
+	}
+
+	container := Container{
+		Name:           "main",
+		Image:          d.BuildArtifact.Name,
+		Env:            env,
+		Resources:      mapResources(dep.Resources),
+		ReadinessProbe: MapStartupIntoProbe(dep.Startup),
+		LivenessProbe:  MapStartupIntoLivenessProbe(dep.Startup),
+	}
+	for i := 0; i < ports; i++ {
+		container.Ports = append(container.Ports, ContainerPort{Name: portName(i), ContainerPort: int32(basePort + i)})
+	}
+
+	spec := PodSpec{}
+	for i, v := range dep.Volumes {
+		if v == nil {
+			continue
+		}
+		name := fmt.Sprintf("volume%d", i)
+		spec.Volumes = append(spec.Volumes, Volume{Name: name, HostPath: &HostPathVolumeSource{Path: v.Host}})
+		container.VolumeMounts = append(container.VolumeMounts, VolumeMount{
+			Name:      name,
+			MountPath: v.Container,
+			ReadOnly:  v.Mode == sous.ReadOnly,
+		})
+	}
+	spec.Containers = []Container{container}
+
+	tmplMeta := ObjectMeta{Labels: meta.Labels}
+	// Pods only read their Secret when they start, so bumping the secrets
+	// version changes the template, to replace them.
+	if v, ok := meta.Annotations[sous.SecretsVersionLabel]; ok {
+		tmplMeta.Annotations = map[string]string{sous.SecretsVersionLabel: v}
+	}
+	return PodTemplateSpec{
+		Metadata: tmplMeta,
+		Spec:     spec,
+	}
+}
+
+func buildDeployment(d sous.Deployable, name string) (*Deployment, error) {
+	meta, err := objectMeta(d.Deployment, name)
+	if err != nil {
+		return nil, err
+	}
+	replicas := int32(d.NumInstances)
+	return &Deployment{
+		Metadata: meta,
+		Spec: DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &LabelSelector{MatchLabels: selectorLabels(d.ID())},
+			Template: podTemplate(d, meta),
+		},
+	}, nil
+}
+
+func buildCronJob(d sous.Deployable, name string) (*CronJob, error) {
+	meta, err := objectMeta(d.Deployment, name)
+	if err != nil {
+		return nil, err
+	}
+	tmpl := podTemplate(d, meta)
+	tmpl.Spec.RestartPolicy = "Never"
+	parallelism := int32(d.NumInstances)
+	return &CronJob{
+		Metadata: meta,
+		Spec: CronJobSpec{
+			Schedule: d.Schedule,
+			JobTemplate: JobTemplateSpec{Spec: JobSpec{
+				Parallelism: &parallelism,
+				Template:    tmpl,
+			}},
+		},
+	}, nil
+}
+
+// buildSecret returns the Secret holding the values of d's env vars which
+// refer to secrets, resolved with secrets, or nil if there are none. The
+// values must never be logged.
+func buildSecret(d sous.Deployable, name string, secrets sous.SecretResolver) (*Secret, error) {
+	refs, err := d.Env.SecretRefs()
+	if err != nil || len(refs) == 0 {
+		return nil, err
+	}
+	resolved, err := d.Env.Resolve(secrets)
+	if err != nil {
+		return nil, err
+	}
+	data := map[string]string{}
+	for n := range refs {
+		data[n] = resolved[n]
+	}
+	meta := ObjectMeta{Name: name, Labels: selectorLabels(d.ID())}
+	meta.Labels[sous.ClusterNameLabel] = d.ClusterName
+	return &Secret{Metadata: meta, Type: "Opaque", StringData: data}, nil
+}
+
+func buildService(d sous.Deployable, name string) *Service {
+	svc := &Service{
+		Metadata: ObjectMeta{Name: name, Labels: selectorLabels(d.ID())},
+		Spec:     ServiceSpec{Selector: selectorLabels(d.ID())},
+	}
+	svc.Metadata.Labels[sous.ClusterNameLabel] = d.ClusterName
+	for i := 0; i < int(d.Resources.Ports()); i++ {
+		svc.Spec.Ports = append(svc.Spec.Ports, ServicePort{
+			Name:       portName(i),
+			Port:       int32(basePort + i),
+			TargetPort: portName(i),
+		})
+	}
+	return svc
+}
+
+// deploymentFromObject restores the sous.Deployment described by the metadata
+// and pod template of a Kubernetes object.
+func deploymentFromObject(meta ObjectMeta, tmpl PodTemplateSpec, clusters sous.Clusters) (sous.Deployment, error) {
+	dep := sous.Deployment{}
+	ann := meta.Annotations
+
+	var err error
+	if dep.SourceID, err = docker.SourceIDFromLabels(ann); err != nil {
+		return dep, errors.Wrapf(err, "%s annotations", meta.Name)
+	}
+	dep.ClusterName = ann[sous.ClusterNameLabel]
+	cluster, ok := clusters[dep.ClusterName]
+	if !ok {
+		return dep, errors.Errorf("%s belongs to cluster %q, not one of %v", meta.Name, dep.ClusterName, clusters.Names())
+	}
+	dep.Cluster = cluster.Clone()
+	dep.Flavor = ann[sous.FlavorLabel]
+	dep.Kind = sous.ManifestKind(ann[kindAnnotation])
+
+	dep.Owners = sous.OwnerSet{}
+	if owners := ann[ownersAnnotation]; owners != "" {
+		for _, o := range strings.Split(owners, ",") {
+			dep.Owners.Add(o)
+		}
+	}
+
+	if md := ann[metadataAnnotation]; md != "" {
+		if err := json.Unmarshal([]byte(md), &dep.DeployConfig.Metadata); err != nil {
+			return dep, errors.Wrapf(err, "%s metadata annotation", meta.Name)
+		}
+	}
+
+	if v := ann[sous.SecretsVersionLabel]; v != "" {
+		if dep.SecretsVersion, err = strconv.Atoi(v); err != nil {
+			return dep, errors.Wrapf(err, "%s secrets version annotation", meta.Name)
+		}
+	}
+
+	if sc := ann[schedulingAnnotation]; sc != "" {
+		us := unmappedScheduling{}
+		if err := json.Unmarshal([]byte(sc), &us); err != nil {
+			return dep, errors.Wrapf(err, "%s scheduling annotation", meta.Name)
+		}
+		dep.ScheduleTimeZone = us.ScheduleTimeZone
+		dep.TaskTimeLimitSeconds = us.TaskTimeLimitSeconds
+		dep.KillGraceSeconds = us.KillGraceSeconds
+		dep.Placement = us.Placement
+	}
+
+	if len(tmpl.Spec.Containers) != 1 {
+		return dep, errors.Errorf("%s has %d containers, expected 1", meta.Name, len(tmpl.Spec.Containers))
+	}
+	c := tmpl.Spec.Containers[0]
+
+	dep.Resources = sous.Resources{
+		"cpus":   c.Resources.Requests["cpu"],
+		"memory": strings.TrimSuffix(c.Resources.Requests["memory"], "Mi"),
+		"ports":  strconv.Itoa(len(c.Ports)),
+	}
+
+	dep.Env = sous.Env{}
+	for _, e := range c.Env {
+		if isPortVar(e.Name, len(c.Ports)) {
+			continue
+		}
+		if e.ValueFrom != nil && e.ValueFrom.SecretKeyRef != nil {
+			dep.Env[e.Name] = ann[sous.SecretRefLabelPrefix+e.Name]
+			continue
+		}
+		dep.Env[e.Name] = e.Value
+	}
+
+	hostPaths := map[string]string{}
+	for _, v := range tmpl.Spec.Volumes {
+		if v.HostPath != nil {
+			hostPaths[v.Name] = v.HostPath.Path
+		}
+	}
+	for _, vm := range c.VolumeMounts {
+		mode := sous.ReadWrite
+		if vm.ReadOnly {
+			mode = sous.ReadOnly
+		}
+		dep.Volumes = append(dep.Volumes, &sous.Volume{Host: hostPaths[vm.Name], Container: vm.MountPath, Mode: mode})
+	}
+
+	if p := c.ReadinessProbe; p != nil && unmapProbeHandler(p, &dep.Startup) {
+		dep.Startup.ConnectDelay = int(p.InitialDelaySeconds)
+		dep.Startup.CheckReadyURITimeout = int(p.TimeoutSeconds)
+		dep.Startup.CheckReadyInterval = int(p.PeriodSeconds)
+		dep.Startup.CheckReadyRetries = int(p.FailureThreshold)
+		us := unmappedStartup{}
+		if su := ann[startupAnnotation]; su != "" {
+			if err := json.Unmarshal([]byte(su), &us); err != nil {
+				return dep, errors.Wrapf(err, "%s startup annotation", meta.Name)
+			}
+		}
+		dep.Startup.Timeout = us.Timeout
+		dep.Startup.ConnectInterval = us.ConnectInterval
+		dep.Startup.CheckReadyFailureStatuses = us.CheckReadyFailureStatuses
+		if lp := c.LivenessProbe; lp != nil {
+			dep.Startup.LivenessInterval = int(lp.PeriodSeconds)
+			dep.Startup.LivenessFailureThreshold = int(lp.FailureThreshold)
+		}
+	} else {
+		dep.Startup.SkipCheck = true
+	}
+
+	return dep, nil
+}
+
+func isPortVar(name string, ports int) bool {
+	if !strings.HasPrefix(name, "PORT") {
+		return false
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(name, "PORT"))
+	return err == nil && n < ports
+}
+
+// deployStatus derives a sous.DeployStatus from the status of a Deployment.
+func deployStatus(d Deployment) (sous.DeployStatus, string) {
+	for _, c := range d.Status.Conditions {
+		if c.Type == "Progressing" && c.Status == "False" {
+			return sous.DeployStatusFailed, fmt.Sprintf("Deploy failure: %s: %s", c.Reason, c.Message)
+		}
+	}
+	want := int32(1)
+	if d.Spec.Replicas != nil {
+		want = *d.Spec.Replicas
+	}
+	if d.Status.ObservedGeneration >= d.Metadata.Generation &&
+		d.Status.UpdatedReplicas >= want &&
+		d.Status.AvailableReplicas >= want {
+		return sous.DeployStatusActive, ""
+	}
+	return sous.DeployStatusPending, ""
+}
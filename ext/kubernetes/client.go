@@ -0,0 +1,214 @@
+package kubernetes
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	deploymentsPath = "/apis/apps/v1/namespaces/%s/deployments"
+	cronJobsPath    = "/apis/batch/v1/namespaces/%s/cronjobs"
+	servicesPath    = "/api/v1/namespaces/%s/services"
+	secretsPath     = "/api/v1/namespaces/%s/secrets"
+)
+
+type (
+	// Client is a minimal Kubernetes API client, covering only the calls
+	// needed to manage Sous deployments within a single namespace.
+	Client struct {
+		BaseURL   string
+		Namespace string
+		Token     string
+		HTTP      *http.Client
+	}
+
+	// Config configures how Sous talks to Kubernetes clusters. The API server
+	// URL for each cluster is its BaseURL in the GDM.
+	Config struct {
+		// Namespace is the namespace Sous deploys into. Defaults to "default".
+		Namespace string `env:"SOUS_KUBERNETES_NAMESPACE"`
+		// Token is a bearer token used to authenticate to the API server.
+		Token string `env:"SOUS_KUBERNETES_TOKEN"`
+		// InsecureSkipVerify disables verification of the API server's TLS
+		// certificate.
+		InsecureSkipVerify bool `env:"SOUS_KUBERNETES_INSECURE"`
+	}
+
+	// apiError is returned when the API server responds with a non-2xx status.
+	apiError struct {
+		Method, URL string
+		Status      int
+		Body        string
+	}
+)
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.URL, e.Status, e.Body)
+}
+
+func isNotFound(err error) bool {
+	ae, is := errors.Cause(err).(*apiError)
+	return is && ae.Status == http.StatusNotFound
+}
+
+// NewClient returns a Client for the API server at baseURL, configured by c.
+func NewClient(baseURL string, c Config) *Client {
+	ns := c.Namespace
+	if ns == "" {
+		ns = "default"
+	}
+	hc := &http.Client{}
+	if c.InsecureSkipVerify {
+		hc.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	}
+	return &Client{
+		BaseURL:   strings.TrimSuffix(baseURL, "/"),
+		Namespace: ns,
+		Token:     c.Token,
+		HTTP:      hc,
+	}
+}
+
+func (c *Client) path(format, name string) string {
+	p := fmt.Sprintf(format, c.Namespace)
+	if name != "" {
+		p += "/" + url.PathEscape(name)
+	}
+	return p
+}
+
+func (c *Client) do(method, path string, query url.Values, body, into interface{}) error {
+	u := c.BaseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	var rqBody *bytes.Buffer
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		rqBody = bytes.NewBuffer(b)
+	} else {
+		rqBody = &bytes.Buffer{}
+	}
+	rq, err := http.NewRequest(method, u, rqBody)
+	if err != nil {
+		return err
+	}
+	rq.Header.Set("Accept", "application/json")
+	if body != nil {
+		rq.Header.Set("Content-Type", "application/json")
+	}
+	if c.Token != "" {
+		rq.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	rz, err := c.HTTP.Do(rq)
+	if err != nil {
+		return errors.Wrapf(err, "%s %s", method, u)
+	}
+	defer rz.Body.Close()
+	b, err := ioutil.ReadAll(rz.Body)
+	if err != nil {
+		return err
+	}
+	if rz.StatusCode < 200 || rz.StatusCode > 299 {
+		return &apiError{Method: method, URL: u, Status: rz.StatusCode, Body: string(b)}
+	}
+	if into == nil || len(b) == 0 {
+		return nil
+	}
+	return errors.Wrapf(json.Unmarshal(b, into), "decoding %s %s", method, u)
+}
+
+func selectorQuery(selector map[string]string) url.Values {
+	parts := []string{}
+	for k, v := range selector {
+		parts = append(parts, k+"="+v)
+	}
+	return url.Values{"labelSelector": {strings.Join(parts, ",")}}
+}
+
+// ListDeployments lists the Deployments matching every label in selector.
+func (c *Client) ListDeployments(selector map[string]string) ([]Deployment, error) {
+	list := DeploymentList{}
+	err := c.do("GET", c.path(deploymentsPath, ""), selectorQuery(selector), nil, &list)
+	return list.Items, err
+}
+
+// ListCronJobs lists the CronJobs matching every label in selector.
+func (c *Client) ListCronJobs(selector map[string]string) ([]CronJob, error) {
+	list := CronJobList{}
+	err := c.do("GET", c.path(cronJobsPath, ""), selectorQuery(selector), nil, &list)
+	return list.Items, err
+}
+
+// ApplyDeployment creates d, or replaces it if it already exists.
+func (c *Client) ApplyDeployment(d *Deployment) error {
+	d.APIVersion, d.Kind = "apps/v1", "Deployment"
+	existing := &Deployment{}
+	err := c.do("GET", c.path(deploymentsPath, d.Metadata.Name), nil, nil, existing)
+	if isNotFound(err) {
+		return c.do("POST", c.path(deploymentsPath, ""), nil, d, nil)
+	}
+	if err != nil {
+		return err
+	}
+	d.Metadata.ResourceVersion = existing.Metadata.ResourceVersion
+	return c.do("PUT", c.path(deploymentsPath, d.Metadata.Name), nil, d, nil)
+}
+
+// ApplyCronJob creates cj, or replaces it if it already exists.
+func (c *Client) ApplyCronJob(cj *CronJob) error {
+	cj.APIVersion, cj.Kind = "batch/v1", "CronJob"
+	existing := &CronJob{}
+	err := c.do("GET", c.path(cronJobsPath, cj.Metadata.Name), nil, nil, existing)
+	if isNotFound(err) {
+		return c.do("POST", c.path(cronJobsPath, ""), nil, cj, nil)
+	}
+	if err != nil {
+		return err
+	}
+	cj.Metadata.ResourceVersion = existing.Metadata.ResourceVersion
+	return c.do("PUT", c.path(cronJobsPath, cj.Metadata.Name), nil, cj, nil)
+}
+
+// ApplyService creates s, or replaces it if it already exists. The
+// ClusterIP of an existing Service is preserved, since it is immutable.
+func (c *Client) ApplyService(s *Service) error {
+	s.APIVersion, s.Kind = "v1", "Service"
+	existing := &Service{}
+	err := c.do("GET", c.path(servicesPath, s.Metadata.Name), nil, nil, existing)
+	if isNotFound(err) {
+		return c.do("POST", c.path(servicesPath, ""), nil, s, nil)
+	}
+	if err != nil {
+		return err
+	}
+	s.Metadata.ResourceVersion = existing.Metadata.ResourceVersion
+	s.Spec.ClusterIP = existing.Spec.ClusterIP
+	return c.do("PUT", c.path(servicesPath, s.Metadata.Name), nil, s, nil)
+}
+
+// ApplySecret creates s, or replaces it if it already exists.
+func (c *Client) ApplySecret(s *Secret) error {
+	s.APIVersion, s.Kind = "v1", "Secret"
+	existing := &Secret{}
+	err := c.do("GET", c.path(secretsPath, s.Metadata.Name), nil, nil, existing)
+	if isNotFound(err) {
+		return c.do("POST", c.path(secretsPath, ""), nil, s, nil)
+	}
+	if err != nil {
+		return err
+	}
+	s.Metadata.ResourceVersion = existing.Metadata.ResourceVersion
+	return c.do("PUT", c.path(secretsPath, s.Metadata.Name), nil, s, nil)
+}
@@ -0,0 +1,204 @@
+// Package kubernetes implements a sous.Deployer which manages deployments on
+// Kubernetes clusters.
+//
+// Each sous.Deployment becomes a Deployment (or a CronJob, for scheduled
+// manifests) with a matching Service for HTTP services. Resources become
+// container requests and limits, and Startup becomes the readiness probe.
+package kubernetes
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/pkg/errors"
+)
+
+type (
+	deployer struct {
+		clientFor func(cluster *sous.Cluster) kubeClient
+		secrets   sous.SecretResolver
+		log       logging.LogSink
+	}
+
+	// kubeClient abstracts the raw interactions with a Kubernetes API server.
+	kubeClient interface {
+		ListDeployments(selector map[string]string) ([]Deployment, error)
+		ListCronJobs(selector map[string]string) ([]CronJob, error)
+		ApplyDeployment(*Deployment) error
+		ApplyCronJob(*CronJob) error
+		ApplyService(*Service) error
+		ApplySecret(*Secret) error
+	}
+
+	// kubeTaskData is the ExecutorData recorded against deployments read
+	// from Kubernetes.
+	kubeTaskData struct {
+		name string
+	}
+)
+
+// NewDeployer creates a new Kubernetes-based sous.Deployer. The API server
+// for each cluster is the cluster's BaseURL. Secret references in env vars
+// are resolved with secrets into a Secret for each deployment.
+func NewDeployer(c Config, secrets sous.SecretResolver, ls logging.LogSink) sous.Deployer {
+	return &deployer{
+		clientFor: func(cl *sous.Cluster) kubeClient { return NewClient(cl.BaseURL, c) },
+		secrets:   secrets,
+		log:       ls,
+	}
+}
+
+// RunningDeployments collects the Sous-managed Deployments and CronJobs from
+// each of the clusters, and returns them as DeployStates.
+func (r *deployer) RunningDeployments(reg sous.Registry, clusters sous.Clusters) (sous.DeployStates, error) {
+	states := sous.NewDeployStates()
+	for name, cluster := range clusters {
+		client := r.clientFor(cluster)
+		selector := map[string]string{ManagedLabel: "true", sous.ClusterNameLabel: name}
+
+		deps, err := client.ListDeployments(selector)
+		if err != nil {
+			return states, errors.Wrapf(err, "listing deployments in %s", name)
+		}
+		for _, d := range deps {
+			dep, err := deploymentFromObject(d.Metadata, d.Spec.Template, clusters)
+			if err != nil {
+				logging.ReportError(r.log, err)
+				continue
+			}
+			if d.Spec.Replicas != nil {
+				dep.NumInstances = int(*d.Spec.Replicas)
+			}
+			status, msg := deployStatus(d)
+			states.Add(&sous.DeployState{
+				Deployment:      dep,
+				Status:          status,
+				ExecutorMessage: msg,
+				ExecutorData:    &kubeTaskData{name: d.Metadata.Name},
+			})
+		}
+
+		jobs, err := client.ListCronJobs(selector)
+		if err != nil {
+			return states, errors.Wrapf(err, "listing cronjobs in %s", name)
+		}
+		for _, cj := range jobs {
+			dep, err := deploymentFromObject(cj.Metadata, cj.Spec.JobTemplate.Spec.Template, clusters)
+			if err != nil {
+				logging.ReportError(r.log, err)
+				continue
+			}
+			dep.Schedule = cj.Spec.Schedule
+			if p := cj.Spec.JobTemplate.Spec.Parallelism; p != nil {
+				dep.NumInstances = int(*p)
+			}
+			states.Add(&sous.DeployState{
+				Deployment:   dep,
+				Status:       sous.DeployStatusActive,
+				ExecutorData: &kubeTaskData{name: cj.Metadata.Name},
+			})
+		}
+	}
+	return states, nil
+}
+
+// Rectify invokes actions to ensure that the real world matches pair.Post,
+// given that it currently matches pair.Prior.
+func (r *deployer) Rectify(pair *sous.DeployablePair) sous.DiffResolution {
+	switch k := pair.Kind(); k {
+	default:
+		panic(fmt.Sprintf("unrecognised kind %q", k))
+	case sous.SameKind:
+		resolution := pair.SameResolution()
+		if pair.Post.Status == sous.DeployStatusFailed {
+			resolution.Error = sous.WrapResolveError(&sous.FailedStatusError{})
+		}
+		return resolution
+	case sous.AddedKind:
+		result := sous.DiffResolution{DeploymentID: pair.ID()}
+		if err := r.apply(pair.Post); err != nil {
+			result.Desc = "not created"
+			result.Error = sous.WrapResolveError(&sous.CreateError{Deployment: pair.Post.Deployment.Clone(), Err: err})
+		} else {
+			result.Desc = sous.CreateDiff
+		}
+		return result
+	case sous.RemovedKind:
+		// As with Singularity, removed deployments are reported but never
+		// actually deleted.
+		r.log.Warnf("NOT DELETING KUBERNETES OBJECTS FOR %q", pair.ID())
+		return sous.DiffResolution{DeploymentID: pair.ID(), Desc: sous.DeleteDiff}
+	case sous.ModifiedKind:
+		result := sous.DiffResolution{DeploymentID: pair.ID()}
+		_, diffs := pair.Post.Deployment.Diff(pair.Prior.Deployment)
+		r.log.Debugf("Rectifying modified %q; Diffs: %s", pair.ID(), strings.Join(diffs, "\n"))
+		if err := r.apply(pair.Post); err != nil {
+			dp := &sous.DeploymentPair{
+				Prior: pair.Prior.Deployment.Clone(),
+				Post:  pair.Post.Deployment.Clone(),
+			}
+			result.Error = sous.WrapResolveError(&sous.ChangeError{Deployments: dp, Err: err})
+			result.Desc = "not updated"
+		} else if pair.Prior.Status == sous.DeployStatusFailed || pair.Post.Status == sous.DeployStatusFailed {
+			result.Desc = sous.ModifyDiff
+			result.Error = sous.WrapResolveError(&sous.FailedStatusError{})
+		} else {
+			result.Desc = sous.ModifyDiff
+		}
+		return result
+	}
+}
+
+// apply writes the Kubernetes objects describing d.
+func (r *deployer) apply(d *sous.Deployable) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = errors.Errorf("Panicked: %s; stack trace:\n%s", p, debug.Stack())
+		}
+	}()
+	if d.BuildArtifact == nil {
+		return &sous.MissingImageNameError{Cause: fmt.Errorf("Missing BuildArtifact on Deployable")}
+	}
+	name, err := ObjectName(d.ID())
+	if err != nil {
+		return err
+	}
+	client := r.clientFor(d.Cluster)
+
+	// The Secret is applied first, so that no pod starts without it.
+	secret, err := buildSecret(*d, name, r.secrets)
+	if err != nil {
+		return err
+	}
+	if secret != nil {
+		if err := client.ApplySecret(secret); err != nil {
+			return err
+		}
+	}
+
+	switch d.Kind {
+	default:
+		return errors.Errorf("manifest kind %q is not supported on Kubernetes", d.Kind)
+	case sous.ManifestKindScheduled:
+		cj, err := buildCronJob(*d, name)
+		if err != nil {
+			return err
+		}
+		return client.ApplyCronJob(cj)
+	case sous.ManifestKindService, sous.ManifestKindWorker:
+		dep, err := buildDeployment(*d, name)
+		if err != nil {
+			return err
+		}
+		if err := client.ApplyDeployment(dep); err != nil {
+			return err
+		}
+		if d.Kind != sous.ManifestKindService {
+			return nil
+		}
+		return client.ApplyService(buildService(*d, name))
+	}
+}
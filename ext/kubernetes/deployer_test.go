@@ -0,0 +1,368 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAPIServer is an in-memory stand-in for the parts of the Kubernetes API
+// that the deployer uses. Objects are stored as raw JSON, keyed by path.
+type fakeAPIServer struct {
+	sync.Mutex
+	objects map[string]json.RawMessage
+	*httptest.Server
+}
+
+func newFakeAPIServer() *fakeAPIServer {
+	f := &fakeAPIServer{objects: map[string]json.RawMessage{}}
+	f.Server = httptest.NewServer(f)
+	return f
+}
+
+func (f *fakeAPIServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+	var body json.RawMessage
+	if r.Body != nil {
+		json.NewDecoder(r.Body).Decode(&body)
+	}
+	switch r.Method {
+	case "GET":
+		if obj, ok := f.objects[r.URL.Path]; ok {
+			w.Write(obj)
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "s") {
+			f.list(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	case "POST":
+		meta := struct {
+			Metadata ObjectMeta `json:"metadata"`
+		}{}
+		json.Unmarshal(body, &meta)
+		f.objects[r.URL.Path+"/"+meta.Metadata.Name] = body
+		w.WriteHeader(http.StatusCreated)
+	case "PUT":
+		if _, ok := f.objects[r.URL.Path]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		f.objects[r.URL.Path] = body
+	}
+}
+
+func (f *fakeAPIServer) list(w http.ResponseWriter, r *http.Request) {
+	want := map[string]string{}
+	for _, kv := range strings.Split(r.URL.Query().Get("labelSelector"), ",") {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) == 2 {
+			want[parts[0]] = parts[1]
+		}
+	}
+	items := []json.RawMessage{}
+	for path, obj := range f.objects {
+		if !strings.HasPrefix(path, r.URL.Path+"/") {
+			continue
+		}
+		meta := struct {
+			Metadata ObjectMeta `json:"metadata"`
+		}{}
+		json.Unmarshal(obj, &meta)
+		matches := true
+		for k, v := range want {
+			if meta.Metadata.Labels[k] != v {
+				matches = false
+			}
+		}
+		if matches {
+			items = append(items, obj)
+		}
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"items": items})
+}
+
+func testDeployable(kind sous.ManifestKind, cluster *sous.Cluster) *sous.Deployable {
+	return &sous.Deployable{
+		Status: sous.DeployStatusActive,
+		Deployment: &sous.Deployment{
+			SourceID:    sous.MustNewSourceID("github.com/opentable/example", "api", "1.2.3"),
+			ClusterName: cluster.Name,
+			Cluster:     cluster,
+			Flavor:      "sweet",
+			Kind:        kind,
+			Owners:      sous.NewOwnerSet("judson", "sam"),
+			DeployConfig: sous.DeployConfig{
+				NumInstances: 3,
+				Resources:    sous.Resources{"cpus": "0.5", "memory": "256", "ports": "2"},
+				Env:          sous.Env{"GREETING": "hello"},
+				Metadata:     sous.Metadata{"team": "deploy"},
+				Volumes:      sous.Volumes{{Host: "/etc/ssl", Container: "/ssl", Mode: sous.ReadOnly}},
+				Startup: sous.Startup{
+					CheckReadyProtocol:        "HTTP",
+					CheckReadyURIPath:         "/health",
+					CheckReadyPortIndex:       1,
+					ConnectDelay:              10,
+					Timeout:                   30,
+					CheckReadyFailureStatuses: []int{500},
+					LivenessInterval:          15,
+					LivenessFailureThreshold:  3,
+				},
+				Schedule:         "*/5 * * * *",
+				KillGraceSeconds: 30,
+				Placement: sous.Placement{
+					RequiredAttributes: sous.Attributes{"zone": "us-west-2a"},
+					RackSensitive:      true,
+				},
+			},
+		},
+		BuildArtifact: &sous.BuildArtifact{Name: "docker.example.com/example/api:1.2.3"},
+	}
+}
+
+func TestObjectName(t *testing.T) {
+	name, err := ObjectName(sous.DeploymentID{
+		ManifestID: sous.ManifestID{
+			Source: sous.SourceLocation{
+				Repo: "github.com/ihaveanincrediblylongname/AndILikeMyProjectsToHaveIncrediblyLongNamesToo",
+				Dir:  "and/also/i/bury/my/services/super/deep",
+			},
+			Flavor: "Spicy_Flavor",
+		},
+		Cluster: "prod-east",
+	})
+	require.NoError(t, err)
+	assert.True(t, len(name) <= maxNameLen, "%q is too long", name)
+	assert.Regexp(t, `^[a-z][a-z0-9-]*[a-z0-9]$`, name)
+}
+
+func TestDeployerRoundTrip(t *testing.T) {
+	for _, kind := range []sous.ManifestKind{sous.ManifestKindService, sous.ManifestKindWorker, sous.ManifestKindScheduled} {
+		api := newFakeAPIServer()
+		cluster := &sous.Cluster{Name: "kube-a", Kind: "kubernetes", BaseURL: api.URL}
+		clusters := sous.Clusters{"kube-a": cluster}
+		d := NewDeployer(Config{}, nil, logging.SilentLogSet())
+
+		intended := testDeployable(kind, cluster)
+		pair := &sous.DeployablePair{Post: intended}
+		rez := d.Rectify(pair)
+		require.Nil(t, rez.Error, "%s: %v", kind, rez.Error)
+		assert.Equal(t, sous.CreateDiff, rez.Desc)
+
+		states, err := d.RunningDeployments(sous.NewDummyRegistry(), clusters)
+		require.NoError(t, err)
+		require.Equal(t, 1, states.Len(), "%s", kind)
+
+		actual, ok := states.Get(intended.ID())
+		require.True(t, ok, "%s: deployment %q not found", kind, intended.ID())
+		different, diffs := intended.Deployment.Diff(&actual.Deployment)
+		assert.False(t, different, "%s: %v", kind, diffs)
+
+		api.Close()
+	}
+}
+
+func TestDeployerRoundTripProbeKinds(t *testing.T) {
+	checks := []sous.Startup{
+		{CheckReadyProtocol: "TCP", CheckReadyPortIndex: 1, Timeout: 30},
+		{CheckReadyProtocol: "COMMAND", CheckReadyCommand: []string{"/bin/check", "-q"}, Timeout: 30},
+	}
+	for _, startup := range checks {
+		api := newFakeAPIServer()
+		cluster := &sous.Cluster{Name: "kube-a", Kind: "kubernetes", BaseURL: api.URL}
+		d := NewDeployer(Config{}, nil, logging.SilentLogSet())
+
+		intended := testDeployable(sous.ManifestKindService, cluster)
+		intended.Startup = startup
+		rez := d.Rectify(&sous.DeployablePair{Post: intended})
+		require.Nil(t, rez.Error, "%s: %v", startup.CheckReadyProtocol, rez.Error)
+
+		states, err := d.RunningDeployments(sous.NewDummyRegistry(), sous.Clusters{"kube-a": cluster})
+		require.NoError(t, err)
+		actual, ok := states.Get(intended.ID())
+		require.True(t, ok)
+		assert.Equal(t, startup.CheckReadyProtocol, actual.Startup.CheckReadyProtocol)
+		different, diffs := intended.Deployment.Diff(&actual.Deployment)
+		assert.False(t, different, "%s: %v", startup.CheckReadyProtocol, diffs)
+
+		api.Close()
+	}
+}
+
+func TestDeployerCreatesServiceAndProbe(t *testing.T) {
+	api := newFakeAPIServer()
+	defer api.Close()
+	cluster := &sous.Cluster{Name: "kube-a", Kind: "kubernetes", BaseURL: api.URL}
+	d := NewDeployer(Config{}, nil, logging.SilentLogSet())
+
+	intended := testDeployable(sous.ManifestKindService, cluster)
+	d.Rectify(&sous.DeployablePair{Post: intended})
+
+	name, err := ObjectName(intended.ID())
+	require.NoError(t, err)
+
+	dep := Deployment{}
+	require.NoError(t, json.Unmarshal(api.objects["/apis/apps/v1/namespaces/default/deployments/"+name], &dep))
+	c := dep.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, "0.5", c.Resources.Requests["cpu"])
+	assert.Equal(t, "256Mi", c.Resources.Limits["memory"])
+	require.NotNil(t, c.ReadinessProbe)
+	assert.Equal(t, "/health", c.ReadinessProbe.HTTPGet.Path)
+	assert.Equal(t, "port1", c.ReadinessProbe.HTTPGet.Port)
+	require.NotNil(t, c.LivenessProbe)
+	assert.Equal(t, "/health", c.LivenessProbe.HTTPGet.Path)
+	assert.Equal(t, int32(15), c.LivenessProbe.PeriodSeconds)
+	assert.Equal(t, int32(30), c.LivenessProbe.InitialDelaySeconds)
+
+	svc := Service{}
+	require.NoError(t, json.Unmarshal(api.objects["/api/v1/namespaces/default/services/"+name], &svc))
+	assert.Len(t, svc.Spec.Ports, 2)
+	assert.Equal(t, dep.Spec.Selector.MatchLabels, svc.Spec.Selector)
+}
+
+func TestDeployerModifies(t *testing.T) {
+	api := newFakeAPIServer()
+	defer api.Close()
+	cluster := &sous.Cluster{Name: "kube-a", Kind: "kubernetes", BaseURL: api.URL}
+	clusters := sous.Clusters{"kube-a": cluster}
+	d := NewDeployer(Config{}, nil, logging.SilentLogSet())
+
+	d.Rectify(&sous.DeployablePair{Post: testDeployable(sous.ManifestKindService, cluster)})
+	states, err := d.RunningDeployments(sous.NewDummyRegistry(), clusters)
+	require.NoError(t, err)
+
+	intended := sous.NewDeployments()
+	post := testDeployable(sous.ManifestKindService, cluster)
+	post.NumInstances = 5
+	intended.Add(post.Deployment)
+	pairs := states.Diff(intended).Collect()
+	require.Len(t, pairs, 1)
+	pairs[0].Post.BuildArtifact = post.BuildArtifact
+
+	rez := d.Rectify(pairs[0])
+	require.Nil(t, rez.Error)
+	assert.Equal(t, sous.ModifyDiff, rez.Desc)
+
+	states, err = d.RunningDeployments(sous.NewDummyRegistry(), clusters)
+	require.NoError(t, err)
+	actual, _ := states.Get(post.ID())
+	assert.Equal(t, 5, actual.NumInstances)
+}
+
+type mapSecrets map[string]string
+
+func (ms mapSecrets) ResolveSecret(ref sous.SecretRef) (string, error) {
+	v, ok := ms[ref.String()]
+	if !ok {
+		return "", fmt.Errorf("no secret %s", ref)
+	}
+	return v, nil
+}
+
+func TestDeployerSecretEnv(t *testing.T) {
+	ref := "secret://vault/db/orders#password"
+	api := newFakeAPIServer()
+	defer api.Close()
+	cluster := &sous.Cluster{Name: "kube-a", Kind: "kubernetes", BaseURL: api.URL}
+	clusters := sous.Clusters{"kube-a": cluster}
+
+	intended := testDeployable(sous.ManifestKindService, cluster)
+	intended.Env["DB_PASSWORD"] = ref
+
+	rez := NewDeployer(Config{}, nil, logging.SilentLogSet()).Rectify(&sous.DeployablePair{Post: intended})
+	assert.NotNil(t, rez.Error, "secrets cannot be resolved without a store")
+
+	d := NewDeployer(Config{}, mapSecrets{ref: "hunter2"}, logging.SilentLogSet())
+	rez = d.Rectify(&sous.DeployablePair{Post: intended})
+	require.Nil(t, rez.Error, "%v", rez.Error)
+
+	name, err := ObjectName(intended.ID())
+	require.NoError(t, err)
+	secret := Secret{}
+	require.NoError(t, json.Unmarshal(api.objects["/api/v1/namespaces/default/secrets/"+name], &secret))
+	assert.Equal(t, map[string]string{"DB_PASSWORD": "hunter2"}, secret.StringData)
+
+	raw := string(api.objects["/apis/apps/v1/namespaces/default/deployments/"+name])
+	assert.NotContains(t, raw, "hunter2", "the secret's value should only be in the Secret")
+	dep := Deployment{}
+	require.NoError(t, json.Unmarshal([]byte(raw), &dep))
+	for _, e := range dep.Spec.Template.Spec.Containers[0].Env {
+		if e.Name == "DB_PASSWORD" {
+			require.NotNil(t, e.ValueFrom)
+			assert.Equal(t, &SecretKeySelector{Name: name, Key: "DB_PASSWORD"}, e.ValueFrom.SecretKeyRef)
+		}
+	}
+
+	states, err := d.RunningDeployments(sous.NewDummyRegistry(), clusters)
+	require.NoError(t, err)
+	actual, ok := states.Get(intended.ID())
+	require.True(t, ok)
+	assert.Equal(t, ref, actual.Env["DB_PASSWORD"], "the reference should be read back in place of the value")
+	different, diffs := intended.Deployment.Diff(&actual.Deployment)
+	assert.False(t, different, "%v", diffs)
+}
+
+func TestDeployerRotatesSecrets(t *testing.T) {
+	ref := "secret://vault/db/orders#password"
+	api := newFakeAPIServer()
+	defer api.Close()
+	cluster := &sous.Cluster{Name: "kube-a", Kind: "kubernetes", BaseURL: api.URL}
+	secrets := mapSecrets{ref: "hunter2"}
+	d := NewDeployer(Config{}, secrets, logging.SilentLogSet())
+
+	intended := testDeployable(sous.ManifestKindService, cluster)
+	intended.Env["DB_PASSWORD"] = ref
+	intended.SecretsVersion = 1
+	require.Nil(t, d.Rectify(&sous.DeployablePair{Post: intended}).Error)
+
+	name, err := ObjectName(intended.ID())
+	require.NoError(t, err)
+	template := func() PodTemplateSpec {
+		dep := Deployment{}
+		require.NoError(t, json.Unmarshal(api.objects["/apis/apps/v1/namespaces/default/deployments/"+name], &dep))
+		return dep.Spec.Template
+	}
+	before := template()
+
+	secrets[ref] = "correct horse"
+	rotated := testDeployable(sous.ManifestKindService, cluster)
+	rotated.Env["DB_PASSWORD"] = ref
+	rotated.SecretsVersion = 2
+	rez := d.Rectify(&sous.DeployablePair{Prior: intended, Post: rotated})
+	require.Nil(t, rez.Error, "%v", rez.Error)
+
+	secret := Secret{}
+	require.NoError(t, json.Unmarshal(api.objects["/api/v1/namespaces/default/secrets/"+name], &secret))
+	assert.Equal(t, "correct horse", secret.StringData["DB_PASSWORD"])
+	assert.NotEqual(t, before.Metadata, template().Metadata, "bumping the secrets version should replace the pods")
+}
+
+func TestDeployStatus(t *testing.T) {
+	three := int32(3)
+	d := Deployment{Spec: DeploymentSpec{Replicas: &three}}
+	d.Metadata.Generation = 2
+
+	d.Status = DeploymentStatus{ObservedGeneration: 1, UpdatedReplicas: 3, AvailableReplicas: 3}
+	status, _ := deployStatus(d)
+	assert.Equal(t, sous.DeployStatusPending, status)
+
+	d.Status.ObservedGeneration = 2
+	status, _ = deployStatus(d)
+	assert.Equal(t, sous.DeployStatusActive, status)
+
+	d.Status.Conditions = []Condition{{Type: "Progressing", Status: "False", Reason: "ProgressDeadlineExceeded"}}
+	status, msg := deployStatus(d)
+	assert.Equal(t, sous.DeployStatusFailed, status)
+	assert.Contains(t, msg, "ProgressDeadlineExceeded")
+}
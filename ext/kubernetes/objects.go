@@ -0,0 +1,230 @@
+package kubernetes
+
+// The types in this file are a minimal subset of the Kubernetes API objects
+// that Sous reads and writes. Only the fields Sous cares about are
+// represented; everything else the API server sends is ignored on decode, and
+// omitted on encode.
+
+type (
+	// ObjectMeta is the standard metadata carried by every Kubernetes object.
+	ObjectMeta struct {
+		Name            string            `json:"name,omitempty"`
+		Namespace       string            `json:"namespace,omitempty"`
+		Labels          map[string]string `json:"labels,omitempty"`
+		Annotations     map[string]string `json:"annotations,omitempty"`
+		ResourceVersion string            `json:"resourceVersion,omitempty"`
+		Generation      int64             `json:"generation,omitempty"`
+	}
+
+	// Deployment is an apps/v1 Deployment.
+	Deployment struct {
+		APIVersion string           `json:"apiVersion,omitempty"`
+		Kind       string           `json:"kind,omitempty"`
+		Metadata   ObjectMeta       `json:"metadata"`
+		Spec       DeploymentSpec   `json:"spec"`
+		Status     DeploymentStatus `json:"status,omitempty"`
+	}
+
+	// DeploymentList is the response to listing Deployments.
+	DeploymentList struct {
+		Items []Deployment `json:"items"`
+	}
+
+	// DeploymentSpec describes the desired state of a Deployment.
+	DeploymentSpec struct {
+		Replicas *int32          `json:"replicas,omitempty"`
+		Selector *LabelSelector  `json:"selector,omitempty"`
+		Template PodTemplateSpec `json:"template"`
+	}
+
+	// DeploymentStatus is the most recently observed status of a Deployment.
+	DeploymentStatus struct {
+		ObservedGeneration int64       `json:"observedGeneration,omitempty"`
+		Replicas           int32       `json:"replicas,omitempty"`
+		UpdatedReplicas    int32       `json:"updatedReplicas,omitempty"`
+		ReadyReplicas      int32       `json:"readyReplicas,omitempty"`
+		AvailableReplicas  int32       `json:"availableReplicas,omitempty"`
+		Conditions         []Condition `json:"conditions,omitempty"`
+	}
+
+	// Condition describes one aspect of an object's state.
+	Condition struct {
+		Type    string `json:"type"`
+		Status  string `json:"status"`
+		Reason  string `json:"reason,omitempty"`
+		Message string `json:"message,omitempty"`
+	}
+
+	// CronJob is a batch/v1 CronJob.
+	CronJob struct {
+		APIVersion string      `json:"apiVersion,omitempty"`
+		Kind       string      `json:"kind,omitempty"`
+		Metadata   ObjectMeta  `json:"metadata"`
+		Spec       CronJobSpec `json:"spec"`
+	}
+
+	// CronJobList is the response to listing CronJobs.
+	CronJobList struct {
+		Items []CronJob `json:"items"`
+	}
+
+	// CronJobSpec describes how and when a CronJob runs.
+	CronJobSpec struct {
+		Schedule    string          `json:"schedule"`
+		JobTemplate JobTemplateSpec `json:"jobTemplate"`
+	}
+
+	// JobTemplateSpec describes the Job a CronJob creates.
+	JobTemplateSpec struct {
+		Metadata ObjectMeta `json:"metadata,omitempty"`
+		Spec     JobSpec    `json:"spec"`
+	}
+
+	// JobSpec describes a single Job.
+	JobSpec struct {
+		Parallelism *int32          `json:"parallelism,omitempty"`
+		Template    PodTemplateSpec `json:"template"`
+	}
+
+	// Service is a core/v1 Service.
+	Service struct {
+		APIVersion string      `json:"apiVersion,omitempty"`
+		Kind       string      `json:"kind,omitempty"`
+		Metadata   ObjectMeta  `json:"metadata"`
+		Spec       ServiceSpec `json:"spec"`
+	}
+
+	// ServiceSpec describes the routing of a Service.
+	ServiceSpec struct {
+		Selector  map[string]string `json:"selector,omitempty"`
+		Ports     []ServicePort     `json:"ports,omitempty"`
+		ClusterIP string            `json:"clusterIP,omitempty"`
+	}
+
+	// ServicePort maps a Service port onto a named container port.
+	ServicePort struct {
+		Name       string `json:"name"`
+		Port       int32  `json:"port"`
+		TargetPort string `json:"targetPort,omitempty"`
+	}
+
+	// Secret is a core/v1 Secret. Sous only writes secrets, as StringData,
+	// and never reads their values back.
+	Secret struct {
+		APIVersion string            `json:"apiVersion,omitempty"`
+		Kind       string            `json:"kind,omitempty"`
+		Metadata   ObjectMeta        `json:"metadata"`
+		Type       string            `json:"type,omitempty"`
+		StringData map[string]string `json:"stringData,omitempty"`
+	}
+
+	// LabelSelector selects objects by their labels.
+	LabelSelector struct {
+		MatchLabels map[string]string `json:"matchLabels,omitempty"`
+	}
+
+	// PodTemplateSpec describes the pods created by a controller.
+	PodTemplateSpec struct {
+		Metadata ObjectMeta `json:"metadata,omitempty"`
+		Spec     PodSpec    `json:"spec"`
+	}
+
+	// PodSpec describes a pod.
+	PodSpec struct {
+		Containers    []Container `json:"containers"`
+		Volumes       []Volume    `json:"volumes,omitempty"`
+		RestartPolicy string      `json:"restartPolicy,omitempty"`
+	}
+
+	// Container describes a single container in a pod.
+	Container struct {
+		Name           string               `json:"name"`
+		Image          string               `json:"image"`
+		Env            []EnvVar             `json:"env,omitempty"`
+		Ports          []ContainerPort      `json:"ports,omitempty"`
+		Resources      ResourceRequirements `json:"resources,omitempty"`
+		ReadinessProbe *Probe               `json:"readinessProbe,omitempty"`
+		LivenessProbe  *Probe               `json:"livenessProbe,omitempty"`
+		VolumeMounts   []VolumeMount        `json:"volumeMounts,omitempty"`
+	}
+
+	// EnvVar is a single environment variable, whose value is either Value
+	// or read from ValueFrom.
+	EnvVar struct {
+		Name      string        `json:"name"`
+		Value     string        `json:"value,omitempty"`
+		ValueFrom *EnvVarSource `json:"valueFrom,omitempty"`
+	}
+
+	// EnvVarSource is where an environment variable's value is read from.
+	EnvVarSource struct {
+		SecretKeyRef *SecretKeySelector `json:"secretKeyRef,omitempty"`
+	}
+
+	// SecretKeySelector selects the value of a key of a Secret.
+	SecretKeySelector struct {
+		Name string `json:"name"`
+		Key  string `json:"key"`
+	}
+
+	// ContainerPort is a named port exposed by a container.
+	ContainerPort struct {
+		Name          string `json:"name,omitempty"`
+		ContainerPort int32  `json:"containerPort"`
+	}
+
+	// ResourceRequirements are the compute resources requested by, and the
+	// limits applied to, a container.
+	ResourceRequirements struct {
+		Requests map[string]string `json:"requests,omitempty"`
+		Limits   map[string]string `json:"limits,omitempty"`
+	}
+
+	// Probe is a health check run against a container. Exactly one of
+	// HTTPGet, TCPSocket and Exec is set.
+	Probe struct {
+		HTTPGet             *HTTPGetAction   `json:"httpGet,omitempty"`
+		TCPSocket           *TCPSocketAction `json:"tcpSocket,omitempty"`
+		Exec                *ExecAction      `json:"exec,omitempty"`
+		InitialDelaySeconds int32            `json:"initialDelaySeconds,omitempty"`
+		TimeoutSeconds      int32            `json:"timeoutSeconds,omitempty"`
+		PeriodSeconds       int32            `json:"periodSeconds,omitempty"`
+		FailureThreshold    int32            `json:"failureThreshold,omitempty"`
+	}
+
+	// HTTPGetAction is an HTTP GET health check. Port is always a named port.
+	HTTPGetAction struct {
+		Path   string `json:"path,omitempty"`
+		Port   string `json:"port"`
+		Scheme string `json:"scheme,omitempty"`
+	}
+
+	// TCPSocketAction is a health check which passes if a connection can be
+	// opened to Port, which is always a named port.
+	TCPSocketAction struct {
+		Port string `json:"port"`
+	}
+
+	// ExecAction is a health check which passes if Command exits zero.
+	ExecAction struct {
+		Command []string `json:"command"`
+	}
+
+	// Volume is a volume available to the containers of a pod.
+	Volume struct {
+		Name     string                `json:"name"`
+		HostPath *HostPathVolumeSource `json:"hostPath,omitempty"`
+	}
+
+	// HostPathVolumeSource maps a path on the node into a pod.
+	HostPathVolumeSource struct {
+		Path string `json:"path"`
+	}
+
+	// VolumeMount mounts a Volume into a container.
+	VolumeMount struct {
+		Name      string `json:"name"`
+		MountPath string `json:"mountPath"`
+		ReadOnly  bool   `json:"readOnly,omitempty"`
+	}
+)
@@ -37,8 +37,10 @@ type (
 		Instances int
 		// Owners is a comma-separated list of email addresses.
 		Owners []string
-		// NOTE: We do not currently support Daemon, RackSensitive or LoadBalanced
-		//Daemon, RackSensitive, LoadBalanced bool
+		// RackSensitive spreads instances evenly across racks.
+		RackSensitive bool
+		// NOTE: We do not currently support Daemon or LoadBalanced
+		//Daemon, LoadBalanced bool
 	}
 )
 
@@ -197,5 +199,6 @@ func (mp *ManifestParser) parseSingleOTPLConfig(wd shell.Shell) *otplDeployConfi
 	}
 	deploySpec.Spec.NumInstances = request.Instances
 	deploySpec.Owners = request.Owners
+	deploySpec.Spec.Placement.RackSensitive = request.RackSensitive
 	return deploySpec
 }
@@ -75,6 +75,7 @@ func TestManifestParser_ParseManifest(t *testing.T) {
 		"config/cluster1.flavor1/singularity-request.json": `{
 	        "owners": ["owner1@example.com"],
 	        "instances": 2,
+	        "rackSensitive": true,
 	        "other fields": "are ignored"
 	    }`,
 		"config/cluster1.flavor1/singularity.json": `{
@@ -151,6 +152,7 @@ func TestManifestParser_ParseManifest(t *testing.T) {
 						},
 						NumInstances: 2,
 						Volumes:      sous.Volumes(nil),
+						Placement:    sous.Placement{RackSensitive: true},
 					},
 					Version: semv.MustParse("0.0.0"),
 				},
@@ -0,0 +1,114 @@
+package secrets
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	sous "github.com/opentable/sous/lib"
+	"github.com/pkg/errors"
+)
+
+// A FileStore keeps secrets in a directory: the secret at path p is a JSON
+// object of keys to values in the file p under the directory.
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore returns a FileStore keeping secrets in dir.
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir: dir}
+}
+
+// ResolveSecret implements sous.SecretResolver on FileStore.
+func (fs *FileStore) ResolveSecret(ref sous.SecretRef) (string, error) {
+	secret, err := fs.read(ref.Path)
+	if err != nil {
+		return "", err
+	}
+	v, ok := secret[ref.Key]
+	if !ok {
+		return "", errors.Errorf("secret %s has no key %q", ref.Path, ref.Key)
+	}
+	return v, nil
+}
+
+// SetSecret implements sous.SecretStore on FileStore.
+func (fs *FileStore) SetSecret(path, key, value string) error {
+	secret, err := fs.read(path)
+	if isNoSecret(err) {
+		secret, err = map[string]string{}, nil
+	}
+	if err != nil {
+		return err
+	}
+	secret[key] = value
+	b, err := json.Marshal(secret)
+	if err != nil {
+		return err
+	}
+	file, err := fs.file(path)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(file), ".secret")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
+
+// SecretKeys implements sous.SecretStore on FileStore. A path with no secret
+// has no keys.
+func (fs *FileStore) SecretKeys(path string) ([]string, error) {
+	secret, err := fs.read(path)
+	if isNoSecret(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return sortedKeys(secret), nil
+}
+
+func (fs *FileStore) read(path string) (map[string]string, error) {
+	file, err := fs.file(path)
+	if err != nil {
+		return nil, err
+	}
+	b, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, noSecret(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	secret := map[string]string{}
+	if err := json.Unmarshal(b, &secret); err != nil {
+		return nil, errors.Wrapf(err, "reading secret %s", path)
+	}
+	return secret, nil
+}
+
+// file returns the file holding the secret at path, which must be inside
+// the store's directory.
+func (fs *FileStore) file(path string) (string, error) {
+	clean := filepath.Clean("/" + path)
+	if clean == "/" || strings.Contains(path, "..") {
+		return "", errors.Errorf("invalid secret path %q", path)
+	}
+	return filepath.Join(fs.dir, filepath.FromSlash(clean)), nil
+}
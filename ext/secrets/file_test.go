@@ -0,0 +1,57 @@
+package secrets
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	sous "github.com/opentable/sous/lib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStoreResolveSecret(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sous-secrets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "db"), 0700))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "db", "orders"), []byte(`{"password": "hunter2"}`), 0600))
+
+	fs := NewFileStore(dir)
+	v, err := fs.ResolveSecret(sous.SecretRef{Store: "file", Path: "db/orders", Key: "password"})
+	assert.NoError(t, err)
+	assert.Equal(t, "hunter2", v)
+
+	_, err = fs.ResolveSecret(sous.SecretRef{Store: "file", Path: "db/orders", Key: "user"})
+	assert.Error(t, err)
+	_, err = fs.ResolveSecret(sous.SecretRef{Store: "file", Path: "db/users", Key: "password"})
+	assert.Error(t, err)
+	_, err = fs.ResolveSecret(sous.SecretRef{Store: "file", Path: "../etc/passwd", Key: "root"})
+	assert.Error(t, err)
+}
+
+func TestFileStoreSetSecret(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sous-secrets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	fs := NewFileStore(dir)
+	keys, err := fs.SecretKeys("sous/app/ci")
+	assert.NoError(t, err)
+	assert.Empty(t, keys)
+
+	require.NoError(t, fs.SetSecret("sous/app/ci", "TOKEN", "one"))
+	require.NoError(t, fs.SetSecret("sous/app/ci", "PASSWORD", "two"))
+	require.NoError(t, fs.SetSecret("sous/app/ci", "TOKEN", "three"))
+
+	keys, err = fs.SecretKeys("sous/app/ci")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"PASSWORD", "TOKEN"}, keys)
+
+	v, err := fs.ResolveSecret(sous.SecretRef{Store: "file", Path: "sous/app/ci", Key: "TOKEN"})
+	assert.NoError(t, err)
+	assert.Equal(t, "three", v)
+
+	assert.Error(t, fs.SetSecret("../outside", "KEY", "value"))
+}
@@ -0,0 +1,97 @@
+// Package secrets provides the stores which secret references in deployment
+// env vars are resolved from.
+package secrets
+
+import (
+	"fmt"
+	"sort"
+
+	sous "github.com/opentable/sous/lib"
+	"github.com/pkg/errors"
+)
+
+// Config configures the secret stores.
+type Config struct {
+	// Dir is the directory holding the "file" secret store. If it is empty,
+	// there is no "file" store.
+	Dir string `env:"SOUS_SECRETS_DIR"`
+	// VaultAddr is the URL of the Vault server holding the "vault" secret
+	// store. If it is empty, there is no "vault" store.
+	VaultAddr string `env:"SOUS_SECRETS_VAULT_ADDR"`
+	// VaultToken is the token used to authenticate to Vault.
+	VaultToken string `env:"SOUS_SECRETS_VAULT_TOKEN"`
+	// VaultMount is the path the KV version 2 secrets engine is mounted
+	// at. Defaults to "secret".
+	VaultMount string `env:"SOUS_SECRETS_VAULT_MOUNT"`
+	// Store is the name of the store `sous secrets` manages secrets in:
+	// "vault" or "file". Defaults to "vault" if it is configured, and
+	// otherwise "file".
+	Store string `env:"SOUS_SECRETS_STORE"`
+}
+
+// Resolvers returns resolvers for each store configured by c.
+func (c Config) Resolvers() sous.SecretResolvers {
+	rs := sous.SecretResolvers{}
+	if c.Dir != "" {
+		rs["file"] = NewFileStore(c.Dir)
+	}
+	if c.VaultAddr != "" {
+		rs["vault"] = c.vault()
+	}
+	return rs
+}
+
+// SecretStore returns the name of the store `sous secrets` manages secrets
+// in, and the store itself.
+func (c Config) SecretStore() (string, sous.SecretStore, error) {
+	name := c.Store
+	if name == "" {
+		name = "file"
+		if c.VaultAddr != "" {
+			name = "vault"
+		}
+	}
+	switch name {
+	default:
+		return name, nil, errors.Errorf("unknown secret store %q", name)
+	case "vault":
+		if c.VaultAddr == "" {
+			return name, nil, errors.New("no vault secret store is configured (set Secrets.VaultAddr)")
+		}
+		return name, c.vault(), nil
+	case "file":
+		if c.Dir == "" {
+			return name, nil, errors.New("no file secret store is configured (set Secrets.Dir)")
+		}
+		return name, NewFileStore(c.Dir), nil
+	}
+}
+
+func (c Config) vault() *VaultStore {
+	mount := c.VaultMount
+	if mount == "" {
+		mount = "secret"
+	}
+	return NewVaultStore(c.VaultAddr, c.VaultToken, mount)
+}
+
+// noSecret is the error returned for a path with no secret.
+type noSecret string
+
+func (ns noSecret) Error() string {
+	return fmt.Sprintf("no secret at %s", string(ns))
+}
+
+func isNoSecret(err error) bool {
+	_, is := errors.Cause(err).(noSecret)
+	return is
+}
+
+func sortedKeys(secret map[string]string) []string {
+	keys := make([]string, 0, len(secret))
+	for k := range secret {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
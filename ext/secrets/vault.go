@@ -0,0 +1,151 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	sous "github.com/opentable/sous/lib"
+	"github.com/pkg/errors"
+)
+
+type (
+	// A VaultStore keeps secrets in a HashiCorp Vault (or compatible) KV
+	// version 2 secrets engine.
+	VaultStore struct {
+		addr, token, mount string
+		http               *http.Client
+	}
+
+	// vaultSecret is the data of a KV version 2 read.
+	vaultSecret struct {
+		Data     map[string]string
+		Metadata struct {
+			Version int
+		}
+	}
+
+	// vaultWrite is the body of a KV version 2 write.
+	vaultWrite struct {
+		Options struct {
+			CAS int `json:"cas"`
+		} `json:"options"`
+		Data map[string]string `json:"data"`
+	}
+
+	vaultError struct {
+		Method, URL string
+		Status      int
+		Body        string
+	}
+)
+
+// NewVaultStore returns a VaultStore for the KV engine mounted at mount on
+// the Vault server at addr, authenticating with token.
+func NewVaultStore(addr, token, mount string) *VaultStore {
+	return &VaultStore{
+		addr:  strings.TrimSuffix(addr, "/"),
+		token: token,
+		mount: strings.Trim(mount, "/"),
+		http:  &http.Client{},
+	}
+}
+
+func (e *vaultError) Error() string {
+	return fmt.Sprintf("vault: %s %s: %d %s", e.Method, e.URL, e.Status, e.Body)
+}
+
+// ResolveSecret implements sous.SecretResolver on VaultStore.
+func (vs *VaultStore) ResolveSecret(ref sous.SecretRef) (string, error) {
+	secret, err := vs.read(ref.Path)
+	if err != nil {
+		return "", err
+	}
+	v, ok := secret.Data[ref.Key]
+	if !ok {
+		return "", errors.Errorf("secret %s has no key %q", ref.Path, ref.Key)
+	}
+	return v, nil
+}
+
+// SetSecret implements sous.SecretStore on VaultStore. The write is checked
+// against the version read, so concurrent changes to other keys are not
+// lost.
+func (vs *VaultStore) SetSecret(path, key, value string) error {
+	secret, err := vs.read(path)
+	if isNoSecret(err) {
+		secret, err = &vaultSecret{Data: map[string]string{}}, nil
+	}
+	if err != nil {
+		return err
+	}
+	w := vaultWrite{Data: secret.Data}
+	w.Data[key] = value
+	w.Options.CAS = secret.Metadata.Version
+	return vs.do("POST", path, w, nil)
+}
+
+// SecretKeys implements sous.SecretStore on VaultStore. A path with no secret
+// has no keys.
+func (vs *VaultStore) SecretKeys(path string) ([]string, error) {
+	secret, err := vs.read(path)
+	if isNoSecret(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return sortedKeys(secret.Data), nil
+}
+
+func (vs *VaultStore) read(path string) (*vaultSecret, error) {
+	rz := struct{ Data vaultSecret }{}
+	err := vs.do("GET", path, nil, &rz)
+	if ve, is := err.(*vaultError); is && ve.Status == http.StatusNotFound {
+		return nil, noSecret(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if rz.Data.Data == nil {
+		rz.Data.Data = map[string]string{}
+	}
+	return &rz.Data, nil
+}
+
+func (vs *VaultStore) do(method, path string, body, into interface{}) error {
+	url := fmt.Sprintf("%s/v1/%s/data/%s", vs.addr, vs.mount, strings.TrimPrefix(path, "/"))
+	var rqBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&rqBody).Encode(body); err != nil {
+			return err
+		}
+	}
+	rq, err := http.NewRequest(method, url, &rqBody)
+	if err != nil {
+		return err
+	}
+	rq.Header.Set("X-Vault-Token", vs.token)
+	if body != nil {
+		rq.Header.Set("Content-Type", "application/json")
+	}
+	rz, err := vs.http.Do(rq)
+	if err != nil {
+		return err
+	}
+	defer rz.Body.Close()
+	b, err := ioutil.ReadAll(rz.Body)
+	if err != nil {
+		return err
+	}
+	if rz.StatusCode < 200 || rz.StatusCode >= 300 {
+		return &vaultError{Method: method, URL: url, Status: rz.StatusCode, Body: string(b)}
+	}
+	if into == nil || len(b) == 0 {
+		return nil
+	}
+	return errors.Wrapf(json.Unmarshal(b, into), "vault: %s %s", method, url)
+}
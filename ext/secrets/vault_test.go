@@ -0,0 +1,92 @@
+package secrets
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	sous "github.com/opentable/sous/lib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeVault serves a KV version 2 secrets engine mounted at "kv".
+type fakeVault struct {
+	sync.Mutex
+	secrets  map[string]map[string]string
+	versions map[string]int
+}
+
+func (fv *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fv.Lock()
+	defer fv.Unlock()
+	if r.Header.Get("X-Vault-Token") != "t0k3n" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	path := strings.TrimPrefix(r.URL.Path, "/v1/kv/data/")
+	switch r.Method {
+	case "GET":
+		secret, ok := fv.secrets[path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		rz := map[string]interface{}{"data": map[string]interface{}{
+			"data":     secret,
+			"metadata": map[string]int{"version": fv.versions[path]},
+		}}
+		json.NewEncoder(w).Encode(rz)
+	case "POST":
+		var rq vaultWrite
+		if err := json.NewDecoder(r.Body).Decode(&rq); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if rq.Options.CAS != fv.versions[path] {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fv.secrets[path] = rq.Data
+		fv.versions[path]++
+	}
+}
+
+func TestVaultStore(t *testing.T) {
+	fv := &fakeVault{
+		secrets:  map[string]map[string]string{"db/orders": {"password": "hunter2"}},
+		versions: map[string]int{"db/orders": 1},
+	}
+	srv := httptest.NewServer(fv)
+	defer srv.Close()
+	vs := NewVaultStore(srv.URL+"/", "t0k3n", "/kv/")
+
+	v, err := vs.ResolveSecret(sous.SecretRef{Store: "vault", Path: "db/orders", Key: "password"})
+	assert.NoError(t, err)
+	assert.Equal(t, "hunter2", v)
+	_, err = vs.ResolveSecret(sous.SecretRef{Store: "vault", Path: "db/orders", Key: "user"})
+	assert.Error(t, err)
+	_, err = vs.ResolveSecret(sous.SecretRef{Store: "vault", Path: "db/users", Key: "password"})
+	assert.Error(t, err)
+
+	require.NoError(t, vs.SetSecret("db/orders", "user", "orders"))
+	require.NoError(t, vs.SetSecret("db/users", "password", "letmein"))
+	assert.Equal(t, map[string]string{"password": "hunter2", "user": "orders"}, fv.secrets["db/orders"])
+	assert.Equal(t, 2, fv.versions["db/orders"])
+
+	keys, err := vs.SecretKeys("db/orders")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"password", "user"}, keys)
+	keys, err = vs.SecretKeys("db/missing")
+	assert.NoError(t, err)
+	assert.Empty(t, keys)
+
+	bad := NewVaultStore(srv.URL, "nope", "kv")
+	_, err = bad.SecretKeys("db/orders")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "403")
+	}
+}
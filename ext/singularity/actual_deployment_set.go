@@ -6,6 +6,7 @@ import (
 	"os"
 	"runtime/debug"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/opentable/go-singularity"
@@ -30,6 +31,7 @@ type (
 	SingClient interface {
 		GetDeploy(requestID string, deployID string) (*dtos.SingularityDeployHistory, error)
 		GetDeploys(requestID string, count, page int32) (dtos.SingularityDeployHistoryList, error)
+		GetPendingDeploys() (dtos.SingularityPendingDeployList, error)
 	}
 
 	// SingReq captures a request made to singularity with its initial response
@@ -40,18 +42,56 @@ type (
 	}
 
 	retryCounter map[string]uint
+
+	// countingSingClient counts the requests made through it in requests,
+	// which is shared by all the clients of a poll.
+	countingSingClient struct {
+		SingClient
+		requests *int64
+	}
 )
 
+// GetDeploy implements SingClient on countingSingClient.
+func (c countingSingClient) GetDeploy(requestID string, deployID string) (*dtos.SingularityDeployHistory, error) {
+	atomic.AddInt64(c.requests, 1)
+	return c.SingClient.GetDeploy(requestID, deployID)
+}
+
+// GetDeploys implements SingClient on countingSingClient.
+func (c countingSingClient) GetDeploys(requestID string, count, page int32) (dtos.SingularityDeployHistoryList, error) {
+	atomic.AddInt64(c.requests, 1)
+	return c.SingClient.GetDeploys(requestID, count, page)
+}
+
+// GetPendingDeploys implements SingClient on countingSingClient.
+func (c countingSingClient) GetPendingDeploys() (dtos.SingularityPendingDeployList, error) {
+	atomic.AddInt64(c.requests, 1)
+	return c.SingClient.GetPendingDeploys()
+}
+
 // RunningDeployments collects data from the Singularity clusters and
-// returns a list of actual deployments.
-func (sc *deployer) RunningDeployments(reg sous.Registry, clusters sous.Clusters) (sous.DeployStates, error) {
-	var deps sous.DeployStates
+// returns a list of actual deployments. Only the requests which have changed
+// since the last call are assembled afresh, unless a full poll is due.
+func (sc *deployer) RunningDeployments(reg sous.Registry, clusters sous.Clusters) (deps sous.DeployStates, err error) {
+	cache := sc.cache
+	if cache == nil {
+		cache = newStateCache(0)
+	}
+	stats := &pollStats{started: time.Now()}
+	stats.full = cache.begin(stats.started)
+	defer func() {
+		stats.finished = time.Now()
+		stats.states = deps.Len()
+		reportPoll(sc.log, stats, err)
+	}()
+
 	retries := make(retryCounter)
 	errCh := make(chan error)
 	deps = sous.NewDeployStates()
 	sings := make(map[string]struct{})
 	reqCh := make(chan SingReq, len(clusters)*sc.ReqsPerServer)
 	depCh := make(chan *sous.DeployState, sc.ReqsPerServer)
+	cached := &cachedStates{}
 
 	defer close(depCh)
 	// XXX The intention here was to use something like the gotools context to
@@ -71,10 +111,10 @@ func (sc *deployer) RunningDeployments(reg sous.Registry, clusters sous.Clusters
 		//sing.Debug = true
 		sings[url] = struct{}{}
 		client := sc.buildSingClient(url)
-		go singPipeline(reg, url, client, &depWait, &singWait, reqCh, errCh, clusters)
+		go singPipeline(reg, url, client, cache, stats, &depWait, &singWait, reqCh, cached, errCh, clusters)
 	}
 
-	go depPipeline(reg, clusters, MaxAssemblers, &depAssWait, reqCh, depCh, errCh)
+	go depPipeline(reg, clusters, cache, stats, MaxAssemblers, &depAssWait, reqCh, depCh, errCh)
 
 	go func() {
 		defer catchAndSend("closing channels", errCh)
@@ -103,6 +143,8 @@ func (sc *deployer) RunningDeployments(reg sous.Registry, clusters sous.Clusters
 		case err, cont := <-errCh:
 			if !cont {
 				Log.Debug.Printf("Errors channel closed. Finishing up.")
+				// Every singPipeline is done by now.
+				cached.addTo(deps)
 				return deps, nil
 			}
 			if isMalformed(err) || ignorableDeploy(err) {
@@ -198,8 +240,11 @@ func singPipeline(
 	reg sous.Registry,
 	url string,
 	client *singularity.Client,
+	cache *stateCache,
+	stats *pollStats,
 	dw, wg *sync.WaitGroup,
 	reqs chan SingReq,
+	cached *cachedStates,
 	errs chan error,
 	//	clusters []string,
 	clusters sous.Clusters,
@@ -208,6 +253,7 @@ func singPipeline(
 	defer func() { Log.Vomit.Printf("Completed cluster at %s", url) }()
 	defer wg.Done()
 	defer catchAndSend(fmt.Sprintf("get requests: %s", url), errs)
+	atomic.AddInt64(&stats.requests, 1)
 	srp, err := getSingularityRequestParents(client)
 	if err != nil {
 		Log.Vomit.Print(err) //XXX connection reset by peer should be retried
@@ -215,9 +261,18 @@ func singPipeline(
 		return
 	}
 
-	rs := convertSingularityRequestParentsToSingReqs(url, client, srp)
+	counted := countingSingClient{SingClient: client, requests: &stats.requests}
+	rs := convertSingularityRequestParentsToSingReqs(url, counted, srp)
+	cache.retain(clusters, url, rs)
 
 	for _, r := range rs {
+		if dep, ok := cache.lookup(clusters, r); ok {
+			atomic.AddInt64(&stats.cached, 1)
+			if dep != nil {
+				cached.add(dep)
+			}
+			continue
+		}
 		Log.Vomit.Printf("Req: %s %s %d", r.SourceURL, reqID(r.ReqParent), r.ReqParent.Request.Instances)
 		dw.Add(1)
 		reqs <- r
@@ -232,7 +287,7 @@ func getSingularityRequestParents(client *singularity.Client) ([]*dtos.Singulari
 	return singRequests, errors.Wrap(err, "getting request")
 }
 
-func convertSingularityRequestParentsToSingReqs(url string, client *singularity.Client, srp []*dtos.SingularityRequestParent) []SingReq {
+func convertSingularityRequestParentsToSingReqs(url string, client SingClient, srp []*dtos.SingularityRequestParent) []SingReq {
 	reqs := make([]SingReq, 0, len(srp))
 
 	for _, sr := range srp {
@@ -244,6 +299,8 @@ func convertSingularityRequestParentsToSingReqs(url string, client *singularity.
 func depPipeline(
 	reg sous.Registry,
 	clusters sous.Clusters,
+	cache *stateCache,
+	stats *pollStats,
 	poolCount int,
 	depAssWait *sync.WaitGroup,
 	reqCh chan SingReq,
@@ -264,11 +321,16 @@ func depPipeline(
 				<-poolLimit
 			}()
 
+			atomic.AddInt64(&stats.assembled, 1)
 			dep, err := assembleDeployState(reg, clusters, req)
 
 			if err != nil {
+				if ignorableDeploy(err) {
+					cache.store(clusters, req, nil)
+				}
 				errCh <- errors.Wrap(err, "assembly problem")
 			} else {
+				cache.store(clusters, req, dep)
 				depCh <- dep
 			}
 		}(req)
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"runtime/debug"
 	"strings"
+	"time"
 
 	"github.com/opentable/go-singularity"
 	"github.com/opentable/sous/lib"
@@ -49,6 +50,8 @@ type (
 		singFac       func(string) *singularity.Client
 		ReqsPerServer int
 		log           logging.LogSink
+		// cache holds the deploy states of the last poll.
+		cache *stateCache
 	}
 
 	// DeployerOption is an option for configuring singularity deployers.
@@ -64,6 +67,26 @@ type (
 
 		// DeleteRequest instructs Singularity to delete a particular request
 		DeleteRequest(cluster, reqID, message string) error
+
+		// AdvanceDeploy moves a staged deploy on to targetInstances new
+		// instances.
+		AdvanceDeploy(cluster, reqID, deployID string, targetInstances int) error
+
+		// CancelDeploy abandons a pending deploy, leaving the previous deploy
+		// in place.
+		CancelDeploy(cluster, reqID, deployID string) error
+
+		// Tasks lists the running tasks of the active deploy of a request.
+		// It only reads from Singularity.
+		Tasks(cluster, reqID string) ([]sous.TaskInfo, error)
+
+		// ReadTaskFile reads a file in the sandbox of a task, from offset
+		// on. It only reads from Singularity.
+		ReadTaskFile(cluster, taskID, path string, offset int64) (*sous.FileChunk, error)
+
+		// RequestID finds the ID of the request which runs d. It only reads
+		// from Singularity.
+		RequestID(d *sous.Deployment) (string, error)
 	}
 
 	// DTOMap is shorthand for map[string]interface{}
@@ -80,7 +103,12 @@ func stripDeployID(in string) string {
 
 // NewDeployer creates a new Singularity-based sous.Deployer.
 func NewDeployer(c rectificationClient, ls logging.LogSink, options ...DeployerOption) sous.Deployer {
-	d := &deployer{Client: c, log: ls, ReqsPerServer: DefaultMaxHTTPConcurrencyPerServer}
+	d := &deployer{
+		Client:        c,
+		log:           ls,
+		ReqsPerServer: DefaultMaxHTTPConcurrencyPerServer,
+		cache:         newStateCache(DefaultFullPollInterval),
+	}
 	for _, opt := range options {
 		opt(d)
 	}
@@ -93,6 +121,21 @@ func OptMaxHTTPReqsPerServer(n int) DeployerOption {
 	return func(d *deployer) { d.ReqsPerServer = n }
 }
 
+// OptFullPollInterval overrides the DefaultFullPollInterval for this
+// deployer. Zero means every poll is a full poll.
+func OptFullPollInterval(interval time.Duration) DeployerOption {
+	return func(d *deployer) { d.cache.fullInterval = interval }
+}
+
+// InvalidateRequest implements sous.StateInvalidator on deployer, so that
+// the state of a request reported changed by a Singularity webhook is
+// assembled afresh by the next poll.
+func (r *deployer) InvalidateRequest(requestID string) {
+	if r.cache != nil {
+		r.cache.invalidate(requestID)
+	}
+}
+
 // Rectify invokes actions to ensure that the real world matches pair.Post,
 // given that it currently matches pair.Prior.
 func (r *deployer) Rectify(pair *sous.DeployablePair) sous.DiffResolution {
@@ -135,7 +178,11 @@ func (r *deployer) Rectify(pair *sous.DeployablePair) sous.DiffResolution {
 		Log.Vomit.Printf("Reporting result of delete: %#v", result)
 		return result
 	case sous.ModifiedKind:
-		result := sous.DiffResolution{DeploymentID: pair.ID()}
+		if result, ok := r.rectifyRollout(pair); ok {
+			Log.Vomit.Printf("Reporting result of rollout: %#v", result)
+			return result
+		}
+		result := sous.DiffResolution{DeploymentID: pair.ID(), Rollout: pair.Post.Rollout}
 		if err := r.RectifySingleModification(pair); err != nil {
 			dp := &sous.DeploymentPair{
 				Prior: pair.Prior.Deployment.Clone(),
@@ -257,6 +304,57 @@ func (r *deployer) RectifySingleModification(pair *sous.DeployablePair) (err err
 	return nil
 }
 
+// rectifyRollout steps a staged rollout on, or abandons it, as planned by the
+// resolver. It returns false if pair does not continue a rollout already in
+// progress, in which case it should be rectified as any other modification.
+func (r *deployer) rectifyRollout(pair *sous.DeployablePair) (result sous.DiffResolution, ok bool) {
+	post, prior := pair.Post.Rollout, pair.Prior.Rollout
+	if post == nil || prior == nil ||
+		!pair.Prior.SourceID.Equal(pair.Post.SourceID) ||
+		changesReq(pair) || changesDepConfig(pair) {
+		return result, false
+	}
+
+	result = sous.DiffResolution{DeploymentID: pair.ID(), Rollout: post}
+	fail := func(desc sous.ResolutionType, err error) (sous.DiffResolution, bool) {
+		Log.Warn.Println(err)
+		result.Desc = desc
+		result.Error = sous.WrapResolveError(&sous.ChangeError{
+			Deployments: &sous.DeploymentPair{
+				Prior: pair.Prior.Deployment.Clone(),
+				Post:  pair.Post.Deployment.Clone(),
+			},
+			Err: err,
+		})
+		return result, true
+	}
+
+	data, isSing := pair.ExecutorData.(*singularityTaskData)
+	if !isSing {
+		return fail("not updated", errors.Errorf("Rollout record %#v doesn't contain Singularity compatible data: was %T", pair.ID(), pair.ExecutorData))
+	}
+	cluster := pair.Post.Cluster.BaseURL
+
+	switch {
+	default:
+		result.Desc = sous.ComingDiff
+	case post.RollingBack:
+		Log.Notice.Printf("Rolling back %q: cancelling deploy %q", pair.ID(), data.deployID)
+		if err := r.Client.CancelDeploy(cluster, data.requestID, data.deployID); err != nil {
+			return fail("not rolled back", err)
+		}
+		result.Desc = sous.RollbackDiff
+		result.Error = sous.WrapResolveError(&sous.FailedStatusError{})
+	case post.Advances(prior):
+		Log.Notice.Printf("Advancing %q to %s", pair.ID(), post)
+		if err := r.Client.AdvanceDeploy(cluster, data.requestID, data.deployID, post.TargetInstances); err != nil {
+			return fail("not updated", err)
+		}
+		result.Desc = sous.ModifyDiff
+	}
+	return result, true
+}
+
 // XXX for logging and other UI purposes, the best thing would be if the
 // DeployablePair had a "diff" method that returned a (cached) list of
 // differences, which these two functions could filter for req/dep triggering
@@ -267,17 +365,25 @@ func changesReq(pair *sous.DeployablePair) bool {
 	return (pair.Prior.Kind == sous.ManifestKindScheduled && pair.Prior.Schedule != pair.Post.Schedule) ||
 		pair.Prior.Kind != pair.Post.Kind ||
 		pair.Prior.NumInstances != pair.Post.NumInstances ||
-		!pair.Prior.Owners.Equal(pair.Post.Owners)
+		!pair.Prior.Owners.Equal(pair.Post.Owners) ||
+		pair.Prior.ScheduleTimeZone != pair.Post.ScheduleTimeZone ||
+		pair.Prior.TaskTimeLimitSeconds != pair.Post.TaskTimeLimitSeconds ||
+		pair.Prior.KillGraceSeconds != pair.Post.KillGraceSeconds ||
+		!pair.Prior.Placement.Equal(pair.Post.Placement)
 }
 
 func changesDep(pair *sous.DeployablePair) bool {
 	return pair.Post.Status == sous.DeployStatusFailed ||
 		pair.Prior.Status == sous.DeployStatusFailed ||
-		!(pair.Prior.SourceID.Equal(pair.Post.SourceID) &&
-			pair.Prior.Resources.Equal(pair.Post.Resources) &&
-			pair.Prior.Env.Equal(pair.Post.Env) &&
-			pair.Prior.DeployConfig.Volumes.Equal(pair.Post.DeployConfig.Volumes) &&
-			pair.Prior.Startup.Equal(pair.Post.Startup))
+		changesDepConfig(pair)
+}
+
+func changesDepConfig(pair *sous.DeployablePair) bool {
+	return !(pair.Prior.SourceID.Equal(pair.Post.SourceID) &&
+		pair.Prior.Resources.Equal(pair.Post.Resources) &&
+		pair.Prior.Env.Equal(pair.Post.Env) &&
+		pair.Prior.DeployConfig.Volumes.Equal(pair.Post.DeployConfig.Volumes) &&
+		pair.Prior.Startup.Equal(pair.Post.Startup))
 }
 
 func computeRequestID(d *sous.Deployable) (string, error) {
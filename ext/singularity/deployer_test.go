@@ -279,7 +279,7 @@ func matchedPair(t *testing.T, startDep *sous.Deployment) *sous.DeployablePair {
 	req := &dtos.SingularityRequest{}
 	jsonRoundtrip(t, aReq, req)
 
-	aDepReq, err := buildDeployRequest(deployable, reqID, map[string]string{})
+	aDepReq, err := buildDeployRequest(deployable, reqID, map[string]string{}, nil)
 	assert.NoError(t, err)
 	assert.NotNil(t, aDepReq)
 
@@ -369,6 +369,33 @@ func TestScheduling(t *testing.T) {
 	assert.False(t, changesDep(pair), "Roundtrip of Deployment through Singularity DTOs reported as changing Deploy!")
 }
 
+func TestSchedulingOptions(t *testing.T) {
+	startDep := baseDeployment()
+	startDep.Kind = sous.ManifestKindScheduled
+	startDep.Schedule = "* 3 * * *"
+	startDep.ScheduleTimeZone = "America/Los_Angeles"
+	startDep.TaskTimeLimitSeconds = 600
+	startDep.KillGraceSeconds = 30
+	startDep.Placement = sous.Placement{
+		RequiredAttributes: sous.Attributes{"zone": "us-west-2a"},
+		AllowedAttributes:  sous.Attributes{"reserved": "batch"},
+		RackSensitive:      true,
+	}
+	pair := matchedPair(t, startDep)
+
+	diff, diffs := pair.Prior.Deployment.Diff(pair.Post.Deployment)
+	assert.False(t, diff, "%v", diffs)
+	assert.False(t, changesReq(pair), "Roundtrip of Deployment through Singularity DTOs reported as changing Request!")
+
+	pair.Prior.Placement.RackSensitive = false
+	assert.True(t, changesReq(pair), "Updating placement reported as not changing Request!")
+	assert.False(t, changesDep(pair), "Updating placement reported as changing Deploy!")
+	pair.Prior.Placement.RackSensitive = true
+
+	pair.Prior.TaskTimeLimitSeconds = 60
+	assert.True(t, changesReq(pair), "Updating task time limit reported as not changing Request!")
+}
+
 func TestSchedulingOnlyForScheduled(t *testing.T) {
 	startDep := baseDeployment()
 	startDep.Schedule = "* 3 * * *"
@@ -680,3 +707,72 @@ func TestOptMaxHTTPReqsPerServer(t *testing.T) {
 		t.Fatalf("got %d; want %d", deployer2.ReqsPerServer, x)
 	}
 }
+
+func TestRolloutAdvanceAndRollback(t *testing.T) {
+	drc := sous.NewDummyRectificationClient()
+	deployer := NewDeployer(drc, logging.SilentLogSet())
+
+	dpl := &sous.Deployment{
+		SourceID: sous.MustNewSourceID("fake.tld/org/project", "", "0.0.2"),
+		DeployConfig: sous.DeployConfig{
+			NumInstances: 10,
+			Resources:    sous.Resources{},
+		},
+		ClusterName: "cluster",
+		Cluster:     &sous.Cluster{BaseURL: "cluster"},
+	}
+	pair := func(priorStatus sous.DeployStatus, prior, post *sous.RolloutProgress) *sous.DeployablePair {
+		return &sous.DeployablePair{
+			ExecutorData: &singularityTaskData{requestID: "reqid", deployID: "depid"},
+			Prior:        &sous.Deployable{Deployment: dpl.Clone(), Status: priorStatus, Rollout: prior},
+			Post:         &sous.Deployable{Deployment: dpl.Clone(), Status: sous.DeployStatusActive, Rollout: post},
+		}
+	}
+
+	rez := deployer.Rectify(pair(sous.DeployStatusPending,
+		&sous.RolloutProgress{Step: 1, Of: 3, TargetInstances: 1, StepComplete: true},
+		&sous.RolloutProgress{Step: 2, Of: 3, TargetInstances: 5}))
+	assert.Equal(t, sous.ModifyDiff, rez.Desc)
+	assert.Nil(t, rez.Error)
+	require.Len(t, drc.Advanced, 1)
+	assert.Equal(t, 5, drc.Advanced[0].Instances)
+	assert.Equal(t, "depid", drc.Advanced[0].DeployID)
+	assert.Equal(t, 2, rez.Rollout.Step)
+	assert.Len(t, drc.Deployed, 0)
+
+	rez = deployer.Rectify(pair(sous.DeployStatusPending,
+		&sous.RolloutProgress{Step: 1, Of: 3, TargetInstances: 1},
+		&sous.RolloutProgress{Step: 1, Of: 3, TargetInstances: 1}))
+	assert.Equal(t, sous.ComingDiff, rez.Desc)
+	assert.Len(t, drc.Advanced, 1)
+
+	rez = deployer.Rectify(pair(sous.DeployStatusFailed,
+		&sous.RolloutProgress{Step: 2, Of: 3, TargetInstances: 5},
+		&sous.RolloutProgress{Step: 2, Of: 3, TargetInstances: 5, RollingBack: true}))
+	assert.Equal(t, sous.RollbackDiff, rez.Desc)
+	assert.NotNil(t, rez.Error)
+	require.Len(t, drc.Canceled, 1)
+	assert.Len(t, drc.Deployed, 0)
+}
+
+func TestBuildDeployRequest_Staged(t *testing.T) {
+	d := sous.Deployable{
+		Deployment: &sous.Deployment{
+			SourceID:     sous.MustNewSourceID("fake.tld/org/project", "", "0.0.2"),
+			DeployConfig: sous.DeployConfig{NumInstances: 10, Resources: sous.Resources{}},
+			ClusterName:  "cluster",
+			Cluster:      &sous.Cluster{BaseURL: "cluster"},
+		},
+		BuildArtifact: &sous.BuildArtifact{Name: "build-artifact"},
+		Rollout:       &sous.RolloutProgress{Step: 1, Of: 3, TargetInstances: 1},
+	}
+	dr, err := buildDeployRequest(d, "reqid", map[string]string{}, nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, dr.Deploy.DeployInstanceCountPerStep)
+	assert.False(t, dr.Deploy.AutoAdvanceDeploySteps)
+
+	d.Rollout = nil
+	dr, err = buildDeployRequest(d, "reqid", map[string]string{}, nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 0, dr.Deploy.DeployInstanceCountPerStep)
+}
@@ -3,7 +3,9 @@ package singularity
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/opentable/go-singularity/dtos"
 	"github.com/opentable/sous/ext/docker"
@@ -126,6 +128,7 @@ func (db *deploymentBuilder) completeConstruction() error {
 		wrapError(db.extractDeployFromDeployHistory, "Failed to extract SingularityDeploy from SingularityDeployHistory."),
 		wrapError(db.sousDeployCheck, "Could not determine if the SingularityDeploy is controlled by Sous"),
 		wrapError(db.determineStatus, "Could not determine current status of SingularityDeploy"),
+		wrapError(db.determineRollout, "Could not determine progress of staged SingularityDeploy"),
 		wrapError(db.extractArtifactName, "Could not extract ArtifactName (Docker image name) from SingularityDeploy."),
 		wrapError(db.retrieveImageLabels, "Could not retrieve ImageLabels (Docker image labels) from sous.Registry."),
 		wrapError(db.restoreFromMetadata, "Could not determine cluster name based on SingularityDeploy Metadata."),
@@ -257,6 +260,9 @@ func (db *deploymentBuilder) extractDeployFromDeployHistory() error {
 	if db.deploy == nil {
 		return malformedResponse{"Singularity deploy history included no deploy"}
 	}
+	if data, ok := db.Target.ExecutorData.(*singularityTaskData); ok {
+		data.deployID = db.deploy.Id
+	}
 
 	return nil
 }
@@ -302,6 +308,46 @@ func (db *deploymentBuilder) determineStatus() error {
 	return nil
 }
 
+// determineRollout records the progress of a pending staged deploy, which is
+// one that Sous advances step by step. A staged deploy with failed tasks is
+// considered failed, so that the resolver can roll it back.
+func (db *deploymentBuilder) determineRollout() error {
+	if db.Target.Status != sous.DeployStatusPending ||
+		db.deploy.DeployInstanceCountPerStep == 0 ||
+		db.deploy.AutoAdvanceDeploySteps {
+		return nil
+	}
+	// !!! makes HTTP req
+	pending, err := db.req.Sing.GetPendingDeploys()
+	if err != nil {
+		return errors.Wrap(err, "GetPendingDeploys")
+	}
+	for _, pd := range pending {
+		if pd.DeployMarker == nil || pd.DeployProgress == nil ||
+			pd.DeployMarker.RequestId != db.deploy.RequestId ||
+			pd.DeployMarker.DeployId != db.deploy.Id {
+			continue
+		}
+		progress := pd.DeployProgress
+		db.Target.Rollout = &sous.RolloutProgress{
+			TargetInstances: int(progress.TargetActiveInstances),
+			ActiveInstances: int(progress.CurrentActiveInstances),
+			StepComplete:    progress.StepComplete,
+			Updated:         time.Unix(0, progress.Timestamp*int64(time.Millisecond)),
+		}
+		if len(progress.FailedDeployTasks) > 0 {
+			db.Target.Status = sous.DeployStatusFailed
+			db.Target.ExecutorMessage = fmt.Sprintf("Staged deploy has %d failed tasks: %s/request/%s/deploy/%s",
+				len(progress.FailedDeployTasks),
+				db.req.SourceURL,
+				db.deploy.RequestId,
+				db.deploy.Id,
+			)
+		}
+	}
+	return nil
+}
+
 func (db *deploymentBuilder) extractArtifactName() error {
 	logFDs("before extractArtifactName()")
 	defer logFDs("after extractArtifactName()")
@@ -366,10 +412,23 @@ func (db *deploymentBuilder) restoreFromMetadata() error {
 }
 
 func (db *deploymentBuilder) unpackDeployConfig() error {
-	db.Target.Env = db.deploy.Env
-	Log.Vomit.Printf("%q Env: %+v", db.reqID, db.deploy.Env)
-	if db.Target.Env == nil {
-		db.Target.Env = make(map[string]string)
+	db.Target.Env = make(map[string]string, len(db.deploy.Env))
+	for name, v := range db.deploy.Env {
+		db.Target.Env[name] = v
+	}
+	// Env vars resolved from secrets are compared by their references.
+	for label, ref := range db.deploy.Metadata {
+		if name := strings.TrimPrefix(label, sous.SecretRefLabelPrefix); name != label {
+			db.Target.Env[name] = ref
+		}
+	}
+	Log.Vomit.Printf("%q Env: %+v", db.reqID, db.Target.Env)
+	if v, ok := db.deploy.Metadata[sous.SecretsVersionLabel]; ok {
+		version, err := strconv.Atoi(v)
+		if err != nil {
+			return malformedResponse{fmt.Sprintf("Deploy Metadata %s is not a number: %q", sous.SecretsVersionLabel, v)}
+		}
+		db.Target.SecretsVersion = version
 	}
 
 	singRez := db.deploy.Resources
@@ -382,6 +441,14 @@ func (db *deploymentBuilder) unpackDeployConfig() error {
 	db.Target.Resources["ports"] = fmt.Sprintf("%d", singRez.NumPorts)
 
 	db.Target.NumInstances = int(db.request.Instances)
+	db.Target.ScheduleTimeZone = db.request.ScheduleTimeZone
+	db.Target.TaskTimeLimitSeconds = int(db.request.TaskExecutionTimeLimitMillis / 1000)
+	db.Target.KillGraceSeconds = int(db.request.KillOldNonLongRunningTasksAfterMillis / 1000)
+	db.Target.Placement = sous.Placement{
+		RequiredAttributes: sous.Attributes(db.request.RequiredSlaveAttributes).Clone(),
+		AllowedAttributes:  sous.Attributes(db.request.AllowedSlaveAttributes).Clone(),
+		RackSensitive:      db.request.RackSensitive,
+	}
 	db.Target.Owners = make(sous.OwnerSet)
 	for _, o := range db.request.Owners {
 		db.Target.Owners.Add(o)
@@ -405,6 +472,9 @@ func (db *deploymentBuilder) unpackDeployConfig() error {
 		db.Target.Startup.Timeout = int(db.deploy.Healthcheck.StartupTimeoutSeconds)
 		db.Target.Startup.ConnectInterval = int(db.deploy.Healthcheck.StartupIntervalSeconds)
 		db.Target.Startup.CheckReadyProtocol = string(db.deploy.Healthcheck.Protocol)
+		if db.Target.Startup.CheckReadyProtocol == "" && db.deploy.Healthcheck.Uri == "" {
+			db.Target.Startup.CheckReadyProtocol = "TCP"
+		}
 		db.Target.Startup.CheckReadyURIPath = string(db.deploy.Healthcheck.Uri)
 		db.Target.Startup.CheckReadyPortIndex = int(db.deploy.Healthcheck.PortIndex)
 		db.Target.Startup.CheckReadyURITimeout = int(db.deploy.Healthcheck.ResponseTimeoutSeconds)
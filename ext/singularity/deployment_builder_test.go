@@ -15,6 +15,7 @@ import (
 type (
 	fakeSingClient struct {
 		cannedAnswer *dtos.SingularityDeployHistory
+		pending      dtos.SingularityPendingDeployList
 	}
 
 	fakeImageLabeller struct {
@@ -30,6 +31,10 @@ func (fake *fakeSingClient) GetDeploys(requestID string, count, page int32) (dto
 	return dtos.SingularityDeployHistoryList{fake.cannedAnswer}, nil
 }
 
+func (fake *fakeSingClient) GetPendingDeploys() (dtos.SingularityPendingDeployList, error) {
+	return fake.pending, nil
+}
+
 func (fake *fakeImageLabeller) ImageLabels(imageName string) (labels map[string]string, err error) {
 	return fake.cannedAnswer, nil
 }
@@ -438,3 +443,37 @@ func TestConstructDeployment(t *testing.T) {
 	}
 }
 */
+
+func TestBuildDeployment_determineRollout(t *testing.T) {
+	deploy := &dtos.SingularityDeploy{
+		Id:                         "depid",
+		RequestId:                  "reqid",
+		DeployInstanceCountPerStep: 1,
+	}
+	progress := &dtos.SingularityDeployProgress{
+		TargetActiveInstances:  5,
+		CurrentActiveInstances: 5,
+		StepComplete:           true,
+		Timestamp:              1500000000000,
+	}
+	db := &deploymentBuilder{
+		deploy: deploy,
+		req: SingReq{Sing: &fakeSingClient{pending: dtos.SingularityPendingDeployList{
+			{DeployMarker: &dtos.SingularityDeployMarker{RequestId: "other", DeployId: "depid"}},
+			{DeployMarker: &dtos.SingularityDeployMarker{RequestId: "reqid", DeployId: "depid"}, DeployProgress: progress},
+		}}},
+	}
+	db.Target.Status = sous.DeployStatusPending
+
+	assert.NoError(t, db.determineRollout())
+	if assert.NotNil(t, db.Target.Rollout) {
+		assert.Equal(t, 5, db.Target.Rollout.TargetInstances)
+		assert.True(t, db.Target.Rollout.StepComplete)
+		assert.Equal(t, int64(1500000000), db.Target.Rollout.Updated.Unix())
+	}
+	assert.Equal(t, sous.DeployStatusPending, db.Target.Status)
+
+	progress.FailedDeployTasks = dtos.SingularityTaskIdList{&dtos.SingularityTaskId{}}
+	assert.NoError(t, db.determineRollout())
+	assert.Equal(t, sous.DeployStatusFailed, db.Target.Status)
+}
@@ -0,0 +1,148 @@
+package singularity
+
+import (
+	"fmt"
+
+	"github.com/opentable/go-singularity"
+	"github.com/opentable/go-singularity/dtos"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/firsterr"
+	"github.com/pkg/errors"
+)
+
+type (
+	// An Importer finds the requests on a Singularity which Sous did not
+	// create, and adopts them. It implements sous.Importer.
+	Importer struct {
+		registry sous.Registry
+		client   rectificationClient
+		singFac  func(url string) importClient
+	}
+
+	// importClient is the part of the Singularity client an Importer uses.
+	importClient interface {
+		SingClient
+		GetRequests(useWebCache bool) (dtos.SingularityRequestParentList, error)
+		GetRequest(requestID string, useWebCache bool) (*dtos.SingularityRequestParent, error)
+	}
+
+	alreadyManagedError struct {
+		requestID string
+	}
+)
+
+func (ame alreadyManagedError) Error() string {
+	return fmt.Sprintf("request %q is already managed by Sous", ame.requestID)
+}
+
+// NewImporter returns an Importer which finds the source IDs of images with
+// reg, and adopts requests by deploying them with c.
+func NewImporter(reg sous.Registry, c rectificationClient) *Importer {
+	return &Importer{
+		registry: reg,
+		client:   c,
+		singFac: func(url string) importClient {
+			return singularity.NewClient(url)
+		},
+	}
+}
+
+// Unmanaged implements sous.Importer. Requests which cannot be understood as
+// Sous deployments are skipped, unless they were asked for by ID.
+func (im *Importer) Unmanaged(cluster *sous.Cluster, requestIDs ...string) ([]sous.UnmanagedDeployment, error) {
+	client := im.singFac(cluster.BaseURL)
+	var rps dtos.SingularityRequestParentList
+	if len(requestIDs) == 0 {
+		all, err := client.GetRequests(false)
+		if err != nil {
+			return nil, errors.Wrap(err, "getting requests")
+		}
+		rps = all
+	}
+	for _, id := range requestIDs {
+		rp, err := client.GetRequest(id, false)
+		if err != nil {
+			return nil, errors.Wrapf(err, "getting request %q", id)
+		}
+		rps = append(rps, rp)
+	}
+
+	uds := []sous.UnmanagedDeployment{}
+	for _, rp := range rps {
+		ud, err := im.inferDeployment(cluster, SingReq{SourceURL: cluster.BaseURL, Sing: client, ReqParent: rp})
+		if err != nil {
+			if len(requestIDs) > 0 {
+				return nil, err
+			}
+			if _, managed := errors.Cause(err).(alreadyManagedError); !managed {
+				Log.Warn.Printf("Not importing request %q: %s", reqID(rp), err)
+			}
+			continue
+		}
+		uds = append(uds, ud)
+	}
+	return uds, nil
+}
+
+// Adopt implements sous.Importer. It deploys the request afresh, with the
+// configuration inferred for it, so that the new deploy carries the metadata
+// which marks it as managed by Sous. Singularity can't change the metadata of
+// a deploy, so the request's tasks are replaced as with any other deploy.
+func (im *Importer) Adopt(u sous.UnmanagedDeployment) error {
+	return im.client.Deploy(sous.Deployable{
+		Status:        sous.DeployStatusActive,
+		Deployment:    u.Deployment,
+		BuildArtifact: u.Artifact,
+	}, u.RequestID)
+}
+
+// inferDeployment is the inverse of buildDeployRequest: it works out the
+// deployment which Sous would have deployed as req, on cluster.
+func (im *Importer) inferDeployment(cluster *sous.Cluster, req SingReq) (sous.UnmanagedDeployment, error) {
+	db := deploymentBuilder{clusters: sous.Clusters{cluster.Name: cluster}, req: req}
+	wrapError := func(fn func() error, msgStr string) func() error {
+		return func() error {
+			return errors.Wrap(fn(), msgStr)
+		}
+	}
+	err := firsterr.Returned(
+		wrapError(db.basics, "Failed to extract basic information from original request."),
+		wrapError(db.determineDeployStatus, "Failed to determine deploy status."),
+		wrapError(db.retrieveDeployHistory, "Failed to retrieve SingularityDeployHistory from SingularityRequestParent."),
+		wrapError(db.extractDeployFromDeployHistory, "Failed to extract SingularityDeploy from SingularityDeployHistory."),
+		wrapError(db.unmanagedCheck, "Could not determine if the SingularityDeploy is controlled by Sous"),
+		wrapError(db.extractArtifactName, "Could not extract ArtifactName (Docker image name) from SingularityDeploy."),
+		wrapError(func() error { return db.lookupSourceID(im.registry) }, "Could not determine the source of the Docker image."),
+		wrapError(db.unpackDeployConfig, "Could not convert data from a SingularityDeploy to a sous.Deployment."),
+		wrapError(db.determineManifestKind, "Could not determine SingularityRequestType."),
+		wrapError(db.extractSchedule, "Could not determine Singularity schedule."),
+	)
+	if err != nil {
+		return sous.UnmanagedDeployment{}, err
+	}
+	db.Target.Cluster = cluster
+	db.Target.ClusterName = cluster.Name
+	return sous.UnmanagedDeployment{
+		RequestID:  db.reqID,
+		Deployment: &db.Target.Deployment,
+		Artifact:   &sous.BuildArtifact{Name: db.imageName, Type: "docker"},
+	}, nil
+}
+
+func (db *deploymentBuilder) unmanagedCheck() error {
+	if _, ok := db.deploy.Metadata[sous.ClusterNameLabel]; ok {
+		return alreadyManagedError{db.reqID}
+	}
+	return nil
+}
+
+// lookupSourceID finds the source ID of the deploy's image by its name, as
+// images deployed outside Sous may lack Sous's labels.
+func (db *deploymentBuilder) lookupSourceID(reg sous.Registry) error {
+	sid, err := reg.GetSourceID(&sous.BuildArtifact{Name: db.imageName, Type: "docker"})
+	if err != nil {
+		return errors.Wrapf(err, "image %q", db.imageName)
+	}
+	db.Target.SourceID = sid
+	return nil
+}
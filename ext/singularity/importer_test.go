@@ -0,0 +1,109 @@
+package singularity
+
+import (
+	"testing"
+
+	"github.com/opentable/go-singularity/dtos"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/swaggering"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeImportClient struct {
+	*fakeSingClient
+	requests dtos.SingularityRequestParentList
+}
+
+func (fake *fakeImportClient) GetRequests(bool) (dtos.SingularityRequestParentList, error) {
+	return fake.requests, nil
+}
+
+func (fake *fakeImportClient) GetRequest(requestID string, _ bool) (*dtos.SingularityRequestParent, error) {
+	for _, rp := range fake.requests {
+		if rp.Request.Id == requestID {
+			return rp, nil
+		}
+	}
+	return nil, &swaggering.ReqError{Status: 404}
+}
+
+func legacyDeployHistory(metadata map[string]string) *dtos.SingularityDeployHistory {
+	return &dtos.SingularityDeployHistory{
+		DeployMarker: &dtos.SingularityDeployMarker{RequestId: "legacy-app", DeployId: "d1"},
+		Deploy: &dtos.SingularityDeploy{
+			Id:       "d1",
+			Metadata: metadata,
+			Env:      map[string]string{"GREETING": "hello"},
+			Healthcheck: &dtos.HealthcheckOptions{
+				Uri:                   "/health",
+				StartupTimeoutSeconds: 300,
+			},
+			ContainerInfo: &dtos.SingularityContainerInfo{
+				Type:   "DOCKER",
+				Docker: &dtos.SingularityDockerInfo{Image: "docker.example.com/legacy-app:1.2.3"},
+			},
+			Resources: &dtos.Resources{Cpus: 0.5, MemoryMb: 256, NumPorts: 1},
+		},
+	}
+}
+
+func TestImporter(t *testing.T) {
+	cluster := &sous.Cluster{Name: "left", BaseURL: "http://singularity.example.com"}
+	client := &fakeImportClient{
+		fakeSingClient: &fakeSingClient{cannedAnswer: legacyDeployHistory(nil)},
+		requests: dtos.SingularityRequestParentList{
+			&dtos.SingularityRequestParent{
+				RequestDeployState: &dtos.SingularityRequestDeployState{},
+				Request: &dtos.SingularityRequest{
+					Id:          "legacy-app",
+					RequestType: dtos.SingularityRequestRequestTypeSERVICE,
+					Instances:   3,
+					Owners:      swaggering.StringList{"owner@example.com"},
+				},
+			},
+		},
+	}
+	sid := sous.MustNewSourceID("github.com/example/legacy-app", "", "1.2.3")
+	reg := sous.NewDummyRegistry()
+	rc := sous.NewDummyRectificationClient()
+	im := NewImporter(reg, rc)
+	im.singFac = func(url string) importClient {
+		assert.Equal(t, cluster.BaseURL, url)
+		return client
+	}
+
+	reg.FeedSourceID(sid, nil)
+	uds, err := im.Unmanaged(cluster)
+	require.NoError(t, err)
+	require.Len(t, uds, 1)
+	ud := uds[0]
+	assert.Equal(t, "legacy-app", ud.RequestID)
+	assert.Equal(t, "docker.example.com/legacy-app:1.2.3", ud.Artifact.Name)
+	d := ud.Deployment
+	assert.Equal(t, sid, d.SourceID)
+	assert.Equal(t, "left", d.ClusterName)
+	assert.Equal(t, cluster, d.Cluster)
+	assert.Equal(t, sous.ManifestKindService, d.Kind)
+	assert.Equal(t, 3, d.NumInstances)
+	assert.Equal(t, []string{"owner@example.com"}, d.Owners.Slice())
+	assert.Equal(t, "hello", d.Env["GREETING"])
+	assert.Equal(t, "/health", d.Startup.CheckReadyURIPath)
+	assert.Equal(t, 300, d.Startup.Timeout)
+	assert.Equal(t, 0.5, d.Resources.Cpus())
+
+	require.NoError(t, im.Adopt(ud))
+	require.Len(t, rc.Deployed, 1)
+	assert.Equal(t, d, rc.Deployed[0].Deployment)
+
+	// Once deployed by Sous, the request is no longer unmanaged.
+	client.cannedAnswer = legacyDeployHistory(map[string]string{sous.ClusterNameLabel: "left"})
+	uds, err = im.Unmanaged(cluster)
+	require.NoError(t, err)
+	assert.Len(t, uds, 0)
+	_, err = im.Unmanaged(cluster, "legacy-app")
+	assert.Error(t, err, "asking for a managed request by ID")
+
+	_, err = im.Unmanaged(cluster, "missing")
+	assert.Error(t, err)
+}
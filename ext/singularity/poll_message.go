@@ -0,0 +1,81 @@
+package singularity
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/opentable/sous/util/logging"
+)
+
+type (
+	// pollStats records what a call to RunningDeployments did. The counts
+	// are updated atomically by the pipelines.
+	pollStats struct {
+		started, finished time.Time
+		// full is true if the cache was dropped before the poll.
+		full bool
+		// requests is the number of HTTP requests made to Singularity.
+		requests int64
+		// assembled is the number of deploy states assembled from
+		// Singularity, and cached the number taken from the cache instead.
+		assembled, cached int64
+		// states is the number of deploy states returned.
+		states int
+	}
+
+	pollMessage struct {
+		logging.CallerInfo
+		logging.MessageInterval
+		stats *pollStats
+		err   error
+	}
+)
+
+func reportPoll(ls logging.LogSink, stats *pollStats, err error) {
+	msg := pollMessage{
+		CallerInfo:      logging.GetCallerInfo(logging.NotHere()),
+		MessageInterval: logging.NewInterval(stats.started, stats.finished),
+		stats:           stats,
+		err:             err,
+	}
+	logging.Deliver(msg, ls)
+}
+
+func (msg pollMessage) DefaultLevel() logging.Level {
+	if msg.err != nil {
+		return logging.WarningLevel
+	}
+	return logging.InformationLevel
+}
+
+func (msg pollMessage) Message() string {
+	if msg.err != nil {
+		return "Polling Singularity failed: " + msg.err.Error()
+	}
+	if msg.stats.full {
+		return "Polled Singularity in full"
+	}
+	return "Polled Singularity for changes"
+}
+
+func (msg pollMessage) MetricsTo(m logging.MetricsSink) {
+	m.UpdateTimer("singularity-poll-duration", msg.stats.finished.Sub(msg.stats.started))
+	m.IncCounter("singularity-requests", atomic.LoadInt64(&msg.stats.requests))
+	m.UpdateSample("singularity-poll-requests", atomic.LoadInt64(&msg.stats.requests))
+	m.UpdateSample("singularity-poll-assembled", atomic.LoadInt64(&msg.stats.assembled))
+	m.UpdateSample("singularity-poll-cached", atomic.LoadInt64(&msg.stats.cached))
+	if msg.stats.full {
+		m.IncCounter("singularity-full-polls", 1)
+	}
+}
+
+func (msg pollMessage) EachField(f logging.FieldReportFn) {
+	f("@loglov3-otl", "sous-generic-v1")
+	f("sous-poll-full", msg.stats.full)
+	f("sous-poll-requests", atomic.LoadInt64(&msg.stats.requests))
+	f("sous-poll-assembled", atomic.LoadInt64(&msg.stats.assembled))
+	f("sous-poll-cached", atomic.LoadInt64(&msg.stats.cached))
+	f("sous-poll-states", msg.stats.states)
+	msg.CallerInfo.EachField(f)
+	msg.MessageInterval.EachField(f)
+}
@@ -3,6 +3,7 @@ package singularity
 import (
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -37,18 +38,24 @@ type (
 		singClients map[string]*singularity.Client
 		sync.RWMutex
 		labeller sous.ImageLabeller
+		secrets  sous.SecretResolver
+		// requestIDs remembers the requests found to run deployments.
+		requestIDs requestIDs
 	}
 
 	singularityTaskData struct {
 		requestID string
+		deployID  string
 	}
 )
 
-// NewRectiAgent returns a set-up RectiAgent
-func NewRectiAgent(l sous.ImageLabeller) *RectiAgent {
+// NewRectiAgent returns a set-up RectiAgent. Secret references in env vars
+// are resolved with secrets when deploying.
+func NewRectiAgent(l sous.ImageLabeller, secrets sous.SecretResolver) *RectiAgent {
 	return &RectiAgent{
 		singClients: make(map[string]*singularity.Client),
 		labeller:    l,
+		secrets:     secrets,
 	}
 }
 
@@ -75,7 +82,7 @@ func (ra *RectiAgent) Deploy(d sous.Deployable, reqID string) error {
 	}
 
 	Log.Debug.Printf("Deploying instance %#v to request %s", d, reqID)
-	depReq, err := buildDeployRequest(d, reqID, labels)
+	depReq, err := buildDeployRequest(d, reqID, labels, ra.secrets)
 	if err != nil {
 		return err
 	}
@@ -85,7 +92,7 @@ func (ra *RectiAgent) Deploy(d sous.Deployable, reqID string) error {
 	return err
 }
 
-func buildDeployRequest(d sous.Deployable, reqID string, metadata map[string]string) (*dtos.SingularityDeployRequest, error) {
+func buildDeployRequest(d sous.Deployable, reqID string, metadata map[string]string, secrets sous.SecretResolver) (*dtos.SingularityDeployRequest, error) {
 	var depReq swaggering.Fielder
 	depID := computeDeployID(&d)
 	dockerImage := d.BuildArtifact.Name
@@ -95,6 +102,23 @@ func buildDeployRequest(d sous.Deployable, reqID string, metadata map[string]str
 
 	metadata[sous.ClusterNameLabel] = d.Deployment.ClusterName
 	metadata[sous.FlavorLabel] = d.Deployment.Flavor
+	metadata[sous.DeploymentIDLabel] = d.ID().String()
+
+	// Secrets are resolved only into the request sent to Singularity: the
+	// references are recorded in the metadata, so that the deployment read
+	// back has them in place of the values.
+	resolvedEnv, err := e.Resolve(secrets)
+	if err != nil {
+		return nil, err
+	}
+	for name, v := range e {
+		if sous.IsSecretRef(v) {
+			metadata[sous.SecretRefLabelPrefix+name] = v
+		}
+	}
+	if v := d.Deployment.DeployConfig.SecretsVersion; v != 0 {
+		metadata[sous.SecretsVersionLabel] = strconv.Itoa(v)
+	}
 
 	dockerInfo, err := swaggering.LoadMap(&dtos.SingularityDockerInfo{}, dtoMap{
 		"Image":   dockerImage,
@@ -140,7 +164,7 @@ func buildDeployRequest(d sous.Deployable, reqID string, metadata map[string]str
 		"RequestId":     reqID,
 		"Resources":     res,
 		"ContainerInfo": ci,
-		"Env":           map[string]string(e),
+		"Env":           map[string]string(resolvedEnv),
 		"Metadata":      metadata,
 	}
 
@@ -148,12 +172,16 @@ func buildDeployRequest(d sous.Deployable, reqID string, metadata map[string]str
 		return nil, err
 	}
 
+	mapRolloutIntoDeploy(depMap, d.Rollout)
+
 	dep, err := swaggering.LoadMap(&dtos.SingularityDeploy{}, depMap)
 	if err != nil {
 		return nil, err
 	}
 
-	Log.Debug.Printf("Deploy: %+ v", dep)
+	logged := *dep.(*dtos.SingularityDeploy)
+	logged.Env = map[string]string(e)
+	Log.Debug.Printf("Deploy: %+ v", logged)
 	Log.Debug.Printf("  Container: %+ v", ci)
 	Log.Debug.Printf("  Docker: %+ v", dockerInfo)
 
@@ -173,6 +201,14 @@ func MapStartupIntoHealthcheckOptions(depMap *map[string]interface{}, startup so
 		return nil
 	}
 
+	protocol := strings.ToUpper(startup.CheckReadyProtocol)
+	if protocol == "COMMAND" {
+		return fmt.Errorf("Singularity cannot run COMMAND checks")
+	}
+	if startup.LivenessInterval != 0 {
+		return fmt.Errorf("Singularity only checks tasks as they start, so cannot check them every %d seconds", startup.LivenessInterval)
+	}
+
 	hcMap := dtoMap{}
 
 	hcMap["StartupDelaySeconds"] = int32(startup.ConnectDelay)
@@ -184,8 +220,12 @@ func MapStartupIntoHealthcheckOptions(depMap *map[string]interface{}, startup so
 	}
 	hcMap["FailureStatusCodes"] = failStatuses
 
-	hcMap["Protocol"] = dtos.HealthcheckOptionsHealthcheckProtocol(startup.CheckReadyProtocol)
-	hcMap["Uri"] = startup.CheckReadyURIPath
+	// A TCP check is left without a protocol or URI, so that Singularity only
+	// waits for the port to accept connections.
+	if protocol != "TCP" {
+		hcMap["Protocol"] = dtos.HealthcheckOptionsHealthcheckProtocol(startup.CheckReadyProtocol)
+		hcMap["Uri"] = startup.CheckReadyURIPath
+	}
 	hcMap["PortIndex"] = int32(startup.CheckReadyPortIndex)
 	hcMap["ResponseTimeoutSeconds"] = int32(startup.CheckReadyURITimeout)
 	hcMap["IntervalSeconds"] = int32(startup.CheckReadyInterval)
@@ -196,6 +236,17 @@ func MapStartupIntoHealthcheckOptions(depMap *map[string]interface{}, startup so
 	return err
 }
 
+// mapRolloutIntoDeploy makes a deploy staged, if rollout calls for it. Sous
+// advances the steps itself, so Singularity is told not to.
+func mapRolloutIntoDeploy(depMap dtoMap, rollout *sous.RolloutProgress) {
+	if rollout == nil || rollout.RollingBack || rollout.Step >= rollout.Of {
+		return
+	}
+	depMap["DeployInstanceCountPerStep"] = int32(rollout.TargetInstances)
+	depMap["AutoAdvanceDeploySteps"] = false
+	depMap["DeployStepWaitTimeMs"] = int32(0)
+}
+
 func singRequestFromDeployment(dep *sous.Deployment, reqID string) (string, *dtos.SingularityRequest, error) {
 	cluster := dep.Cluster.BaseURL
 	instanceCount := dep.DeployConfig.NumInstances
@@ -218,9 +269,17 @@ func singRequestFromDeployment(dep *sous.Deployment, reqID string) (string, *dto
 		// until and unless someone asks
 		reqFields["ScheduleType"] = dtos.SingularityRequestScheduleTypeCRON
 
-		// also present but not addressed:
-		// taskExecutionTimeLimitMillis
+		if tz := dep.ScheduleTimeZone; tz != "" {
+			reqFields["ScheduleTimeZone"] = tz
+		}
+		if limit := dep.TaskTimeLimitSeconds; limit != 0 {
+			reqFields["TaskExecutionTimeLimitMillis"] = int64(limit) * 1000
+		}
+	}
+	if grace := dep.KillGraceSeconds; grace != 0 {
+		reqFields["KillOldNonLongRunningTasksAfterMillis"] = int64(grace) * 1000
 	}
+	mapPlacementIntoRequest(reqFields, dep.Placement)
 	req, err := swaggering.LoadMap(&dtos.SingularityRequest{}, reqFields)
 
 	if err != nil {
@@ -242,6 +301,18 @@ func (ra *RectiAgent) PostRequest(d sous.Deployable, reqID string) error {
 	return err
 }
 
+// mapPlacementIntoRequest sets the fields of a Singularity request which
+// constrain where its tasks run.
+func mapPlacementIntoRequest(reqFields dtoMap, p sous.Placement) {
+	if len(p.RequiredAttributes) != 0 {
+		reqFields["RequiredSlaveAttributes"] = map[string]string(p.RequiredAttributes)
+	}
+	if len(p.AllowedAttributes) != 0 {
+		reqFields["AllowedSlaveAttributes"] = map[string]string(p.AllowedAttributes)
+	}
+	reqFields["RackSensitive"] = p.RackSensitive
+}
+
 func determineRequestType(kind sous.ManifestKind) (dtos.SingularityRequestRequestType, error) {
 	switch kind {
 	default:
@@ -275,6 +346,29 @@ func (ra *RectiAgent) DeleteRequest(cluster, reqID, message string) error {
 	return err
 }
 
+// AdvanceDeploy sends a request to Singularity to move a staged deploy on to
+// its next step.
+func (ra *RectiAgent) AdvanceDeploy(cluster, reqID, deployID string, targetInstances int) error {
+	Log.Debug.Printf("Advancing deploy %s %s %s to %d instances", cluster, reqID, deployID, targetInstances)
+	req, err := swaggering.LoadMap(&dtos.SingularityUpdatePendingDeployRequest{}, dtoMap{
+		"RequestId":             reqID,
+		"DeployId":              deployID,
+		"TargetActiveInstances": int32(targetInstances),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = ra.singularityClient(cluster).UpdatePendingDeploy(req.(*dtos.SingularityUpdatePendingDeployRequest))
+	return err
+}
+
+// CancelDeploy sends a request to Singularity to cancel a pending deploy.
+func (ra *RectiAgent) CancelDeploy(cluster, reqID, deployID string) error {
+	Log.Debug.Printf("Cancelling deploy %s %s %s", cluster, reqID, deployID)
+	_, err := ra.singularityClient(cluster).CancelDeploy(reqID, deployID)
+	return err
+}
+
 // Scale sends requests to Singularity to change the number of instances
 // running for a given Request
 func (ra *RectiAgent) Scale(cluster, reqID string, instanceCount int, message string) error {
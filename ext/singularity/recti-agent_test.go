@@ -1,6 +1,7 @@
 package singularity
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/opentable/go-singularity/dtos"
@@ -91,7 +92,7 @@ func TestDetermineRequestType(t *testing.T) {
 func TestFailOnNilBuildArtifact(t *testing.T) {
 	r := sous.NewDummyRegistry()
 	d := sous.Deployable{}
-	ra := NewRectiAgent(r)
+	ra := NewRectiAgent(r, nil)
 	err := ra.Deploy(d, "testReq")
 	if err != nil {
 		t.Logf("Correctly returned an error upon encountering: %#v", err)
@@ -148,6 +149,31 @@ func TestMapStartup(t *testing.T) {
 	})
 }
 
+func TestMapStartup_otherChecks(t *testing.T) {
+	depMap := dtoMap{}
+	tcp := sous.Startup{
+		ConnectDelay:        10,
+		Timeout:             20,
+		CheckReadyProtocol:  "TCP",
+		CheckReadyURIPath:   "/health",
+		CheckReadyPortIndex: 1,
+	}
+	if err := MapStartupIntoHealthcheckOptions((*map[string]interface{})(&depMap), tcp); err != nil {
+		t.Fatal(err)
+	}
+	hco := depMap["Healthcheck"].(*dtos.HealthcheckOptions)
+	assert.Equal(t, int32(20), hco.StartupTimeoutSeconds)
+	assert.Equal(t, int32(1), hco.PortIndex)
+	assert.Equal(t, dtos.HealthcheckOptionsHealthcheckProtocol(""), hco.Protocol)
+	assert.Equal(t, "", hco.Uri)
+
+	command := sous.Startup{CheckReadyProtocol: "COMMAND", CheckReadyCommand: []string{"true"}}
+	assert.Error(t, MapStartupIntoHealthcheckOptions((*map[string]interface{})(&dtoMap{}), command))
+
+	liveness := sous.Startup{CheckReadyProtocol: "HTTP", LivenessInterval: 30}
+	assert.Error(t, MapStartupIntoHealthcheckOptions((*map[string]interface{})(&dtoMap{}), liveness))
+}
+
 func TestContainerStartupOptions(t *testing.T) {
 	checkReadyPath := "/use-this-route"
 	checkReadyTimeout := 45
@@ -163,7 +189,7 @@ func TestContainerStartupOptions(t *testing.T) {
 	d.Startup.CheckReadyURIPath = checkReadyPath
 	d.Startup.Timeout = checkReadyTimeout
 
-	dr, err := buildDeployRequest(d, "fake-request-id", map[string]string{})
+	dr, err := buildDeployRequest(d, "fake-request-id", map[string]string{}, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -177,3 +203,72 @@ func TestContainerStartupOptions(t *testing.T) {
 	}
 
 }
+
+type mapSecrets map[string]string
+
+func (ms mapSecrets) ResolveSecret(ref sous.SecretRef) (string, error) {
+	v, ok := ms[ref.String()]
+	if !ok {
+		return "", fmt.Errorf("no secret %s", ref)
+	}
+	return v, nil
+}
+
+func TestSecretEnvRoundTrip(t *testing.T) {
+	ref := "secret://vault/db/orders#password"
+	d := sous.Deployable{
+		Deployment:    &sous.Deployment{ClusterName: "cluster"},
+		BuildArtifact: &sous.BuildArtifact{Name: "an-image"},
+	}
+	d.Resources = sous.Resources{"cpus": "0.1", "memory": "100", "ports": "1"}
+	d.Env = sous.Env{"DB_PASSWORD": ref, "PLAIN": "value"}
+	d.Startup.SkipCheck = true
+
+	_, err := buildDeployRequest(d, "reqid", map[string]string{}, nil)
+	assert.Error(t, err, "secrets cannot be resolved without a store")
+
+	dr, err := buildDeployRequest(d, "reqid", map[string]string{}, mapSecrets{ref: "hunter2"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, map[string]string{"DB_PASSWORD": "hunter2", "PLAIN": "value"}, dr.Deploy.Env)
+	assert.Equal(t, ref, dr.Deploy.Metadata[sous.SecretRefLabelPrefix+"DB_PASSWORD"])
+	assert.Equal(t, ref, d.Env["DB_PASSWORD"], "the deployment keeps the reference")
+
+	db := &deploymentBuilder{deploy: dr.Deploy, request: &dtos.SingularityRequest{}, reqID: "reqid"}
+	if assert.NoError(t, db.unpackDeployConfig()) {
+		assert.Equal(t, sous.Env{"DB_PASSWORD": ref, "PLAIN": "value"}, db.Target.Env)
+	}
+}
+
+func TestStartupRoundTrip(t *testing.T) {
+	roundTrip := func(startup sous.Startup) sous.Startup {
+		d := sous.Deployable{
+			Deployment:    &sous.Deployment{ClusterName: "cluster"},
+			BuildArtifact: &sous.BuildArtifact{Name: "an-image"},
+		}
+		d.Resources = sous.Resources{"cpus": "0.1", "memory": "100", "ports": "1"}
+		d.Startup = startup
+		dr, err := buildDeployRequest(d, "reqid", map[string]string{}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		db := &deploymentBuilder{deploy: dr.Deploy, request: &dtos.SingularityRequest{}, reqID: "reqid"}
+		if err := db.unpackDeployConfig(); err != nil {
+			t.Fatal(err)
+		}
+		return db.Target.Startup
+	}
+
+	for _, startup := range []sous.Startup{
+		{CheckReadyProtocol: "HTTP", CheckReadyURIPath: "/health", CheckReadyPortIndex: 1},
+		{CheckReadyProtocol: "TCP", CheckReadyPortIndex: 1},
+		// Deploys with no health-check URI, as made before there were TCP
+		// checks, must not differ from their manifests.
+		{CheckReadyProtocol: "HTTP", CheckReadyPortIndex: 1},
+		{CheckReadyPortIndex: 1},
+	} {
+		actual := roundTrip(startup)
+		assert.True(t, startup.Equal(actual), "%+v came back as %+v", startup, actual)
+	}
+}
@@ -38,7 +38,7 @@ func TestBuildDeployRequest(t *testing.T) {
 				BaseURL: "http://cluster",
 			},
 		},
-	}, rID, map[string]string{})
+	}, rID, map[string]string{}, nil)
 	require.NoError(err)
 	assert.NotNil(dr)
 	assert.Equal(dr.Deploy.RequestId, rID)
@@ -73,7 +73,7 @@ func TestDockerMetadataSet(t *testing.T) {
 				BaseURL: "http://cluster",
 			},
 		},
-	}, rID, md)
+	}, rID, md, nil)
 
 	if err != nil {
 		t.Fatal(err)
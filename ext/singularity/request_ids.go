@@ -0,0 +1,92 @@
+package singularity
+
+import (
+	"sync"
+
+	"github.com/opentable/go-singularity/dtos"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/swaggering"
+	"github.com/pkg/errors"
+)
+
+type (
+	// requestFinder is the part of the Singularity client used to find the
+	// request which runs a deployment.
+	requestFinder interface {
+		GetRequest(requestID string, useWebCache bool) (*dtos.SingularityRequestParent, error)
+		GetRequests(useWebCache bool) (dtos.SingularityRequestParentList, error)
+		GetDeploy(requestID string, deployID string) (*dtos.SingularityDeployHistory, error)
+	}
+
+	// requestIDs remembers the requests found to run deployments, so that
+	// each is only looked for once. The zero value is ready to use.
+	requestIDs struct {
+		sync.Mutex
+		// found is keyed by Singularity URL and deployment ID.
+		found map[[2]string]string
+	}
+)
+
+// find returns the ID of the request which runs d, on the Singularity client
+// talks to.
+func (ri *requestIDs) find(client requestFinder, d *sous.Deployment) (string, error) {
+	if d.Cluster == nil {
+		return "", errors.Errorf("%s has no cluster", d.ID())
+	}
+	key := [2]string{d.Cluster.BaseURL, d.ID().String()}
+	ri.Lock()
+	id, ok := ri.found[key]
+	ri.Unlock()
+	if ok {
+		return id, nil
+	}
+	id, err := findRequestID(client, d)
+	if err != nil {
+		return "", err
+	}
+	ri.Lock()
+	defer ri.Unlock()
+	if ri.found == nil {
+		ri.found = map[[2]string]string{}
+	}
+	ri.found[key] = id
+	return id, nil
+}
+
+// findRequestID returns the ID of the request which runs d. Sous names the
+// requests it creates with MakeRequestID, but requests adopted by sous import
+// keep the IDs they had. Those are found by the deployment ID recorded in the
+// metadata of their active deploys.
+func findRequestID(client requestFinder, d *sous.Deployment) (string, error) {
+	id, err := MakeRequestID(d.ID())
+	if err != nil {
+		return "", err
+	}
+	_, err = client.GetRequest(id, false)
+	if err == nil {
+		return id, nil
+	}
+	if re, ok := errors.Cause(err).(*swaggering.ReqError); !ok || re.Status != 404 {
+		return "", errors.Wrapf(err, "getting request %s", id)
+	}
+
+	rps, err := client.GetRequests(false)
+	if err != nil {
+		return "", errors.Wrap(err, "getting requests")
+	}
+	did := d.ID().String()
+	for _, rp := range rps {
+		if rp == nil || rp.RequestDeployState == nil || rp.RequestDeployState.ActiveDeploy == nil {
+			continue
+		}
+		rid, depID := reqID(rp), rp.RequestDeployState.ActiveDeploy.DeployId
+		dh, err := client.GetDeploy(rid, depID)
+		if err != nil {
+			return "", errors.Wrapf(err, "getting deploy %s of %s", depID, rid)
+		}
+		if dh != nil && dh.Deploy != nil && dh.Deploy.Metadata[sous.DeploymentIDLabel] == did {
+			return rid, nil
+		}
+	}
+	return "", errors.Errorf("no request runs %s", d.ID())
+}
@@ -0,0 +1,97 @@
+package singularity
+
+import (
+	"testing"
+
+	"github.com/opentable/go-singularity/dtos"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/swaggering"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// remember records that d runs as the request id.
+func (ri *requestIDs) remember(d *sous.Deployment, id string) {
+	ri.Lock()
+	defer ri.Unlock()
+	if ri.found == nil {
+		ri.found = map[[2]string]string{}
+	}
+	ri.found[[2]string{d.Cluster.BaseURL, d.ID().String()}] = id
+}
+
+type fakeRequestFinder struct {
+	requests dtos.SingularityRequestParentList
+	deploys  map[string]*dtos.SingularityDeployHistory
+	gets     int
+}
+
+func (f *fakeRequestFinder) GetRequest(requestID string, _ bool) (*dtos.SingularityRequestParent, error) {
+	f.gets++
+	for _, rp := range f.requests {
+		if rp.Request.Id == requestID {
+			return rp, nil
+		}
+	}
+	return nil, &swaggering.ReqError{Status: 404}
+}
+
+func (f *fakeRequestFinder) GetRequests(bool) (dtos.SingularityRequestParentList, error) {
+	return f.requests, nil
+}
+
+func (f *fakeRequestFinder) GetDeploy(requestID, deployID string) (*dtos.SingularityDeployHistory, error) {
+	return f.deploys[requestID+"/"+deployID], nil
+}
+
+func activeRequest(id, deployID string) *dtos.SingularityRequestParent {
+	return &dtos.SingularityRequestParent{
+		Request: &dtos.SingularityRequest{Id: id},
+		RequestDeployState: &dtos.SingularityRequestDeployState{
+			ActiveDeploy: &dtos.SingularityDeployMarker{RequestId: id, DeployId: deployID},
+		},
+	}
+}
+
+func TestRequestIDs(t *testing.T) {
+	d := &sous.Deployment{
+		ClusterName: "left",
+		Cluster:     &sous.Cluster{Name: "left", BaseURL: "http://singularity"},
+		SourceID:    sous.MustNewSourceID("github.com/ot/app", "", "1.0.0"),
+	}
+	named, err := MakeRequestID(d.ID())
+	require.NoError(t, err)
+
+	client := &fakeRequestFinder{requests: dtos.SingularityRequestParentList{activeRequest(named, "d1")}}
+	id, err := (&requestIDs{}).find(client, d)
+	require.NoError(t, err)
+	assert.Equal(t, named, id, "a request Sous created is found by its name")
+
+	client = &fakeRequestFinder{
+		requests: dtos.SingularityRequestParentList{
+			activeRequest("other-app", "d1"),
+			activeRequest("legacy-app", "d2"),
+		},
+		deploys: map[string]*dtos.SingularityDeployHistory{
+			"other-app/d1": {Deploy: &dtos.SingularityDeploy{Metadata: map[string]string{}}},
+		},
+	}
+	dr, err := buildDeployRequest(sous.Deployable{
+		Deployment:    d,
+		BuildArtifact: &sous.BuildArtifact{Name: "an-image"},
+	}, "legacy-app", map[string]string{}, nil)
+	require.NoError(t, err)
+	client.deploys["legacy-app/d2"] = &dtos.SingularityDeployHistory{Deploy: dr.Deploy}
+	ri := &requestIDs{}
+	id, err = ri.find(client, d)
+	require.NoError(t, err)
+	assert.Equal(t, "legacy-app", id, "an adopted request is found by the deployment ID Sous records in its deploy's metadata")
+	id, err = ri.find(client, d)
+	require.NoError(t, err)
+	assert.Equal(t, "legacy-app", id)
+	assert.Equal(t, 1, client.gets, "the request found is remembered")
+
+	client.deploys["legacy-app/d2"].Deploy.Metadata = map[string]string{}
+	_, err = (&requestIDs{}).find(client, d)
+	assert.Error(t, err)
+}
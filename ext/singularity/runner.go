@@ -0,0 +1,159 @@
+package singularity
+
+import (
+	"regexp"
+	"strconv"
+
+	"github.com/opentable/go-singularity"
+	"github.com/opentable/go-singularity/dtos"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/swaggering"
+	"github.com/pkg/errors"
+)
+
+type (
+	// Runner is a sous.Runner which launches runs of on-demand and one-off
+	// deployments on Singularity.
+	Runner struct {
+		singFac func(string) *singularity.Client
+		log     logging.LogSink
+		// requestIDs remembers the requests found to run deployments.
+		requestIDs requestIDs
+	}
+)
+
+// maxRunOutputChunk is the most output RunOutput returns at once.
+const maxRunOutputChunk = 64 * 1024
+
+// exitStatusPattern matches the exit status in the message Mesos gives the
+// final update of a task.
+var exitStatusPattern = regexp.MustCompile(`(?i)exit(?:ed with)? (?:code|status) (-?\d+)`)
+
+// NewRunner returns a Runner.
+func NewRunner(ls logging.LogSink) *Runner {
+	return &Runner{log: ls}
+}
+
+// SetSingularityFactory sets the function used to make Singularity clients.
+func (r *Runner) SetSingularityFactory(fn func(string) *singularity.Client) {
+	r.singFac = fn
+}
+
+// client returns a client for the Singularity of d's cluster, and the ID of
+// d's Singularity request.
+func (r *Runner) client(d *sous.Deployment) (*singularity.Client, string, error) {
+	if d.Cluster == nil {
+		return nil, "", errors.Errorf("%s has no cluster", d.ID())
+	}
+	if d.Cluster.Kind != "" && d.Cluster.Kind != "singularity" {
+		return nil, "", errors.Errorf("can't run %s: cluster %s is not a Singularity cluster", d.ID(), d.ClusterName)
+	}
+	client := singularity.NewClient(d.Cluster.BaseURL, r.log)
+	if r.singFac != nil {
+		client = r.singFac(d.Cluster.BaseURL)
+	}
+	reqID, err := r.requestIDs.find(client, d)
+	if err != nil {
+		return nil, "", err
+	}
+	return client, reqID, nil
+}
+
+// Run implements sous.Runner on Runner.
+func (r *Runner) Run(d *sous.Deployment, runID string, args []string) error {
+	client, reqID, err := r.client(d)
+	if err != nil {
+		return err
+	}
+	body, err := swaggering.LoadMap(&dtos.SingularityRunNowRequest{}, dtoMap{
+		"RunId":           runID,
+		"CommandLineArgs": swaggering.StringList(args),
+		"Message":         "sous run",
+	})
+	if err != nil {
+		return err
+	}
+	_, err = client.ScheduleImmediately(reqID, body.(*dtos.SingularityRunNowRequest))
+	return errors.Wrapf(err, "running %s", reqID)
+}
+
+// RunStatus implements sous.Runner on Runner. A run Singularity doesn't know
+// about yet is pending.
+func (r *Runner) RunStatus(d *sous.Deployment, runID string) (*sous.RunStatus, error) {
+	client, reqID, err := r.client(d)
+	if err != nil {
+		return nil, err
+	}
+	status := &sous.RunStatus{RunID: runID, State: sous.RunPending, ExitCode: -1}
+	history, err := client.GetTaskHistoryForRequestAndRunId(reqID, runID)
+	if re, ok := errors.Cause(err).(*swaggering.ReqError); ok && re.Status == 404 {
+		return status, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting run %s of %s", runID, reqID)
+	}
+	if history.TaskId == nil {
+		return status, nil
+	}
+	status.TaskID = history.TaskId.Id
+
+	switch history.LastTaskState {
+	default:
+		status.State = sous.RunRunning
+	case dtos.SingularityTaskIdHistoryExtendedTaskStateTASK_LAUNCHED,
+		dtos.SingularityTaskIdHistoryExtendedTaskStateTASK_STAGING,
+		dtos.SingularityTaskIdHistoryExtendedTaskStateTASK_STARTING:
+		status.State = sous.RunPending
+	case dtos.SingularityTaskIdHistoryExtendedTaskStateTASK_FINISHED:
+		status.State = sous.RunSucceeded
+		status.ExitCode = 0
+	case dtos.SingularityTaskIdHistoryExtendedTaskStateTASK_FAILED,
+		dtos.SingularityTaskIdHistoryExtendedTaskStateTASK_KILLED,
+		dtos.SingularityTaskIdHistoryExtendedTaskStateTASK_LOST,
+		dtos.SingularityTaskIdHistoryExtendedTaskStateTASK_LOST_WHILE_DOWN,
+		dtos.SingularityTaskIdHistoryExtendedTaskStateTASK_ERROR:
+		status.State = sous.RunFailed
+	}
+	if status.State == sous.RunFailed {
+		task, err := client.GetHistoryForTask(status.TaskID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "getting history of task %s", status.TaskID)
+		}
+		if n := len(task.TaskUpdates); n != 0 {
+			status.Message = task.TaskUpdates[n-1].StatusMessage
+		}
+		if m := exitStatusPattern.FindStringSubmatch(status.Message); m != nil {
+			status.ExitCode, _ = strconv.Atoi(m[1])
+		}
+	}
+	return status, nil
+}
+
+// RunOutput implements sous.Runner on Runner, reading stream from the
+// sandbox of the run's task. A run which hasn't started has no output yet.
+func (r *Runner) RunOutput(d *sous.Deployment, runID, stream string, offset int64) (*sous.RunOutput, error) {
+	if stream != "stdout" && stream != "stderr" {
+		return nil, errors.Errorf("no stream %q: only stdout and stderr", stream)
+	}
+	status, err := r.RunStatus(d, runID)
+	if err != nil {
+		return nil, err
+	}
+	if status.TaskID == "" || status.State == sous.RunPending {
+		return &sous.RunOutput{NextOffset: offset}, nil
+	}
+	client, _, err := r.client(d)
+	if err != nil {
+		return nil, err
+	}
+	chunk, err := client.Read(status.TaskID, stream, "", offset, maxRunOutputChunk)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading %s of task %s", stream, status.TaskID)
+	}
+	next := chunk.NextOffset
+	if next == 0 {
+		next = offset + int64(len(chunk.Data))
+	}
+	return &sous.RunOutput{Data: chunk.Data, NextOffset: next}, nil
+}
@@ -0,0 +1,94 @@
+package singularity
+
+import (
+	"testing"
+
+	"github.com/opentable/go-singularity"
+	"github.com/opentable/go-singularity/dtos"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/swaggering"
+)
+
+func runnerTestDeployment() *sous.Deployment {
+	return &sous.Deployment{
+		ClusterName: "left",
+		Cluster:     &sous.Cluster{Name: "left", BaseURL: "http://singularity"},
+		SourceID:    sous.MustNewSourceID("github.com/ot/job", "", "1.0.0"),
+		Kind:        sous.ManifestKindOnDemand,
+	}
+}
+
+// runnerWithResponses returns a Runner whose Singularity clients respond
+// with feed, and which knows the request of runnerTestDeployment.
+func runnerWithResponses(feed func(swaggering.DummyControl)) *Runner {
+	r := NewRunner(logging.SilentLogSet())
+	r.requestIDs.remember(runnerTestDeployment(), "job-request")
+	r.SetSingularityFactory(func(url string) *singularity.Client {
+		cl, co := singularity.NewDummyClient(url)
+		feed(co)
+		return cl
+	})
+	return r
+}
+
+func TestRunnerRunStatus(t *testing.T) {
+	d := runnerTestDeployment()
+
+	pending := runnerWithResponses(func(co swaggering.DummyControl) {
+		co.FeedDTO(nil, &swaggering.ReqError{Status: 404})
+	})
+	status, err := pending.RunStatus(d, "run-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.State != sous.RunPending {
+		t.Errorf("got %s for a run Singularity doesn't know yet; want %s", status.State, sous.RunPending)
+	}
+
+	failed := runnerWithResponses(func(co swaggering.DummyControl) {
+		co.FeedDTO(&dtos.SingularityTaskIdHistory{
+			TaskId:        &dtos.SingularityTaskId{Id: "task-1"},
+			LastTaskState: dtos.SingularityTaskIdHistoryExtendedTaskStateTASK_FAILED,
+		}, nil)
+		co.FeedDTO(&dtos.SingularityTaskHistory{TaskUpdates: dtos.SingularityTaskHistoryUpdateList{
+			{StatusMessage: "Command exited with status 3"},
+		}}, nil)
+	})
+	status, err = failed.RunStatus(d, "run-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.State != sous.RunFailed || status.ExitCode != 3 || status.TaskID != "task-1" {
+		t.Errorf("got %+v; want task-1 failed with exit status 3", status)
+	}
+
+	finished := runnerWithResponses(func(co swaggering.DummyControl) {
+		co.FeedDTO(&dtos.SingularityTaskIdHistory{
+			TaskId:        &dtos.SingularityTaskId{Id: "task-1"},
+			LastTaskState: dtos.SingularityTaskIdHistoryExtendedTaskStateTASK_FINISHED,
+		}, nil)
+	})
+	status, err = finished.RunStatus(d, "run-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.State != sous.RunSucceeded || status.ExitCode != 0 {
+		t.Errorf("got %+v; want succeeded with exit status 0", status)
+	}
+}
+
+func TestRunnerRun(t *testing.T) {
+	r := runnerWithResponses(func(co swaggering.DummyControl) {
+		co.FeedDTO(&dtos.SingularityRequestParent{}, nil)
+	})
+	if err := r.Run(runnerTestDeployment(), "run-1", []string{"-v"}); err != nil {
+		t.Fatal(err)
+	}
+
+	kube := runnerTestDeployment()
+	kube.Cluster.Kind = "kubernetes"
+	if err := r.Run(kube, "run-1", nil); err == nil {
+		t.Errorf("ran a deployment in a kubernetes cluster")
+	}
+}
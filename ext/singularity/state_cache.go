@@ -0,0 +1,199 @@
+package singularity
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/opentable/go-singularity/dtos"
+	"github.com/opentable/sous/lib"
+)
+
+// DefaultFullPollInterval is how often the deployer drops the deploy states
+// it has cached, and assembles every deployment afresh.
+// To configure per deployer, see OptFullPollInterval.
+const DefaultFullPollInterval = 10 * time.Minute
+
+type (
+	// stateCache holds the deploy states assembled from the requests of
+	// each Singularity, so that a poll only assembles those whose entry in
+	// the request list has changed since the last. Every fullInterval, the
+	// whole cache is dropped, as a backstop for changes the request list
+	// doesn't show.
+	stateCache struct {
+		sync.Mutex
+		// fullInterval is how long states are cached for. If it is zero,
+		// every poll is a full poll.
+		fullInterval time.Duration
+		lastFull     time.Time
+		states       map[requestKey]cachedState
+		// invalid holds the IDs of requests reported to have changed. They
+		// are dropped from the cache when the next poll begins.
+		invalid map[string]struct{}
+	}
+
+	// requestKey identifies a request of a Singularity, as polled for a
+	// set of clusters. Whether a request is ignored depends on the clusters
+	// polled for, so each set is cached separately.
+	requestKey struct {
+		clusters, url, requestID string
+	}
+
+	cachedState struct {
+		fingerprint string
+		// state is nil if the request is ignored because it isn't a Sous
+		// deployment to one of the clusters.
+		state *sous.DeployState
+	}
+
+	// cachedStates collects the states each singPipeline of a poll finds in
+	// the cache. They are added to the poll's result once every pipeline is
+	// done, rather than sent on depCh, which may be closed by then if the
+	// poll fails.
+	cachedStates struct {
+		sync.Mutex
+		states []*sous.DeployState
+	}
+)
+
+func newStateCache(fullInterval time.Duration) *stateCache {
+	return &stateCache{
+		fullInterval: fullInterval,
+		states:       map[requestKey]cachedState{},
+		invalid:      map[string]struct{}{},
+	}
+}
+
+// begin starts a poll at now. It drops the whole cache and returns true if
+// the poll should be a full one, and otherwise drops the requests reported
+// invalid since the last poll.
+func (c *stateCache) begin(now time.Time) (full bool) {
+	c.Lock()
+	defer c.Unlock()
+	if c.fullInterval == 0 || now.Sub(c.lastFull) >= c.fullInterval {
+		c.states = map[requestKey]cachedState{}
+		c.invalid = map[string]struct{}{}
+		c.lastFull = now
+		return true
+	}
+	for k := range c.states {
+		if _, ok := c.invalid[k.requestID]; ok {
+			delete(c.states, k)
+		}
+	}
+	c.invalid = map[string]struct{}{}
+	return false
+}
+
+// invalidate marks the request with requestID as changed.
+func (c *stateCache) invalidate(requestID string) {
+	c.Lock()
+	defer c.Unlock()
+	c.invalid[requestID] = struct{}{}
+}
+
+// retain drops the requests of the Singularity at url which aren't in reqs,
+// since they have been deleted.
+func (c *stateCache) retain(clusters sous.Clusters, url string, reqs []SingReq) {
+	listed := make(map[string]struct{}, len(reqs))
+	for _, r := range reqs {
+		listed[reqID(r.ReqParent)] = struct{}{}
+	}
+	names := clusterKey(clusters)
+	c.Lock()
+	defer c.Unlock()
+	for k := range c.states {
+		if _, ok := listed[k.requestID]; !ok && k.url == url && k.clusters == names {
+			delete(c.states, k)
+		}
+	}
+}
+
+// lookup returns the cached state of req, if it hasn't changed since it was
+// cached. A nil state means req is ignored.
+func (c *stateCache) lookup(clusters sous.Clusters, req SingReq) (*sous.DeployState, bool) {
+	key := newRequestKey(clusters, req)
+	fp := requestFingerprint(req.ReqParent)
+	c.Lock()
+	defer c.Unlock()
+	cs, ok := c.states[key]
+	if !ok || fp == "" || cs.fingerprint != fp {
+		return nil, false
+	}
+	if cs.state == nil {
+		return nil, true
+	}
+	return cs.state.Clone(), true
+}
+
+// store caches the state assembled from req. A nil state records that req
+// is ignored. Pending deploys are in flux, so aren't cached.
+func (c *stateCache) store(clusters sous.Clusters, req SingReq, state *sous.DeployState) {
+	key := newRequestKey(clusters, req)
+	fp := requestFingerprint(req.ReqParent)
+	c.Lock()
+	defer c.Unlock()
+	if fp == "" || (state != nil && state.Status == sous.DeployStatusPending) {
+		delete(c.states, key)
+		return
+	}
+	if state != nil {
+		state = state.Clone()
+	}
+	c.states[key] = cachedState{fingerprint: fp, state: state}
+}
+
+func newRequestKey(clusters sous.Clusters, req SingReq) requestKey {
+	return requestKey{
+		clusters:  clusterKey(clusters),
+		url:       req.SourceURL,
+		requestID: reqID(req.ReqParent),
+	}
+}
+
+func clusterKey(clusters sous.Clusters) string {
+	names := clusters.Names()
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// requestFingerprint summarises what the request list says about a request:
+// the request itself, its state, and the markers of its active and pending
+// deploys, whose timestamps change with each deploy. It returns "" if there
+// is no request, which never matches.
+func requestFingerprint(rp *dtos.SingularityRequestParent) string {
+	if rp == nil {
+		return ""
+	}
+	h := sha256.New()
+	fingerprintConfig.Fdump(h, rp.Request, rp.State, rp.RequestDeployState)
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// fingerprintConfig dumps DTOs field by field. Their MarshalJSON only
+// includes fields set through their setters, which those read from
+// Singularity's responses aren't.
+var fingerprintConfig = spew.ConfigState{
+	DisableMethods:          true,
+	DisablePointerAddresses: true,
+	DisableCapacities:       true,
+	SortKeys:                true,
+}
+
+func (cs *cachedStates) add(state *sous.DeployState) {
+	cs.Lock()
+	defer cs.Unlock()
+	cs.states = append(cs.states, state)
+}
+
+func (cs *cachedStates) addTo(deps sous.DeployStates) {
+	cs.Lock()
+	defer cs.Unlock()
+	for _, state := range cs.states {
+		deps.Add(state)
+	}
+}
@@ -0,0 +1,179 @@
+package singularity
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opentable/go-singularity"
+	"github.com/opentable/go-singularity/dtos"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// labelledRegistry labels every image as a build of the same source.
+type labelledRegistry struct {
+	*sous.DummyRegistry
+}
+
+func (labelledRegistry) ImageLabels(string) (map[string]string, error) {
+	return map[string]string{
+		"com.opentable.sous.repo_url":    "github.com/opentable/example",
+		"com.opentable.sous.repo_offset": "",
+		"com.opentable.sous.revision":    "abc123",
+		"com.opentable.sous.version":     "1.2.3",
+	}, nil
+}
+
+func cacheTestRequest(instances int32) *dtos.SingularityRequestParent {
+	return &dtos.SingularityRequestParent{
+		RequestDeployState: &dtos.SingularityRequestDeployState{
+			ActiveDeploy: &dtos.SingularityDeployMarker{RequestId: "request-1", DeployId: "deploy-1", Timestamp: 1},
+		},
+		Request: &dtos.SingularityRequest{
+			Id:          "request-1",
+			RequestType: dtos.SingularityRequestRequestTypeSERVICE,
+			Instances:   instances,
+		},
+	}
+}
+
+func TestRunningDeploymentsCachesStates(t *testing.T) {
+	history := &dtos.SingularityDeployHistory{
+		DeployMarker: &dtos.SingularityDeployMarker{RequestId: "request-1", DeployId: "deploy-1"},
+		DeployResult: &dtos.SingularityDeployResult{DeployState: dtos.SingularityDeployResultDeployStateSUCCEEDED},
+		Deploy: &dtos.SingularityDeploy{
+			Id:            "deploy-1",
+			Metadata:      map[string]string{sous.ClusterNameLabel: "left"},
+			ContainerInfo: &dtos.SingularityContainerInfo{Type: "DOCKER", Docker: &dtos.SingularityDockerInfo{Image: "example:1.2.3"}},
+			Resources:     &dtos.Resources{},
+		},
+	}
+	// assemble is true if the next client made should answer the requests
+	// needed to assemble request-1.
+	assemble := true
+	instances := int32(1)
+	dep := NewDeployer(sous.NewDummyRectificationClient(), logging.SilentLogSet()).(*deployer)
+	dep.SetSingularityFactory(func(url string) *singularity.Client {
+		cl, co := singularity.NewDummyClient(url)
+		co.FeedDTO(&dtos.SingularityRequestParentList{cacheTestRequest(instances)}, nil)
+		if assemble {
+			co.FeedDTO(&dtos.SingularityDeployHistoryList{history}, nil)
+			co.FeedDTO(history, nil)
+		}
+		return cl
+	})
+	reg := labelledRegistry{sous.NewDummyRegistry()}
+	clusters := sous.Clusters{"left": {Name: "left", BaseURL: "http://singularity"}}
+
+	first, err := dep.RunningDeployments(reg, clusters)
+	require.NoError(t, err)
+	require.Equal(t, 1, first.Len())
+
+	assemble = false
+	second, err := dep.RunningDeployments(reg, clusters)
+	require.NoError(t, err, "polling an unchanged request should not ask Singularity about it")
+	require.Equal(t, 1, second.Len())
+	for id, want := range first.Snapshot() {
+		got, ok := second.Get(id)
+		require.True(t, ok, "%s missing from the second poll", id)
+		different, diffs := want.Diff(got)
+		assert.False(t, different, "%v", diffs)
+	}
+
+	instances = 2
+	_, err = dep.RunningDeployments(reg, clusters)
+	assert.Error(t, err, "a changed request should be assembled afresh")
+
+	instances = 1
+	assemble = true
+	_, err = dep.RunningDeployments(reg, clusters)
+	require.NoError(t, err)
+	dep.InvalidateRequest("request-1")
+	assemble = false
+	_, err = dep.RunningDeployments(reg, clusters)
+	assert.Error(t, err, "an invalidated request should be assembled afresh")
+}
+
+func TestStateCache(t *testing.T) {
+	clusters := sous.Clusters{"left": {Name: "left"}}
+	req := SingReq{SourceURL: "http://singularity", ReqParent: cacheTestRequest(1)}
+	active := &sous.DeployState{Status: sous.DeployStatusActive}
+	start := time.Now()
+
+	c := newStateCache(time.Minute)
+	assert.True(t, c.begin(start), "the first poll should be full")
+	c.store(clusters, req, active)
+	got, ok := c.lookup(clusters, req)
+	assert.True(t, ok)
+	if different, diffs := active.Diff(got); different {
+		t.Errorf("cached state differs: %v", diffs)
+	}
+
+	_, ok = c.lookup(sous.Clusters{"right": {Name: "right"}}, req)
+	assert.False(t, ok, "states are cached per set of clusters")
+
+	changed := SingReq{SourceURL: req.SourceURL, ReqParent: cacheTestRequest(2)}
+	_, ok = c.lookup(clusters, changed)
+	assert.False(t, ok, "a changed request should miss")
+
+	assert.False(t, c.begin(start.Add(time.Second)))
+	c.retain(clusters, req.SourceURL, nil)
+	_, ok = c.lookup(clusters, req)
+	assert.False(t, ok, "a deleted request should be dropped")
+
+	c.store(clusters, req, nil)
+	got, ok = c.lookup(clusters, req)
+	assert.True(t, ok)
+	assert.Nil(t, got, "an ignored request should be cached as ignored")
+
+	c.store(clusters, req, &sous.DeployState{Status: sous.DeployStatusPending})
+	_, ok = c.lookup(clusters, req)
+	assert.False(t, ok, "pending deploys should not be cached")
+
+	c.store(clusters, req, active)
+	assert.True(t, c.begin(start.Add(time.Minute)), "a full poll is due")
+	_, ok = c.lookup(clusters, req)
+	assert.False(t, ok, "a full poll should drop the cache")
+
+	uncached := newStateCache(0)
+	uncached.begin(start)
+	assert.True(t, uncached.begin(start), "every poll should be full without a full poll interval")
+}
+
+func TestCachedStates(t *testing.T) {
+	did := func(cluster string) sous.DeploymentID {
+		return sous.DeploymentID{ManifestID: sous.MustParseManifestID("github.com/example/app"), Cluster: cluster}
+	}
+	cs := &cachedStates{}
+	var wg sync.WaitGroup
+	for _, cluster := range []string{"left", "right"} {
+		wg.Add(1)
+		go func(cluster string) {
+			defer wg.Done()
+			cs.add(&sous.DeployState{Deployment: sous.Deployment{ClusterName: cluster, SourceID: sous.SourceID{Location: did(cluster).ManifestID.Source}}})
+		}(cluster)
+	}
+	wg.Wait()
+
+	deps := sous.NewDeployStates()
+	cs.addTo(deps)
+	assert.Equal(t, 2, deps.Len())
+	for _, cluster := range []string{"left", "right"} {
+		_, ok := deps.Get(did(cluster))
+		assert.True(t, ok, "%s missing", did(cluster))
+	}
+}
+
+func TestRequestFingerprint(t *testing.T) {
+	assert.Equal(t, requestFingerprint(cacheTestRequest(1)), requestFingerprint(cacheTestRequest(1)))
+	assert.NotEqual(t, requestFingerprint(cacheTestRequest(1)), requestFingerprint(cacheTestRequest(2)))
+
+	redeployed := cacheTestRequest(1)
+	redeployed.RequestDeployState.ActiveDeploy.Timestamp = 2
+	assert.NotEqual(t, requestFingerprint(cacheTestRequest(1)), requestFingerprint(redeployed))
+
+	assert.Equal(t, "", requestFingerprint(nil))
+}
@@ -0,0 +1,82 @@
+package singularity
+
+import (
+	"encoding/json"
+
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/swaggering"
+	"github.com/pkg/errors"
+)
+
+type (
+	// taskPorts is the part of a Singularity task which records the ports
+	// Mesos allocated to it. The generated DTOs don't include the Mesos task,
+	// so it is decoded directly.
+	taskPorts struct {
+		MesosTask struct {
+			Resources []struct {
+				Name   string
+				Ranges struct {
+					Range []struct {
+						Begin, End int
+					}
+				}
+			}
+		}
+	}
+)
+
+// TaskAddresses implements sous.TaskAddresser, listing the active tasks of
+// the current deploy of pair.Prior.
+func (r *deployer) TaskAddresses(pair *sous.DeployablePair) ([]sous.TaskAddress, error) {
+	data, ok := pair.ExecutorData.(*singularityTaskData)
+	if !ok || data.deployID == "" {
+		return nil, errors.Errorf("%s doesn't contain Singularity deploy data: was %T", pair.ID(), pair.ExecutorData)
+	}
+	if pair.Post == nil || pair.Post.Cluster == nil {
+		return nil, errors.Errorf("%s has no cluster", pair.ID())
+	}
+	client := r.buildSingClient(pair.Post.Cluster.BaseURL)
+	ids, err := client.GetActiveDeployTasks(data.requestID, data.deployID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing tasks of %s", data.deployID)
+	}
+	var addrs []sous.TaskAddress
+	for _, id := range ids {
+		if id.TaskId == nil {
+			continue
+		}
+		ports, err := taskPortsOf(client.Requester, id.TaskId.Id)
+		if err != nil {
+			return nil, err
+		}
+		addrs = append(addrs, sous.TaskAddress{TaskID: id.TaskId.Id, Host: id.TaskId.Host, Ports: ports})
+	}
+	return addrs, nil
+}
+
+// taskPortsOf returns the ports allocated to the task taskID, in order.
+func taskPortsOf(client swaggering.Requester, taskID string) ([]int, error) {
+	body, err := client.Request("singularity-getactivetask", "GET", "/api/tasks/task/{taskId}",
+		map[string]interface{}{"taskId": taskID}, map[string]interface{}{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting task %s", taskID)
+	}
+	defer body.Close()
+	var task taskPorts
+	if err := json.NewDecoder(body).Decode(&task); err != nil {
+		return nil, malformedResponse{"Singularity task " + taskID + ": " + err.Error()}
+	}
+	var ports []int
+	for _, res := range task.MesosTask.Resources {
+		if res.Name != "ports" {
+			continue
+		}
+		for _, rng := range res.Ranges.Range {
+			for p := rng.Begin; p <= rng.End; p++ {
+				ports = append(ports, p)
+			}
+		}
+	}
+	return ports, nil
+}
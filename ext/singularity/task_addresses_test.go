@@ -0,0 +1,48 @@
+package singularity
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/opentable/go-singularity"
+	"github.com/opentable/go-singularity/dtos"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+)
+
+func TestDeployerTaskAddresses(t *testing.T) {
+	dep := NewDeployer(sous.NewDummyRectificationClient(), logging.SilentLogSet()).(*deployer)
+	dep.SetSingularityFactory(func(url string) *singularity.Client {
+		cl, co := singularity.NewDummyClient(url)
+		co.FeedDTO(&dtos.SingularityTaskIdHistoryList{
+			&dtos.SingularityTaskIdHistory{TaskId: &dtos.SingularityTaskId{Id: "task-1", Host: "host-1"}},
+		}, nil)
+		co.FeedSimple(`{
+			"taskId": {"id": "task-1", "host": "host-1"},
+			"mesosTask": {"resources": [
+				{"name": "cpus", "scalar": {"value": 0.1}},
+				{"name": "ports", "ranges": {"range": [{"begin": 31000, "end": 31001}, {"begin": 31005, "end": 31005}]}}
+			]}
+		}`, nil)
+		return cl
+	})
+
+	pair := &sous.DeployablePair{
+		ExecutorData: &singularityTaskData{requestID: "request-1", deployID: "deploy-1"},
+		Prior:        &sous.Deployable{Deployment: &sous.Deployment{}},
+		Post:         &sous.Deployable{Deployment: &sous.Deployment{Cluster: &sous.Cluster{BaseURL: "http://singularity"}}},
+	}
+	addrs, err := dep.TaskAddresses(pair)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []sous.TaskAddress{{TaskID: "task-1", Host: "host-1", Ports: []int{31000, 31001, 31005}}}
+	if !reflect.DeepEqual(addrs, want) {
+		t.Errorf("got %+v; want %+v", addrs, want)
+	}
+
+	pair.ExecutorData = nil
+	if _, err := dep.TaskAddresses(pair); err == nil {
+		t.Errorf("listed tasks without Singularity deploy data")
+	}
+}
@@ -0,0 +1,126 @@
+package singularity
+
+import (
+	"strings"
+	"time"
+
+	"github.com/opentable/go-singularity/dtos"
+	"github.com/opentable/sous/lib"
+	"github.com/pkg/errors"
+)
+
+// maxTaskFileChunk is the most of a sandbox file read at once.
+const maxTaskFileChunk = 64 * 1024
+
+// Tasks implements sous.TaskInspector on deployer.
+func (r *deployer) Tasks(d *sous.Deployment) ([]sous.TaskInfo, error) {
+	cluster, reqID, err := r.inspectedRequest(d)
+	if err != nil {
+		return nil, err
+	}
+	return r.Client.Tasks(cluster, reqID)
+}
+
+// ReadTaskFile implements sous.TaskInspector on deployer. Only the tasks of
+// d's own request may be read.
+func (r *deployer) ReadTaskFile(d *sous.Deployment, taskID, path string, offset int64) (*sous.FileChunk, error) {
+	cluster, reqID, err := r.inspectedRequest(d)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(taskID, reqID+"-") {
+		return nil, errors.Errorf("task %s is not a task of %s", taskID, d.ID())
+	}
+	return r.Client.ReadTaskFile(cluster, taskID, path, offset)
+}
+
+// inspectedRequest returns the URL of the Singularity of d's cluster, and the
+// ID of d's request there.
+func (r *deployer) inspectedRequest(d *sous.Deployment) (string, string, error) {
+	if d.Cluster == nil {
+		return "", "", errors.Errorf("%s has no cluster", d.ID())
+	}
+	reqID, err := r.Client.RequestID(d)
+	if err != nil {
+		return "", "", err
+	}
+	return d.Cluster.BaseURL, reqID, nil
+}
+
+// RequestID implements rectificationClient on RectiAgent.
+func (ra *RectiAgent) RequestID(d *sous.Deployment) (string, error) {
+	if d.Cluster == nil {
+		return "", errors.Errorf("%s has no cluster", d.ID())
+	}
+	return ra.requestIDs.find(ra.singularityClient(d.Cluster.BaseURL), d)
+}
+
+// Tasks lists the running tasks of the active deploy of reqID, with their
+// ports and the outcome of their latest health check.
+func (ra *RectiAgent) Tasks(cluster, reqID string) ([]sous.TaskInfo, error) {
+	client := ra.singularityClient(cluster)
+	req, err := client.GetRequest(reqID, false)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting request %s", reqID)
+	}
+	if req.ActiveDeploy == nil {
+		return nil, nil
+	}
+	ids, err := client.GetActiveDeployTasks(reqID, req.ActiveDeploy.Id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing tasks of %s", req.ActiveDeploy.Id)
+	}
+	var tasks []sous.TaskInfo
+	for _, id := range ids {
+		if id.TaskId == nil {
+			continue
+		}
+		ports, err := taskPortsOf(client.Requester, id.TaskId.Id)
+		if err != nil {
+			return nil, err
+		}
+		history, err := client.GetHistoryForTask(id.TaskId.Id)
+		if err != nil {
+			return nil, errors.Wrapf(err, "getting history of task %s", id.TaskId.Id)
+		}
+		tasks = append(tasks, sous.TaskInfo{
+			TaskAddress: sous.TaskAddress{TaskID: id.TaskId.Id, Host: id.TaskId.Host, Ports: ports},
+			Started:     time.Unix(0, id.TaskId.StartedAt*int64(time.Millisecond)),
+			Health:      taskHealth(history.HealthcheckResults),
+		})
+	}
+	return tasks, nil
+}
+
+// taskHealth returns the health of a task given by the latest of its
+// healthcheck results.
+func taskHealth(results dtos.SingularityTaskHealthcheckResultList) sous.TaskHealth {
+	var latest *dtos.SingularityTaskHealthcheckResult
+	for _, r := range results {
+		if r != nil && (latest == nil || r.Timestamp > latest.Timestamp) {
+			latest = r
+		}
+	}
+	switch {
+	case latest == nil:
+		return sous.TaskHealthUnknown
+	case latest.ErrorMessage == "" && latest.StatusCode >= 200 && latest.StatusCode < 300:
+		return sous.TaskHealthy
+	default:
+		return sous.TaskUnhealthy
+	}
+}
+
+// ReadTaskFile reads the file at path in the sandbox of taskID, from offset
+// on.
+func (ra *RectiAgent) ReadTaskFile(cluster, taskID, path string, offset int64) (*sous.FileChunk, error) {
+	chunk, err := ra.singularityClient(cluster).Read(taskID, path, "", offset, maxTaskFileChunk)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading %s of task %s", path, taskID)
+	}
+	next := chunk.NextOffset
+	if next == 0 {
+		next = offset + int64(len(chunk.Data))
+	}
+	return &sous.FileChunk{Data: chunk.Data, NextOffset: next}, nil
+}
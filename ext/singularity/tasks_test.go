@@ -0,0 +1,97 @@
+package singularity
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/opentable/go-singularity"
+	"github.com/opentable/go-singularity/dtos"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/swaggering"
+)
+
+// rectiAgentWithResponses returns a RectiAgent whose client for url responds
+// with feed.
+func rectiAgentWithResponses(url string, feed func(swaggering.DummyControl)) *RectiAgent {
+	ra := NewRectiAgent(nil, nil)
+	cl, co := singularity.NewDummyClient(url)
+	feed(co)
+	ra.singClients[url] = cl
+	return ra
+}
+
+func TestRectiAgentTasks(t *testing.T) {
+	started := time.Date(2017, 6, 1, 12, 0, 0, 0, time.UTC)
+	ra := rectiAgentWithResponses("http://singularity", func(co swaggering.DummyControl) {
+		co.FeedDTO(&dtos.SingularityRequestParent{ActiveDeploy: &dtos.SingularityDeploy{Id: "deploy-1"}}, nil)
+		co.FeedDTO(&dtos.SingularityTaskIdHistoryList{
+			&dtos.SingularityTaskIdHistory{TaskId: &dtos.SingularityTaskId{
+				Id: "task-1", Host: "host-1", StartedAt: started.UnixNano() / int64(time.Millisecond),
+			}},
+		}, nil)
+		co.FeedSimple(`{"mesosTask": {"resources": [
+			{"name": "ports", "ranges": {"range": [{"begin": 31000, "end": 31000}]}}
+		]}}`, nil)
+		co.FeedDTO(&dtos.SingularityTaskHistory{HealthcheckResults: dtos.SingularityTaskHealthcheckResultList{
+			&dtos.SingularityTaskHealthcheckResult{Timestamp: 2, StatusCode: 200},
+			&dtos.SingularityTaskHealthcheckResult{Timestamp: 1, StatusCode: 503},
+		}}, nil)
+	})
+
+	tasks, err := ra.Tasks("http://singularity", "request-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []sous.TaskInfo{{
+		TaskAddress: sous.TaskAddress{TaskID: "task-1", Host: "host-1", Ports: []int{31000}},
+		Health:      sous.TaskHealthy,
+	}}
+	if len(tasks) != 1 || !tasks[0].Started.Equal(started) {
+		t.Fatalf("got %+v; want one task started at %s", tasks, started)
+	}
+	tasks[0].Started = time.Time{}
+	if !reflect.DeepEqual(tasks, want) {
+		t.Errorf("got %+v; want %+v", tasks, want)
+	}
+}
+
+func TestTaskHealth(t *testing.T) {
+	if got := taskHealth(nil); got != sous.TaskHealthUnknown {
+		t.Errorf("got %s for a task never checked", got)
+	}
+	failed := dtos.SingularityTaskHealthcheckResultList{
+		&dtos.SingularityTaskHealthcheckResult{Timestamp: 1, StatusCode: 200},
+		&dtos.SingularityTaskHealthcheckResult{Timestamp: 2, ErrorMessage: "connection refused"},
+	}
+	if got := taskHealth(failed); got != sous.TaskUnhealthy {
+		t.Errorf("got %s for a task whose latest check failed", got)
+	}
+}
+
+func TestDeployerReadTaskFile(t *testing.T) {
+	ra := rectiAgentWithResponses("http://singularity", func(co swaggering.DummyControl) {
+		co.FeedDTO(&dtos.MesosFileChunkObject{Data: "hello\n", Offset: 10}, nil)
+	})
+	dep := NewDeployer(ra, logging.SilentLogSet()).(*deployer)
+	d := &sous.Deployment{
+		ClusterName: "left",
+		Cluster:     &sous.Cluster{Name: "left", BaseURL: "http://singularity"},
+		SourceID:    sous.MustNewSourceID("github.com/ot/app", "", "1.0.0"),
+	}
+	reqID := "legacy-app"
+	ra.requestIDs.remember(d, reqID)
+
+	chunk, err := dep.ReadTaskFile(d, reqID+"-deploy-1-1", "stdout", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (&sous.FileChunk{Data: "hello\n", NextOffset: 16}); !reflect.DeepEqual(chunk, want) {
+		t.Errorf("got %+v; want %+v", chunk, want)
+	}
+
+	if _, err := dep.ReadTaskFile(d, "other-request-deploy-1-1", "stdout", 0); err == nil {
+		t.Errorf("read the file of a task of another request")
+	}
+}
@@ -48,3 +48,15 @@ func (dup *DuplexStateManager) WriteState(state *sous.State, user sous.User) err
 	reportWriting(dup.log, start, state, err)
 	return err
 }
+
+// ReadHistory implements sous.HistoryReader on DuplexStateManager, reading
+// from the primary StateManager if it keeps history, and the secondary if not.
+func (dup *DuplexStateManager) ReadHistory(did sous.DeploymentID) (sous.DeploymentHistory, error) {
+	if hr, ok := dup.primary.(sous.HistoryReader); ok {
+		return hr.ReadHistory(did)
+	}
+	if hr, ok := dup.secondary.(sous.HistoryReader); ok {
+		return hr.ReadHistory(did)
+	}
+	return nil, errors.Errorf("neither primary nor secondary StateManager keeps history")
+}
@@ -5,11 +5,14 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/opentable/sous/lib"
 	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/yaml"
 	"github.com/pborman/uuid"
 	"github.com/pkg/errors"
 )
@@ -236,3 +239,64 @@ func (gsm *GitStateManager) WriteState(s *sous.State, u sous.User) error {
 	}
 	return fmt.Errorf("unable to merge changes")
 }
+
+// ReadHistory implements sous.HistoryReader on GitStateManager. Each commit
+// which touched the manifest of the deployment is a potential change: the
+// commit's author is the User who made it.
+func (gsm *GitStateManager) ReadHistory(did sous.DeploymentID) (sous.DeploymentHistory, error) {
+	gsm.Lock()
+	defer gsm.Unlock()
+	gsm.git("pull")
+
+	path := manifestPath(did.ManifestID)
+	out, err := gsm.gitOut("log", "--reverse", "--format=%H%x09%at%x09%an%x09%ae", "--", path)
+	if err != nil {
+		return nil, err
+	}
+
+	history := sous.DeploymentHistory{}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if line == "" {
+			continue
+		}
+		fields := strings.SplitN(line, "\t", 4)
+		if len(fields) != 4 {
+			return nil, errors.Errorf("unexpected git log output: %q", line)
+		}
+		secs, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing commit time of %s", fields[0])
+		}
+		spec, err := gsm.specAt(fields[0], path, did.Cluster)
+		if err != nil {
+			return nil, err
+		}
+		history.Record(spec, sous.User{Name: fields[2], Email: fields[3]}, time.Unix(secs, 0))
+	}
+	return history, nil
+}
+
+// specAt returns the DeploySpec for cluster in the manifest at path as of
+// commit rev, or nil if there was no such manifest or deployment.
+func (gsm *GitStateManager) specAt(rev, path, cluster string) (*sous.DeploySpec, error) {
+	content, err := gsm.gitOut("show", rev+":"+path)
+	if err != nil {
+		// The commit deleted the manifest.
+		return nil, nil
+	}
+	m := &sous.Manifest{}
+	if err := yaml.Unmarshal([]byte(content), m); err != nil {
+		return nil, errors.Wrapf(err, "parsing %s at %s", path, rev)
+	}
+	spec, ok := m.Deployments[cluster]
+	if !ok {
+		return nil, nil
+	}
+	return &spec, nil
+}
+
+// manifestPath returns the path, relative to the root of the state, of the
+// file where the manifest with ID mid is stored.
+func manifestPath(mid sous.ManifestID) string {
+	return filepath.Join("manifests", mid.String()+".yaml")
+}
@@ -6,10 +6,12 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/opentable/sous/lib"
 	"github.com/opentable/sous/util/yaml"
 	"github.com/pkg/errors"
+	"github.com/samsalisbury/semv"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -76,6 +78,47 @@ func TestGitStateManager_WriteState_multiple_manifests(t *testing.T) {
 	}
 }
 
+func TestGitStateManager_WriteState_freeze_override(t *testing.T) {
+	require := require.New(t)
+
+	now := time.Now()
+	s := exampleState()
+	s.Defs.Freezes = sous.Freezes{{
+		Clusters:   []string{"cluster-1"},
+		Start:      now.Add(-time.Hour),
+		Overriders: []string{testUser.Email},
+	}}
+	PrepareTestGitRepo(t, s, "testdata/remote", "testdata/out")
+	gsm := NewGitStateManager(NewDiskStateManager("testdata/out"))
+
+	before := s.Manifests.Clone()
+	beforeDs, err := s.Deployments()
+	require.NoError(err)
+	m, ok := s.Manifests.Any(func(m *sous.Manifest) bool { return m.Source.Repo == "github.com/opentable/sous" })
+	require.True(ok)
+	m = m.Clone()
+	m.Deployments["cluster-1"].Env["NEWVAR"] = "YOLO"
+	s.Manifests.Set(m.ID(), m)
+	afterDs, err := s.Deployments()
+	require.NoError(err)
+
+	overrides, err := s.Defs.Freezes.Override(beforeDs, afterDs, testUser, "urgent", now)
+	require.NoError(err)
+	require.Len(overrides, 1)
+	s.Defs.Freezes.RecordOverrides(before, s.Manifests, overrides, now)
+
+	require.NoError(gsm.WriteState(s, testUser), "recording an override should only change the manifest")
+
+	actual, err := gsm.ReadState()
+	require.NoError(err)
+	written, ok := actual.Manifests.Get(m.ID())
+	require.True(ok)
+	if assert.Len(t, written.FreezeOverrides, 1) {
+		assert.Equal(t, "urgent", written.FreezeOverrides[0].Reason)
+	}
+	assert.Len(t, actual.Defs.Freezes, 1)
+}
+
 func TestGitReadState(t *testing.T) {
 	require := require.New(t)
 
@@ -327,3 +370,32 @@ func TestGitReadState_empty(t *testing.T) {
 		t.Errorf("got len %d; want %d", d.Len(), 0)
 	}
 }
+
+func TestGitStateManager_ReadHistory(t *testing.T) {
+	require := require.New(t)
+
+	s := exampleState()
+	PrepareTestGitRepo(t, s, "testdata/remote", "testdata/out")
+	gsm := NewGitStateManager(NewDiskStateManager("testdata/out"))
+
+	mid := sous.ManifestID{Source: sous.SourceLocation{Repo: "github.com/opentable/sous"}}
+	m, ok := s.Manifests.Get(mid)
+	require.True(ok)
+	spec := m.Deployments["cluster-1"]
+	spec.Version = semv.MustParse("1.0.0")
+	spec.NumInstances = 3
+	m.Deployments["cluster-1"] = spec
+	require.NoError(gsm.WriteState(s, testUser))
+
+	history, err := gsm.ReadHistory(sous.DeploymentID{ManifestID: mid, Cluster: "cluster-1"})
+	require.NoError(err)
+	require.Len(history, 2)
+
+	assert.Equal(t, "1.0.0-rc.1+deadbeef", history[0].Version.String())
+	assert.Empty(t, history[0].Diffs)
+
+	assert.Equal(t, "1.0.0", history[1].Version.String())
+	assert.Equal(t, testUser, history[1].User)
+	assert.Len(t, history[1].Diffs, 2)
+	assert.False(t, history[1].Time.IsZero())
+}
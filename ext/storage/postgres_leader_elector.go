@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/opentable/sous/util/logging"
+	"github.com/pkg/errors"
+)
+
+// A PostgresLeaderElector elects leaders with Postgres advisory locks: the
+// leader for a cluster is the server holding the cluster's lock. A lock is
+// held by a database session, so a leader which dies, or loses its
+// connection, loses its leadership. The URL of each leader is recorded in the
+// cluster_leaders table, for other servers to find it.
+type PostgresLeaderElector struct {
+	db  *sql.DB
+	url string
+	log logging.LogSink
+	mu  sync.Mutex
+	// sessions holds the connection holding the lock for each cluster led.
+	sessions map[string]*sql.Conn
+}
+
+// NewPostgresLeaderElector returns a PostgresLeaderElector for the server
+// at url.
+func NewPostgresLeaderElector(db *sql.DB, url string, log logging.LogSink) *PostgresLeaderElector {
+	return &PostgresLeaderElector{db: db, url: url, log: log, sessions: map[string]*sql.Conn{}}
+}
+
+// leaderLockKey is the advisory lock key for cluster.
+func leaderLockKey(cluster string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("sous leader " + cluster))
+	return int64(h.Sum64())
+}
+
+// Campaign implements sous.LeaderElector.
+func (le *PostgresLeaderElector) Campaign(cluster string) (bool, error) {
+	le.mu.Lock()
+	defer le.mu.Unlock()
+	ctx := context.TODO()
+	if conn, ok := le.sessions[cluster]; ok {
+		if err := conn.PingContext(ctx); err != nil {
+			conn.Close()
+			delete(le.sessions, cluster)
+			return false, errors.Wrapf(err, "lost the session holding the lock")
+		}
+		return true, nil
+	}
+
+	conn, err := le.db.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+	key := leaderLockKey(cluster)
+	var locked bool
+	start := time.Now()
+	query := `select pg_try_advisory_lock($1)`
+	err = conn.QueryRowContext(ctx, query, key).Scan(&locked)
+	reportSQLMessage(le.log, start, query, 1, err)
+	if err != nil || !locked {
+		conn.Close()
+		return false, err
+	}
+
+	start = time.Now()
+	query = `insert into cluster_leaders (cluster, url, since) values ($1, $2, now())
+		on conflict (cluster) do update set url = excluded.url, since = excluded.since`
+	_, err = conn.ExecContext(ctx, query, cluster, le.url)
+	reportSQLMessage(le.log, start, query, 1, err)
+	if err != nil {
+		// Closing conn only returns the session to the pool, which would
+		// keep holding the lock.
+		le.unlock(ctx, conn, cluster)
+		conn.Close()
+		return false, errors.Wrapf(err, "recording leader")
+	}
+	le.sessions[cluster] = conn
+	return true, nil
+}
+
+// Resign implements sous.LeaderElector.
+func (le *PostgresLeaderElector) Resign(cluster string) error {
+	le.mu.Lock()
+	defer le.mu.Unlock()
+	conn, ok := le.sessions[cluster]
+	if !ok {
+		return nil
+	}
+	delete(le.sessions, cluster)
+	defer conn.Close()
+	ctx := context.TODO()
+	start := time.Now()
+	query := `delete from cluster_leaders where cluster = $1 and url = $2`
+	_, err := conn.ExecContext(ctx, query, cluster, le.url)
+	reportSQLMessage(le.log, start, query, 1, err)
+	// Unlock even if the delete failed: the session goes back to the pool,
+	// and would keep holding the lock. Leader ignores a recorded leader
+	// without it.
+	if uerr := le.unlock(ctx, conn, cluster); err == nil {
+		err = uerr
+	}
+	return err
+}
+
+// unlock releases the lock for cluster held by conn's session.
+func (le *PostgresLeaderElector) unlock(ctx context.Context, conn *sql.Conn, cluster string) error {
+	start := time.Now()
+	query := `select pg_advisory_unlock($1)`
+	_, err := conn.ExecContext(ctx, query, leaderLockKey(cluster))
+	reportSQLMessage(le.log, start, query, 1, err)
+	return err
+}
+
+// Leader implements sous.LeaderElector. A recorded leader whose lock is no
+// longer held, e.g. because it died, is not reported.
+func (le *PostgresLeaderElector) Leader(cluster string) (string, error) {
+	// A bigint advisory lock key appears in pg_locks split across classid
+	// (the high half) and objid (the low half), with objsubid 1.
+	key := uint64(leaderLockKey(cluster))
+	start := time.Now()
+	query := `select url from cluster_leaders where cluster = $1 and exists (
+			select 1 from pg_locks
+			where locktype = 'advisory' and granted and objsubid = 1
+				and classid::bigint = $2 and objid::bigint = $3
+		)`
+	var url string
+	err := le.db.QueryRow(query, cluster, int64(key>>32), int64(key&0xffffffff)).Scan(&url)
+	if err == sql.ErrNoRows {
+		reportSQLMessage(le.log, start, query, 0, nil)
+		return "", nil
+	}
+	reportSQLMessage(le.log, start, query, 1, err)
+	return url, err
+}
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/opentable/sous/util/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresLeaderElector(t *testing.T) {
+	db := setupDB(t)
+	left := NewPostgresLeaderElector(db, "http://left", logging.SilentLogSet())
+	right := NewPostgresLeaderElector(db, "http://right", logging.SilentLogSet())
+
+	leading, err := left.Campaign("cluster-1")
+	require.NoError(t, err)
+	assert.True(t, leading)
+	leading, err = left.Campaign("cluster-1")
+	require.NoError(t, err)
+	assert.True(t, leading, "the leader stays the leader")
+	leading, err = right.Campaign("cluster-1")
+	require.NoError(t, err)
+	assert.False(t, leading)
+
+	leader, err := right.Leader("cluster-1")
+	require.NoError(t, err)
+	assert.Equal(t, "http://left", leader)
+	leader, err = right.Leader("cluster-2")
+	require.NoError(t, err)
+	assert.Equal(t, "", leader)
+
+	require.NoError(t, left.Resign("cluster-1"))
+	leader, err = right.Leader("cluster-1")
+	require.NoError(t, err)
+	assert.Equal(t, "", leader)
+	leading, err = right.Campaign("cluster-1")
+	require.NoError(t, err)
+	assert.True(t, leading)
+	require.NoError(t, right.Resign("cluster-1"))
+}
+
+func TestPostgresLeaderElector_failuresReleaseLock(t *testing.T) {
+	db := setupDB(t)
+	left := NewPostgresLeaderElector(db, "http://left", logging.SilentLogSet())
+	right := NewPostgresLeaderElector(db, "http://right", logging.SilentLogSet())
+
+	_, err := db.Exec(`alter table cluster_leaders rename to cluster_leaders_gone`)
+	require.NoError(t, err)
+	leading, err := left.Campaign("cluster-1")
+	assert.Error(t, err, "recording the leader should fail")
+	assert.False(t, leading)
+	_, err = db.Exec(`alter table cluster_leaders_gone rename to cluster_leaders`)
+	require.NoError(t, err)
+
+	leading, err = right.Campaign("cluster-1")
+	require.NoError(t, err)
+	assert.True(t, leading, "a failed campaign should release the lock")
+
+	_, err = db.Exec(`alter table cluster_leaders rename to cluster_leaders_gone`)
+	require.NoError(t, err)
+	assert.Error(t, right.Resign("cluster-1"), "deleting the leader should fail")
+	_, err = db.Exec(`alter table cluster_leaders_gone rename to cluster_leaders`)
+	require.NoError(t, err)
+
+	leading, err = left.Campaign("cluster-1")
+	require.NoError(t, err)
+	assert.True(t, leading, "a failed resignation should release the lock")
+	require.NoError(t, left.Resign("cluster-1"))
+}
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"database/sql"
+	"time"
+
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/pkg/errors"
+)
+
+// A PostgresR11nQueueStore keeps the records of queued rectifications in the
+// rectifications table, so that they outlive the server process.
+type PostgresR11nQueueStore struct {
+	db  *sql.DB
+	log logging.LogSink
+}
+
+// NewPostgresR11nQueueStore creates a new PostgresR11nQueueStore.
+func NewPostgresR11nQueueStore(db *sql.DB, log logging.LogSink) *PostgresR11nQueueStore {
+	return &PostgresR11nQueueStore{db: db, log: log}
+}
+
+// Queued implements sous.R11nQueueStore.
+func (s *PostgresR11nQueueStore) Queued(rec sous.R11nRecord) error {
+	return s.exec(`insert into rectifications
+		(r11n_id, repo, dir, flavor, cluster, change, queued_at, owner)
+		values ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		string(rec.ID),
+		rec.DeploymentID.ManifestID.Source.Repo,
+		rec.DeploymentID.ManifestID.Source.Dir,
+		rec.DeploymentID.ManifestID.Flavor,
+		rec.DeploymentID.Cluster,
+		string(rec.Change),
+		rec.Queued,
+		rec.Owner)
+}
+
+// Dequeued implements sous.R11nQueueStore.
+func (s *PostgresR11nQueueStore) Dequeued(id sous.R11nID) error {
+	return s.exec(`delete from rectifications where r11n_id = $1`, string(id))
+}
+
+// Pending implements sous.R11nQueueStore.
+func (s *PostgresR11nQueueStore) Pending() ([]sous.R11nRecord, error) {
+	start := time.Now()
+	query := `select r11n_id, repo, dir, flavor, cluster, change, queued_at, owner
+		from rectifications order by queued_at`
+	recs := []sous.R11nRecord{}
+	rows, err := s.db.Query(query)
+	if err != nil {
+		reportSQLMessage(s.log, start, query, 0, err)
+		return nil, errors.Wrapf(err, "reading queued rectifications")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		rec := sous.R11nRecord{}
+		did := &rec.DeploymentID
+		var id, change string
+		if err := rows.Scan(&id,
+			&did.ManifestID.Source.Repo, &did.ManifestID.Source.Dir, &did.ManifestID.Flavor, &did.Cluster,
+			&change, &rec.Queued, &rec.Owner); err != nil {
+			reportSQLMessage(s.log, start, query, len(recs), err)
+			return nil, errors.Wrapf(err, "reading queued rectifications")
+		}
+		rec.ID = sous.R11nID(id)
+		rec.Change = sous.ResolutionType(change)
+		recs = append(recs, rec)
+	}
+	err = rows.Err()
+	reportSQLMessage(s.log, start, query, len(recs), err)
+	return recs, errors.Wrapf(err, "reading queued rectifications")
+}
+
+func (s *PostgresR11nQueueStore) exec(query string, args ...interface{}) error {
+	start := time.Now()
+	res, err := s.db.Exec(query, args...)
+	rowcount := 0
+	if err == nil {
+		if n, rerr := res.RowsAffected(); rerr == nil {
+			rowcount = int(n)
+		}
+	}
+	reportSQLMessage(s.log, start, query, rowcount, err)
+	return err
+}
@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresR11nQueueStore(t *testing.T) {
+	store := NewPostgresR11nQueueStore(setupDB(t), logging.SilentLogSet())
+
+	did := sous.DeploymentID{ManifestID: sous.MustParseManifestID("github.com/opentable/sous,api~canary"), Cluster: "cluster-1"}
+	queued := time.Now().Truncate(time.Second)
+	first := sous.R11nRecord{ID: sous.NewR11nID(), DeploymentID: did, Change: sous.ModifyDiff, Queued: queued, Owner: "<cluster:cluster-1 repo:* offset:* flavor:* tag:* revision:*>"}
+	second := sous.R11nRecord{ID: sous.NewR11nID(), DeploymentID: did, Change: sous.DeleteDiff, Queued: queued.Add(time.Second)}
+	require.NoError(t, store.Queued(second))
+	require.NoError(t, store.Queued(first))
+
+	pending, err := store.Pending()
+	require.NoError(t, err)
+	if assert.Len(t, pending, 2) {
+		assert.Equal(t, first.ID, pending[0].ID, "oldest first")
+		assert.Equal(t, did, pending[0].DeploymentID)
+		assert.Equal(t, sous.ModifyDiff, pending[0].Change)
+		assert.Equal(t, first.Owner, pending[0].Owner)
+		assert.True(t, queued.Equal(pending[0].Queued))
+	}
+
+	require.NoError(t, store.Dequeued(first.ID))
+	require.NoError(t, store.Dequeued("unknown"))
+	pending, err = store.Pending()
+	require.NoError(t, err)
+	if assert.Len(t, pending, 1) {
+		assert.Equal(t, second.ID, pending[0].ID)
+	}
+}
@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	sous "github.com/opentable/sous/lib"
+	"github.com/pkg/errors"
+	"github.com/samsalisbury/semv"
+)
+
+// historyRow is a single row of the deployments table, with the user who
+// wrote it.
+type historyRow struct {
+	spec           sous.DeploySpec
+	decommissioned bool
+	user           sous.User
+	at             time.Time
+}
+
+// ReadHistory implements sous.HistoryReader on PostgresStateManager. Every
+// write that changes a deployment adds a row to the deployments table, so
+// the history is those rows in order.
+func (m PostgresStateManager) ReadHistory(did sous.DeploymentID) (sous.DeploymentHistory, error) {
+	ctx := context.TODO()
+	tx, err := m.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, errors.Wrapf(err, "opening transaction")
+	}
+	defer func(tx *sql.Tx) {
+		// ignoring error - since if the Tx is committed, we would expect an error on rollback
+		tx.Rollback()
+	}(tx)
+
+	var order []int
+	rows := map[int]*historyRow{}
+
+	err = loadTable(ctx, m.log, tx,
+		`select
+			deployment_id,
+			"versionstring", "num_instances", "schedule_string", "lifecycle",
+			"updated_by_name", "updated_by_email", "updated_at",
+			"cr_skip", "cr_connect_delay", "cr_timeout", "cr_connect_interval",
+			"cr_proto", "cr_path", "cr_port_index", "cr_failure_statuses",
+			"cr_uri_timeout", "cr_interval", "cr_retries",
+			envs.key, envs.value,
+			"resource_name", "resource_value",
+			metadatas.name, metadatas.value,
+			"host", "container", "mode"
+		from
+			components
+			join deployments using (component_id)
+			join clusters using (cluster_id)
+			left join envs using (deployment_id)
+			left join resources using (deployment_id)
+			left join metadatas using (deployment_id)
+			left join volumes using (deployment_id)
+		where
+			repo = $1 and dir = $2 and flavor = $3 and clusters.name = $4
+		order by deployment_id
+		`,
+		func(rs *sql.Rows) error {
+			var id int
+			hr := &historyRow{spec: sous.DeploySpec{
+				DeployConfig: sous.DeployConfig{
+					Resources: map[string]string{},
+					Metadata:  map[string]string{},
+					Env:       map[string]string{},
+					Volumes:   sous.Volumes{},
+				},
+			}}
+			ds := &hr.spec
+			var versionString, lifecycle string
+
+			var envKey, envValue,
+				resName, resValue,
+				mdName, mdValue,
+				volHost, volContainer, volMode sql.NullString
+
+			if err := rs.Scan(
+				&id,
+				&versionString, &ds.NumInstances, &ds.Schedule, &lifecycle,
+				&hr.user.Name, &hr.user.Email, &hr.at,
+				&ds.Startup.SkipCheck, &ds.Startup.ConnectDelay, &ds.Startup.Timeout, &ds.Startup.ConnectInterval,
+				&ds.Startup.CheckReadyProtocol, &ds.Startup.CheckReadyURIPath, &ds.Startup.CheckReadyPortIndex, pq.Array(&ds.Startup.CheckReadyFailureStatuses),
+				&ds.Startup.CheckReadyURITimeout, &ds.Startup.CheckReadyInterval, &ds.Startup.CheckReadyRetries,
+				&envKey, &envValue,
+				&resName, &resValue,
+				&mdName, &mdValue,
+				&volHost, &volContainer, &volMode,
+			); err != nil {
+				return err
+			}
+			if prev, has := rows[id]; has {
+				hr = prev
+				ds = &hr.spec
+			} else {
+				var err error
+				if ds.Version, err = semv.Parse(versionString); err != nil {
+					return err
+				}
+				hr.decommissioned = lifecycle == "decommissioned"
+				rows[id] = hr
+				order = append(order, id)
+			}
+			if envKey.Valid && envValue.Valid {
+				ds.Env[envKey.String] = envValue.String
+			}
+			if resName.Valid && resValue.Valid {
+				ds.Resources[resName.String] = resValue.String
+			}
+			if mdName.Valid && mdValue.Valid {
+				ds.Metadata[mdName.String] = mdValue.String
+			}
+			if volHost.Valid && volContainer.Valid && volMode.Valid {
+				vol := sous.Volume{
+					Host:      volHost.String,
+					Container: volContainer.String,
+					Mode:      sous.VolumeMode(volMode.String),
+				}
+				for i := range ds.Volumes {
+					if ds.Volumes[i].Equal(&vol) {
+						return nil
+					}
+				}
+				ds.Volumes = append(ds.Volumes, &vol)
+			}
+			return nil
+		},
+		did.ManifestID.Source.Repo, did.ManifestID.Source.Dir, did.ManifestID.Flavor, did.Cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.Wrapf(err, "committing transaction")
+	}
+
+	history := sous.DeploymentHistory{}
+	for _, id := range order {
+		hr := rows[id]
+		if hr.decommissioned {
+			history.Record(nil, hr.user, hr.at)
+			continue
+		}
+		history.Record(&hr.spec, hr.user, hr.at)
+	}
+	return history, nil
+}
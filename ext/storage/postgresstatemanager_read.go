@@ -269,10 +269,10 @@ func loadManifests(context context.Context, log logging.LogSink, tx *sql.Tx, sta
 		})
 }
 
-func loadTable(ctx context.Context, log logging.LogSink, tx *sql.Tx, sql string, pack func(*sql.Rows) error) error {
+func loadTable(ctx context.Context, log logging.LogSink, tx *sql.Tx, sql string, pack func(*sql.Rows) error, args ...interface{}) error {
 	rowcount := 0
 	start := time.Now()
-	rows, err := tx.QueryContext(ctx, sql)
+	rows, err := tx.QueryContext(ctx, sql, args...)
 	if err != nil {
 		reportSQLMessage(log, start, sql, rowcount, err)
 		return err
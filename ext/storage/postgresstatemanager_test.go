@@ -92,6 +92,29 @@ func TestPostgresStateManagerWriteState_success(t *testing.T) {
 	}
 }
 
+func TestPostgresStateManagerReadHistory(t *testing.T) {
+	suite := SetupTest(t)
+
+	s := exampleState()
+	suite.require.NoError(suite.manager.WriteState(s, testUser))
+
+	mid := sous.ManifestID{Source: sous.SourceLocation{Repo: "github.com/opentable/sous"}}
+	m, ok := s.Manifests.Get(mid)
+	suite.require.True(ok)
+	spec := m.Deployments["cluster-1"]
+	spec.NumInstances = 3
+	m.Deployments["cluster-1"] = spec
+	otherUser := sous.User{Name: "Other User", Email: "other@user.com"}
+	suite.require.NoError(suite.manager.WriteState(s, otherUser))
+
+	history, err := suite.manager.ReadHistory(sous.DeploymentID{ManifestID: mid, Cluster: "cluster-1"})
+	suite.require.NoError(err)
+	suite.require.Len(history, 2)
+	suite.Equal(testUser, history[0].User)
+	suite.Equal(otherUser, history[1].User)
+	suite.Len(history[1].Diffs, 1)
+}
+
 func (suite *PostgresStateManagerSuite) pluckSQL(sql string) interface{} {
 	var v interface{}
 
@@ -30,7 +30,7 @@ func (m PostgresStateManager) WriteState(state *sous.State, user sous.User) erro
 		tx.Rollback()
 	}(tx)
 
-	if err := storeManifests(context, m.log, state, tx); err != nil {
+	if err := storeManifests(context, m.log, state, user, tx); err != nil {
 		reportWriting(m.log, start, state, errors.Wrapf(err, "storing state"))
 		return err
 	}
@@ -43,7 +43,7 @@ func (m PostgresStateManager) WriteState(state *sous.State, user sous.User) erro
 	return nil
 }
 
-func storeManifests(ctx context.Context, log logging.LogSink, state *sous.State, tx *sql.Tx) error {
+func storeManifests(ctx context.Context, log logging.LogSink, state *sous.State, user sous.User, tx *sql.Tx) error {
 	newDeps, err := state.Deployments()
 	if err != nil {
 		return err
@@ -108,6 +108,8 @@ func storeManifests(ctx context.Context, log logging.LogSink, state *sous.State,
 			r.fd("?", "num_instances", dep.NumInstances)
 			r.fd("?", "schedule_string", dep.Schedule)
 			r.fd("?", "lifecycle", "active")
+			r.fd("?", "updated_by_name", user.Name)
+			r.fd("?", "updated_by_email", user.Email)
 			startupFields(r, "cr", s)
 		})
 	}); err != nil {
@@ -122,7 +124,9 @@ func storeManifests(ctx context.Context, log logging.LogSink, state *sous.State,
 			r.fd("?", "versionstring", dep.SourceID.Version.String())
 			r.fd("?", "num_instances", dep.NumInstances)
 			r.fd("?", "schedule_string", dep.Schedule)
-			r.fd("?", "lifecycle", "decommisioned")
+			r.fd("?", "lifecycle", "decommissioned")
+			r.fd("?", "updated_by_name", user.Name)
+			r.fd("?", "updated_by_email", user.Email)
 			startupFields(r, "cr", s)
 		})
 	}); err != nil {
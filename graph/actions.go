@@ -2,10 +2,13 @@ package graph
 
 import (
 	"os"
+	"time"
 
 	"github.com/opentable/sous/cli/actions"
 	"github.com/opentable/sous/config"
+	"github.com/opentable/sous/ext/singularity"
 	sous "github.com/opentable/sous/lib"
+	"github.com/pkg/errors"
 	"github.com/samsalisbury/semv"
 )
 
@@ -18,7 +21,7 @@ func (di *SousGraph) guardedAdd(guardName string, value interface{}) {
 }
 
 // GetUpdate returns an update Action.
-func (di *SousGraph) GetUpdate(dff config.DeployFilterFlags, otpl config.OTPLFlags) (actions.Action, error) {
+func (di *SousGraph) GetUpdate(dff config.DeployFilterFlags, otpl config.OTPLFlags, freezeOverride string) (actions.Action, error) {
 	di.guardedAdd("DeployFilterFlags", &dff)
 	di.guardedAdd("OTPLFlags", &otpl)
 	di.guardedAdd("Dryrun", DryrunNeither)
@@ -35,12 +38,205 @@ func (di *SousGraph) GetUpdate(dff config.DeployFilterFlags, otpl config.OTPLFla
 		return nil, err
 	}
 	return &actions.Update{
-		Manifest:      updateScoop.Manifest.Manifest,
-		GDM:           updateScoop.GDM.Deployments,
-		Client:        updateScoop.Client.HTTPClient,
-		ResolveFilter: (*sous.ResolveFilter)(updateScoop.ResolveFilter),
-		User:          updateScoop.User,
-		Log:           updateScoop.LogSink.LogSink,
+		Manifest:       updateScoop.Manifest.Manifest,
+		GDM:            updateScoop.GDM.Deployments,
+		Client:         updateScoop.Client.HTTPClient,
+		ResolveFilter:  (*sous.ResolveFilter)(updateScoop.ResolveFilter),
+		User:           updateScoop.User,
+		Log:            updateScoop.LogSink.LogSink,
+		FreezeOverride: freezeOverride,
+	}, nil
+}
+
+// GetResolveNow returns an Action which asks the server of the target
+// deployment's cluster to resolve it straight away.
+func (di *SousGraph) GetResolveNow(dff config.DeployFilterFlags) (actions.Action, error) {
+	di.guardedAdd("DeployFilterFlags", &dff)
+	di.guardedAdd("Dryrun", DryrunNeither)
+
+	scoop := struct {
+		Config        LocalSousConfig
+		Manifest      TargetManifest
+		Client        HTTPClient
+		ResolveFilter *RefinedResolveFilter
+		User          sous.User
+		LogSink       LogSink
+	}{}
+	if err := di.Inject(&scoop); err != nil {
+		return nil, err
+	}
+	did, err := (*sous.ResolveFilter)(scoop.ResolveFilter).DeploymentID(scoop.Manifest.ID())
+	if err != nil {
+		return nil, err
+	}
+	return &actions.ResolveNow{
+		Client:       scoop.Client.HTTPClient,
+		Auth:         scoop.Config.Auth,
+		DeploymentID: did,
+		User:         scoop.User,
+		Log:          scoop.LogSink.LogSink,
+	}, nil
+}
+
+// GetRun returns an Action which asks the server of the target deployment's
+// cluster to launch a run of it with args, and waits for the run to finish.
+func (di *SousGraph) GetRun(dff config.DeployFilterFlags, args []string, stream bool, timeout time.Duration) (actions.Action, error) {
+	di.guardedAdd("DeployFilterFlags", &dff)
+	di.guardedAdd("Dryrun", DryrunNeither)
+
+	scoop := struct {
+		Config        LocalSousConfig
+		Manifest      TargetManifest
+		Client        HTTPClient
+		ResolveFilter *RefinedResolveFilter
+		Out           OutWriter
+		Err           ErrWriter
+		User          sous.User
+		LogSink       LogSink
+	}{}
+	if err := di.Inject(&scoop); err != nil {
+		return nil, err
+	}
+	if !scoop.Manifest.Kind.Runnable() {
+		return nil, errors.Errorf("%s is a %s; only %s and %s manifests can be run",
+			scoop.Manifest.ID(), scoop.Manifest.Kind, sous.ManifestKindOnDemand, sous.ManifestKindOnce)
+	}
+	did, err := (*sous.ResolveFilter)(scoop.ResolveFilter).DeploymentID(scoop.Manifest.ID())
+	if err != nil {
+		return nil, err
+	}
+	return &actions.Run{
+		Client:       scoop.Client.HTTPClient,
+		Auth:         scoop.Config.Auth,
+		DeploymentID: did,
+		Args:         args,
+		Stream:       stream,
+		Out:          scoop.Out,
+		Err:          scoop.Err,
+		PollInterval: 2 * time.Second,
+		Timeout:      timeout,
+		User:         scoop.User,
+		Log:          scoop.LogSink.LogSink,
+	}, nil
+}
+
+// GetTasks returns an Action which lists the running tasks of the target
+// deployment.
+func (di *SousGraph) GetTasks(dff config.DeployFilterFlags) (actions.Action, error) {
+	ti, d, out, err := di.inspectedDeployment(dff)
+	if err != nil {
+		return nil, err
+	}
+	return &actions.Tasks{Inspector: ti, Deployment: d, Out: out}, nil
+}
+
+// GetLogs returns an Action which copies file from the sandbox of a running
+// task of the target deployment, following it as it grows if follow is true.
+func (di *SousGraph) GetLogs(dff config.DeployFilterFlags, taskID, file string, follow bool) (actions.Action, error) {
+	ti, d, out, err := di.inspectedDeployment(dff)
+	if err != nil {
+		return nil, err
+	}
+	return &actions.Logs{
+		Inspector:    ti,
+		Deployment:   d,
+		TaskID:       taskID,
+		File:         file,
+		Follow:       follow,
+		PollInterval: time.Second,
+		Out:          out,
+	}, nil
+}
+
+// inspectedDeployment returns the target deployment, and the TaskInspector
+// of the deployer of its cluster.
+func (di *SousGraph) inspectedDeployment(dff config.DeployFilterFlags) (sous.TaskInspector, *sous.Deployment, OutWriter, error) {
+	di.guardedAdd("DeployFilterFlags", &dff)
+	di.guardedAdd("Dryrun", DryrunNeither)
+
+	scoop := struct {
+		Manifest      TargetManifest
+		GDM           CurrentGDM
+		ResolveFilter *RefinedResolveFilter
+		Deployer      sous.Deployer
+		Out           OutWriter
+	}{}
+	if err := di.Inject(&scoop); err != nil {
+		return nil, nil, nil, err
+	}
+	did, err := (*sous.ResolveFilter)(scoop.ResolveFilter).DeploymentID(scoop.Manifest.ID())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	d, ok := scoop.GDM.Get(did)
+	if !ok {
+		return nil, nil, nil, errors.Errorf("%s is not deployed to %s", did.ManifestID, did.Cluster)
+	}
+	ti, ok := scoop.Deployer.(sous.TaskInspector)
+	if !ok {
+		return nil, nil, nil, errors.Errorf("the deployer can't inspect the tasks of %s", did)
+	}
+	return ti, d, scoop.Out, nil
+}
+
+// GetRollback returns a rollback Action.
+func (di *SousGraph) GetRollback(dff config.DeployFilterFlags, to string, waitStable bool) (actions.Action, error) {
+	di.guardedAdd("DeployFilterFlags", &dff)
+	di.guardedAdd("Dryrun", DryrunNeither)
+
+	scoop := struct {
+		Manifest      TargetManifest
+		GDM           CurrentGDM
+		Client        HTTPClient
+		Registry      sous.Registry
+		ResolveFilter *RefinedResolveFilter
+		User          sous.User
+		LogSink       LogSink
+	}{}
+	if err := di.Inject(&scoop); err != nil {
+		return nil, err
+	}
+	return &actions.Rollback{
+		Manifest:      scoop.Manifest.Manifest,
+		GDM:           scoop.GDM.Deployments,
+		Client:        scoop.Client.HTTPClient,
+		Registry:      scoop.Registry,
+		ResolveFilter: (*sous.ResolveFilter)(scoop.ResolveFilter),
+		To:            to,
+		WaitStable:    waitStable,
+		User:          scoop.User,
+		Log:           scoop.LogSink.LogSink,
+	}, nil
+}
+
+// GetPromote produces an Action to promote the version stable in the cluster
+// upstream of the one in dff.
+func (di *SousGraph) GetPromote(dff config.DeployFilterFlags) (actions.Action, error) {
+	di.guardedAdd("DeployFilterFlags", &dff)
+	di.guardedAdd("Dryrun", DryrunNeither)
+
+	scoop := struct {
+		Manifest      TargetManifest
+		State         *sous.State
+		GDM           CurrentGDM
+		Client        HTTPClient
+		Registry      sous.Registry
+		ResolveFilter *RefinedResolveFilter
+		User          sous.User
+		LogSink       LogSink
+	}{}
+	if err := di.Inject(&scoop); err != nil {
+		return nil, err
+	}
+	return &actions.Promote{
+		Manifest:      scoop.Manifest.Manifest,
+		Defs:          scoop.State.Defs,
+		GDM:           scoop.GDM.Deployments,
+		Client:        scoop.Client.HTTPClient,
+		Registry:      scoop.Registry,
+		ResolveFilter: (*sous.ResolveFilter)(scoop.ResolveFilter),
+		User:          scoop.User,
+		Log:           scoop.LogSink.LogSink,
 	}, nil
 }
 
@@ -65,6 +261,48 @@ func (di *SousGraph) GetRectify(dryrun string, dff config.DeployFilterFlags) (ac
 	}, nil
 }
 
+// GetPlan produces an Action to print the changes a rectification of the
+// deployments selected by dff would make. The plan is made by the server, if
+// one is configured.
+func (di *SousGraph) GetPlan(dff config.DeployFilterFlags, jsonOut bool) (actions.Action, error) {
+	di.guardedAdd("Dryrun", DryrunNeither)
+	di.guardedAdd("DeployFilterFlags", &dff)
+
+	scoop := struct {
+		Config        LocalSousConfig
+		ResolveFilter *sous.ResolveFilter
+		Out           OutWriter
+	}{}
+	if err := di.Inject(&scoop); err != nil {
+		return nil, err
+	}
+	plan := &actions.Plan{
+		ResolveFilter: scoop.ResolveFilter,
+		JSON:          jsonOut,
+		Out:           scoop.Out,
+	}
+
+	if scoop.Config.Server != "" {
+		clientScoop := struct{ Client HTTPClient }{}
+		if err := di.Inject(&clientScoop); err != nil {
+			return nil, err
+		}
+		plan.Client = clientScoop.Client.HTTPClient
+		return plan, nil
+	}
+
+	localScoop := struct {
+		Resolver *sous.Resolver
+		State    *sous.State
+	}{}
+	if err := di.Inject(&localScoop); err != nil {
+		return nil, err
+	}
+	plan.Resolver = localScoop.Resolver
+	plan.State = localScoop.State
+	return plan, nil
+}
+
 // GetPollStatus produces an Action to poll the status of a deployment.
 func (di *SousGraph) GetPollStatus(dryrun string, dff config.DeployFilterFlags) (actions.Action, error) {
 	di.guardedAdd("Dryrun", DryrunOption(dryrun))
@@ -99,6 +337,7 @@ func (di *SousGraph) GetServer(dff config.DeployFilterFlags, dryrun string, ladd
 		Config        *config.Config
 		ServerHandler ServerHandler
 		AutoResolver  *sous.AutoResolver
+		Leadership    *sous.Leadership
 	}{}
 
 	if err := di.Inject(&scoop); err != nil {
@@ -114,5 +353,131 @@ func (di *SousGraph) GetServer(dff config.DeployFilterFlags, dryrun string, ladd
 		Config:            scoop.Config,
 		ServerHandler:     scoop.ServerHandler.Handler,
 		AutoResolver:      scoop.AutoResolver,
+		Leadership:        scoop.Leadership,
+	}, nil
+}
+
+// secretsScoop is what the `sous secrets` actions are made from.
+type secretsScoop struct {
+	Config        LocalSousConfig
+	ManifestID    TargetManifestID
+	ResolveFilter *RefinedResolveFilter
+	In            InReader
+	Out           OutWriter
+}
+
+func (di *SousGraph) injectSecrets(dff config.DeployFilterFlags) (secretsScoop, string, sous.SecretStore, sous.DeploymentID, error) {
+	di.guardedAdd("DeployFilterFlags", &dff)
+	di.guardedAdd("Dryrun", DryrunNeither)
+
+	scoop := secretsScoop{}
+	if err := di.Inject(&scoop); err != nil {
+		return scoop, "", nil, sous.DeploymentID{}, err
+	}
+	did, err := (*sous.ResolveFilter)(scoop.ResolveFilter).DeploymentID(sous.ManifestID(scoop.ManifestID))
+	if err != nil {
+		return scoop, "", nil, did, err
+	}
+	name, store, err := scoop.Config.Secrets.SecretStore()
+	return scoop, name, store, did, err
+}
+
+// GetSecretsSet produces an Action to set a secret of the deployment
+// selected by dff. If value is empty, it is read from stdin.
+func (di *SousGraph) GetSecretsSet(dff config.DeployFilterFlags, key, value string) (actions.Action, error) {
+	scoop, name, store, did, err := di.injectSecrets(dff)
+	if err != nil {
+		return nil, err
+	}
+	return &actions.SecretsSet{
+		Store:        store,
+		StoreName:    name,
+		DeploymentID: did,
+		Key:          key,
+		Value:        value,
+		In:           scoop.In,
+		Out:          scoop.Out,
+	}, nil
+}
+
+// GetSecretsList produces an Action to list the secrets of the deployment
+// selected by dff.
+func (di *SousGraph) GetSecretsList(dff config.DeployFilterFlags) (actions.Action, error) {
+	scoop, name, store, did, err := di.injectSecrets(dff)
+	if err != nil {
+		return nil, err
+	}
+	return &actions.SecretsList{
+		Store:        store,
+		StoreName:    name,
+		DeploymentID: did,
+		Out:          scoop.Out,
+	}, nil
+}
+
+// GetSecretsRotate produces an Action to rotate a secret of the deployment
+// selected by dff. If value is empty, it is read from stdin.
+func (di *SousGraph) GetSecretsRotate(dff config.DeployFilterFlags, key, value string) (actions.Action, error) {
+	scoop, name, store, did, err := di.injectSecrets(dff)
+	if err != nil {
+		return nil, err
+	}
+	clientScoop := struct {
+		Client HTTPClient
+		User   sous.User
+	}{}
+	if err := di.Inject(&clientScoop); err != nil {
+		return nil, err
+	}
+	return &actions.SecretsRotate{
+		Store:        store,
+		StoreName:    name,
+		DeploymentID: did,
+		Key:          key,
+		Value:        value,
+		In:           scoop.In,
+		Client:       clientScoop.Client.HTTPClient,
+		User:         clientScoop.User,
+		Out:          scoop.Out,
+	}, nil
+}
+
+// GetImport produces an Action to import the requests on cluster which Sous
+// does not manage; all of them, unless requestIDs are given.
+func (di *SousGraph) GetImport(cluster string, requestIDs []string, yes bool) (actions.Action, error) {
+	di.guardedAdd("Dryrun", DryrunNeither)
+
+	scoop := struct {
+		Config      LocalSousConfig
+		NameCache   lazyNameCache
+		Registry    sous.Registry
+		State       *sous.State
+		StateWriter StateWriter
+		User        sous.User
+		In          InReader
+		Out         OutWriter
+	}{}
+	if err := di.Inject(&scoop); err != nil {
+		return nil, err
+	}
+	c, ok := scoop.State.Defs.Clusters[cluster]
+	if !ok {
+		return nil, errors.Errorf("cluster %q not defined, pick one of: %s", cluster, scoop.State.Defs.Clusters)
+	}
+	nc, err := scoop.NameCache()
+	if err != nil {
+		return nil, err
+	}
+	rc := singularity.NewRectiAgent(nc, scoop.Config.Secrets.Resolvers())
+	return &actions.Import{
+		Importer:    singularity.NewImporter(scoop.Registry, rc),
+		Cluster:     c,
+		RequestIDs:  requestIDs,
+		State:       scoop.State,
+		StateWriter: scoop.StateWriter,
+		User:        scoop.User,
+		Yes:         yes,
+		In:          scoop.In,
+		Out:         scoop.Out,
 	}, nil
 }
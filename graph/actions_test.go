@@ -29,7 +29,7 @@ func fixtureGraph() *SousGraph {
 }
 
 func TestActionUpdate(t *testing.T) {
-	action, err := fixtureGraph().GetUpdate(fixtureDeployFilterFlags(), config.OTPLFlags{})
+	action, err := fixtureGraph().GetUpdate(fixtureDeployFilterFlags(), config.OTPLFlags{}, "")
 	require.NoError(t, err)
 	update, rightType := action.(*actions.Update)
 	require.True(t, rightType)
@@ -7,11 +7,13 @@ import (
 	"net/http"
 	"os"
 	"os/user"
+	"time"
 
 	"github.com/opentable/sous/config"
 	"github.com/opentable/sous/ext/docker"
 	"github.com/opentable/sous/ext/git"
 	"github.com/opentable/sous/ext/github"
+	"github.com/opentable/sous/ext/kubernetes"
 	"github.com/opentable/sous/ext/singularity"
 	"github.com/opentable/sous/ext/storage"
 	"github.com/opentable/sous/lib"
@@ -228,6 +230,7 @@ func AddDocker(graph adder) {
 func AddSingularity(graph adder) {
 	graph.Add(
 		newDeployer,
+		newRunner,
 	)
 }
 
@@ -266,6 +269,9 @@ func AddInternals(graph adder) {
 		newResolveFilter,
 		newResolver,
 		newAutoResolver,
+		newR11nQueueStore,
+		newLeadership,
+		newWebhooks,
 		newInserter,
 		newStatusPoller,
 		newServerComponentLocator,
@@ -277,14 +283,54 @@ func newResolveFilter(sf *config.DeployFilterFlags, shc sous.SourceHostChooser)
 	return sf.BuildFilter(shc.ParseSourceLocation)
 }
 
-func newResolver(filter *sous.ResolveFilter, d sous.Deployer, r sous.Registry, ls LogSink) *sous.Resolver {
-	return sous.NewResolver(d, r, filter, ls.Child("resolver"))
+func newResolver(filter *sous.ResolveFilter, d sous.Deployer, r sous.Registry, c LocalSousConfig, ls LogSink) *sous.Resolver {
+	rez := sous.NewResolver(d, r, filter, ls.Child("resolver"))
+	rez.DeletionBrake = sous.NewDeletionBrake(c.DeletionBrake)
+	rez.Events = sous.NewResolveEvents()
+	return rez
 }
 
-func newAutoResolver(rez *sous.Resolver, sr *ServerStateManager, ls LogSink) *sous.AutoResolver {
+func newAutoResolver(rez *sous.Resolver, sr *ServerStateManager, qs sous.R11nQueueStore, wh *sous.Webhooks, ls LogSink) *sous.AutoResolver {
+	wh.Watch(rez.Events)
+	rez.UseQueueStore(qs)
 	return sous.NewAutoResolver(rez, sr, ls.Child("autoresolver"))
 }
 
+// newR11nQueueStore returns a store for the server's rectification queue,
+// in the database if there is one; otherwise, queued rectifications are kept
+// only in memory.
+func newR11nQueueStore(c LocalSousConfig, log LogSink) sous.R11nQueueStore {
+	db, err := c.Database.DB()
+	if err != nil {
+		logging.ReportError(log, errors.Wrapf(err, "connecting to database for the rectification queue"))
+		return sous.NewMemoryR11nQueueStore()
+	}
+	return storage.NewPostgresR11nQueueStore(db, log)
+}
+
+// newLeadership returns the server's Leadership of the cluster it resolves,
+// or nil if leader election is not enabled.
+func newLeadership(c LocalSousConfig, rf *sous.ResolveFilter, log LogSink) (*sous.Leadership, error) {
+	lc := c.Leadership
+	if !lc.Enabled {
+		return nil, nil
+	}
+	db, err := c.Database.DB()
+	if err != nil {
+		return nil, errors.Wrapf(err, "leader election needs the database")
+	}
+	le := storage.NewPostgresLeaderElector(db, lc.URL, log.Child("leadership"))
+	l := sous.NewLeadership(le, rf.Cluster.ValueOr("*"), log.Child("leadership"))
+	if lc.IntervalSeconds > 0 {
+		l.Interval = time.Duration(lc.IntervalSeconds) * time.Second
+	}
+	return l, nil
+}
+
+func newWebhooks(c LocalSousConfig, ls LogSink) (*sous.Webhooks, error) {
+	return sous.NewWebhooks(c.Webhooks, ls.Child("webhooks"))
+}
+
 func newSourceHostChooser() sous.SourceHostChooser {
 	return sous.SourceHostChooser{
 		SourceHosts: []sous.SourceHost{
@@ -488,22 +534,37 @@ func newDeployer(dryrun DryrunOption, nc lazyNameCache, ls LogSink, c LocalSousC
 	if dryrun == DryrunBoth || dryrun == DryrunScheduler {
 		drc := sous.NewDummyRectificationClient()
 		drc.SetLogger(ls.Child("rectify"))
-		return singularity.NewDeployer(
-			drc,
-			ls,
-			singularity.OptMaxHTTPReqsPerServer(c.MaxHTTPConcurrencySingularity),
-		), nil
+		return sous.NewDispatchDeployer(map[string]sous.Deployer{
+			"singularity": singularity.NewDeployer(
+				drc,
+				ls,
+				singularity.OptMaxHTTPReqsPerServer(c.MaxHTTPConcurrencySingularity),
+				singularity.OptFullPollInterval(time.Duration(c.SingularityFullPollSeconds)*time.Second),
+			),
+			"kubernetes": sous.NewDummyDeployer(),
+		}), nil
 	}
 	// We need the real name cache.
 	nameCache, err := nc()
 	if err != nil {
 		return nil, err
 	}
-	return singularity.NewDeployer(
-		singularity.NewRectiAgent(nameCache),
-		ls,
-		singularity.OptMaxHTTPReqsPerServer(c.MaxHTTPConcurrencySingularity),
-	), nil
+	return sous.NewDispatchDeployer(map[string]sous.Deployer{
+		"singularity": singularity.NewDeployer(
+			singularity.NewRectiAgent(nameCache, c.Secrets.Resolvers()),
+			ls,
+			singularity.OptMaxHTTPReqsPerServer(c.MaxHTTPConcurrencySingularity),
+			singularity.OptFullPollInterval(time.Duration(c.SingularityFullPollSeconds)*time.Second),
+		),
+		"kubernetes": kubernetes.NewDeployer(c.Kubernetes, c.Secrets.Resolvers(), ls.Child("kubernetes")),
+	}), nil
+}
+
+func newRunner(dryrun DryrunOption, ls LogSink) sous.Runner {
+	if dryrun == DryrunBoth || dryrun == DryrunScheduler {
+		return sous.NewDummyRunner()
+	}
+	return singularity.NewRunner(ls.Child("singularity"))
 }
 
 func newDockerClient(ls LogSink) LocalDockerClient {
@@ -535,10 +596,13 @@ func newHTTPClient(c LocalSousConfig, user sous.User, srvr ServerHandler, log Lo
 	}
 	log.Debugf("Using server at %s", c.Server)
 	cl, err := restful.NewClient(c.Server, log.Child("http-client"))
-	return HTTPClient{HTTPClient: cl}, err
+	if err != nil {
+		return HTTPClient{HTTPClient: cl}, err
+	}
+	return HTTPClient{HTTPClient: cl}, cl.SetAuth(c.Auth)
 }
 
-func newServerStateManager(c LocalSousConfig, log LogSink) *ServerStateManager {
+func newServerStateManager(c LocalSousConfig, wh *sous.Webhooks, log LogSink) *ServerStateManager {
 	var secondary sous.StateManager
 	db, err := c.Database.DB()
 	if err == nil {
@@ -551,7 +615,7 @@ func newServerStateManager(c LocalSousConfig, log LogSink) *ServerStateManager {
 	dm := storage.NewDiskStateManager(c.StateLocation)
 	gm := storage.NewGitStateManager(dm)
 	duplex := storage.NewDuplexStateManager(gm, secondary, log.LogSink)
-	return &ServerStateManager{StateManager: duplex}
+	return &ServerStateManager{StateManager: sous.NewWebhookStateManager(duplex, wh)}
 }
 
 // newStateManager returns a wrapped sous.HTTPStateManager if cl is not nil.
@@ -560,7 +624,7 @@ func newServerStateManager(c LocalSousConfig, log LogSink) *ServerStateManager {
 func newStateManager(cl HTTPClient, c LocalSousConfig, log LogSink) *StateManager {
 	if c.Server == "" {
 		log.Warnf("Using local state stored at %s", c.StateLocation)
-		return &StateManager{StateManager: newServerStateManager(c, log).StateManager}
+		return &StateManager{StateManager: newServerStateManager(c, nil, log).StateManager}
 	}
 	hsm := sous.NewHTTPStateManager(cl)
 	return &StateManager{StateManager: hsm}
@@ -573,7 +637,7 @@ func newStatusPoller(cl HTTPClient, rf *RefinedResolveFilter, user sous.User, lo
 		return nil
 	}
 	logs.Debugf("...looks good...")
-	return sous.NewStatusPoller(cl, (*sous.ResolveFilter)(rf), user, logs)
+	return sous.NewStatusPoller(cl.HTTPClient, (*sous.ResolveFilter)(rf), user, logs)
 }
 
 func newLocalStateReader(sm *StateManager) StateReader {
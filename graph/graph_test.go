@@ -104,6 +104,7 @@ func injectedStateManager(t *testing.T, cfg *config.Config) *StateManager {
 	g.Add(newSourceHostChooser)
 	g.Add(DryrunBoth)
 	g.Add(newDeployer)
+	g.Add(newRunner)
 	g.Add(newLazyNameCache)
 	g.Add(newNameCache)
 	g.Add(newRegistry)
@@ -113,6 +114,9 @@ func injectedStateManager(t *testing.T, cfg *config.Config) *StateManager {
 	g.Add(&config.DeployFilterFlags{})
 	g.Add(newResolver)
 	g.Add(newAutoResolver)
+	g.Add(newWebhooks)
+	g.Add(newR11nQueueStore)
+	g.Add(newLeadership)
 	g.Add(newServerHandler)
 	g.Add(newHTTPClient)
 	g.Add(g)
@@ -5,14 +5,19 @@ import (
 	"github.com/opentable/sous/server"
 )
 
-func newServerComponentLocator(ls LogSink, cfg LocalSousConfig, ins sous.Inserter, sm *ServerStateManager, rf *sous.ResolveFilter, ar *sous.AutoResolver) server.ComponentLocator {
+func newServerComponentLocator(ls LogSink, cfg LocalSousConfig, ins sous.Inserter, sm *ServerStateManager, rf *sous.ResolveFilter, ar *sous.AutoResolver, wh *sous.Webhooks, l *sous.Leadership, runner sous.Runner, d sous.Deployer) server.ComponentLocator {
+	si, _ := d.(sous.StateInvalidator)
 	return server.ComponentLocator{
-		LogSink:       ls.LogSink,
-		Config:        cfg.Config,
-		Inserter:      ins,
-		StateManager:  sm.StateManager,
-		ResolveFilter: rf,
-		AutoResolver:  ar,
+		LogSink:          ls.LogSink,
+		Config:           cfg.Config,
+		Inserter:         ins,
+		StateManager:     sm.StateManager,
+		ResolveFilter:    rf,
+		AutoResolver:     ar,
+		Webhooks:         wh,
+		Leadership:       l,
+		Runner:           runner,
+		StateInvalidator: si,
 	}
 
 }
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/opentable/sous/util/logging"
+	"github.com/pkg/errors"
 )
 
 type (
@@ -23,6 +24,7 @@ type (
 	AutoResolver struct {
 		UpdateTime time.Duration
 		StateReader
+		StateWriter
 		GDM Deployments
 		*Resolver
 		logging.LogSink
@@ -30,6 +32,11 @@ type (
 		sync.RWMutex
 		stableStatus, liveStatus *ResolveStatus
 		currentRecorder          *ResolveRecorder
+		// rolledBack holds rollbacks to known-good versions, until their
+		// deployments are next changed in the GDM.
+		rolledBack map[DeploymentID]Rollback
+		// rerun is set when the next resolve should not wait for UpdateTime.
+		rerun bool
 	}
 )
 
@@ -38,13 +45,24 @@ func (tc TriggerChannel) trigger() {
 }
 
 // NewAutoResolver creates a new AutoResolver.
-func NewAutoResolver(rez *Resolver, sr StateReader, ls logging.LogSink) *AutoResolver {
+// The Resolver is given a KnownGoodVersions if it doesn't have one, so that
+// failed deploys can be rolled back, and a Verifier if it doesn't have one and
+// its Deployer can list the tasks of deployments.
+func NewAutoResolver(rez *Resolver, sm StateManager, ls logging.LogSink) *AutoResolver {
+	if rez.KnownGood == nil {
+		rez.KnownGood = NewKnownGoodVersions()
+	}
+	if ta, ok := rez.Deployer.(TaskAddresser); ok && rez.Verifier == nil {
+		rez.Verifier = NewVerifier(ta)
+	}
 	ar := &AutoResolver{
 		UpdateTime:  60 * time.Second,
 		Resolver:    rez,
-		StateReader: sr,
+		StateReader: sm,
+		StateWriter: sm,
 		LogSink:     ls,
 		listeners:   make([]autoResolveListener, 0),
+		rolledBack:  map[DeploymentID]Rollback{},
 	}
 	ar.StandardListeners()
 	return ar
@@ -89,6 +107,7 @@ func (ar *AutoResolver) Kickoff() TriggerChannel {
 	go loopTilDone(func() {
 		ar.multicast(done, announce, fanout)
 	}, done)
+	ar.resumeInterrupted()
 	trigger.trigger()
 
 	return done
@@ -175,34 +194,219 @@ func (ar *AutoResolver) resolveOnce(ac announceChannel) {
 	}
 
 	ar.write(func() {
+		ar.Resolver.Freezes = state.Defs.Freezes
+		ar.Resolver.FreezeOverrides = state.FreezeOverrides()
+		ar.Resolver.Dependencies = state.Dependencies()
 		ar.currentRecorder = ar.Resolver.Begin(ar.GDM, state.Defs.Clusters)
 	})
 	defer ar.write(func() {
 		ar.currentRecorder = nil
 	})
-	ac <- ar.currentRecorder.Wait()
+	err = ar.currentRecorder.Wait()
+	rerun := ar.rollBack()
 	ar.write(func() {
 		ss := ar.currentRecorder.CurrentStatus()
+		ss.Log = append(ss.Log, ar.rollbackResolutions()...)
 
 		reportResolverStatus(ar.LogSink, &ss)
 
 		ar.stableStatus = &ss
+		ar.rerun = rerun
 	})
+	ac <- err
 	ar.Statuses() // XXX this is debugging
 }
 
+// rollBack writes the last known-good version of each failed deployment
+// whose manifest asks for it back to the GDM. It returns true if it changed
+// the GDM, so that the next resolve should happen straight away.
+//
+// The state is read afresh, rather than taken from the start of the cycle, so
+// that changes made during the cycle, like deploys, aren't written over.
+func (ar *AutoResolver) rollBack() bool {
+	if ar.KnownGood == nil || ar.StateWriter == nil {
+		return false
+	}
+	rbs := ar.KnownGood.Rollbacks()
+	if len(rbs) == 0 {
+		return false
+	}
+	state, err := ar.StateReader.ReadState()
+	if err != nil {
+		logging.ReportError(ar.LogSink, errors.Wrapf(err, "reading state to roll back %d failed deployments", len(rbs)))
+		return false
+	}
+	var applied []Rollback
+	for _, rb := range rbs {
+		if rb.Apply(state) {
+			applied = append(applied, rb)
+		}
+	}
+	if len(applied) == 0 {
+		return false
+	}
+	if err := ar.StateWriter.WriteState(state, SystemUser); err != nil {
+		logging.ReportError(ar.LogSink, errors.Wrapf(err, "rolling back %d failed deployments", len(applied)))
+		return false
+	}
+	gdm, err := state.Deployments()
+	ar.write(func() {
+		if err == nil {
+			ar.GDM = gdm
+		}
+		for _, rb := range applied {
+			ar.KnownGood.forget(rb.DeploymentID)
+			logging.ReportMsg(ar.LogSink, logging.WarningLevel, fmt.Sprintf("Deploy of %s version %s failed: rolled back to version %s", rb.DeploymentID, rb.Failed, rb.KnownGood))
+			ar.rolledBack[rb.DeploymentID] = rb
+		}
+	})
+	return true
+}
+
+// rollbackResolutions returns a DiffResolution for each rollback whose
+// deployment still has its known-good version in the GDM, and forgets the
+// rest. It must be called with ar locked.
+func (ar *AutoResolver) rollbackResolutions() []DiffResolution {
+	var rezs []DiffResolution
+	for did, rb := range ar.rolledBack {
+		if d, ok := ar.GDM.Get(did); !ok || !d.SourceID.Version.Equals(rb.KnownGood) {
+			delete(ar.rolledBack, did)
+			continue
+		}
+		rezs = append(rezs, DiffResolution{
+			DeploymentID:   did,
+			Desc:           RollbackDiff,
+			Error:          WrapResolveError(&RolledBackError{Rollback: rb, User: SystemUser}),
+			RolledBackFrom: rb.Failed.String(),
+		})
+	}
+	return rezs
+}
+
+// ResolveNow starts resolving the deployments matching rf straight away,
+// rather than in the next cycle. Only the deployments this server resolves
+// are considered, and rectifications already queued, e.g. by a cycle, are not
+// repeated. When it finishes, its resolutions replace those of the targeted
+// deployments in the stable status.
+func (ar *AutoResolver) ResolveNow(rf *ResolveFilter) (*ResolveRecorder, error) {
+	ar.RLock()
+	rez := *ar.Resolver
+	ar.RUnlock()
+
+	target, ok := rez.ResolveFilter.Narrowed(rf)
+	if !ok {
+		return nil, errors.Errorf("%s matches nothing resolved by this server (%s)", rf, rez.ResolveFilter)
+	}
+	state, err := ar.StateReader.ReadState()
+	if err != nil {
+		return nil, err
+	}
+	gdm, err := state.Deployments()
+	if err != nil {
+		return nil, err
+	}
+
+	rez.ResolveFilter = target
+	rez.Freezes = state.Defs.Freezes
+	rez.FreezeOverrides = state.FreezeOverrides()
+	rez.Dependencies = state.Dependencies()
+	if rez.DeletionBrake != nil {
+		// Deletions held here can't be acknowledged, but nor should a
+		// targeted resolve release those held by a cycle.
+		rez.DeletionBrake = NewDeletionBrake(rez.DeletionBrake.DeletionBrakeConfig)
+	}
+	recorder := rez.Begin(gdm, state.Defs.Clusters)
+	go func() {
+		if err := recorder.Wait(); err != nil {
+			logging.ReportError(ar.LogSink, errors.Wrapf(err, "resolving %s", target))
+		}
+		ar.mergeStatus(recorder.CurrentStatus(), target)
+	}()
+	return recorder, nil
+}
+
+// resumeInterrupted starts resolving straight away each deployment whose
+// rectification was interrupted, e.g. by the server restarting, rather than
+// leaving it for the cycle to reach. It is resolved afresh, since the GDM and
+// the cluster may have changed since.
+func (ar *AutoResolver) resumeInterrupted() {
+	if ar.Resolver.QueueSet == nil {
+		return
+	}
+	interrupted, err := ar.Resolver.QueueSet.TakeInterrupted(ar.Resolver.ResolveFilter)
+	if err != nil {
+		logging.ReportError(ar.LogSink, errors.Wrapf(err, "reading interrupted rectifications"))
+	}
+	resumed := map[DeploymentID]bool{}
+	for _, rec := range interrupted {
+		if resumed[rec.DeploymentID] {
+			continue
+		}
+		resumed[rec.DeploymentID] = true
+		logging.ReportMsg(ar.LogSink, logging.InformationLevel,
+			fmt.Sprintf("Resuming rectification of %s (%s), queued at %s", rec.DeploymentID, rec.Change, rec.Queued))
+		if _, err := ar.ResolveNow(DeploymentFilter(rec.DeploymentID)); err != nil {
+			logging.ReportError(ar.LogSink, errors.Wrapf(err, "resuming rectification %s", rec.ID))
+		}
+	}
+}
+
+// mergeStatus replaces the intended deployments and resolutions in the
+// stable status of those deployments matching target with those in rs.
+func (ar *AutoResolver) mergeStatus(rs ResolveStatus, target *ResolveFilter) {
+	matches := func(did DeploymentID) bool {
+		return target.FilterManifestID(did.ManifestID) && target.FilterClusterName(did.Cluster)
+	}
+	ar.write(func() {
+		if ar.stableStatus == nil {
+			return
+		}
+		ss := *ar.stableStatus
+		ss.Intended = []*Deployment{}
+		for _, d := range ar.stableStatus.Intended {
+			if !matches(d.ID()) {
+				ss.Intended = append(ss.Intended, d)
+			}
+		}
+		for _, d := range rs.Intended {
+			if matches(d.ID()) {
+				ss.Intended = append(ss.Intended, d)
+			}
+		}
+		ss.Log = []DiffResolution{}
+		for _, rez := range ar.stableStatus.Log {
+			if !matches(rez.DeploymentID) {
+				ss.Log = append(ss.Log, rez)
+			}
+		}
+		ss.Log = append(ss.Log, rs.Log...)
+		ar.stableStatus = &ss
+	})
+}
+
 func (ar *AutoResolver) afterDone(tc, done TriggerChannel, ac announceChannel) {
 	select {
 	case <-done:
 		return
 	case <-ac:
 	}
+	wait := ar.UpdateTime
+	ar.write(func() {
+		if ar.rerun {
+			wait = 0
+			ar.rerun = false
+		}
+	})
 	select {
 	case <-done:
 		return
-	case <-time.After(ar.UpdateTime):
+	case <-time.After(wait):
+	}
+	// The resolve loop may have been stopped, e.g. by losing leadership.
+	select {
+	case <-done:
+	case tc <- TriggerType{}:
 	}
-	tc.trigger()
 }
 
 func (ar *AutoResolver) errorLogging(tc, done TriggerChannel, errs announceChannel) {
@@ -99,3 +99,100 @@ func TestResolveLoop(t *testing.T) {
 		t.Error("Should have announced a result")
 	}
 }
+
+func TestAutoResolver_ResolveNow(t *testing.T) {
+	dd := NewDummyDeployer()
+	state := NewState()
+	state.Defs.Clusters = Clusters{"x": &Cluster{Name: "x"}}
+	for i := 0; i < 2; i++ {
+		d := brakeTestDeployment(i, "x", 1)
+		dd.deps.Add(&DeployState{Deployment: *d, Status: DeployStatusActive})
+		state.Manifests.Add(&Manifest{
+			Source: d.SourceID.Location,
+			Kind:   ManifestKindService,
+			Deployments: DeploySpecs{"x": {
+				DeployConfig: DeployConfig{NumInstances: 2},
+				Version:      d.SourceID.Version,
+			}},
+		})
+	}
+	rez := NewResolver(dd, NewDummyRegistry(), &ResolveFilter{Cluster: NewResolveFieldMatcher("x")}, logging.SilentLogSet())
+	ar := NewAutoResolver(rez, &DummyStateManager{State: state}, logging.SilentLogSet())
+
+	_, err := ar.ResolveNow(&ResolveFilter{Cluster: NewResolveFieldMatcher("y")})
+	assert.Error(t, err, "cluster y is not resolved by this server")
+
+	untouched := DiffResolution{DeploymentID: brakeTestDeployment(1, "x", 1).ID(), Desc: StableDiff}
+	ar.stableStatus = &ResolveStatus{Log: []DiffResolution{
+		{DeploymentID: brakeTestDeployment(0, "x", 1).ID(), Desc: StableDiff},
+		untouched,
+	}}
+
+	target := &ResolveFilter{Repo: NewResolveFieldMatcher("github.com/ot/app0")}
+	recorder, err := ar.ResolveNow(target)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, recorder.Wait())
+	status := recorder.CurrentStatus()
+	// The rectifications are made by the first Resolver's Deployer, so only
+	// their number can be relied on here.
+	assert.Len(t, status.Log, 1, "only the target is resolved")
+
+	// The targeted resolutions are merged into the stable status.
+	var stable *ResolveStatus
+	for i := 0; i < 1000; i++ {
+		if stable, _ = ar.Statuses(); stable.Log[0] == untouched {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, append([]DiffResolution{untouched}, status.Log...), stable.Log)
+}
+
+// queuedRecorder is an R11nQueueStore which reports each record queued.
+type queuedRecorder struct {
+	*MemoryR11nQueueStore
+	queued chan R11nRecord
+}
+
+func (qr queuedRecorder) Queued(rec R11nRecord) error {
+	qr.queued <- rec
+	return qr.MemoryR11nQueueStore.Queued(rec)
+}
+
+func TestAutoResolver_resumesInterrupted(t *testing.T) {
+	dd := NewDummyDeployer()
+	d := brakeTestDeployment(0, "x", 1)
+	dd.deps.Add(&DeployState{Deployment: *d, Status: DeployStatusActive})
+	state := NewState()
+	state.Defs.Clusters = Clusters{"x": &Cluster{Name: "x"}}
+	state.Manifests.Add(&Manifest{
+		Source: d.SourceID.Location,
+		Kind:   ManifestKindService,
+		Deployments: DeploySpecs{"x": {
+			DeployConfig: DeployConfig{NumInstances: 2},
+			Version:      d.SourceID.Version,
+		}},
+	})
+
+	// The record left by the server before it restarted.
+	store := queuedRecorder{NewMemoryR11nQueueStore(), make(chan R11nRecord, 10)}
+	store.MemoryR11nQueueStore.Queued(R11nRecord{ID: "earlier", DeploymentID: d.ID(), Change: ModifyDiff})
+
+	rez := NewResolver(dd, NewDummyRegistry(), &ResolveFilter{}, logging.SilentLogSet())
+	rez.UseQueueStore(store)
+	ar := NewAutoResolver(rez, &DummyStateManager{State: state}, logging.SilentLogSet())
+	ar.resumeInterrupted()
+
+	select {
+	case rec := <-store.queued:
+		assert.Equal(t, d.ID(), rec.DeploymentID)
+		assert.NotEqual(t, R11nID("earlier"), rec.ID)
+	case <-time.After(time.Second):
+		t.Fatal("the interrupted rectification was not resumed")
+	}
+	interrupted, err := rez.QueueSet.TakeInterrupted(rez.ResolveFilter)
+	assert.NoError(t, err)
+	assert.Empty(t, interrupted, "the interrupted record is taken once")
+}
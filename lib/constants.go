@@ -21,3 +21,17 @@ const VersionLabel = "com.opentable.sous.version"
 
 // RevisionLabel is a metadata fieldname that records the git revision ID of a Sous-controlled service.
 const RevisionLabel = "com.opentable.sous.revision"
+
+// SecretRefLabelPrefix prefixes the metadata fieldnames that record which env
+// vars of a Sous-controlled service were resolved from secrets, and the
+// references they were resolved from.
+const SecretRefLabelPrefix = "com.opentable.sous.secret."
+
+// SecretsVersionLabel is the metadata fieldname that records the version of
+// the secrets a Sous-controlled service was deployed with.
+const SecretsVersionLabel = "com.opentable.sous.secrets_version"
+
+// DeploymentIDLabel is the metadata fieldname that records the ID of the
+// deployment a deploy was made for, so that the request of a deployment can
+// be found even when Sous didn't name it.
+const DeploymentIDLabel = "com.opentable.sous.deployment_id"
@@ -0,0 +1,285 @@
+package sous
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opentable/sous/util/logging"
+	"github.com/pkg/errors"
+)
+
+type (
+	// DeletionBrakeConfig configures a DeletionBrake. With neither limit set,
+	// the brake never trips.
+	DeletionBrakeConfig struct {
+		// MaxDeletions is the most deployments a resolve cycle may delete or
+		// scale to zero, across all clusters.
+		MaxDeletions int `env:"SOUS_DELETION_BRAKE_MAX"`
+		// MaxDeletionPercent is the largest percentage of the deployments
+		// running in a cluster which a resolve cycle may delete or scale to
+		// zero.
+		MaxDeletionPercent int `env:"SOUS_DELETION_BRAKE_MAX_PERCENT"`
+	}
+
+	// A DeletionBrake halts resolve cycles which would delete, or scale to
+	// zero, more deployments than its config allows, as happens when the GDM
+	// is truncated by mistake. The halted deletions are held until an operator
+	// acknowledges them.
+	DeletionBrake struct {
+		DeletionBrakeConfig
+		mu    sync.Mutex
+		held  *HeldDeletions
+		acked map[DeploymentID]bool
+	}
+
+	// HeldDeletions are the deletions which tripped a DeletionBrake.
+	HeldDeletions struct {
+		// ID identifies this set of deletions. It must be given to
+		// acknowledge them.
+		ID     string
+		Reason string
+		// Since is when the brake first tripped on this set of deletions.
+		Since     time.Time
+		Deletions []PlannedChange
+	}
+
+	// DeletionBrakeStatus reports the config of a DeletionBrake, and the
+	// deletions it holds, if any.
+	DeletionBrakeStatus struct {
+		DeletionBrakeConfig
+		Held *HeldDeletions `json:",omitempty"`
+	}
+
+	// A DeletionBrakeError reports that a resolve cycle was halted by the
+	// deletion brake.
+	DeletionBrakeError struct {
+		Reason string
+		// HeldID is the ID of the held deletions.
+		HeldID string
+	}
+
+	deletionBrakeMessage struct {
+		logging.CallerInfo
+		held *HeldDeletions
+	}
+)
+
+// NewDeletionBrake returns a DeletionBrake configured by c.
+func NewDeletionBrake(c DeletionBrakeConfig) *DeletionBrake {
+	return &DeletionBrake{DeletionBrakeConfig: c}
+}
+
+func (e *DeletionBrakeError) Error() string {
+	return fmt.Sprintf("deletion brake: %s; acknowledge deletions %s to continue", e.Reason, e.HeldID)
+}
+
+// Enabled returns true if the brake can trip.
+func (db *DeletionBrake) Enabled() bool {
+	return db != nil && (db.MaxDeletions > 0 || db.MaxDeletionPercent > 0)
+}
+
+// Held returns the deletions currently held by the brake, or nil.
+func (db *DeletionBrake) Held() *HeldDeletions {
+	if db == nil {
+		return nil
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.held == nil {
+		return nil
+	}
+	held := *db.held
+	held.Deletions = append([]PlannedChange(nil), db.held.Deletions...)
+	return &held
+}
+
+// Status returns the status of the brake.
+func (db *DeletionBrake) Status() DeletionBrakeStatus {
+	if db == nil {
+		return DeletionBrakeStatus{}
+	}
+	return DeletionBrakeStatus{DeletionBrakeConfig: db.DeletionBrakeConfig, Held: db.Held()}
+}
+
+// Acknowledge releases the held deletions with the given ID, so that the
+// next resolve cycle makes them. Further deletions beyond the limits trip the
+// brake again.
+func (db *DeletionBrake) Acknowledge(id string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.held == nil {
+		return errors.New("no deletions are held")
+	}
+	if db.held.ID != id {
+		return errors.Errorf("the held deletions are %s, not %s", db.held.ID, id)
+	}
+	db.acked = map[DeploymentID]bool{}
+	for _, d := range db.held.Deletions {
+		db.acked[d.DeploymentID] = true
+	}
+	db.held = nil
+	return nil
+}
+
+// Check returns the deletions in pairs which trip the brake, or nil if it
+// lets them through. Acknowledged deletions don't count, and the
+// acknowledgement is spent once a cycle gets through.
+func (db *DeletionBrake) Check(pairs DeployablePairs, now time.Time) *HeldDeletions {
+	if !db.Enabled() {
+		return nil
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	running := map[string]int{}
+	deleting := map[string]int{}
+	var deletions []PlannedChange
+	for _, dp := range pairs {
+		did := dp.ID()
+		if dp.Prior != nil {
+			running[did.Cluster]++
+		}
+		if !isDeletion(dp) {
+			continue
+		}
+		change := PlannedChange{DeploymentID: did, Action: PlanDelete, Prior: dp.Prior.SourceID.Version.String()}
+		if dp.Post != nil {
+			change.Action = PlanModify
+			change.Post = dp.Post.SourceID.Version.String()
+			change.Diffs = Differences{"scaled to zero instances"}
+		}
+		deletions = append(deletions, change)
+		if !db.acked[did] {
+			deleting[did.Cluster]++
+		}
+	}
+
+	reason := db.exceeded(running, deleting)
+	if reason == "" {
+		db.held = nil
+		db.acked = nil
+		return nil
+	}
+
+	sort.Slice(deletions, func(i, j int) bool {
+		return deletions[i].DeploymentID.String() < deletions[j].DeploymentID.String()
+	})
+	id := heldDeletionsID(deletions)
+	if db.held == nil || db.held.ID != id {
+		db.held = &HeldDeletions{ID: id, Since: now}
+	}
+	db.held.Reason = reason
+	db.held.Deletions = deletions
+	held := *db.held
+	return &held
+}
+
+// exceeded returns the reason the counts of deletions exceed the brake's
+// limits, or "" if they don't.
+func (db *DeletionBrake) exceeded(running, deleting map[string]int) string {
+	total := 0
+	clusters := make([]string, 0, len(deleting))
+	for c, n := range deleting {
+		total += n
+		clusters = append(clusters, c)
+	}
+	if db.MaxDeletions > 0 && total > db.MaxDeletions {
+		return fmt.Sprintf("%d deployments would be deleted or scaled to zero (the limit is %d)", total, db.MaxDeletions)
+	}
+	sort.Strings(clusters)
+	for _, c := range clusters {
+		n := deleting[c]
+		if db.MaxDeletionPercent > 0 && running[c] > 0 && n*100 > db.MaxDeletionPercent*running[c] {
+			return fmt.Sprintf("%d of %d deployments in %s would be deleted or scaled to zero (the limit is %d%%)",
+				n, running[c], c, db.MaxDeletionPercent)
+		}
+	}
+	return ""
+}
+
+// isDeletion returns true if dp deletes a running deployment, or scales it
+// to zero instances.
+func isDeletion(dp *DeployablePair) bool {
+	switch dp.Kind() {
+	default:
+		return false
+	case RemovedKind:
+		return true
+	case ModifiedKind:
+		return dp.Prior.NumInstances > 0 && dp.Post.NumInstances == 0
+	}
+}
+
+func heldDeletionsID(deletions []PlannedChange) string {
+	ids := make([]string, len(deletions))
+	for i, d := range deletions {
+		ids[i] = d.DeploymentID.String()
+	}
+	return fmt.Sprintf("%x", sha1.Sum([]byte(strings.Join(ids, "\n"))))[:12]
+}
+
+// brakeDeletions is the resolve phase which checks the deletion brake. If it
+// trips, each held deletion is reported to log, and a *DeletionBrakeError
+// returned, so that nothing is rectified this cycle.
+func (r *Resolver) brakeDeletions(diffs *DeployableChans, log chan<- DiffResolution) (*DeployableChans, error) {
+	if !r.DeletionBrake.Enabled() {
+		return diffs, nil
+	}
+	pairs := diffs.Collect()
+	held := r.DeletionBrake.Check(pairs, time.Now())
+	if held == nil {
+		return pairs.chans(), nil
+	}
+	reportDeletionBrake(r.ls, held)
+	err := &DeletionBrakeError{Reason: held.Reason, HeldID: held.ID}
+	for _, d := range held.Deletions {
+		log <- DiffResolution{
+			DeploymentID: d.DeploymentID,
+			Desc:         HeldDiff,
+			Error:        WrapResolveError(err),
+		}
+	}
+	return nil, err
+}
+
+// chans returns a closed DeployableChans which yields dps.
+func (dps DeployablePairs) chans() *DeployableChans {
+	dc := NewDeployableChans(len(dps))
+	for _, dp := range dps {
+		dc.Pairs <- dp
+	}
+	dc.Close()
+	return dc
+}
+
+func reportDeletionBrake(ls logging.LogSink, held *HeldDeletions) {
+	logging.Deliver(deletionBrakeMessage{
+		CallerInfo: logging.GetCallerInfo(logging.NotHere()),
+		held:       held,
+	}, ls)
+}
+
+func (msg deletionBrakeMessage) DefaultLevel() logging.Level {
+	return logging.CriticalLevel
+}
+
+func (msg deletionBrakeMessage) Message() string {
+	return "deletion brake tripped: " + msg.held.Reason
+}
+
+func (msg deletionBrakeMessage) MetricsTo(m logging.MetricsSink) {
+	m.IncCounter("deletion-brake-trips", 1)
+	m.UpdateSample("deletion-brake-held", int64(len(msg.held.Deletions)))
+}
+
+func (msg deletionBrakeMessage) EachField(f logging.FieldReportFn) {
+	f("@loglov3-otl", "sous-generic-v1")
+	f("sous-deletion-brake-id", msg.held.ID)
+	f("sous-deletion-brake-held", len(msg.held.Deletions))
+	f("sous-deletion-brake-since", msg.held.Since.Format(time.RFC3339))
+	msg.CallerInfo.EachField(f)
+}
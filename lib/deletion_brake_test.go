@@ -0,0 +1,101 @@
+package sous
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/opentable/sous/util/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func brakeTestDeployment(n int, cluster string, instances int) *Deployment {
+	return &Deployment{
+		SourceID:     MustParseSourceID(fmt.Sprintf("github.com/ot/app%d,1.0.0", n)),
+		ClusterName:  cluster,
+		Cluster:      &Cluster{Name: cluster},
+		DeployConfig: DeployConfig{NumInstances: instances},
+	}
+}
+
+func brakeTestPairs(running, deleted, scaledToZero int) DeployablePairs {
+	var pairs DeployablePairs
+	for i := 0; i < running; i++ {
+		d := brakeTestDeployment(i, "x", 1)
+		dp := &DeployablePair{name: d.ID(), Prior: &Deployable{Deployment: d, Status: DeployStatusActive}}
+		switch {
+		case i < deleted:
+		case i < deleted+scaledToZero:
+			dp.Post = &Deployable{Deployment: brakeTestDeployment(i, "x", 0), Status: DeployStatusActive}
+		default:
+			dp.Post = dp.Prior
+		}
+		pairs = append(pairs, dp)
+	}
+	return pairs
+}
+
+func TestDeletionBrakeLimits(t *testing.T) {
+	now := time.Now()
+	check := func(c DeletionBrakeConfig, pairs DeployablePairs) *HeldDeletions {
+		return NewDeletionBrake(c).Check(pairs, now)
+	}
+
+	assert.Nil(t, check(DeletionBrakeConfig{}, brakeTestPairs(10, 10, 0)), "no limits")
+	assert.Nil(t, check(DeletionBrakeConfig{MaxDeletions: 3}, brakeTestPairs(10, 2, 1)))
+	held := check(DeletionBrakeConfig{MaxDeletions: 3}, brakeTestPairs(10, 2, 2))
+	if assert.NotNil(t, held) {
+		assert.Len(t, held.Deletions, 4)
+		assert.Equal(t, PlanDelete, held.Deletions[0].Action)
+		assert.Equal(t, PlanModify, held.Deletions[2].Action)
+		assert.Contains(t, held.Reason, "the limit is 3")
+	}
+	assert.Nil(t, check(DeletionBrakeConfig{MaxDeletionPercent: 20}, brakeTestPairs(10, 2, 0)))
+	held = check(DeletionBrakeConfig{MaxDeletionPercent: 20}, brakeTestPairs(10, 3, 0))
+	if assert.NotNil(t, held) {
+		assert.Contains(t, held.Reason, "3 of 10 deployments in x")
+	}
+}
+
+func TestDeletionBrakeAcknowledge(t *testing.T) {
+	db := NewDeletionBrake(DeletionBrakeConfig{MaxDeletions: 1})
+	assert.Error(t, db.Acknowledge("anything"), "nothing is held")
+
+	held := db.Check(brakeTestPairs(5, 3, 0), time.Now())
+	require.NotNil(t, held)
+	again := db.Check(brakeTestPairs(5, 3, 0), time.Now().Add(time.Minute))
+	require.NotNil(t, again)
+	assert.Equal(t, held.ID, again.ID, "the same deletions are held under the same ID")
+	assert.Equal(t, held.Since, again.Since)
+	assert.Equal(t, held.ID, db.Status().Held.ID)
+
+	assert.Error(t, db.Acknowledge("not-"+held.ID))
+	require.NoError(t, db.Acknowledge(held.ID))
+	assert.Nil(t, db.Held())
+
+	assert.Nil(t, db.Check(brakeTestPairs(5, 3, 0), time.Now()), "acknowledged deletions pass")
+	assert.NotNil(t, db.Check(brakeTestPairs(5, 3, 0), time.Now()), "acknowledgements are spent")
+}
+
+func TestResolverHaltsOnDeletionBrake(t *testing.T) {
+	dd := NewDummyDeployer()
+	for i := 0; i < 4; i++ {
+		dd.deps.Add(&DeployState{Deployment: *brakeTestDeployment(i, "x", 1), Status: DeployStatusActive})
+	}
+	clusters := Clusters{"x": &Cluster{Name: "x"}}
+	r := NewResolver(dd, NewDummyRegistry(), &ResolveFilter{}, logging.SilentLogSet())
+	r.DeletionBrake = NewDeletionBrake(DeletionBrakeConfig{MaxDeletionPercent: 50})
+
+	recorder := r.Begin(NewDeployments(brakeTestDeployment(0, "x", 1)), clusters)
+	err := recorder.Wait()
+	if assert.IsType(t, &DeletionBrakeError{}, err) {
+		assert.Contains(t, err.Error(), "3 of 4 deployments in x")
+	}
+	status := recorder.CurrentStatus()
+	assert.Len(t, status.Log, 3)
+	for _, rez := range status.Log {
+		assert.Equal(t, HeldDiff, rez.Desc)
+	}
+	assert.Len(t, r.DeletionBrake.Held().Deletions, 3)
+}
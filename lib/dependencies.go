@@ -0,0 +1,160 @@
+package sous
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/samsalisbury/semv"
+)
+
+type (
+	// Dependencies maps each manifest to the manifests it depends on: those
+	// whose deployments must be active before its own are changed, in the
+	// same cluster.
+	Dependencies map[ManifestID][]ManifestID
+
+	// A DependencyError reports that a change to a deployment was held
+	// because a deployment it depends on is not yet active at its intended
+	// version.
+	DependencyError struct {
+		DeploymentID DeploymentID
+		// Dependency is the deployment waited for.
+		Dependency DeploymentID
+		// Version is the version Dependency must be active at.
+		Version semv.Version
+		// Running is true if Dependency is running, in which case Status is
+		// its current status.
+		Running bool
+		Status  DeployStatus
+	}
+)
+
+// Dependencies returns the dependencies declared by the manifests in s.
+func (s *State) Dependencies() Dependencies {
+	deps := Dependencies{}
+	for mid, m := range s.Manifests.Snapshot() {
+		if len(m.DependsOn) > 0 {
+			deps[mid] = append([]ManifestID(nil), m.DependsOn...)
+		}
+	}
+	return deps
+}
+
+// Validate returns a flaw for each dependency on a manifest not in ms, and
+// for each cycle of dependencies, which could never be resolved.
+func (deps Dependencies) Validate(ms Manifests) []Flaw {
+	var flaws []Flaw
+	for _, mid := range deps.sortedIDs() {
+		for _, dep := range deps[mid] {
+			if _, ok := ms.Get(dep); !ok {
+				flaws = append(flaws, FatalFlaw("manifest %q depends on unknown manifest %q", mid, dep))
+			}
+		}
+	}
+	for _, cycle := range deps.cycles() {
+		names := make([]string, len(cycle))
+		for i, mid := range cycle {
+			names[i] = mid.String()
+		}
+		flaws = append(flaws, FatalFlaw("dependency cycle: %s", strings.Join(names, " -> ")))
+	}
+	return flaws
+}
+
+func (deps Dependencies) sortedIDs() []ManifestID {
+	mids := make([]ManifestID, 0, len(deps))
+	for mid := range deps {
+		mids = append(mids, mid)
+	}
+	sort.Slice(mids, func(i, j int) bool { return mids[i].String() < mids[j].String() })
+	return mids
+}
+
+// cycles returns each cycle of dependencies found, as the path around it
+// ending where it started.
+func (deps Dependencies) cycles() [][]ManifestID {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := map[ManifestID]int{}
+	var path []ManifestID
+	var cycles [][]ManifestID
+	var visit func(mid ManifestID)
+	visit = func(mid ManifestID) {
+		state[mid] = visiting
+		path = append(path, mid)
+		for _, dep := range deps[mid] {
+			switch state[dep] {
+			case unvisited:
+				visit(dep)
+			case visiting:
+				for i := range path {
+					if path[i] == dep {
+						cycle := append(append([]ManifestID(nil), path[i:]...), dep)
+						cycles = append(cycles, cycle)
+						break
+					}
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[mid] = visited
+	}
+	for _, mid := range deps.sortedIDs() {
+		if state[mid] == unvisited {
+			visit(mid)
+		}
+	}
+	return cycles
+}
+
+func (e *DependencyError) Error() string {
+	status := "not running"
+	if e.Running {
+		status = e.Status.String()
+	}
+	return fmt.Sprintf("waiting for %s to be active at version %s (it is %s) before changing %s",
+		e.Dependency, e.Version, status, e.DeploymentID)
+}
+
+// dependencyHolder is a DeployableProcessor which holds changes to
+// deployments until the deployments they depend on in the same cluster are
+// active at their intended versions. Dependencies not intended for the
+// cluster are not waited for.
+type dependencyHolder struct {
+	deps     Dependencies
+	intended Deployments
+	actual   DeployStates
+}
+
+func (dh dependencyHolder) HandlePairs(dp *DeployablePair) (*DeployablePair, *DiffResolution) {
+	kind := dp.Kind()
+	if kind == SameKind || kind == RemovedKind {
+		return dp, nil
+	}
+	did := dp.ID()
+	for _, mid := range dh.deps[did.ManifestID] {
+		depID := DeploymentID{ManifestID: mid, Cluster: did.Cluster}
+		want, ok := dh.intended.Get(depID)
+		if !ok {
+			continue
+		}
+		got, ok := dh.actual.Get(depID)
+		if ok && got.Status == DeployStatusActive && got.SourceID.Version.Equals(want.SourceID.Version) {
+			continue
+		}
+		err := &DependencyError{DeploymentID: did, Dependency: depID, Version: want.SourceID.Version}
+		if ok {
+			err.Running, err.Status = true, got.Status
+		}
+		return nil, &DiffResolution{
+			DeploymentID: did,
+			Desc:         BlockedDiff,
+			Error:        WrapResolveError(err),
+		}
+	}
+	return dp, nil
+}
@@ -0,0 +1,101 @@
+package sous
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/samsalisbury/semv"
+)
+
+func TestDependencies_Validate(t *testing.T) {
+	a, b, c := MustParseManifestID("github.com/example/a"), MustParseManifestID("github.com/example/b"), MustParseManifestID("github.com/example/c")
+	ms := NewManifests(&Manifest{Source: a.Source}, &Manifest{Source: b.Source}, &Manifest{Source: c.Source})
+
+	if flaws := (Dependencies{a: {b}, b: {c}}).Validate(ms); len(flaws) != 0 {
+		t.Errorf("got flaws for acyclic dependencies: %v", flaws)
+	}
+
+	flaws := Dependencies{a: {b}, b: {c}, c: {a}}.Validate(ms)
+	if len(flaws) != 1 || !strings.Contains(fmt.Sprint(flaws[0]), "dependency cycle") {
+		t.Fatalf("got flaws %v; want one dependency cycle", flaws)
+	}
+	if !strings.Contains(fmt.Sprint(flaws[0]), "github.com/example/a -> github.com/example/b -> github.com/example/c -> github.com/example/a") {
+		t.Errorf("cycle not described: %s", flaws[0])
+	}
+
+	if flaws := (Dependencies{a: {a}}).Validate(ms); len(flaws) != 1 {
+		t.Errorf("got flaws %v; want one for a self-dependency", flaws)
+	}
+
+	unknown := MustParseManifestID("github.com/example/unknown")
+	flaws = Dependencies{a: {unknown}}.Validate(ms)
+	if len(flaws) != 1 || !strings.Contains(fmt.Sprint(flaws[0]), "unknown manifest") {
+		t.Errorf("got flaws %v; want one for an unknown dependency", flaws)
+	}
+}
+
+func TestState_Validate_dependencyCycle(t *testing.T) {
+	a, b := MustParseManifestID("github.com/example/a"), MustParseManifestID("github.com/example/b")
+	s := NewState()
+	s.Manifests.Add(&Manifest{Source: a.Source, Kind: ManifestKindService, DependsOn: []ManifestID{b}})
+	s.Manifests.Add(&Manifest{Source: b.Source, Kind: ManifestKindService, DependsOn: []ManifestID{a}})
+	found := false
+	for _, f := range s.Validate() {
+		found = found || strings.Contains(fmt.Sprint(f), "dependency cycle")
+	}
+	if !found {
+		t.Errorf("State.Validate did not report the dependency cycle")
+	}
+}
+
+func TestDependencyHolder(t *testing.T) {
+	dependent := rollbackTestPair("1.0.0", "2.0.0", DeployStatusActive)
+	migrator := MustParseManifestID("github.com/example/migrator")
+	deployment := func(version string) *Deployment {
+		return &Deployment{ClusterName: "cluster-1", SourceID: migrator.Source.SourceID(semv.MustParse(version))}
+	}
+	deps := Dependencies{dependent.ID().ManifestID: {migrator}}
+
+	holder := func(intended string, actual string, status DeployStatus) dependencyHolder {
+		dh := dependencyHolder{deps: deps, intended: NewDeployments(), actual: NewDeployStates()}
+		if intended != "" {
+			dh.intended.Add(deployment(intended))
+		}
+		if actual != "" {
+			dh.actual.Add(&DeployState{Deployment: *deployment(actual), Status: status})
+		}
+		return dh
+	}
+	expectHeld := func(dh dependencyHolder, want string) {
+		t.Helper()
+		dp, rez := dh.HandlePairs(dependent)
+		if dp != nil || rez == nil || rez.Desc != BlockedDiff {
+			t.Fatalf("change not held: %v, %v", dp, rez)
+		}
+		if !strings.Contains(rez.Error.Error(), want) {
+			t.Errorf("got reason %q; want one containing %q", rez.Error.Error(), want)
+		}
+	}
+	expectPassed := func(dh dependencyHolder) {
+		t.Helper()
+		if dp, rez := dh.HandlePairs(dependent); dp == nil || rez != nil {
+			t.Errorf("change held: %v", rez)
+		}
+	}
+
+	expectHeld(holder("2.0.0", "", 0), "not running")
+	expectHeld(holder("2.0.0", "1.0.0", DeployStatusActive), "active at version 2.0.0")
+	expectHeld(holder("2.0.0", "2.0.0", DeployStatusPending), "it is DeployStatusPending")
+	expectPassed(holder("2.0.0", "2.0.0", DeployStatusActive))
+	expectPassed(holder("", "1.0.0", DeployStatusActive))
+
+	same := rollbackTestPair("1.0.0", "1.0.0", DeployStatusActive)
+	same.Post.Status = DeployStatusActive
+	if dp, rez := holder("2.0.0", "", 0).HandlePairs(same); dp == nil || rez != nil {
+		t.Errorf("unchanged deployment held: %v", rez)
+	}
+	if !IsTransientResolveError(&DependencyError{}) {
+		t.Errorf("DependencyError is not transient")
+	}
+}
@@ -2,6 +2,7 @@ package sous
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/opentable/sous/util/logging"
@@ -37,6 +38,26 @@ type (
 		Startup Startup `yaml:",omitempty"`
 		// Schedule is a cronjob-format schedule for jobs.
 		Schedule string
+		// ScheduleTimeZone is the time zone Schedule is in, e.g.
+		// "America/Los_Angeles". If empty, the scheduler's own is used.
+		ScheduleTimeZone string `yaml:",omitempty"`
+		// TaskTimeLimitSeconds is how long a task of a scheduled job may run
+		// before it is killed. Zero means no limit.
+		TaskTimeLimitSeconds int `yaml:",omitempty"`
+		// KillGraceSeconds is how long the tasks of a job's previous deploy
+		// are left to finish once a new deploy is active, before they are
+		// killed. Zero leaves it to the scheduler.
+		KillGraceSeconds int `yaml:",omitempty"`
+		// Placement constrains which hosts tasks run on.
+		Placement Placement `yaml:",omitempty"`
+		// Strategy describes how new versions are rolled out.
+		Strategy Strategy `yaml:",omitempty"`
+		// SecretsVersion is incremented when the secrets in Env are rotated,
+		// so that the deployment is redeployed with their new values.
+		SecretsVersion int `yaml:",omitempty"`
+		// Verify lists probes the Sous server makes of each task once a new
+		// version is active, to verify that it works.
+		Verify Probes `yaml:",omitempty"`
 	}
 
 	// A DeployConfigs is a map from cluster name to DeployConfig
@@ -78,6 +99,28 @@ func (dc *DeployConfig) Validate() []Flaw {
 
 	flaws = append(flaws, dc.Startup.Validate()...)
 
+	flaws = append(flaws, dc.Strategy.Validate()...)
+
+	flaws = append(flaws, dc.Verify.Validate()...)
+
+	flaws = append(flaws, dc.Placement.Validate()...)
+
+	if dc.TaskTimeLimitSeconds < 0 {
+		flaws = append(flaws, FatalFlaw("TaskTimeLimitSeconds less than zero: %d", dc.TaskTimeLimitSeconds))
+	}
+	if dc.KillGraceSeconds < 0 {
+		flaws = append(flaws, FatalFlaw("KillGraceSeconds less than zero: %d", dc.KillGraceSeconds))
+	}
+	if dc.ScheduleTimeZone != "" {
+		if _, err := time.LoadLocation(dc.ScheduleTimeZone); err != nil {
+			flaws = append(flaws, FatalFlaw("ScheduleTimeZone %q is not a known time zone", dc.ScheduleTimeZone))
+		}
+	}
+
+	if _, err := dc.Env.SecretRefs(); err != nil {
+		flaws = append(flaws, FatalFlaw("%v", err))
+	}
+
 	for _, f := range flaws {
 		f.AddContext("deploy config", dc)
 	}
@@ -149,6 +192,19 @@ func (dc *DeployConfig) Diff(o DeployConfig) (bool, []string) {
 			diffs = append(diffs, fmt.Sprintf("volumes; this: %v; other: %v", dc.Volumes, o.Volumes))
 		}
 	}
+	if dc.SecretsVersion != o.SecretsVersion {
+		diffs = append(diffs, fmt.Sprintf("secrets version; this: %d; other: %d", dc.SecretsVersion, o.SecretsVersion))
+	}
+	if dc.ScheduleTimeZone != o.ScheduleTimeZone {
+		diffs = append(diffs, fmt.Sprintf("schedule time zone; this: %q; other: %q", dc.ScheduleTimeZone, o.ScheduleTimeZone))
+	}
+	if dc.TaskTimeLimitSeconds != o.TaskTimeLimitSeconds {
+		diffs = append(diffs, fmt.Sprintf("task time limit; this: %ds; other: %ds", dc.TaskTimeLimitSeconds, o.TaskTimeLimitSeconds))
+	}
+	if dc.KillGraceSeconds != o.KillGraceSeconds {
+		diffs = append(diffs, fmt.Sprintf("kill grace period; this: %ds; other: %ds", dc.KillGraceSeconds, o.KillGraceSeconds))
+	}
+	diffs = append(diffs, dc.Placement.diff(o.Placement)...)
 	diffs = append(diffs, dc.Startup.diff(o.Startup)...)
 	// TODO: Compare Args
 	return len(diffs) == 0, diffs
@@ -174,6 +230,13 @@ func (dc DeployConfig) Clone() (c DeployConfig) {
 	c.Volumes = dc.Volumes.Clone()
 	c.Startup = dc.Startup
 	c.Schedule = dc.Schedule
+	c.ScheduleTimeZone = dc.ScheduleTimeZone
+	c.TaskTimeLimitSeconds = dc.TaskTimeLimitSeconds
+	c.KillGraceSeconds = dc.KillGraceSeconds
+	c.Placement = dc.Placement.Clone()
+	c.Strategy = dc.Strategy.Clone()
+	c.SecretsVersion = dc.SecretsVersion
+	c.Verify = dc.Verify.Clone()
 
 	return
 }
@@ -250,6 +313,48 @@ func flattenDeployConfigs(dcs []DeployConfig) DeployConfig {
 			break
 		}
 	}
+	for _, c := range dcs {
+		if c.ScheduleTimeZone != "" {
+			dc.ScheduleTimeZone = c.ScheduleTimeZone
+			break
+		}
+	}
+	for _, c := range dcs {
+		if c.TaskTimeLimitSeconds != 0 {
+			dc.TaskTimeLimitSeconds = c.TaskTimeLimitSeconds
+			break
+		}
+	}
+	for _, c := range dcs {
+		if c.KillGraceSeconds != 0 {
+			dc.KillGraceSeconds = c.KillGraceSeconds
+			break
+		}
+	}
+	for _, c := range dcs {
+		if !c.Placement.IsZero() {
+			dc.Placement = c.Placement.Clone()
+			break
+		}
+	}
+	for _, c := range dcs {
+		if c.SecretsVersion != 0 {
+			dc.SecretsVersion = c.SecretsVersion
+			break
+		}
+	}
+	for _, c := range dcs {
+		if !c.Strategy.IsZero() {
+			dc.Strategy = c.Strategy.Clone()
+			break
+		}
+	}
+	for _, c := range dcs {
+		if len(c.Verify) != 0 {
+			dc.Verify = c.Verify.Clone()
+			break
+		}
+	}
 	for _, c := range dcs {
 		for n, v := range c.Resources {
 			if _, set := dc.Resources[n]; !set {
@@ -29,3 +29,33 @@ func TestValidateRepair(t *testing.T) {
 	assert.Len(t, es, 0)
 	assert.Len(t, dc.Volumes, 1)
 }
+
+func TestValidateSchedulingOptions(t *testing.T) {
+	dc := DeployConfig{
+		Resources:            Resources{"cpus": "0.1", "memory": "32", "ports": "1"},
+		ScheduleTimeZone:     "America/Los_Angeles",
+		TaskTimeLimitSeconds: 60,
+		KillGraceSeconds:     30,
+		Startup:              Startup{SkipCheck: true},
+	}
+	assert.Empty(t, dc.Validate())
+
+	dc.ScheduleTimeZone = "Middle/Earth"
+	dc.TaskTimeLimitSeconds = -1
+	dc.KillGraceSeconds = -1
+	assert.Len(t, dc.Validate(), 3)
+}
+
+func TestDiffSchedulingOptions(t *testing.T) {
+	dc := DeployConfig{
+		ScheduleTimeZone:     "UTC",
+		TaskTimeLimitSeconds: 60,
+		KillGraceSeconds:     30,
+		Placement:            Placement{RackSensitive: true},
+	}
+	same, diffs := dc.Diff(dc.Clone())
+	assert.True(t, same, "%v", diffs)
+
+	_, diffs = dc.Diff(DeployConfig{})
+	assert.Len(t, diffs, 4)
+}
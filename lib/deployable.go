@@ -6,4 +6,6 @@ type Deployable struct {
 	Status DeployStatus
 	*Deployment
 	*BuildArtifact
+	// Rollout is the progress of a staged rollout of this Deployable, if any.
+	Rollout *RolloutProgress
 }
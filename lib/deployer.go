@@ -8,6 +8,14 @@ type (
 		Rectify(*DeployablePair) DiffResolution
 	}
 
+	// A StateInvalidator is a Deployer which keeps the states it reads
+	// between calls to RunningDeployments. InvalidateRequest tells it that
+	// the scheduler request with requestID has changed, so that its state is
+	// read afresh next time.
+	StateInvalidator interface {
+		InvalidateRequest(requestID string)
+	}
+
 	// DummyDeployer is a noop deployer.
 	DummyDeployer struct {
 		deps DeployStates
@@ -126,6 +126,19 @@ func (d *Deployment) Validate() []Flaw {
 	return flaws
 }
 
+// ValidateForCluster returns flaws for what d asks of its cluster that
+// clusters of that kind can't do.
+func (d *Deployment) ValidateForCluster() []Flaw {
+	if d.Cluster == nil {
+		return nil
+	}
+	flaws := d.Startup.validateForKind(d.Cluster.Kind)
+	for _, f := range flaws {
+		f.AddContext("deployment", d)
+	}
+	return flaws
+}
+
 // ManifestID returns the ID of the Manifest describing this deployment.
 func (d *Deployment) ManifestID() ManifestID {
 	return ManifestID{
@@ -96,6 +96,7 @@ func makeDeployablePair(exists bool, id DeploymentID, existingDS, intendedDS *De
 		post = &Deployable{
 			Deployment: &intendedDS.Deployment,
 			Status:     intendedDS.Status,
+			Rollout:    intendedDS.Rollout,
 		}
 		executorData = intendedDS.ExecutorData
 	}
@@ -130,6 +131,7 @@ func (d *stateDiffer) diff(existing DeployStates) {
 			Prior: &Deployable{
 				Deployment: &deletedDS.Deployment,
 				Status:     deletedDS.Status,
+				Rollout:    deletedDS.Rollout,
 			},
 			Post: nil,
 		}
@@ -32,10 +32,22 @@ func TestDeploymentDiffAnalysis(t *testing.T) {
 		"Deployment.Cluster.Startup.CheckReadyInterval",
 		"Deployment.Cluster.Startup.ConnectDelay",
 		"Deployment.Cluster.Startup.CheckReadyPortIndex",
+		"Deployment.Cluster.Startup.CheckReadyCommand",
+		"Deployment.Cluster.Startup.LivenessInterval",
+		"Deployment.Cluster.Startup.LivenessFailureThreshold",
 		// SourceID.Location is incorporated into the value of ID(),
 		// is is compared directly - Repo and Dir are compared implicitly thereby
 		"Deployment.SourceID.Location.Repo",
 		"Deployment.SourceID.Location.Dir",
+		// Strategy governs how changes are rolled out, and isn't itself
+		// deployed, so it can't differ.
+		"Deployment.Strategy",
+		"Deployment.Strategy.Steps",
+		"Deployment.DeployConfig.Strategy",
+		"Deployment.DeployConfig.Strategy.Steps",
+		// Verify probes check deployments, rather than being deployed.
+		"Deployment.Verify",
+		"Deployment.DeployConfig.Verify",
 		/*
 			"Deployment.Owners",
 			"Deployment.DeployConfig.Args",
@@ -174,7 +186,7 @@ func TestDeployment_String(t *testing.T) {
 			in: &Deployment{},
 			// Current value of want reflects current reality.
 			// I think we can do better than this representation...
-			want: ",0.0.0 \"\" @ <unknown> #0 {false 0 0 0   0 <nil> 0 0 0} map[] : map[] []",
+			want: ",0.0.0 \"\" @ <unknown> #0 {false 0 0 0   0 <nil> 0 0 0 <nil> 0 0} map[] : map[] []",
 		},
 	}
 	for name, tc := range testCases {
@@ -12,6 +12,8 @@ type DeployState struct {
 	Status          DeployStatus
 	ExecutorMessage string
 	ExecutorData    interface{}
+	// Rollout is the progress of a staged rollout, if one is underway.
+	Rollout *RolloutProgress
 }
 
 // DeployStatus represents the status of a deployment in an external cluster.
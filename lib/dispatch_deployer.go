@@ -0,0 +1,163 @@
+package sous
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+type (
+	// DispatchDeployer is a Deployer which delegates to a Deployer per kind of
+	// cluster, so that a single GDM can span several schedulers.
+	DispatchDeployer struct {
+		deployers map[string]Deployer
+		// kinds records the kind of each cluster seen by RunningDeployments,
+		// since the deployments read back from a cluster may not carry it.
+		kinds map[string]string
+		sync.RWMutex
+	}
+
+	// UnknownClusterKindError is returned when a cluster's Kind has no
+	// registered Deployer.
+	UnknownClusterKindError struct {
+		Cluster, Kind string
+	}
+)
+
+func (e *UnknownClusterKindError) Error() string {
+	return "no deployer for cluster " + e.Cluster + " of kind " + e.Kind
+}
+
+// NewDispatchDeployer returns a DispatchDeployer which dispatches to
+// deployers by Cluster.Kind.
+func NewDispatchDeployer(deployers map[string]Deployer) *DispatchDeployer {
+	return &DispatchDeployer{
+		deployers: deployers,
+		kinds:     map[string]string{},
+	}
+}
+
+// RunningDeployments implements Deployer on DispatchDeployer. Each backend is
+// asked only about the clusters of its own kind.
+func (dd *DispatchDeployer) RunningDeployments(reg Registry, from Clusters) (DeployStates, error) {
+	byKind := map[string]Clusters{}
+	dd.Lock()
+	for name, c := range from {
+		kind := c.Kind
+		if kind == "" {
+			kind = "singularity"
+		}
+		dd.kinds[name] = kind
+		if byKind[kind] == nil {
+			byKind[kind] = Clusters{}
+		}
+		byKind[kind][name] = c
+	}
+	dd.Unlock()
+
+	all := NewDeployStates()
+	for kind, clusters := range byKind {
+		d, ok := dd.deployers[kind]
+		if !ok {
+			return all, &UnknownClusterKindError{Cluster: clusters.String(), Kind: kind}
+		}
+		states, err := d.RunningDeployments(reg, clusters)
+		if err != nil {
+			return all, errors.Wrapf(err, "getting %s deployments", kind)
+		}
+		for _, s := range states.Snapshot() {
+			all.Add(s)
+		}
+	}
+	return all, nil
+}
+
+// Rectify implements Deployer on DispatchDeployer.
+func (dd *DispatchDeployer) Rectify(pair *DeployablePair) DiffResolution {
+	kind := dd.kindOf(pair)
+	d, ok := dd.deployers[kind]
+	if !ok {
+		return DiffResolution{
+			DeploymentID: pair.ID(),
+			Desc:         "not rectified",
+			Error:        WrapResolveError(&UnknownClusterKindError{Cluster: pair.ID().Cluster, Kind: kind}),
+		}
+	}
+	return d.Rectify(pair)
+}
+
+func (dd *DispatchDeployer) kindOf(pair *DeployablePair) string {
+	for _, d := range []*Deployable{pair.Post, pair.Prior} {
+		if d != nil && d.Deployment != nil && d.Cluster != nil && d.Cluster.Kind != "" {
+			return d.Cluster.Kind
+		}
+	}
+	dd.RLock()
+	defer dd.RUnlock()
+	if kind, ok := dd.kinds[pair.ID().Cluster]; ok {
+		return kind
+	}
+	return "singularity"
+}
+
+// TaskAddresses implements TaskAddresser on DispatchDeployer, for deployments
+// whose Deployer implements it.
+func (dd *DispatchDeployer) TaskAddresses(pair *DeployablePair) ([]TaskAddress, error) {
+	kind := dd.kindOf(pair)
+	d, ok := dd.deployers[kind]
+	if !ok {
+		return nil, &UnknownClusterKindError{Cluster: pair.ID().Cluster, Kind: kind}
+	}
+	ta, ok := d.(TaskAddresser)
+	if !ok {
+		return nil, errors.Errorf("%s deployer can't list the tasks of %s", kind, pair.ID())
+	}
+	return ta.TaskAddresses(pair)
+}
+
+// inspector returns the TaskInspector for the cluster of d.
+func (dd *DispatchDeployer) inspector(d *Deployment) (TaskInspector, error) {
+	kind := "singularity"
+	if d.Cluster != nil && d.Cluster.Kind != "" {
+		kind = d.Cluster.Kind
+	}
+	dep, ok := dd.deployers[kind]
+	if !ok {
+		return nil, &UnknownClusterKindError{Cluster: d.ClusterName, Kind: kind}
+	}
+	ti, ok := dep.(TaskInspector)
+	if !ok {
+		return nil, errors.Errorf("%s deployer can't inspect the tasks of %s", kind, d.ID())
+	}
+	return ti, nil
+}
+
+// Tasks implements TaskInspector on DispatchDeployer, for deployments whose
+// Deployer implements it.
+func (dd *DispatchDeployer) Tasks(d *Deployment) ([]TaskInfo, error) {
+	ti, err := dd.inspector(d)
+	if err != nil {
+		return nil, err
+	}
+	return ti.Tasks(d)
+}
+
+// ReadTaskFile implements TaskInspector on DispatchDeployer, for deployments
+// whose Deployer implements it.
+func (dd *DispatchDeployer) ReadTaskFile(d *Deployment, taskID, path string, offset int64) (*FileChunk, error) {
+	ti, err := dd.inspector(d)
+	if err != nil {
+		return nil, err
+	}
+	return ti.ReadTaskFile(d, taskID, path, offset)
+}
+
+// InvalidateRequest implements StateInvalidator on DispatchDeployer, passing
+// requestID on to each Deployer which implements it.
+func (dd *DispatchDeployer) InvalidateRequest(requestID string) {
+	for _, d := range dd.deployers {
+		if si, ok := d.(StateInvalidator); ok {
+			si.InvalidateRequest(requestID)
+		}
+	}
+}
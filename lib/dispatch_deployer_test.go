@@ -0,0 +1,139 @@
+package sous
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingDeployer struct {
+	states    DeployStates
+	asked     Clusters
+	rectified []DeploymentID
+}
+
+func (rd *recordingDeployer) RunningDeployments(reg Registry, from Clusters) (DeployStates, error) {
+	rd.asked = from
+	return rd.states, nil
+}
+
+func (rd *recordingDeployer) Rectify(p *DeployablePair) DiffResolution {
+	rd.rectified = append(rd.rectified, p.ID())
+	return DiffResolution{DeploymentID: p.ID(), Desc: ModifyDiff}
+}
+
+func TestDispatchDeployer(t *testing.T) {
+	sing := &Cluster{Name: "sing", Kind: "singularity"}
+	kube := &Cluster{Name: "kube", Kind: "kubernetes"}
+	legacy := &Cluster{Name: "legacy"}
+
+	singDep := &DeployState{Deployment: Deployment{ClusterName: "sing", SourceID: MustNewSourceID("github.com/x/a", "", "1.0.0")}}
+	kubeDep := &DeployState{Deployment: Deployment{ClusterName: "kube", SourceID: MustNewSourceID("github.com/x/a", "", "1.0.0")}}
+
+	sd := &recordingDeployer{states: NewDeployStates(singDep)}
+	kd := &recordingDeployer{states: NewDeployStates(kubeDep)}
+	dd := NewDispatchDeployer(map[string]Deployer{"singularity": sd, "kubernetes": kd})
+
+	states, err := dd.RunningDeployments(NewDummyRegistry(), Clusters{"sing": sing, "kube": kube, "legacy": legacy})
+	require.NoError(t, err)
+	assert.Equal(t, 2, states.Len())
+	assert.Len(t, sd.asked, 2, "singularity deployer should get the singularity and kindless clusters")
+	assert.Len(t, kd.asked, 1)
+
+	// The prior was read from the cluster without a Cluster, so the kind
+	// must be remembered from RunningDeployments.
+	pair := &DeployablePair{
+		name:  kubeDep.ID(),
+		Prior: &Deployable{Deployment: &kubeDep.Deployment},
+	}
+	dd.Rectify(pair)
+	assert.Equal(t, []DeploymentID{kubeDep.ID()}, kd.rectified)
+	assert.Empty(t, sd.rectified)
+}
+
+func TestDispatchDeployer_UnknownKind(t *testing.T) {
+	dd := NewDispatchDeployer(map[string]Deployer{})
+	_, err := dd.RunningDeployments(NewDummyRegistry(), Clusters{"x": &Cluster{Name: "x", Kind: "nomad"}})
+	assert.IsType(t, &UnknownClusterKindError{}, err)
+
+	pair := &DeployablePair{
+		name: DeploymentID{Cluster: "y"},
+		Post: &Deployable{Deployment: &Deployment{ClusterName: "y", Cluster: &Cluster{Kind: "nomad"}}},
+	}
+	rez := dd.Rectify(pair)
+	require.NotNil(t, rez.Error)
+}
+
+type addressingDeployer struct {
+	recordingDeployer
+	fakeTaskAddresser
+}
+
+func TestDispatchDeployer_TaskAddresses(t *testing.T) {
+	tasks := []TaskAddress{{TaskID: "task-1", Host: "host-1", Ports: []int{31000}}}
+	sd := &addressingDeployer{fakeTaskAddresser: fakeTaskAddresser{tasks: tasks}}
+	kd := &recordingDeployer{}
+	dd := NewDispatchDeployer(map[string]Deployer{"singularity": sd, "kubernetes": kd})
+
+	deployable := func(kind string) *Deployable {
+		return &Deployable{Deployment: &Deployment{Cluster: &Cluster{Kind: kind}}}
+	}
+	got, err := dd.TaskAddresses(&DeployablePair{Prior: deployable("singularity"), Post: deployable("singularity")})
+	require.NoError(t, err)
+	assert.Equal(t, tasks, got)
+
+	_, err = dd.TaskAddresses(&DeployablePair{Prior: deployable("kubernetes"), Post: deployable("kubernetes")})
+	assert.Error(t, err, "kubernetes deployer can't list tasks")
+}
+
+type inspectingDeployer struct {
+	recordingDeployer
+	tasks []TaskInfo
+}
+
+func (id *inspectingDeployer) Tasks(d *Deployment) ([]TaskInfo, error) {
+	return id.tasks, nil
+}
+
+func (id *inspectingDeployer) ReadTaskFile(d *Deployment, taskID, path string, offset int64) (*FileChunk, error) {
+	return &FileChunk{Data: taskID + ":" + path, NextOffset: offset + 1}, nil
+}
+
+func TestDispatchDeployer_TaskInspector(t *testing.T) {
+	tasks := []TaskInfo{{TaskAddress: TaskAddress{TaskID: "task-1"}, Health: TaskHealthy}}
+	dd := NewDispatchDeployer(map[string]Deployer{
+		"singularity": &inspectingDeployer{tasks: tasks},
+		"kubernetes":  &recordingDeployer{},
+	})
+
+	got, err := dd.Tasks(&Deployment{Cluster: &Cluster{}})
+	require.NoError(t, err)
+	assert.Equal(t, tasks, got)
+
+	chunk, err := dd.ReadTaskFile(&Deployment{Cluster: &Cluster{Kind: "singularity"}}, "task-1", "stdout", 3)
+	require.NoError(t, err)
+	assert.Equal(t, &FileChunk{Data: "task-1:stdout", NextOffset: 4}, chunk)
+
+	_, err = dd.Tasks(&Deployment{Cluster: &Cluster{Kind: "kubernetes"}})
+	assert.Error(t, err, "kubernetes deployer can't inspect tasks")
+}
+
+type invalidatedDeployer struct {
+	recordingDeployer
+	invalidated []string
+}
+
+func (id *invalidatedDeployer) InvalidateRequest(requestID string) {
+	id.invalidated = append(id.invalidated, requestID)
+}
+
+func TestDispatchDeployer_InvalidateRequest(t *testing.T) {
+	sing := &invalidatedDeployer{}
+	dd := NewDispatchDeployer(map[string]Deployer{
+		"singularity": sing,
+		"kubernetes":  &recordingDeployer{},
+	})
+	dd.InvalidateRequest("request-1")
+	assert.Equal(t, []string{"request-1"}, sing.invalidated)
+}
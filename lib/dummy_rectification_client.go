@@ -12,11 +12,22 @@ type (
 		Created  []Deployable
 		Deployed []Deployable
 		Deleted  []dummyDelete
+		Advanced []dummyAdvance
+		Canceled []dummyCancel
 	}
 
 	dummyDelete struct {
 		Cluster, Reqid, Message string
 	}
+
+	dummyAdvance struct {
+		Cluster, Reqid, DeployID string
+		Instances                int
+	}
+
+	dummyCancel struct {
+		Cluster, Reqid, DeployID string
+	}
 )
 
 // NewDummyRectificationClient builds a new DummyRectificationClient
@@ -57,3 +68,36 @@ func (drc *DummyRectificationClient) DeleteRequest(
 	drc.Deleted = append(drc.Deleted, dummyDelete{cluster, reqid, message})
 	return nil
 }
+
+// AdvanceDeploy (cluster url, request id, deploy id, target instances)
+func (drc *DummyRectificationClient) AdvanceDeploy(cluster, reqid, deployID string, instances int) error {
+	drc.logf("Advancing deploy %s %s %s to %d instances", cluster, reqid, deployID, instances)
+	drc.Advanced = append(drc.Advanced, dummyAdvance{cluster, reqid, deployID, instances})
+	return nil
+}
+
+// CancelDeploy (cluster url, request id, deploy id)
+func (drc *DummyRectificationClient) CancelDeploy(cluster, reqid, deployID string) error {
+	drc.logf("Cancelling deploy %s %s %s", cluster, reqid, deployID)
+	drc.Canceled = append(drc.Canceled, dummyCancel{cluster, reqid, deployID})
+	return nil
+}
+
+// Tasks (cluster url, request id) reports that no tasks are running.
+func (drc *DummyRectificationClient) Tasks(cluster, reqid string) ([]TaskInfo, error) {
+	drc.logf("Listing tasks of %s %s", cluster, reqid)
+	return nil, nil
+}
+
+// RequestID (deployment) names the request of d after its ID.
+func (drc *DummyRectificationClient) RequestID(d *Deployment) (string, error) {
+	drc.logf("Finding the request of %s", d.ID())
+	return d.ID().String(), nil
+}
+
+// ReadTaskFile (cluster url, task id, path, offset) reports that the file has
+// nothing more in it.
+func (drc *DummyRectificationClient) ReadTaskFile(cluster, taskID, path string, offset int64) (*FileChunk, error) {
+	drc.logf("Reading %s of task %s %s from %d", path, cluster, taskID, offset)
+	return &FileChunk{NextOffset: offset}, nil
+}
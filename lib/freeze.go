@@ -0,0 +1,291 @@
+package sous
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FreezeOverrideHeader is the HTTP header a client sets to the reason for
+// changing deployments despite a freeze.
+const FreezeOverrideHeader = "Sous-Freeze-Override"
+
+type (
+	// Freezes is a list of deployment freezes.
+	Freezes []Freeze
+
+	// A Freeze stops changes to the deployments in some clusters for a window
+	// of time, e.g. over a holiday or during an incident.
+	Freeze struct {
+		// Clusters are the names of the frozen clusters. If it is empty, every
+		// cluster is frozen.
+		Clusters []string `yaml:",omitempty"`
+		// Start and End bound the freeze. A zero End means that the freeze
+		// lasts until it is removed.
+		Start, End time.Time
+		// Reason explains the freeze to those whose changes it rejects.
+		Reason string `yaml:",omitempty"`
+		// Overriders are the emails of the owners who may make changes
+		// despite the freeze, by giving a reason.
+		Overriders []string `yaml:",omitempty"`
+		// HoldDeletions stops the server deleting deployments in frozen
+		// clusters. Creations and updates are always held.
+		HoldDeletions bool `yaml:",omitempty"`
+	}
+
+	// A FreezeOverride records a change made to a deployment despite a
+	// freeze. Overrides are recorded in the FreezeOverrides of the
+	// deployment's manifest, so that recording one doesn't change the defs
+	// as well; each applies to the freezes covering its cluster at its Time.
+	FreezeOverride struct {
+		ManifestID ManifestID
+		Cluster    string
+		User       User
+		Reason     string
+		Time       time.Time
+	}
+
+	// A FrozenError reports that a change to a deployment was rejected or
+	// held because its cluster is frozen.
+	FrozenError struct {
+		DeploymentID DeploymentID
+		Freeze       Freeze
+		// User, if not empty, is the user who tried to override the freeze
+		// but is not one of its Overriders.
+		User string `json:",omitempty"`
+	}
+)
+
+// Clone returns a deep copy of this Freezes.
+func (fs Freezes) Clone() Freezes {
+	if fs == nil {
+		return nil
+	}
+	c := make(Freezes, len(fs))
+	for i, f := range fs {
+		f.Clusters = append([]string(nil), f.Clusters...)
+		f.Overriders = append([]string(nil), f.Overriders...)
+		c[i] = f
+	}
+	return c
+}
+
+// Validate checks that each freeze names known clusters and ends after it
+// starts.
+func (fs Freezes) Validate(clusters Clusters) []Flaw {
+	var flaws []Flaw
+	for _, f := range fs {
+		for _, c := range f.Clusters {
+			if _, ok := clusters[c]; !ok {
+				flaws = append(flaws, FatalFlaw("freeze of unknown cluster %q", c))
+			}
+		}
+		if !f.End.IsZero() && !f.End.After(f.Start) {
+			flaws = append(flaws, FatalFlaw("freeze starting %s ends at or before its start", f.Start))
+		}
+	}
+	return flaws
+}
+
+// Covers returns true if f freezes the named cluster at time at.
+func (f Freeze) Covers(cluster string, at time.Time) bool {
+	if at.Before(f.Start) || (!f.End.IsZero() && !at.Before(f.End)) {
+		return false
+	}
+	if len(f.Clusters) == 0 {
+		return true
+	}
+	for _, c := range f.Clusters {
+		if c == cluster {
+			return true
+		}
+	}
+	return false
+}
+
+// CanOverride returns true if u is one of f's Overriders.
+func (f Freeze) CanOverride(u User) bool {
+	for _, o := range f.Overriders {
+		if u.Email != "" && strings.EqualFold(o, u.Email) {
+			return true
+		}
+	}
+	return false
+}
+
+// Overridden returns true if one of overrides was made to did during f.
+func (f Freeze) Overridden(did DeploymentID, overrides []FreezeOverride) bool {
+	for _, o := range overrides {
+		if o.ManifestID == did.ManifestID && o.Cluster == did.Cluster && f.Covers(o.Cluster, o.Time) {
+			return true
+		}
+	}
+	return false
+}
+
+// Override checks each deployment which differs between before and after
+// against the freezes in effect at time at. A change to a frozen cluster is
+// rejected with a *FrozenError, unless reason is not empty and user is one
+// of the freeze's Overriders. The overrides made are returned, one for each
+// deployment, to be recorded with RecordOverrides so that the server will
+// make the change.
+func (fs Freezes) Override(before, after Deployments, user User, reason string, at time.Time) ([]FreezeOverride, error) {
+	var overrides []FreezeOverride
+	for _, did := range changedDeployments(before, after) {
+		frozen := false
+		for _, f := range fs {
+			if !f.Covers(did.Cluster, at) {
+				continue
+			}
+			if reason == "" {
+				return nil, &FrozenError{DeploymentID: did, Freeze: f}
+			}
+			if !f.CanOverride(user) {
+				return nil, &FrozenError{DeploymentID: did, Freeze: f, User: user.String()}
+			}
+			frozen = true
+		}
+		if frozen {
+			overrides = append(overrides, FreezeOverride{
+				ManifestID: did.ManifestID,
+				Cluster:    did.Cluster,
+				User:       user,
+				Reason:     reason,
+				Time:       at,
+			})
+		}
+	}
+	return overrides, nil
+}
+
+// RecordOverrides sets the FreezeOverrides of each manifest in after to those
+// recorded for it in before, which clients can't change, and adds overrides
+// to the manifests they were made to. Only manifests given new overrides
+// drop those of freezes no longer in effect at time at, so that other
+// manifests are left as they were.
+func (fs Freezes) RecordOverrides(before, after Manifests, overrides []FreezeOverride, at time.Time) {
+	for mid, m := range after.Snapshot() {
+		var recorded []FreezeOverride
+		if b, ok := before.Get(mid); ok {
+			recorded = b.FreezeOverrides
+		}
+		var added []FreezeOverride
+		for _, o := range overrides {
+			if o.ManifestID == mid {
+				added = append(added, o)
+			}
+		}
+		if len(added) == 0 {
+			m.FreezeOverrides = recorded
+			continue
+		}
+		m.FreezeOverrides = nil
+		for _, o := range recorded {
+			if fs.inEffect(o, at) {
+				m.FreezeOverrides = append(m.FreezeOverrides, o)
+			}
+		}
+		m.FreezeOverrides = append(m.FreezeOverrides, added...)
+	}
+}
+
+// inEffect returns true if o overrides a freeze which is still in effect at
+// time at.
+func (fs Freezes) inEffect(o FreezeOverride, at time.Time) bool {
+	for _, f := range fs {
+		if f.Covers(o.Cluster, o.Time) && (f.End.IsZero() || at.Before(f.End)) {
+			return true
+		}
+	}
+	return false
+}
+
+// FreezeOverrides returns the freeze overrides recorded in the manifests in
+// s.
+func (s *State) FreezeOverrides() []FreezeOverride {
+	var overrides []FreezeOverride
+	for _, m := range s.Manifests.Snapshot() {
+		overrides = append(overrides, m.FreezeOverrides...)
+	}
+	return overrides
+}
+
+// Holding returns the freeze, if any, which stops the server changing did at
+// time at. Deletions are only held by freezes with HoldDeletions set, and no
+// change is held by a freeze overridden for did.
+func (fs Freezes) Holding(did DeploymentID, deletion bool, at time.Time, overrides []FreezeOverride) (Freeze, bool) {
+	for _, f := range fs {
+		if !f.Covers(did.Cluster, at) || f.Overridden(did, overrides) {
+			continue
+		}
+		if deletion && !f.HoldDeletions {
+			continue
+		}
+		return f, true
+	}
+	return Freeze{}, false
+}
+
+// changedDeployments returns the IDs of the deployments which are different
+// in before and after, including those only in one of them.
+func changedDeployments(before, after Deployments) []DeploymentID {
+	var changed []DeploymentID
+	for did, b := range before.Snapshot() {
+		a, ok := after.Get(did)
+		if !ok {
+			changed = append(changed, did)
+			continue
+		}
+		if different, _ := a.Diff(b); different {
+			changed = append(changed, did)
+		}
+	}
+	for did := range after.Snapshot() {
+		if _, ok := before.Get(did); !ok {
+			changed = append(changed, did)
+		}
+	}
+	return changed
+}
+
+func (e *FrozenError) Error() string {
+	msg := fmt.Sprintf("%s is frozen", e.DeploymentID.Cluster)
+	if !e.Freeze.End.IsZero() {
+		msg += fmt.Sprintf(" until %s", e.Freeze.End.Format(time.RFC3339))
+	}
+	if e.Freeze.Reason != "" {
+		msg += fmt.Sprintf(" (%s)", e.Freeze.Reason)
+	}
+	msg += fmt.Sprintf(": cannot change %s", e.DeploymentID)
+	switch {
+	case e.User != "":
+		msg += fmt.Sprintf("; %s may not override the freeze", e.User)
+	case len(e.Freeze.Overriders) != 0:
+		msg += fmt.Sprintf("; %s may override it, giving a reason", strings.Join(e.Freeze.Overriders, ", "))
+	}
+	return msg
+}
+
+// freezeHolder is a DeployableProcessor which holds changes to deployments
+// in frozen clusters.
+type freezeHolder struct {
+	freezes   Freezes
+	overrides []FreezeOverride
+	now       time.Time
+}
+
+func (fh freezeHolder) HandlePairs(dp *DeployablePair) (*DeployablePair, *DiffResolution) {
+	kind := dp.Kind()
+	if kind == SameKind {
+		return dp, nil
+	}
+	f, held := fh.freezes.Holding(dp.ID(), kind == RemovedKind, fh.now, fh.overrides)
+	if !held {
+		return dp, nil
+	}
+	return nil, &DiffResolution{
+		DeploymentID: dp.ID(),
+		Desc:         HeldDiff,
+		Error:        WrapResolveError(&FrozenError{DeploymentID: dp.ID(), Freeze: f}),
+	}
+}
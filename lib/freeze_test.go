@@ -0,0 +1,136 @@
+package sous
+
+import (
+	"testing"
+	"time"
+
+	"github.com/samsalisbury/semv"
+)
+
+func TestFreeze_Covers(t *testing.T) {
+	start := time.Date(2017, 12, 22, 0, 0, 0, 0, time.UTC)
+	end := start.Add(14 * 24 * time.Hour)
+	f := Freeze{Clusters: []string{"prod"}, Start: start, End: end}
+
+	cases := []struct {
+		cluster string
+		at      time.Time
+		covers  bool
+	}{
+		{"prod", start, true},
+		{"prod", start.Add(-time.Second), false},
+		{"prod", end.Add(-time.Second), true},
+		{"prod", end, false},
+		{"ci", start, false},
+	}
+	for _, c := range cases {
+		if got := f.Covers(c.cluster, c.at); got != c.covers {
+			t.Errorf("Covers(%q, %s) = %t; want %t", c.cluster, c.at, got, c.covers)
+		}
+	}
+
+	global := Freeze{Start: start}
+	if !global.Covers("ci", end.Add(time.Hour)) {
+		t.Errorf("freeze with no clusters and no end doesn't cover ci after its start")
+	}
+}
+
+func TestFreezes_Override(t *testing.T) {
+	now := time.Now()
+	owner := User{Name: "Owner", Email: "owner@example.com"}
+	other := User{Name: "Other", Email: "other@example.com"}
+	deployments := func(version string) Deployments {
+		return NewDeployments(&Deployment{
+			ClusterName: "prod",
+			SourceID:    project1.SourceID(semv.MustParse(version)),
+		})
+	}
+	freezes := func() Freezes {
+		return Freezes{{Clusters: []string{"prod"}, Start: now.Add(-time.Hour), Overriders: []string{owner.Email}}}
+	}
+
+	fs := freezes()
+	if _, err := fs.Override(deployments("1.0.0"), deployments("1.0.0"), other, "", now); err != nil {
+		t.Errorf("got error %v for no change; want none", err)
+	}
+	if _, err := fs.Override(deployments("1.0.0"), deployments("2.0.0"), owner, "", now); err == nil {
+		t.Errorf("got no error for a change without a reason")
+	}
+	if _, err := fs.Override(deployments("1.0.0"), NewDeployments(), owner, "", now); err == nil {
+		t.Errorf("got no error for a deletion without a reason")
+	}
+	if _, err := fs.Override(deployments("1.0.0"), deployments("2.0.0"), other, "urgent", now); err == nil {
+		t.Errorf("got no error for an override by someone not allowed to")
+	}
+
+	overrides, err := fs.Override(deployments("1.0.0"), deployments("2.0.0"), owner, "urgent", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(overrides) != 1 || overrides[0].Reason != "urgent" {
+		t.Errorf("got overrides %v; want one with reason \"urgent\"", overrides)
+	}
+}
+
+func TestFreezes_RecordOverrides(t *testing.T) {
+	now := time.Now()
+	fs := Freezes{{Clusters: []string{"prod"}, Start: now.Add(-time.Hour)}}
+	mid := ManifestID{Source: project1}
+	other := ManifestID{Source: SourceLocation{Repo: "github.com/opentable/other"}}
+	ended := FreezeOverride{ManifestID: mid, Cluster: "prod", Reason: "old", Time: now.Add(-2 * time.Hour)}
+	stale := FreezeOverride{ManifestID: other, Cluster: "prod", Reason: "old", Time: now.Add(-2 * time.Hour)}
+	manifests := func(forged ...FreezeOverride) Manifests {
+		return NewManifests(
+			&Manifest{Source: mid.Source, FreezeOverrides: append([]FreezeOverride{ended}, forged...)},
+			&Manifest{Source: other.Source, FreezeOverrides: []FreezeOverride{stale}},
+		)
+	}
+
+	before := manifests()
+	after := manifests(FreezeOverride{ManifestID: mid, Cluster: "prod", Reason: "forged", Time: now})
+	fs.RecordOverrides(before, after, nil, now)
+	m, _ := after.Get(mid)
+	if len(m.FreezeOverrides) != 1 || m.FreezeOverrides[0].Reason != "old" {
+		t.Errorf("got overrides %v; want those recorded before", m.FreezeOverrides)
+	}
+
+	after = manifests()
+	fs.RecordOverrides(before, after, []FreezeOverride{{ManifestID: mid, Cluster: "prod", Reason: "urgent", Time: now}}, now)
+	m, _ = after.Get(mid)
+	if len(m.FreezeOverrides) != 1 || m.FreezeOverrides[0].Reason != "urgent" {
+		t.Errorf("got overrides %v; want just the new one", m.FreezeOverrides)
+	}
+	if o, _ := after.Get(other); len(o.FreezeOverrides) != 1 {
+		t.Errorf("overrides of a manifest given no new ones were dropped: %v", o.FreezeOverrides)
+	}
+}
+
+func TestFreezeHolder(t *testing.T) {
+	now := time.Now()
+	did := DeploymentID{ManifestID: ManifestID{Source: project1}, Cluster: "cluster-1"}
+	freeze := Freeze{Start: now.Add(-time.Hour)}
+
+	modify := rollbackTestPair("1.0.0", "2.0.0", DeployStatusActive)
+	remove := rollbackTestPair("1.0.0", "1.0.0", DeployStatusActive)
+	remove.Post = nil
+
+	fh := freezeHolder{freezes: Freezes{freeze}, now: now}
+	if dp, rez := fh.HandlePairs(modify); dp != nil || rez == nil || rez.Desc != HeldDiff {
+		t.Errorf("modification in a frozen cluster not held: %v, %v", dp, rez)
+	}
+	if dp, rez := fh.HandlePairs(remove); dp == nil || rez != nil {
+		t.Errorf("deletion held by a freeze without HoldDeletions: %v", rez)
+	}
+
+	freeze.HoldDeletions = true
+	fh = freezeHolder{freezes: Freezes{freeze}, now: now}
+	if dp, _ := fh.HandlePairs(remove); dp != nil {
+		t.Errorf("deletion not held by a freeze with HoldDeletions")
+	}
+
+	overrides := []FreezeOverride{{ManifestID: did.ManifestID, Cluster: did.Cluster, Time: now.Add(-time.Minute)}}
+	fh = freezeHolder{freezes: Freezes{freeze}, overrides: overrides, now: now}
+	if dp, rez := fh.HandlePairs(modify); dp == nil || rez != nil {
+		t.Errorf("overridden modification held: %v", rez)
+	}
+}
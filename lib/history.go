@@ -0,0 +1,72 @@
+package sous
+
+import (
+	"time"
+
+	"github.com/samsalisbury/semv"
+)
+
+type (
+	// A HistoryReader reads the history of changes to the intended state of
+	// a deployment.
+	HistoryReader interface {
+		ReadHistory(DeploymentID) (DeploymentHistory, error)
+	}
+
+	// DeploymentHistory is a list of changes to the intended state of a
+	// single deployment, oldest first.
+	DeploymentHistory []DeploymentChange
+
+	// A DeploymentChange is a single change to the intended state of a
+	// deployment.
+	DeploymentChange struct {
+		// Version is the intended version after the change.
+		Version semv.Version
+		// Diffs are the differences between the DeploySpec after the change
+		// ("this") and before it ("other"), as reported by DeploySpec.Diff.
+		// They are empty for the change which created the deployment.
+		Diffs []string `json:",omitempty"`
+		// Deleted is true if the change removed the deployment.
+		Deleted bool `json:",omitempty"`
+		// User is who made the change.
+		User User
+		// Time is when the change was made.
+		Time time.Time
+
+		// spec is the DeploySpec after the change, or nil if it was deleted.
+		spec *DeploySpec
+	}
+)
+
+// Record adds a change to the history if spec differs from the most recent
+// one, and returns true if it did. A nil spec means that the deployment does
+// not exist as of this change.
+func (h *DeploymentHistory) Record(spec *DeploySpec, user User, at time.Time) bool {
+	var last *DeploySpec
+	if n := len(*h); n != 0 {
+		last = (*h)[n-1].spec
+	}
+	change := DeploymentChange{User: user, Time: at}
+	switch {
+	case spec == nil && last == nil:
+		return false
+	case spec == nil:
+		change.Deleted = true
+		change.Version = last.Version
+	case last == nil:
+		change.Version = spec.Version
+	default:
+		different, diffs := spec.Diff(*last)
+		if !different {
+			return false
+		}
+		change.Version = spec.Version
+		change.Diffs = diffs
+	}
+	if spec != nil {
+		s := spec.Clone()
+		change.spec = &s
+	}
+	*h = append(*h, change)
+	return true
+}
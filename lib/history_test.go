@@ -0,0 +1,60 @@
+package sous
+
+import (
+	"testing"
+	"time"
+
+	"github.com/samsalisbury/semv"
+)
+
+func TestDeploymentHistoryRecord(t *testing.T) {
+	user := User{Name: "Test User", Email: "test@example.com"}
+	at := time.Unix(1500000000, 0)
+	spec := func(version string, instances int) *DeploySpec {
+		return &DeploySpec{
+			Version:      semv.MustParse(version),
+			DeployConfig: DeployConfig{NumInstances: instances},
+		}
+	}
+
+	h := DeploymentHistory{}
+	steps := []struct {
+		spec      *DeploySpec
+		recorded  bool
+		diffCount int
+	}{
+		{nil, false, 0},
+		{spec("1.0.0", 1), true, 0},
+		{spec("1.0.0", 1), false, 0},
+		{spec("1.1.0", 2), true, 2},
+		{nil, true, 0},
+		{nil, false, 0},
+		{spec("1.1.0", 2), true, 0},
+	}
+	for i, step := range steps {
+		at = at.Add(time.Minute)
+		if recorded := h.Record(step.spec, user, at); recorded != step.recorded {
+			t.Errorf("step %d: Record returned %t; want %t", i, recorded, step.recorded)
+			continue
+		}
+		if !step.recorded {
+			continue
+		}
+		change := h[len(h)-1]
+		if len(change.Diffs) != step.diffCount {
+			t.Errorf("step %d: got diffs %q; want %d of them", i, change.Diffs, step.diffCount)
+		}
+		if change.Deleted != (step.spec == nil) {
+			t.Errorf("step %d: got Deleted %t", i, change.Deleted)
+		}
+		if !change.Time.Equal(at) || change.User != user {
+			t.Errorf("step %d: got change by %s at %s; want by %s at %s", i, change.User, change.Time, user, at)
+		}
+	}
+	if len(h) != 4 {
+		t.Fatalf("got %d changes; want 4", len(h))
+	}
+	if h[2].Version.String() != "1.1.0" {
+		t.Errorf("deletion recorded version %s; want 1.1.0", h[2].Version)
+	}
+}
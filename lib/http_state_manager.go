@@ -16,6 +16,9 @@ type (
 		gdmState restful.Updater
 		restful.HTTPClient
 		User User
+		// FreezeOverride, if not empty, is sent as the reason to change
+		// deployments despite any freeze of their clusters.
+		FreezeOverride string
 	}
 
 	gdmWrapper struct {
@@ -125,7 +128,11 @@ func (hsm *HTTPStateManager) getManifests(defs Defs) (Manifests, error) {
 
 func (hsm *HTTPStateManager) putDeployments(new Deployments) error {
 	wNew := wrapDeployments(new)
-	return errors.Wrapf(hsm.gdmState.Update(&wNew, hsm.User.HTTPHeaders()), "putting GDM")
+	headers := hsm.User.HTTPHeaders()
+	if hsm.FreezeOverride != "" {
+		headers[FreezeOverrideHeader] = hsm.FreezeOverride
+	}
+	return errors.Wrapf(hsm.gdmState.Update(&wNew, headers), "putting GDM")
 }
 
 // EmptyReceiver implements Comparable on Manifest
@@ -0,0 +1,28 @@
+package sous
+
+type (
+	// An Importer finds deployments on a cluster which Sous does not manage,
+	// and hands them over to Sous.
+	Importer interface {
+		// Unmanaged returns the deployments on cluster which Sous does not
+		// manage, as Sous would manage them. If requestIDs are given, only
+		// those requests are examined, and each must be importable.
+		Unmanaged(cluster *Cluster, requestIDs ...string) ([]UnmanagedDeployment, error)
+		// Adopt relabels u so that Sous recognises it as the deployment it
+		// manages from then on. The scheduler may have to replace u's tasks
+		// to relabel it, but they run the same artifact and configuration.
+		Adopt(u UnmanagedDeployment) error
+	}
+
+	// An UnmanagedDeployment is a deployment found on a cluster which Sous
+	// does not manage.
+	UnmanagedDeployment struct {
+		// RequestID is the ID of the request which runs it, in the cluster's
+		// scheduler.
+		RequestID string
+		// Deployment is the deployment as Sous would manage it.
+		Deployment *Deployment
+		// Artifact is the build artifact it runs.
+		Artifact *BuildArtifact
+	}
+)
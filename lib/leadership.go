@@ -0,0 +1,190 @@
+package sous
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/opentable/sous/util/logging"
+	"github.com/pkg/errors"
+)
+
+type (
+	// A LeaderElector elects one leader for a cluster among the servers
+	// which resolve it. Each server has its own LeaderElector.
+	LeaderElector interface {
+		// Campaign makes this server the leader for cluster if it has none,
+		// and returns whether this server is the leader. A leader stays the
+		// leader until it resigns or fails.
+		Campaign(cluster string) (bool, error)
+		// Resign ends this server's leadership of cluster, if it leads it.
+		Resign(cluster string) error
+		// Leader returns the URL of the leader for cluster, or "" if it has
+		// none.
+		Leader(cluster string) (string, error)
+	}
+
+	// LeadershipConfig configures leader election among several servers
+	// which resolve the same cluster, e.g. for high availability.
+	LeadershipConfig struct {
+		// Enabled turns leader election on. It needs the database.
+		Enabled bool `env:"SOUS_LEADER_ELECTION"`
+		// URL is the URL other servers reach this server at, as opposed to
+		// the cluster's URL in SiblingURLs, which any of them may serve.
+		URL string `env:"SOUS_LEADER_URL"`
+		// IntervalSeconds is how often each server campaigns for leadership.
+		// The default is 10.
+		IntervalSeconds int `env:"SOUS_LEADER_INTERVAL"`
+	}
+
+	// Leadership runs an AutoResolver only while its server leads the
+	// cluster. Followers serve reads, and forward writes to the leader.
+	Leadership struct {
+		Elector LeaderElector
+		// Cluster is the cluster led; "*" stands for all clusters.
+		Cluster string
+		// Interval is how often Run campaigns for leadership.
+		Interval time.Duration
+		ls       logging.LogSink
+		sync.Mutex
+		leading bool
+	}
+
+	// LeadershipStatus reports the leader for a cluster.
+	LeadershipStatus struct {
+		Cluster string
+		// Leader is the URL of the leader, if it has one.
+		Leader string
+		// Leading is true if the reporting server is the leader.
+		Leading bool
+	}
+
+	// MemoryLeaderElection elects leaders among LeaderElectors in one
+	// process, for testing.
+	MemoryLeaderElection struct {
+		mu      sync.Mutex
+		leaders map[string]string
+	}
+
+	memoryLeaderElector struct {
+		election *MemoryLeaderElection
+		url      string
+	}
+)
+
+// DefaultLeadershipInterval is how often leadership is campaigned for, when
+// LeadershipConfig.IntervalSeconds is not set.
+const DefaultLeadershipInterval = 10 * time.Second
+
+// NewLeadership returns a Leadership of cluster by le.
+func NewLeadership(le LeaderElector, cluster string, ls logging.LogSink) *Leadership {
+	if cluster == "" {
+		cluster = "*"
+	}
+	return &Leadership{Elector: le, Cluster: cluster, Interval: DefaultLeadershipInterval, ls: ls}
+}
+
+// Leading returns true if this server leads its cluster. A nil Leadership
+// always leads: there is no election.
+func (l *Leadership) Leading() bool {
+	if l == nil {
+		return true
+	}
+	l.Lock()
+	defer l.Unlock()
+	return l.leading
+}
+
+// Status reports the leader for this server's cluster.
+func (l *Leadership) Status() (LeadershipStatus, error) {
+	status := LeadershipStatus{Cluster: l.Cluster, Leading: l.Leading()}
+	leader, err := l.Elector.Leader(l.Cluster)
+	status.Leader = leader
+	return status, err
+}
+
+// LeaderOf returns the URL of the leader for cluster, which is the leader for
+// all clusters if this Leadership's Cluster is "*".
+func (l *Leadership) LeaderOf(cluster string) (string, error) {
+	if l.Cluster == "*" {
+		cluster = "*"
+	}
+	return l.Elector.Leader(cluster)
+}
+
+// Run campaigns for leadership every Interval until done is closed, kicking
+// off ar when this server becomes the leader, and stopping it if it stops
+// being the leader. An error campaigning is treated as not leading, so that
+// two servers never resolve at once. Run resigns before returning.
+func (l *Leadership) Run(ar *AutoResolver, done <-chan struct{}) {
+	var stop TriggerChannel
+	ticker := time.NewTicker(l.Interval)
+	defer ticker.Stop()
+	for {
+		leading, err := l.Elector.Campaign(l.Cluster)
+		if err != nil {
+			logging.ReportError(l.ls, errors.Wrapf(err, "campaigning to lead %s", l.Cluster))
+		}
+		switch {
+		case leading && stop == nil:
+			logging.ReportMsg(l.ls, logging.WarningLevel, fmt.Sprintf("Leading %s: starting to resolve", l.Cluster))
+			stop = ar.Kickoff()
+		case !leading && stop != nil:
+			logging.ReportMsg(l.ls, logging.WarningLevel, fmt.Sprintf("No longer leading %s: stopping resolving", l.Cluster))
+			close(stop)
+			stop = nil
+		}
+		l.Lock()
+		l.leading = leading
+		l.Unlock()
+
+		select {
+		case <-ticker.C:
+		case <-done:
+			if stop != nil {
+				close(stop)
+			}
+			l.Lock()
+			l.leading = false
+			l.Unlock()
+			if err := l.Elector.Resign(l.Cluster); err != nil {
+				logging.ReportError(l.ls, errors.Wrapf(err, "resigning leadership of %s", l.Cluster))
+			}
+			return
+		}
+	}
+}
+
+// NewMemoryLeaderElection returns a MemoryLeaderElection with no leaders.
+func NewMemoryLeaderElection() *MemoryLeaderElection {
+	return &MemoryLeaderElection{leaders: map[string]string{}}
+}
+
+// Elector returns the LeaderElector for the server at url.
+func (me *MemoryLeaderElection) Elector(url string) LeaderElector {
+	return memoryLeaderElector{election: me, url: url}
+}
+
+func (le memoryLeaderElector) Campaign(cluster string) (bool, error) {
+	le.election.mu.Lock()
+	defer le.election.mu.Unlock()
+	if _, has := le.election.leaders[cluster]; !has {
+		le.election.leaders[cluster] = le.url
+	}
+	return le.election.leaders[cluster] == le.url, nil
+}
+
+func (le memoryLeaderElector) Resign(cluster string) error {
+	le.election.mu.Lock()
+	defer le.election.mu.Unlock()
+	if le.election.leaders[cluster] == le.url {
+		delete(le.election.leaders, cluster)
+	}
+	return nil
+}
+
+func (le memoryLeaderElector) Leader(cluster string) (string, error) {
+	le.election.mu.Lock()
+	defer le.election.mu.Unlock()
+	return le.election.leaders[cluster], nil
+}
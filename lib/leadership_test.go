@@ -0,0 +1,99 @@
+package sous
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/opentable/sous/util/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingElector struct{}
+
+func (failingElector) Campaign(string) (bool, error) { return false, fmt.Errorf("no database") }
+func (failingElector) Resign(string) error           { return nil }
+func (failingElector) Leader(string) (string, error) { return "", fmt.Errorf("no database") }
+
+func TestMemoryLeaderElection(t *testing.T) {
+	assert := assert.New(t)
+	election := NewMemoryLeaderElection()
+	left, right := election.Elector("http://left"), election.Elector("http://right")
+
+	leading, err := left.Campaign("c1")
+	assert.NoError(err)
+	assert.True(leading)
+	leading, err = right.Campaign("c1")
+	assert.NoError(err)
+	assert.False(leading)
+	leading, err = right.Campaign("c2")
+	assert.NoError(err)
+	assert.True(leading, "clusters are led independently")
+
+	leader, err := right.Leader("c1")
+	assert.NoError(err)
+	assert.Equal("http://left", leader)
+
+	assert.NoError(right.Resign("c1"))
+	leader, _ = left.Leader("c1")
+	assert.Equal("http://left", leader, "only the leader can resign")
+
+	assert.NoError(left.Resign("c1"))
+	leader, _ = left.Leader("c1")
+	assert.Equal("", leader)
+	leading, _ = right.Campaign("c1")
+	assert.True(leading)
+}
+
+func waitForLeading(t *testing.T, l *Leadership, leading bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for l.Leading() != leading {
+		if time.Now().After(deadline) {
+			t.Fatalf("%s: Leading() never became %t", l.Elector.(memoryLeaderElector).url, leading)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestLeadership_Run(t *testing.T) {
+	election := NewMemoryLeaderElection()
+	ls := logging.SilentLogSet()
+	first := NewLeadership(election.Elector("http://first"), "", ls)
+	second := NewLeadership(election.Elector("http://second"), "", ls)
+	first.Interval, second.Interval = time.Millisecond, time.Millisecond
+
+	firstDone, secondDone := make(chan struct{}), make(chan struct{})
+	go first.Run(setupAR(), firstDone)
+	waitForLeading(t, first, true)
+	go second.Run(setupAR(), secondDone)
+	defer close(secondDone)
+
+	time.Sleep(5 * time.Millisecond)
+	assert.False(t, second.Leading(), "only one server leads")
+
+	status, err := second.Status()
+	assert.NoError(t, err)
+	assert.Equal(t, LeadershipStatus{Cluster: "*", Leader: "http://first", Leading: false}, status)
+
+	close(firstDone)
+	waitForLeading(t, first, false)
+	waitForLeading(t, second, true)
+	leader, _ := first.LeaderOf("any-cluster")
+	assert.Equal(t, "http://second", leader)
+}
+
+func TestLeadership_Run_errorsMeanFollowing(t *testing.T) {
+	l := NewLeadership(failingElector{}, "c1", logging.SilentLogSet())
+	l.Interval = time.Millisecond
+	done := make(chan struct{})
+	go l.Run(setupAR(), done)
+	time.Sleep(5 * time.Millisecond)
+	assert.False(t, l.Leading())
+	close(done)
+}
+
+func TestLeadership_Leading_nil(t *testing.T) {
+	var l *Leadership
+	assert.True(t, l.Leading(), "without an election, every server leads")
+}
@@ -31,6 +31,17 @@ type (
 		Kind ManifestKind `validate:"nonzero"`
 		// Deployments is a map of cluster names to DeploymentSpecs
 		Deployments DeploySpecs `validate:"keys=nonempty,values=nonzero"`
+		// Rollback is what the server should do when a deploy of this
+		// manifest fails. By default it does nothing.
+		Rollback RollbackPolicy `yaml:",omitempty"`
+		// DependsOn lists the manifests whose deployments must be active at
+		// their intended versions before this manifest's deployments are
+		// changed, in each cluster they share.
+		DependsOn []ManifestID `yaml:",omitempty"`
+		// FreezeOverrides records the changes made to this manifest's
+		// deployments despite freezes. It is kept by the server, which
+		// ignores any clients send. It isn't compared by Diff.
+		FreezeOverrides []FreezeOverride `yaml:",omitempty"`
 	}
 )
 
@@ -59,6 +70,12 @@ func (m Manifest) Clone() (c *Manifest) {
 	}
 	c.Owners = owners
 	c.Deployments = deployments
+	if m.DependsOn != nil {
+		c.DependsOn = append([]ManifestID(nil), m.DependsOn...)
+	}
+	if m.FreezeOverrides != nil {
+		c.FreezeOverrides = append([]FreezeOverride(nil), m.FreezeOverrides...)
+	}
 	return
 }
 
@@ -82,6 +99,12 @@ func (m *Manifest) Diff(o *Manifest) (bool, []string) {
 	if m.Kind != o.Kind {
 		diff("kind; this: %q; other: %q", m.Kind, o.Kind)
 	}
+	if m.Rollback != o.Rollback {
+		diff("rollback; this: %q; other: %q", m.Rollback, o.Rollback)
+	}
+	if !manifestIDsEqual(m.DependsOn, o.DependsOn) {
+		diff("depends on; this: %v; other: %v", m.DependsOn, o.DependsOn)
+	}
 	if len(m.Owners) != len(o.Owners) {
 		diff("number of owners; this: %d; other: %d", len(m.Owners), len(o.Owners))
 	} else {
@@ -114,6 +137,18 @@ func (m *Manifest) Diff(o *Manifest) (bool, []string) {
 	return len(diffs) != 0, diffs
 }
 
+func manifestIDsEqual(a, b []ManifestID) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
 // Equal returns true iff o is equal to m.
 func (m *Manifest) Equal(o *Manifest) bool {
 	diff, _ := m.Diff(o)
@@ -131,6 +166,16 @@ func (m *Manifest) Validate() []Flaw {
 	} else {
 		flaws = append(flaws, m.Kind.Validate()...)
 	}
+	flaws = append(flaws, m.Rollback.Validate()...)
+
+	if m.Kind != ManifestKindScheduled {
+		for cluster, spec := range m.Deployments {
+			if spec.TaskTimeLimitSeconds != 0 || spec.ScheduleTimeZone != "" {
+				flaws = append(flaws, FatalFlaw("manifest %q is a %s: only %s manifests may set TaskTimeLimitSeconds or ScheduleTimeZone (in %s)",
+					m.ID(), m.Kind, ManifestKindScheduled, cluster))
+			}
+		}
+	}
 
 	/*
 		Cannot validate Deployments without defs...
@@ -130,6 +130,19 @@ var manifestTests = []struct {
 			},
 		},
 	},
+	{
+		TestName: "time limit on a service",
+		OriginalManifest: &Manifest{
+			Kind: ManifestKindService,
+			Deployments: DeploySpecs{
+				"some-cluster": DeploySpec{
+					DeployConfig: DeployConfig{TaskTimeLimitSeconds: 60},
+				},
+			},
+		},
+		FlawDesc:    `manifest "" is a http-service: only scheduled manifests may set TaskTimeLimitSeconds or ScheduleTimeZone (in some-cluster)`,
+		RepairError: `manifest "" is a http-service: only scheduled manifests may set TaskTimeLimitSeconds or ScheduleTimeZone (in some-cluster): cannot be repaired.`,
+	},
 	{
 		// NOTE: This one is valid, hence no FlawDesc.
 		TestName: "valid",
@@ -61,6 +61,13 @@ func (ds Deployments) PutbackManifests(defs Defs, olds Manifests) (Manifests, er
 			m = &Manifest{Deployments: DeploySpecs{}}
 			m.Owners = d.Owners.Slice()
 			m.SetID(mid)
+			if was {
+				// Rollback, DependsOn and FreezeOverrides aren't part of any
+				// Deployment, so keep them from the old manifest.
+				m.Rollback = old.Rollback
+				m.DependsOn = old.DependsOn
+				m.FreezeOverrides = old.FreezeOverrides
+			}
 		}
 		spec := DeploySpec{
 			Version:      d.SourceID.Version,
@@ -59,7 +59,7 @@ func resolveName(r Registry, d *Deployable) (*Deployable, *DiffResolution) {
 			Error: &ErrorWrapper{error: fmt.Errorf("nil deployable")},
 		}
 	}
-	art, err := guardImage(r, d.Deployment)
+	art, err := GuardImage(r, d.Deployment)
 	if err != nil {
 		return d, &DiffResolution{
 			DeploymentID: d.ID(),
@@ -70,7 +70,10 @@ func resolveName(r Registry, d *Deployable) (*Deployable, *DiffResolution) {
 	return d, nil
 }
 
-func guardImage(r Registry, d *Deployment) (*BuildArtifact, error) {
+// GuardImage returns the artifact to deploy for d. It returns an error if
+// the artifact can't be found, or if it carries advisories which d's cluster
+// doesn't allow.
+func GuardImage(r Registry, d *Deployment) (*BuildArtifact, error) {
 	if d.NumInstances == 0 {
 		logging.Log.Info.Printf("Deployment %q has 0 instances, skipping artifact check.", d.ID())
 		return nil, nil
@@ -0,0 +1,98 @@
+package sous
+
+import "fmt"
+
+type (
+	// Placement constrains which hosts the tasks of a deployment are run on.
+	// The zero Placement runs them on any host.
+	Placement struct {
+		// RequiredAttributes are host attributes which a host must have
+		// all of to run a task.
+		RequiredAttributes Attributes `yaml:",omitempty"`
+		// AllowedAttributes are host attributes which allow a host reserved
+		// for particular work by those attributes to run a task.
+		AllowedAttributes Attributes `yaml:",omitempty"`
+		// RackSensitive spreads the tasks evenly across racks.
+		RackSensitive bool `yaml:",omitempty"`
+	}
+
+	// Attributes maps the names of host attributes to their values.
+	Attributes map[string]string
+)
+
+// IsZero returns true if p doesn't constrain placement at all.
+func (p Placement) IsZero() bool {
+	return len(p.RequiredAttributes) == 0 && len(p.AllowedAttributes) == 0 && !p.RackSensitive
+}
+
+// Clone returns a deep copy of p.
+func (p Placement) Clone() Placement {
+	return Placement{
+		RequiredAttributes: p.RequiredAttributes.Clone(),
+		AllowedAttributes:  p.AllowedAttributes.Clone(),
+		RackSensitive:      p.RackSensitive,
+	}
+}
+
+// Equal returns true if p and o constrain placement in the same way.
+func (p Placement) Equal(o Placement) bool {
+	return len(p.diff(o)) == 0
+}
+
+// Validate implements Flawed on Placement.
+func (p *Placement) Validate() []Flaw {
+	var flaws []Flaw
+	flaws = append(flaws, p.RequiredAttributes.validate("RequiredAttributes")...)
+	flaws = append(flaws, p.AllowedAttributes.validate("AllowedAttributes")...)
+	return flaws
+}
+
+func (p Placement) diff(o Placement) []string {
+	var diffs []string
+	if !p.RequiredAttributes.Equal(o.RequiredAttributes) {
+		diffs = append(diffs, fmt.Sprintf("required attributes; this: %v; other: %v", p.RequiredAttributes, o.RequiredAttributes))
+	}
+	if !p.AllowedAttributes.Equal(o.AllowedAttributes) {
+		diffs = append(diffs, fmt.Sprintf("allowed attributes; this: %v; other: %v", p.AllowedAttributes, o.AllowedAttributes))
+	}
+	if p.RackSensitive != o.RackSensitive {
+		diffs = append(diffs, fmt.Sprintf("rack sensitive; this: %t; other: %t", p.RackSensitive, o.RackSensitive))
+	}
+	return diffs
+}
+
+// Clone returns a copy of a, or nil if a is empty.
+func (a Attributes) Clone() Attributes {
+	if len(a) == 0 {
+		return nil
+	}
+	c := make(Attributes, len(a))
+	for k, v := range a {
+		c[k] = v
+	}
+	return c
+}
+
+// Equal returns true if a and o have the same attributes. Nil is equal to
+// empty.
+func (a Attributes) Equal(o Attributes) bool {
+	if len(a) != len(o) {
+		return false
+	}
+	for k, v := range a {
+		if ov, ok := o[k]; !ok || ov != v {
+			return false
+		}
+	}
+	return true
+}
+
+func (a Attributes) validate(field string) []Flaw {
+	var flaws []Flaw
+	for k, v := range a {
+		if k == "" || v == "" {
+			flaws = append(flaws, FatalFlaw("Placement %s must have non-empty names and values, has %q: %q", field, k, v))
+		}
+	}
+	return flaws
+}
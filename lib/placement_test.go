@@ -0,0 +1,31 @@
+package sous
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlacementDiff(t *testing.T) {
+	p := Placement{
+		RequiredAttributes: Attributes{"zone": "a"},
+		RackSensitive:      true,
+	}
+	assert.True(t, p.Equal(p.Clone()))
+	assert.True(t, Placement{AllowedAttributes: Attributes{}}.Equal(Placement{}), "empty attributes are no attributes")
+	assert.True(t, Placement{AllowedAttributes: Attributes{}}.IsZero())
+
+	o := p.Clone()
+	o.RequiredAttributes["zone"] = "b"
+	o.RackSensitive = false
+	assert.Equal(t, "a", p.RequiredAttributes["zone"], "clone shares attributes")
+	assert.Len(t, p.diff(o), 2)
+}
+
+func TestPlacementValidate(t *testing.T) {
+	p := Placement{
+		RequiredAttributes: Attributes{"zone": ""},
+		AllowedAttributes:  Attributes{"": "reserved"},
+	}
+	assert.Len(t, p.Validate(), 2)
+}
@@ -0,0 +1,134 @@
+package sous
+
+import (
+	"sort"
+)
+
+type (
+	// A Plan lists the changes a rectification would make to the running
+	// deployments, without making them.
+	Plan struct {
+		// Clusters are the plans for each cluster, in name order.
+		Clusters []ClusterPlan
+	}
+
+	// A ClusterPlan lists the changes a rectification would make in one
+	// cluster.
+	ClusterPlan struct {
+		Cluster string
+		// Changes are the changes to deployments in the cluster, in
+		// DeploymentID order.
+		Changes []PlannedChange
+		// Unchanged is the number of deployments which would not be changed.
+		Unchanged int
+	}
+
+	// A PlannedChange is a change a rectification would make to one
+	// deployment.
+	PlannedChange struct {
+		DeploymentID DeploymentID
+		Action       PlanAction
+		// Prior is the version running now, and Post the version which would
+		// be running after the change. Each is empty if there is no
+		// deployment.
+		Prior, Post string `json:",omitempty"`
+		// Diffs are the differences between the running and intended
+		// deployment, for changes which modify a deployment.
+		Diffs Differences `json:",omitempty"`
+	}
+
+	// A PlanAction is the kind of a PlannedChange.
+	PlanAction string
+)
+
+const (
+	// PlanCreate is the action of a deployment which is not yet running.
+	PlanCreate = PlanAction("create")
+	// PlanModify is the action of a running deployment which differs from its
+	// intended state.
+	PlanModify = PlanAction("modify")
+	// PlanDelete is the action of a running deployment which is not intended.
+	PlanDelete = PlanAction("delete")
+)
+
+// Plan runs the phases of Begin up to generating the diff, and returns the
+// changes a rectification would make to bring the running deployments in
+// clusters in line with intended. Nothing is changed.
+func (r *Resolver) Plan(intended Deployments, clusters Clusters) (*Plan, error) {
+	intended = intended.Filter(r.FilterDeployment)
+	clusters = r.FilteredClusters(clusters)
+	actual, err := r.Deployer.RunningDeployments(r.Registry, clusters)
+	if err != nil {
+		return nil, err
+	}
+	actual = actual.Filter(r.FilterDeployStates)
+	return NewPlan(actual.Diff(intended).Collect()), nil
+}
+
+// NewPlan returns the Plan for the DeployablePairs of a diff.
+func NewPlan(pairs DeployablePairs) *Plan {
+	byCluster := map[string]*ClusterPlan{}
+	for _, dp := range pairs {
+		did := dp.ID()
+		cp, ok := byCluster[did.Cluster]
+		if !ok {
+			cp = &ClusterPlan{Cluster: did.Cluster}
+			byCluster[did.Cluster] = cp
+		}
+		change := PlannedChange{DeploymentID: did}
+		if dp.Prior != nil {
+			change.Prior = dp.Prior.SourceID.Version.String()
+		}
+		if dp.Post != nil {
+			change.Post = dp.Post.SourceID.Version.String()
+		}
+		switch dp.Kind() {
+		default:
+			cp.Unchanged++
+			continue
+		case AddedKind:
+			change.Action = PlanCreate
+		case RemovedKind:
+			change.Action = PlanDelete
+		case ModifiedKind:
+			change.Action = PlanModify
+			change.Diffs = dp.Diffs()
+		}
+		cp.Changes = append(cp.Changes, change)
+	}
+
+	plan := &Plan{Clusters: []ClusterPlan{}}
+	for _, cp := range byCluster {
+		sort.Slice(cp.Changes, func(i, j int) bool {
+			return cp.Changes[i].DeploymentID.String() < cp.Changes[j].DeploymentID.String()
+		})
+		plan.Clusters = append(plan.Clusters, *cp)
+	}
+	sort.Slice(plan.Clusters, func(i, j int) bool {
+		return plan.Clusters[i].Cluster < plan.Clusters[j].Cluster
+	})
+	return plan
+}
+
+// Count returns the number of planned changes with action a.
+func (p *Plan) Count(a PlanAction) int {
+	n := 0
+	for _, cp := range p.Clusters {
+		for _, c := range cp.Changes {
+			if c.Action == a {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// Empty returns true if the plan makes no changes.
+func (p *Plan) Empty() bool {
+	for _, cp := range p.Clusters {
+		if len(cp.Changes) != 0 {
+			return false
+		}
+	}
+	return true
+}
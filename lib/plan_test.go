@@ -0,0 +1,75 @@
+package sous
+
+import (
+	"testing"
+
+	"github.com/opentable/sous/util/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolverPlan(t *testing.T) {
+	clusters := Clusters{"x": &Cluster{Name: "x"}, "y": &Cluster{Name: "y"}}
+	deployment := func(sid, cluster string, instances int) *Deployment {
+		return &Deployment{
+			SourceID:     MustParseSourceID(sid),
+			ClusterName:  cluster,
+			Cluster:      clusters[cluster],
+			DeployConfig: DeployConfig{NumInstances: instances},
+		}
+	}
+	running := func(d *Deployment) *DeployState {
+		return &DeployState{Deployment: *d, Status: DeployStatusActive}
+	}
+
+	dd := NewDummyDeployer()
+	dd.deps = NewDeployStates(
+		running(deployment("github.com/ot/same,1.0.0", "x", 1)),
+		running(deployment("github.com/ot/bump,1.0.0", "x", 1)),
+		running(deployment("github.com/ot/gone,1.0.0", "y", 1)),
+	)
+	intended := NewDeployments(
+		deployment("github.com/ot/same,1.0.0", "x", 1),
+		deployment("github.com/ot/bump,1.1.0", "x", 2),
+		deployment("github.com/ot/new,2.0.0", "y", 1),
+	)
+
+	r := NewResolver(dd, NewDummyRegistry(), &ResolveFilter{}, logging.SilentLogSet())
+	plan, err := r.Plan(intended, clusters)
+	require.NoError(t, err)
+
+	require.Len(t, plan.Clusters, 2)
+	x, y := plan.Clusters[0], plan.Clusters[1]
+	assert.Equal(t, "x", x.Cluster)
+	assert.Equal(t, 1, x.Unchanged)
+	if assert.Len(t, x.Changes, 1) {
+		c := x.Changes[0]
+		assert.Equal(t, PlanModify, c.Action)
+		assert.Equal(t, "1.0.0", c.Prior)
+		assert.Equal(t, "1.1.0", c.Post)
+		assert.Len(t, c.Diffs, 2, "version and instances differ: %v", c.Diffs)
+	}
+	assert.Equal(t, "y", y.Cluster)
+	if assert.Len(t, y.Changes, 2) {
+		assert.Equal(t, PlanDelete, y.Changes[0].Action)
+		assert.Equal(t, "github.com/ot/gone", y.Changes[0].DeploymentID.ManifestID.String())
+		assert.Equal(t, PlanCreate, y.Changes[1].Action)
+		assert.Equal(t, "2.0.0", y.Changes[1].Post)
+	}
+	assert.Equal(t, 1, plan.Count(PlanCreate))
+	assert.False(t, plan.Empty())
+
+	r.ResolveFilter = &ResolveFilter{Cluster: NewResolveFieldMatcher("x"), Repo: NewResolveFieldMatcher("github.com/ot/same")}
+	plan, err = r.Plan(intended, clusters)
+	require.NoError(t, err)
+	assert.True(t, plan.Empty())
+}
+
+func TestResolveFilterQueryMap(t *testing.T) {
+	rf := &ResolveFilter{}
+	assert.Empty(t, rf.QueryMap())
+	assert.True(t, rf.SetQueryField("cluster", "x"))
+	assert.True(t, rf.SetQueryField("repo", "github.com/ot/one"))
+	assert.False(t, rf.SetQueryField("colour", "blue"))
+	assert.Equal(t, map[string]string{"cluster": "x", "repo": "github.com/ot/one"}, rf.QueryMap())
+}
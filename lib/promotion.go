@@ -0,0 +1,150 @@
+package sous
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/samsalisbury/semv"
+)
+
+type (
+	// Promotions is a list of promotion edges between clusters.
+	Promotions []Promotion
+
+	// A Promotion declares that versions stable in one cluster may be
+	// promoted to one or more other clusters, once its Gates pass.
+	Promotion struct {
+		// From is the name of the upstream cluster.
+		From string
+		// To are the names of the downstream clusters.
+		To []string
+		// Gates are the conditions a version in From must meet before it is
+		// promoted.
+		Gates PromotionGates `yaml:",omitempty"`
+	}
+
+	// PromotionGates are the conditions under which a version may be
+	// promoted from one cluster to the next.
+	PromotionGates struct {
+		// SoakTime is the minimum time the version must have been active in
+		// the upstream cluster, as a duration like "30m" or "2h".
+		SoakTime string `yaml:",omitempty"`
+		// RequiredMetadata lists metadata fields which must be set on the
+		// upstream deployment.
+		RequiredMetadata []string `yaml:",omitempty"`
+	}
+
+	// An ActiveVersion is the version of a deployment currently active in a
+	// cluster, and the time it was first seen to be active.
+	ActiveVersion struct {
+		Version semv.Version
+		Since   time.Time
+	}
+
+	// A PromotionBlockedError reports the gates which stop a version being
+	// promoted.
+	PromotionBlockedError struct {
+		From, To string
+		Version  semv.Version
+		Reasons  []string
+	}
+)
+
+// Clone returns a deep copy of this Promotions.
+func (ps Promotions) Clone() Promotions {
+	if ps == nil {
+		return nil
+	}
+	c := make(Promotions, len(ps))
+	for i, p := range ps {
+		p.To = append([]string(nil), p.To...)
+		p.Gates.RequiredMetadata = append([]string(nil), p.Gates.RequiredMetadata...)
+		c[i] = p
+	}
+	return c
+}
+
+// Upstream returns the Promotion leading into the named cluster, if any.
+func (ps Promotions) Upstream(cluster string) (Promotion, bool) {
+	for _, p := range ps {
+		for _, to := range p.To {
+			if to == cluster {
+				return p, true
+			}
+		}
+	}
+	return Promotion{}, false
+}
+
+// Validate checks that each promotion names known clusters, that no cluster
+// has more than one upstream, and that promotions don't form a cycle.
+func (ps Promotions) Validate(clusters Clusters) []Flaw {
+	var flaws []Flaw
+	upstream := map[string]string{}
+	for _, p := range ps {
+		if _, ok := clusters[p.From]; !ok {
+			flaws = append(flaws, FatalFlaw("promotion from unknown cluster %q", p.From))
+		}
+		if len(p.To) == 0 {
+			flaws = append(flaws, FatalFlaw("promotion from %q has no downstream clusters", p.From))
+		}
+		for _, to := range p.To {
+			if _, ok := clusters[to]; !ok {
+				flaws = append(flaws, FatalFlaw("promotion from %q to unknown cluster %q", p.From, to))
+			}
+			if from, ok := upstream[to]; ok && from != p.From {
+				flaws = append(flaws, FatalFlaw("cluster %q is promoted to from both %q and %q", to, from, p.From))
+				continue
+			}
+			upstream[to] = p.From
+		}
+		if p.Gates.SoakTime != "" {
+			if _, err := time.ParseDuration(p.Gates.SoakTime); err != nil {
+				flaws = append(flaws, FatalFlaw("promotion from %q: SoakTime: %v", p.From, err))
+			}
+		}
+	}
+	for cluster := range upstream {
+		seen := map[string]bool{cluster: true}
+		for c, ok := upstream[cluster]; ok; c, ok = upstream[c] {
+			if seen[c] {
+				flaws = append(flaws, FatalFlaw("promotions into cluster %q form a cycle", cluster))
+				break
+			}
+			seen[c] = true
+		}
+	}
+	return flaws
+}
+
+// Check returns a *PromotionBlockedError listing every gate the upstream
+// deployment, active as described by av, fails to pass at time now, for a
+// promotion into the cluster named to.
+func (p Promotion) Check(to string, upstream *Deployment, av ActiveVersion, now time.Time) error {
+	var reasons []string
+	if p.Gates.SoakTime != "" {
+		soak, err := time.ParseDuration(p.Gates.SoakTime)
+		if err != nil {
+			return err
+		}
+		if active := now.Sub(av.Since); active < soak {
+			reasons = append(reasons, fmt.Sprintf("active for %s, less than soak time %s",
+				active.Truncate(time.Second), soak))
+		}
+	}
+	for _, field := range p.Gates.RequiredMetadata {
+		if upstream.Metadata[field] == "" {
+			reasons = append(reasons, fmt.Sprintf("metadata field %q is not set", field))
+		}
+	}
+	if len(reasons) == 0 {
+		return nil
+	}
+	return &PromotionBlockedError{From: p.From, To: to, Version: av.Version, Reasons: reasons}
+}
+
+func (e *PromotionBlockedError) Error() string {
+	return fmt.Sprintf("cannot promote version %s from %s to %s: %s",
+		e.Version, e.From, e.To, strings.Join(e.Reasons, "; "))
+}
@@ -0,0 +1,95 @@
+package sous
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samsalisbury/semv"
+)
+
+func TestPromotions_Validate(t *testing.T) {
+	clusters := Clusters{"ci": {}, "staging": {}, "prod-east": {}, "prod-west": {}}
+
+	valid := Promotions{
+		{From: "ci", To: []string{"staging"}},
+		{From: "staging", To: []string{"prod-east", "prod-west"}, Gates: PromotionGates{SoakTime: "1h"}},
+	}
+	if flaws := valid.Validate(clusters); len(flaws) != 0 {
+		t.Errorf("got flaws %v; want none", flaws)
+	}
+
+	invalid := map[string]Promotions{
+		"unknown cluster":  {{From: "ci", To: []string{"qa"}}},
+		"no downstream":    {{From: "ci"}},
+		"two upstreams":    {{From: "ci", To: []string{"prod-east"}}, {From: "staging", To: []string{"prod-east"}}},
+		"cycle":            {{From: "ci", To: []string{"staging"}}, {From: "staging", To: []string{"ci"}}},
+		"bad soak time":    {{From: "ci", To: []string{"staging"}, Gates: PromotionGates{SoakTime: "soon"}}},
+		"self-promotion":   {{From: "ci", To: []string{"ci"}}},
+		"unknown upstream": {{From: "qa", To: []string{"ci"}}},
+	}
+	for name, ps := range invalid {
+		if flaws := ps.Validate(clusters); len(flaws) == 0 {
+			t.Errorf("%s: got no flaws", name)
+		}
+	}
+}
+
+func TestPromotions_Upstream(t *testing.T) {
+	ps := Promotions{{From: "staging", To: []string{"prod-east", "prod-west"}}}
+	if p, ok := ps.Upstream("prod-west"); !ok || p.From != "staging" {
+		t.Errorf("got upstream %q, %t; want staging, true", p.From, ok)
+	}
+	if _, ok := ps.Upstream("staging"); ok {
+		t.Errorf("got an upstream for staging; want none")
+	}
+}
+
+func TestPromotion_Check(t *testing.T) {
+	now := time.Now()
+	p := Promotion{From: "staging", Gates: PromotionGates{
+		SoakTime:         "30m",
+		RequiredMetadata: []string{"qa-approved"},
+	}}
+	upstream := &Deployment{ClusterName: "staging", DeployConfig: DeployConfig{Metadata: Metadata{}}}
+	av := ActiveVersion{Version: semv.MustParse("1.0.0"), Since: now.Add(-10 * time.Minute)}
+
+	err := p.Check("prod", upstream, av, now)
+	blocked, ok := err.(*PromotionBlockedError)
+	if !ok {
+		t.Fatalf("got error %v; want a *PromotionBlockedError", err)
+	}
+	if len(blocked.Reasons) != 2 {
+		t.Errorf("got reasons %q; want 2", blocked.Reasons)
+	}
+	if !strings.Contains(err.Error(), "from staging to prod") {
+		t.Errorf("got error %q; want it to name the clusters", err)
+	}
+
+	upstream.Metadata["qa-approved"] = "yes"
+	av.Since = now.Add(-time.Hour)
+	if err := p.Check("prod", upstream, av, now); err != nil {
+		t.Errorf("got error %v; want none", err)
+	}
+}
+
+func TestKnownGoodVersions_Active(t *testing.T) {
+	kg := NewKnownGoodVersions()
+	did := DeploymentID{ManifestID: ManifestID{Source: project1}, Cluster: "cluster-1"}
+
+	kg.observe(rollbackTestPair("1.0.0", "1.0.0", DeployStatusActive))
+	first, ok := kg.Active(did)
+	if !ok || first.Version.String() != "1.0.0" {
+		t.Fatalf("got %v, %t; want 1.0.0 active", first, ok)
+	}
+
+	kg.observe(rollbackTestPair("1.0.0", "1.0.0", DeployStatusActive))
+	if again, _ := kg.Active(did); !again.Since.Equal(first.Since) {
+		t.Errorf("active since moved from %s to %s while still active", first.Since, again.Since)
+	}
+
+	kg.observe(rollbackTestPair("1.0.0", "2.0.0", DeployStatusPending))
+	if av, ok := kg.Active(did); ok {
+		t.Errorf("got %v active while pending", av)
+	}
+}
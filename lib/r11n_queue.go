@@ -2,9 +2,13 @@ package sous
 
 import (
 	"container/ring"
+	"sort"
 	"sync"
+	"time"
 
+	"github.com/opentable/sous/util/logging"
 	"github.com/pborman/uuid"
+	"github.com/pkg/errors"
 )
 
 // MaxRefsPerR11nQueue is the maximum number of rectifications to cache in memory.
@@ -18,6 +22,9 @@ type R11nQueue struct {
 	fifoRefs      *ring.Ring
 	handler       func(*QueuedR11n) DiffResolution
 	start         bool
+	store         R11nQueueStore
+	owner         string
+	log           logging.LogSink
 	sync.Mutex
 }
 
@@ -58,6 +65,17 @@ func R11nQueueStartWithHandler(handler func(*QueuedR11n) DiffResolution) R11nQue
 	}
 }
 
+// R11nQueuePersistTo records each rectification queued in store, as owned by
+// owner, until it has been handled or cancelled. Errors from store are
+// reported to ls, and do not stop the queue.
+func R11nQueuePersistTo(store R11nQueueStore, owner string, ls logging.LogSink) R11nQueueOpt {
+	return func(rq *R11nQueue) {
+		rq.store = store
+		rq.owner = owner
+		rq.log = ls
+	}
+}
+
 func (rq *R11nQueue) init() *R11nQueue {
 	rq.Lock()
 	defer rq.Unlock()
@@ -73,7 +91,20 @@ type QueuedR11n struct {
 	ID            R11nID
 	Pos           int
 	Rectification *Rectification
-	done          chan struct{}
+	// Queued is when the rectification was queued.
+	Queued    time.Time
+	done      chan struct{}
+	cancelled bool
+}
+
+func (qr *QueuedR11n) record(owner string) R11nRecord {
+	return R11nRecord{
+		ID:           qr.ID,
+		DeploymentID: qr.Rectification.Pair.ID(),
+		Change:       qr.Rectification.Pair.Kind().ExpectedResolutionType(),
+		Queued:       qr.Queued,
+		Owner:        owner,
+	}
 }
 
 // R11nID is a QueuedR11n identifier.
@@ -92,11 +123,17 @@ func (rq *R11nQueue) Start(handler func(*QueuedR11n) DiffResolution) <-chan Diff
 	go func() {
 		for {
 			qr := rq.next()
-			results <- handler(qr)
+			rq.Lock()
+			cancelled := qr.cancelled
+			rq.Unlock()
+			if !cancelled {
+				results <- handler(qr)
+			}
 			rq.Lock()
 			close(qr.done)
 			delete(rq.refs, qr.ID)
 			rq.Unlock()
+			rq.dequeued(qr.ID)
 		}
 	}()
 	return results
@@ -135,8 +172,14 @@ func (rq *R11nQueue) internalPush(r *Rectification) *QueuedR11n {
 		ID:            id,
 		Pos:           len(rq.queue),
 		Rectification: r,
+		Queued:        time.Now(),
 		done:          make(chan struct{}),
 	}
+	if rq.store != nil {
+		if err := rq.store.Queued(qr.record(rq.owner)); err != nil {
+			logging.ReportError(rq.log, errors.Wrapf(err, "recording rectification %s", id))
+		}
+	}
 	rq.refs[id] = qr
 	rq.allRefs[id] = qr
 	rq.fifoRefs = rq.fifoRefs.Next()
@@ -163,6 +206,53 @@ func (rq *R11nQueue) PushIfEmpty(r *Rectification) (*QueuedR11n, bool) {
 	return rq.internalPush(r), true
 }
 
+// Cancel cancels the queued rectification with id, so that it is never
+// begun; waiters receive a resolution with an error. It returns false if id
+// is not queued here, and an *R11nInFlightError if it has already begun.
+func (rq *R11nQueue) Cancel(id R11nID) (bool, error) {
+	rq.Lock()
+	defer rq.Unlock()
+	qr, ok := rq.refs[id]
+	if !ok || qr.cancelled {
+		return false, nil
+	}
+	if qr.Pos < 0 {
+		return false, &R11nInFlightError{ID: id}
+	}
+	qr.cancelled = true
+	qr.Rectification.Resolution = DiffResolution{
+		DeploymentID: qr.Rectification.Pair.ID(),
+		Desc:         qr.Rectification.Pair.Kind().ExpectedResolutionType(),
+		Error:        WrapResolveError(errors.Errorf("rectification %s cancelled", id)),
+	}
+	rq.dequeued(id)
+	return true, nil
+}
+
+// Entries returns the rectifications which are queued or in progress, and
+// not cancelled, in the order they will be handled.
+func (rq *R11nQueue) Entries() []R11nQueueEntry {
+	rq.Lock()
+	defer rq.Unlock()
+	entries := []R11nQueueEntry{}
+	for _, qr := range rq.refs {
+		if !qr.cancelled {
+			entries = append(entries, R11nQueueEntry{R11nRecord: qr.record(rq.owner), Pos: qr.Pos})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Pos < entries[j].Pos })
+	return entries
+}
+
+func (rq *R11nQueue) dequeued(id R11nID) {
+	if rq.store == nil {
+		return
+	}
+	if err := rq.store.Dequeued(id); err != nil {
+		logging.ReportError(rq.log, errors.Wrapf(err, "removing the record of rectification %s", id))
+	}
+}
+
 // Len returns the current number of items in the queue.
 func (rq *R11nQueue) Len() int {
 	return len(rq.queue)
@@ -1,19 +1,35 @@
 package sous
 
-import "sync"
+import (
+	"sort"
+	"sync"
+	"time"
+)
 
 // R11nQueueSet is a concurrency-safe mapping of DeploymentID to R11nQueue.
 type R11nQueueSet struct {
 	set  map[DeploymentID]*R11nQueue
 	opts []R11nQueueOpt
+	// proto has opts applied, but is never started; it holds the store the
+	// queues share, if any.
+	proto *R11nQueue
+	// created is when this set was created; rectifications queued before
+	// then were queued by another.
+	created time.Time
 	sync.RWMutex
 }
 
 // NewR11nQueueSet returns a ready to use R11nQueueSet.
 func NewR11nQueueSet(opts ...R11nQueueOpt) *R11nQueueSet {
+	proto := &R11nQueue{}
+	for _, opt := range opts {
+		opt(proto)
+	}
 	return &R11nQueueSet{
-		set:  map[DeploymentID]*R11nQueue{},
-		opts: opts,
+		set:     map[DeploymentID]*R11nQueue{},
+		opts:    opts,
+		proto:   proto,
+		created: time.Now(),
 	}
 }
 
@@ -43,3 +59,69 @@ func (rqs *R11nQueueSet) Wait(did DeploymentID, id R11nID) (DiffResolution, bool
 	}
 	return rq.Wait(id)
 }
+
+// Entries returns the rectifications queued or in progress for every
+// DeploymentID, ordered by DeploymentID and then position.
+func (rqs *R11nQueueSet) Entries() []R11nQueueEntry {
+	rqs.RLock()
+	defer rqs.RUnlock()
+	entries := []R11nQueueEntry{}
+	for _, rq := range rqs.set {
+		entries = append(entries, rq.Entries()...)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].DeploymentID.String() < entries[j].DeploymentID.String()
+	})
+	return entries
+}
+
+// Cancel cancels the queued rectification with id, in whichever queue it is.
+// It returns false if no queue holds it, and an *R11nInFlightError if it has
+// already begun.
+func (rqs *R11nQueueSet) Cancel(id R11nID) (bool, error) {
+	rqs.RLock()
+	defer rqs.RUnlock()
+	for _, rq := range rqs.set {
+		if ok, err := rq.Cancel(id); ok || err != nil {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+// TakeInterrupted returns the records in the store of rectifications queued
+// before this set was created, i.e. by an earlier process which never
+// finished them, oldest first. Only records with this set's owner (or none)
+// of deployments rf matches are taken, since other servers may share the
+// store. They are removed from the store, so each is taken only once.
+// Without a store, there is nothing to take.
+func (rqs *R11nQueueSet) TakeInterrupted(rf *ResolveFilter) ([]R11nRecord, error) {
+	store := rqs.proto.store
+	if store == nil {
+		return nil, nil
+	}
+	if rf == nil {
+		rf = &ResolveFilter{}
+	}
+	pending, err := store.Pending()
+	if err != nil {
+		return nil, err
+	}
+	interrupted := []R11nRecord{}
+	for _, rec := range pending {
+		if !rec.Queued.Before(rqs.created) {
+			continue
+		}
+		if rec.Owner != "" && rec.Owner != rqs.proto.owner {
+			continue
+		}
+		if !rf.FilterManifestID(rec.DeploymentID.ManifestID) || !rf.FilterClusterName(rec.DeploymentID.Cluster) {
+			continue
+		}
+		if err := store.Dequeued(rec.ID); err != nil {
+			return interrupted, err
+		}
+		interrupted = append(interrupted, rec)
+	}
+	return interrupted, nil
+}
@@ -4,6 +4,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/opentable/sous/util/logging"
 )
 
 func TestR11nQueueSet_PushIfEmpty(t *testing.T) {
@@ -111,3 +113,62 @@ func TestR11nQueueSet_Wait(t *testing.T) {
 		}
 	}
 }
+
+func TestR11nQueueSet_TakeInterrupted(t *testing.T) {
+	store := NewMemoryR11nQueueStore()
+	rf := &ResolveFilter{Cluster: NewResolveFieldMatcher("left")}
+	record := func(repo, cluster, owner string) R11nRecord {
+		rec := R11nRecord{
+			ID:           NewR11nID(),
+			DeploymentID: DeploymentID{ManifestID: MustParseManifestID(repo), Cluster: cluster},
+			Change:       ModifyDiff,
+			Queued:       time.Now().Add(-time.Minute),
+			Owner:        owner,
+		}
+		store.Queued(rec)
+		return rec
+	}
+	earlier := record("one", "left", rf.String())
+	// Records made before owners were, are taken if they match.
+	unowned := record("three", "left", "")
+	// Other servers' records are left alone.
+	others := record("four", "left", "another server")
+	unmatched := record("five", "right", "")
+
+	proceed := make(chan struct{})
+	defer close(proceed)
+	rqs := NewR11nQueueSet(
+		R11nQueuePersistTo(store, rf.String(), logging.SilentLogSet()),
+		R11nQueueStartWithHandler(func(*QueuedR11n) DiffResolution {
+			<-proceed
+			return DiffResolution{}
+		}))
+	qr, _ := rqs.PushIfEmpty(makeTestR11nWithRepo("two"))
+
+	if entries := rqs.Entries(); len(entries) != 1 || entries[0].ID != qr.ID {
+		t.Errorf("got entries %v; want only %s", entries, qr.ID)
+	}
+
+	interrupted, err := rqs.TakeInterrupted(rf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(interrupted) != 2 || interrupted[0].ID != earlier.ID && interrupted[1].ID != earlier.ID ||
+		interrupted[0].ID != unowned.ID && interrupted[1].ID != unowned.ID {
+		t.Errorf("got interrupted %v; want %v and %v", interrupted, earlier, unowned)
+	}
+	if again, _ := rqs.TakeInterrupted(rf); len(again) != 0 {
+		t.Errorf("took %v again", again)
+	}
+	pending, _ := store.Pending()
+	left := map[R11nID]R11nRecord{}
+	for _, rec := range pending {
+		left[rec.ID] = rec
+	}
+	if len(pending) != 3 || left[others.ID] != others || left[unmatched.ID] != unmatched {
+		t.Errorf("got pending %v; want %s, %s and %s", pending, qr.ID, others.ID, unmatched.ID)
+	}
+	if left[qr.ID].Owner != rf.String() {
+		t.Errorf("got owner %q for %s; want %q", left[qr.ID].Owner, qr.ID, rf.String())
+	}
+}
@@ -0,0 +1,99 @@
+package sous
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+type (
+	// An R11nQueueStore keeps a record of each queued rectification until it
+	// has been handled or cancelled, so that rectifications interrupted by a
+	// restart can be found and resumed.
+	R11nQueueStore interface {
+		// Queued records that a rectification has been queued.
+		Queued(R11nRecord) error
+		// Dequeued removes the record of the rectification with id, once it
+		// has been handled or cancelled. Removing an unknown id is not an
+		// error.
+		Dequeued(id R11nID) error
+		// Pending returns the records of every rectification queued and not
+		// yet dequeued, oldest first.
+		Pending() ([]R11nRecord, error)
+	}
+
+	// An R11nRecord describes a queued rectification.
+	R11nRecord struct {
+		ID           R11nID
+		DeploymentID DeploymentID
+		// Change is the change the rectification makes: created, updated or
+		// deleted.
+		Change ResolutionType
+		// Queued is when the rectification was queued.
+		Queued time.Time
+		// Owner identifies the resolver which queued the rectification, as
+		// the String of its ResolveFilter, since servers resolving
+		// different clusters may share a store. It is empty for records
+		// made before owners were recorded.
+		Owner string
+	}
+
+	// An R11nQueueEntry is an R11nRecord with its place in the queue for
+	// its DeploymentID.
+	R11nQueueEntry struct {
+		R11nRecord
+		// Pos is the number of rectifications ahead of this one; it is -1
+		// once this one has begun.
+		Pos int
+	}
+
+	// MemoryR11nQueueStore is an R11nQueueStore which keeps its records in
+	// memory, and so forgets them on a restart.
+	MemoryR11nQueueStore struct {
+		mu      sync.Mutex
+		records map[R11nID]R11nRecord
+	}
+
+	// R11nInFlightError is returned when cancelling a rectification which
+	// has already begun.
+	R11nInFlightError struct {
+		ID R11nID
+	}
+)
+
+func (e *R11nInFlightError) Error() string {
+	return "rectification " + string(e.ID) + " has already begun"
+}
+
+// NewMemoryR11nQueueStore returns an empty MemoryR11nQueueStore.
+func NewMemoryR11nQueueStore() *MemoryR11nQueueStore {
+	return &MemoryR11nQueueStore{records: map[R11nID]R11nRecord{}}
+}
+
+// Queued implements R11nQueueStore.
+func (ms *MemoryR11nQueueStore) Queued(rec R11nRecord) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.records[rec.ID] = rec
+	return nil
+}
+
+// Dequeued implements R11nQueueStore.
+func (ms *MemoryR11nQueueStore) Dequeued(id R11nID) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	delete(ms.records, id)
+	return nil
+}
+
+// Pending implements R11nQueueStore.
+func (ms *MemoryR11nQueueStore) Pending() ([]R11nRecord, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	recs := make([]R11nRecord, 0, len(ms.records))
+	for _, rec := range ms.records {
+		recs = append(recs, rec)
+	}
+	sort.Slice(recs, func(i, j int) bool { return recs[i].Queued.Before(recs[j].Queued) })
+	return recs, nil
+}
@@ -6,6 +6,8 @@ import (
 	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/opentable/sous/util/logging"
 )
 
 // Test synchronous behaviour of the queue.
@@ -364,3 +366,59 @@ func checkR11nHasRepo(repo string) func(*QueuedR11n) error {
 		return nil
 	}
 }
+
+func TestR11nQueue_Cancel(t *testing.T) {
+	store := NewMemoryR11nQueueStore()
+	proceed := make(chan struct{})
+	begun := make(chan R11nID, 2)
+	rq := NewR11nQueue(
+		R11nQueuePersistTo(store, "", logging.SilentLogSet()),
+		R11nQueueStartWithHandler(func(qr *QueuedR11n) DiffResolution {
+			begun <- qr.ID
+			<-proceed
+			return DiffResolution{}
+		}))
+
+	first, _ := rq.Push(makeTestR11nWithRepo("one"))
+	second, _ := rq.Push(makeTestR11nWithRepo("one"))
+	if id := <-begun; id != first.ID {
+		t.Fatalf("began %s; want %s", id, first.ID)
+	}
+
+	if _, err := rq.Cancel(first.ID); err == nil {
+		t.Errorf("cancelled rectification %s after it began", first.ID)
+	}
+	if ok, err := rq.Cancel(second.ID); !ok || err != nil {
+		t.Fatalf("Cancel(%s) = %t, %v; want true, nil", second.ID, ok, err)
+	}
+	if ok, _ := rq.Cancel(second.ID); ok {
+		t.Errorf("cancelled %s twice", second.ID)
+	}
+
+	entries := rq.Entries()
+	if len(entries) != 1 || entries[0].ID != first.ID || entries[0].Pos != -1 {
+		t.Errorf("got entries %v; want only %s, begun", entries, first.ID)
+	}
+	if pending, _ := store.Pending(); len(pending) != 1 || pending[0].ID != first.ID {
+		t.Errorf("got pending %v; want only %s", pending, first.ID)
+	}
+
+	close(proceed)
+	rez, ok := rq.Wait(second.ID)
+	if !ok || rez.Error == nil {
+		t.Errorf("got %v, %t waiting for the cancelled rectification; want an error", rez, ok)
+	}
+	select {
+	case id := <-begun:
+		t.Errorf("began cancelled rectification %s", id)
+	default:
+	}
+	rq.Wait(first.ID)
+	for i := 0; i < 100; i++ {
+		if pending, _ := store.Pending(); len(pending) == 0 {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Error("the handled rectification was never removed from the store")
+}
@@ -129,18 +129,31 @@ func IsTransientResolveError(err error) bool {
 			return true
 		case "*sous.CreateError":
 			return true
+		case "*sous.DependencyError":
+			return true
 		}
 
 	case *FailedStatusError:
 		// Anything but SUCCEEDED on Singularity is a failure for this deploy.
-		// There's no expectation that it will self correct. In the future, we
-		// should do a automatic rollback.
+		// There's no expectation that it will self correct, although the
+		// server may roll it back: see RollbackPolicy.
 		return false
 	case *UnacceptableAdvisory:
 		// UnacceptableAdvisory is excluded, since this requires operator
 		// intervention: either the image needs to be rebuilt clean, or the cluster
 		// reconfigured to accept the advisory.
 		return false
+	case *DependencyError:
+		// DependencyError clears by itself once the dependency is active.
+		return true
+	case *VerificationError:
+		// VerificationError isn't transient: the version failed its probes,
+		// and won't be probed again until it changes.
+		return false
+	case *FrozenError:
+		// FrozenError isn't transient: the freeze may last a long time, and
+		// the change needs an owner to override it, or to wait and retry.
+		return false
 	case *MissingImageNameError:
 		// MissingImageNameError isn't transient: it requires that an appropriate
 		// image be built with the desired name and the server needs to be able to
@@ -0,0 +1,151 @@
+package sous
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// A ResolveEvent is published as a resolve cycle progresses: when it
+	// enters a phase, and for each DiffResolution it records.
+	ResolveEvent struct {
+		Type ResolveEventType
+		Time time.Time
+		// Phase is the phase entered, for PhaseEvents.
+		Phase string `json:",omitempty"`
+		// Resolution is the resolution recorded, for ResolutionEvents.
+		Resolution *DiffResolution `json:",omitempty"`
+	}
+
+	// ResolveEventType is the kind of a ResolveEvent.
+	ResolveEventType string
+
+	// ResolveEvents publishes ResolveEvents to its subscribers. A nil
+	// *ResolveEvents publishes nothing.
+	ResolveEvents struct {
+		mu   sync.Mutex
+		subs map[*ResolveEventSubscription]struct{}
+	}
+
+	// A ResolveEventSubscription receives the ResolveEvents matching its
+	// filter on C, until it is closed.
+	ResolveEventSubscription struct {
+		C      <-chan ResolveEvent
+		c      chan ResolveEvent
+		filter *ResolveFilter
+		events *ResolveEvents
+		once   sync.Once
+	}
+)
+
+const (
+	// PhaseEvent is published when a resolve cycle enters a phase. The last
+	// phase of a successful cycle is "finished".
+	PhaseEvent = ResolveEventType("phase")
+	// ResolutionEvent is published for each DiffResolution of a resolve
+	// cycle.
+	ResolutionEvent = ResolveEventType("resolution")
+)
+
+// resolveEventBuffer is the number of events a subscriber may fall behind
+// by before further events to it are dropped.
+const resolveEventBuffer = 100
+
+// NewResolveEvents returns a ResolveEvents with no subscribers.
+func NewResolveEvents() *ResolveEvents {
+	return &ResolveEvents{subs: map[*ResolveEventSubscription]struct{}{}}
+}
+
+// Subscribe returns a subscription to the resolution events for deployments
+// matching rf, and to every phase event. A nil rf matches every deployment.
+// The subscription must be closed when no longer needed.
+func (re *ResolveEvents) Subscribe(rf *ResolveFilter) *ResolveEventSubscription {
+	c := make(chan ResolveEvent, resolveEventBuffer)
+	sub := &ResolveEventSubscription{C: c, c: c, filter: rf, events: re}
+	re.mu.Lock()
+	defer re.mu.Unlock()
+	re.subs[sub] = struct{}{}
+	return sub
+}
+
+// Close ends the subscription, and closes C.
+func (sub *ResolveEventSubscription) Close() {
+	sub.once.Do(func() {
+		sub.events.mu.Lock()
+		defer sub.events.mu.Unlock()
+		delete(sub.events.subs, sub)
+		close(sub.c)
+	})
+}
+
+func (sub *ResolveEventSubscription) matches(ev ResolveEvent) bool {
+	if ev.Resolution == nil || sub.filter == nil {
+		return true
+	}
+	did := ev.Resolution.DeploymentID
+	return sub.filter.FilterManifestID(did.ManifestID) && sub.filter.FilterClusterName(did.Cluster)
+}
+
+// Publish sends ev to each matching subscriber. Events to subscribers which
+// have fallen too far behind are dropped rather than holding up the resolve.
+func (re *ResolveEvents) Publish(ev ResolveEvent) {
+	if re == nil {
+		return
+	}
+	re.mu.Lock()
+	defer re.mu.Unlock()
+	for sub := range re.subs {
+		if !sub.matches(ev) {
+			continue
+		}
+		select {
+		case sub.c <- ev:
+		default:
+		}
+	}
+}
+
+func (re *ResolveEvents) publishPhase(phase string) {
+	re.Publish(ResolveEvent{Type: PhaseEvent, Time: time.Now(), Phase: phase})
+}
+
+func (re *ResolveEvents) publishResolution(rez DiffResolution) {
+	re.Publish(ResolveEvent{Type: ResolutionEvent, Time: time.Now(), Resolution: &rez})
+}
+
+// readResolveEvents decodes the server-sent events stream in r, sending each
+// ResolveEvent on events, until r ends or done is closed. Only the data lines
+// of each event are read; comments and other fields are ignored.
+func readResolveEvents(r io.Reader, events chan<- ResolveEvent, done <-chan struct{}) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	data := []string{}
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line != "" {
+			if strings.HasPrefix(line, "data:") {
+				data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+			}
+			continue
+		}
+		if len(data) == 0 {
+			continue
+		}
+		ev := ResolveEvent{}
+		err := json.Unmarshal([]byte(strings.Join(data, "\n")), &ev)
+		data = data[:0]
+		if err != nil {
+			return err
+		}
+		select {
+		case events <- ev:
+		case <-done:
+			return nil
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,250 @@
+package sous
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/restful"
+)
+
+func TestResolveEvents_filter(t *testing.T) {
+	events := NewResolveEvents()
+	rf := &ResolveFilter{
+		Repo:    NewResolveFieldMatcher("github.com/example/one"),
+		Cluster: NewResolveFieldMatcher("left"),
+	}
+	sub := events.Subscribe(rf)
+	all := events.Subscribe(nil)
+
+	did := func(repo, cluster string) DeploymentID {
+		return DeploymentID{ManifestID: MustParseManifestID(repo), Cluster: cluster}
+	}
+	events.publishResolution(DiffResolution{DeploymentID: did("github.com/example/two", "left"), Desc: StableDiff})
+	events.publishResolution(DiffResolution{DeploymentID: did("github.com/example/one", "right"), Desc: StableDiff})
+	events.publishResolution(DiffResolution{DeploymentID: did("github.com/example/one", "left"), Desc: ModifyDiff})
+	events.publishPhase("finished")
+
+	sub.Close()
+	sub.Close()
+	events.publishPhase("after close")
+	all.Close()
+
+	got := []string{}
+	for ev := range sub.C {
+		if ev.Type == ResolutionEvent {
+			got = append(got, ev.Resolution.DeploymentID.String())
+		} else {
+			got = append(got, ev.Phase)
+		}
+	}
+	want := []string{"left:github.com/example/one", "finished"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("filtered subscription got %q; want %q", got, want)
+	}
+
+	n := 0
+	for range all.C {
+		n++
+	}
+	if n != 5 {
+		t.Errorf("unfiltered subscription got %d events; want 5", n)
+	}
+
+	// A nil ResolveEvents publishes nothing.
+	var none *ResolveEvents
+	none.publishPhase("ignored")
+}
+
+func TestResolveRecorder_publishesEvents(t *testing.T) {
+	events := NewResolveEvents()
+	sub := events.Subscribe(nil)
+	defer sub.Close()
+
+	rez := DiffResolution{DeploymentID: DeploymentID{Cluster: "left"}, Desc: CreateDiff}
+	rr := newResolveRecorder(NewDeployments(), events, func(rr *ResolveRecorder) {
+		rr.performGuaranteedPhase("first", func() {})
+		rr.Log <- rez
+		close(rr.Log)
+	})
+	if err := rr.Wait(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := []string{}
+	timeout := time.After(time.Second)
+	for len(got) < 3 {
+		select {
+		case <-timeout:
+			t.Fatalf("got events %q; timed out waiting for more", got)
+		case ev := <-sub.C:
+			switch ev.Type {
+			case PhaseEvent:
+				got = append(got, ev.Phase)
+			case ResolutionEvent:
+				got = append(got, string(ev.Resolution.Desc))
+			}
+		}
+	}
+	// The resolution and the final phase are published by different
+	// goroutines, so only the first event's position is certain.
+	if got[0] != "first" {
+		t.Errorf("got first event %q; want phase %q", got[0], "first")
+	}
+	joined := strings.Join(got, ",")
+	if !strings.Contains(joined, "created") || !strings.Contains(joined, "finished") {
+		t.Errorf("got events %q; want a created resolution and the finished phase", got)
+	}
+}
+
+func TestReadResolveEvents(t *testing.T) {
+	stream := ":\n\n" +
+		"event: phase\ndata: {\"Type\":\"phase\",\"Phase\":\"generating diff\"}\n\n" +
+		"event: resolution\ndata: {\"Type\":\"resolution\",\n" +
+		"data: \"Resolution\":{\"Cluster\":\"left\",\"Desc\":\"updated\"}}\n\n"
+	events := make(chan ResolveEvent, 2)
+	if err := readResolveEvents(strings.NewReader(stream), events, nil); err != nil {
+		t.Fatal(err)
+	}
+	close(events)
+
+	got := []ResolveEvent{}
+	for ev := range events {
+		got = append(got, ev)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d events; want 2", len(got))
+	}
+	if got[0].Phase != "generating diff" {
+		t.Errorf("got phase %q; want %q", got[0].Phase, "generating diff")
+	}
+	if got[1].Resolution == nil || got[1].Resolution.Desc != ModifyDiff || got[1].Resolution.Cluster != "left" {
+		t.Errorf("got resolution %v; want left updated", got[1].Resolution)
+	}
+}
+
+func TestSubPoller_pollsOnEvents(t *testing.T) {
+	repoName := "github.com/opentable/example"
+	publish := make(chan struct{})
+	stable := make(chan struct{})
+	queries := make(chan string, 1)
+
+	status := func(desc string) string {
+		return `{
+			"completed": {
+				"intended": [{"clustername": "main", "sourceid": {"location": "` + repoName + `", "version": "1.0.1"}}],
+				"log": [{"manifestid": "` + repoName + `", "desc": "` + desc + `"}]
+			}
+		}`
+	}
+
+	h := func(rw http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		default:
+			http.NotFound(rw, r)
+		case "/status":
+			select {
+			case <-stable:
+				rw.Write([]byte(status("unchanged")))
+			default:
+				rw.Write([]byte(status("updated")))
+			}
+		case "/events":
+			queries <- r.URL.RawQuery
+			rw.Header().Set("Content-Type", "text/event-stream")
+			rw.WriteHeader(http.StatusOK)
+			rw.(http.Flusher).Flush()
+			select {
+			case <-r.Context().Done():
+				return
+			case <-publish:
+			}
+			close(stable)
+			rw.Write([]byte("event: phase\ndata: {\"Type\":\"phase\",\"Phase\":\"finished\"}\n\n"))
+			rw.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}
+	}
+	srv := httptest.NewServer(http.HandlerFunc(h))
+	defer srv.Close()
+
+	rf := &ResolveFilter{Repo: NewResolveFieldMatcher(repoName)}
+	rf.SetTag("1.0.1")
+	sub, err := newSubPoller(nil, "main", srv.URL, rf, User{}, logging.SilentLogSet())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := make(chan pollResult)
+	done := make(chan struct{})
+	ctx, cancel := context.WithTimeout(context.Background(), EventsPollTimeout/2)
+	defer cancel()
+	go sub.start(results, done)
+	defer close(done)
+
+	next := func() ResolveState {
+		select {
+		case <-ctx.Done():
+			t.Fatalf("timed out waiting for a poll")
+		case r := <-results:
+			return r.stat
+		}
+		return ResolveNotPolled
+	}
+
+	if s := next(); s != ResolveNotPolled {
+		t.Errorf("got %s; want %s", s, ResolveNotPolled)
+	}
+	if q := <-queries; q != "cluster=main&repo="+strings.Replace(repoName, "/", "%2F", -1) {
+		t.Errorf("got /events query %q", q)
+	}
+	if s := next(); s != ResolveInProgress {
+		t.Errorf("got %s; want %s", s, ResolveInProgress)
+	}
+	close(publish)
+	// The event prompts a poll long before EventsPollTimeout.
+	if s := next(); s != ResolveComplete {
+		t.Errorf("got %s after event; want %s", s, ResolveComplete)
+	}
+}
+
+func TestSubPoller_signsEvents(t *testing.T) {
+	auth := restful.AuthConfig{HMACSecret: "secret"}
+	authErrs := make(chan error, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		id, err := auth.Authenticator().Authenticate(r)
+		if id == nil && err == nil {
+			err = fmt.Errorf("no credentials")
+		}
+		authErrs <- err
+		http.Error(rw, "done", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	parent, err := restful.NewClient("http://sous.example.com/", logging.SilentLogSet())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parent.SetAuth(auth); err != nil {
+		t.Fatal(err)
+	}
+	rf := &ResolveFilter{Repo: NewResolveFieldMatcher("github.com/opentable/example")}
+	sub, err := newSubPoller(parent, "main", srv.URL, rf, User{}, logging.SilentLogSet())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	if _, err := sub.subscribe(done); err == nil {
+		t.Errorf("subscribe succeeded; want the server's error")
+	}
+	if err := <-authErrs; err != nil {
+		t.Errorf("GET /events wasn't authenticated: %s", err)
+	}
+}
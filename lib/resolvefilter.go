@@ -135,6 +135,71 @@ func (rf *ResolveFilter) DeploymentID(mid ManifestID) (DeploymentID, error) {
 	return DeploymentID{ManifestID: mid, Cluster: rf.Cluster.ValueOr("<no-cluster!>")}, nil
 }
 
+// QueryMap returns the fields of rf which match particular values, keyed by
+// the names of the query parameters which select them in the HTTP API.
+func (rf *ResolveFilter) QueryMap() map[string]string {
+	q := map[string]string{}
+	for name, m := range rf.queryFields() {
+		if !m.All() {
+			q[name] = *m.Match
+		}
+	}
+	return q
+}
+
+// SetQueryField sets the field of rf selected by the query parameter name to
+// match value. It returns false if name selects no field.
+func (rf *ResolveFilter) SetQueryField(name, value string) bool {
+	m, ok := rf.queryFields()[name]
+	if ok {
+		*m = NewResolveFieldMatcher(value)
+	}
+	return ok
+}
+
+// DeploymentFilter returns a ResolveFilter which matches only the deployment
+// with did.
+func DeploymentFilter(did DeploymentID) *ResolveFilter {
+	return &ResolveFilter{
+		Repo:    NewResolveFieldMatcher(did.ManifestID.Source.Repo),
+		Offset:  NewResolveFieldMatcher(did.ManifestID.Source.Dir),
+		Flavor:  NewResolveFieldMatcher(did.ManifestID.Flavor),
+		Cluster: NewResolveFieldMatcher(did.Cluster),
+	}
+}
+
+// Narrowed returns a filter which matches only what both rf and by match.
+// It returns false if there is nothing they could both match. A nil rf
+// matches everything.
+func (rf *ResolveFilter) Narrowed(by *ResolveFilter) (*ResolveFilter, bool) {
+	narrowed := &ResolveFilter{}
+	if rf != nil {
+		*narrowed = *rf
+	}
+	fields := narrowed.queryFields()
+	for name, m := range by.queryFields() {
+		if m.All() {
+			continue
+		}
+		if have := fields[name]; !have.All() && *have.Match != *m.Match {
+			return nil, false
+		}
+		*fields[name] = *m
+	}
+	return narrowed, true
+}
+
+func (rf *ResolveFilter) queryFields() map[string]*ResolveFieldMatcher {
+	return map[string]*ResolveFieldMatcher{
+		"repo":     &rf.Repo,
+		"offset":   &rf.Offset,
+		"tag":      &rf.Tag,
+		"revision": &rf.Revision,
+		"flavor":   &rf.Flavor,
+		"cluster":  &rf.Cluster,
+	}
+}
+
 func (rf *ResolveFilter) String() string {
 
 	return fmt.Sprintf(
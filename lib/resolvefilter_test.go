@@ -70,3 +70,27 @@ func TestGetDeployID(t *testing.T) {
 		}
 	})
 }
+
+func TestResolveFilter_Narrowed(t *testing.T) {
+	server := &ResolveFilter{Cluster: NewResolveFieldMatcher("left")}
+
+	rf, ok := server.Narrowed(&ResolveFilter{Repo: NewResolveFieldMatcher("github.com/example/one")})
+	if !ok {
+		t.Fatal("want a narrowed filter")
+	}
+	if *rf.Repo.Match != "github.com/example/one" || *rf.Cluster.Match != "left" {
+		t.Errorf("got %s; want both repo and cluster", rf)
+	}
+	if !server.Repo.All() {
+		t.Errorf("narrowing changed the original filter to %s", server)
+	}
+
+	if _, ok := server.Narrowed(&ResolveFilter{Cluster: NewResolveFieldMatcher("right")}); ok {
+		t.Error("want no overlap between clusters left and right")
+	}
+
+	var all *ResolveFilter
+	if rf, ok := all.Narrowed(server); !ok || *rf.Cluster.Match != "left" {
+		t.Errorf("got %s, %t narrowing nil; want cluster left", rf, ok)
+	}
+}
@@ -3,6 +3,7 @@ package sous
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/opentable/sous/util/logging"
 )
@@ -14,7 +15,30 @@ type (
 		Deployer Deployer
 		Registry Registry
 		*ResolveFilter
-		ls logging.LogSink
+		// KnownGood, if not nil, records the versions of deployments seen to
+		// be active or failed.
+		KnownGood *KnownGoodVersions
+		// Verifier, if not nil, runs the Verify probes of deployments once
+		// they are active.
+		Verifier *Verifier
+		// Freezes are the deployment freezes which hold changes in frozen
+		// clusters.
+		Freezes Freezes
+		// FreezeOverrides are the changes made despite Freezes, which aren't
+		// held.
+		FreezeOverrides []FreezeOverride
+		// Dependencies hold changes to deployments until those they depend on
+		// are active.
+		Dependencies Dependencies
+		// DeletionBrake, if not nil, halts cycles which would delete too
+		// many deployments.
+		DeletionBrake *DeletionBrake
+		// Events, if not nil, publishes the progress of each resolve cycle.
+		Events *ResolveEvents
+		// QueueSet, if not nil, queues the rectifications this Resolver
+		// makes, rather than the process-wide queue set. See UseQueueStore.
+		QueueSet *R11nQueueSet
+		ls       logging.LogSink
 	}
 
 	// DeploymentPredicate takes a *Deployment and returns true if the
@@ -33,24 +57,45 @@ func NewResolver(d Deployer, r Registry, rf *ResolveFilter, ls logging.LogSink)
 	}
 }
 
-var globalQueueSet *R11nQueueSet
+var (
+	globalQueueSet     *R11nQueueSet
+	globalQueueSetOnce sync.Once
+)
+
+// UseQueueStore gives r a queue set of its own, which records the
+// rectifications it queues in store, as owned by r's ResolveFilter. Copies of
+// r share the queue set.
+func (r *Resolver) UseQueueStore(store R11nQueueStore) {
+	rf := r.ResolveFilter
+	if rf == nil {
+		rf = &ResolveFilter{}
+	}
+	r.QueueSet = NewR11nQueueSet(
+		R11nQueueStartWithHandler(r.rectifyQueued),
+		R11nQueuePersistTo(store, rf.String(), r.ls))
+}
+
+func (r *Resolver) rectifyQueued(qr *QueuedR11n) DiffResolution {
+	qr.Rectification.Begin(r.Deployer)
+	return qr.Rectification.Wait()
+}
 
 // queueDiffs adds a rectification for each required change in DeployableChans,
 // as long as there is no planned or currently executing resolution for the
 // DeploymentID relating to that rectification.
 func (r *Resolver) queueDiffs(dcs *DeployableChans, results chan DiffResolution) {
-	if globalQueueSet == nil {
-		globalQueueSet = NewR11nQueueSet(R11nQueueStartWithHandler(
-			func(qr *QueuedR11n) DiffResolution {
-				qr.Rectification.Begin(r.Deployer)
-				return qr.Rectification.Wait()
-			}))
+	queueSet := r.QueueSet
+	if queueSet == nil {
+		globalQueueSetOnce.Do(func() {
+			globalQueueSet = NewR11nQueueSet(R11nQueueStartWithHandler(r.rectifyQueued))
+		})
+		queueSet = globalQueueSet
 	}
 
 	var wg sync.WaitGroup
 	for p := range dcs.Pairs {
 		sr := NewRectification(*p)
-		queued, ok := globalQueueSet.PushIfEmpty(sr)
+		queued, ok := queueSet.PushIfEmpty(sr)
 		if !ok {
 			reportR11nAnomaly(r.ls, sr, r11nDroppedQueueNotEmpty)
 			continue
@@ -59,7 +104,7 @@ func (r *Resolver) queueDiffs(dcs *DeployableChans, results chan DiffResolution)
 		did := p.ID() // Capture did from the range var p outside the goroutine.
 		go func() {
 			defer wg.Done()
-			result, ok := globalQueueSet.Wait(did, queued.ID)
+			result, ok := queueSet.Wait(did, queued.ID)
 			if !ok {
 				reportR11nAnomaly(r.ls, sr, r11nWentMissing)
 			}
@@ -79,10 +124,15 @@ func (r *Resolver) queueDiffs(dcs *DeployableChans, results chan DiffResolution)
 // the actual set, compute the diffs and then issue the commands to rectify
 // those differences.
 func (r *Resolver) Begin(intended Deployments, clusters Clusters) *ResolveRecorder {
+	// Dependencies are checked among all deployments, not just those being
+	// resolved.
+	allIntended := intended
 	intended = intended.Filter(r.FilterDeployment)
+	freezes, overrides := r.Freezes, r.FreezeOverrides
+	deps := r.Dependencies
 
-	return NewResolveRecorder(intended, func(recorder *ResolveRecorder) {
-		var actual DeployStates
+	return newResolveRecorder(intended, r.Events, func(recorder *ResolveRecorder) {
+		var actual, allActual DeployStates
 		var diffs *DeployableChans
 		var logger *DeployableChans
 
@@ -97,6 +147,7 @@ func (r *Resolver) Begin(intended Deployments, clusters Clusters) *ResolveRecord
 		})
 
 		recorder.performGuaranteedPhase("filtering running deployments", func() {
+			allActual = actual
 			actual = actual.Filter(r.FilterDeployStates)
 		})
 
@@ -104,7 +155,33 @@ func (r *Resolver) Begin(intended Deployments, clusters Clusters) *ResolveRecord
 			diffs = actual.Diff(intended)
 		})
 
+		recorder.performPhase("checking deletion brake", func() error {
+			var err error
+			diffs, err = r.brakeDeletions(diffs, recorder.Log)
+			return err
+		})
+
 		ctx := context.Background()
+		recorder.performGuaranteedPhase("verifying active deployments", func() {
+			diffs = diffs.Pipeline(ctx, deploymentVerifier{Verifier: r.Verifier, KnownGood: r.KnownGood})
+		})
+
+		recorder.performGuaranteedPhase("recording known-good versions", func() {
+			diffs = diffs.Pipeline(ctx, knownGoodRecorder{r.KnownGood})
+		})
+
+		recorder.performGuaranteedPhase("holding frozen clusters", func() {
+			diffs = diffs.Pipeline(ctx, freezeHolder{freezes: freezes, overrides: overrides, now: time.Now()})
+		})
+
+		recorder.performGuaranteedPhase("holding deployments for their dependencies", func() {
+			diffs = diffs.Pipeline(ctx, dependencyHolder{deps: deps, intended: allIntended, actual: allActual})
+		})
+
+		recorder.performGuaranteedPhase("planning rollouts", func() {
+			diffs = diffs.Pipeline(ctx, rolloutPlanner{now: time.Now()})
+		})
+
 		recorder.performGuaranteedPhase("resolving deployment artifacts", func() {
 			namer := diffs.ResolveNames(ctx, r.Registry)
 			logger = namer.Log(ctx, r.ls)
@@ -124,6 +201,11 @@ func (r *Resolver) Begin(intended Deployments, clusters Clusters) *ResolveRecord
 			close(recorder.Log)
 		})
 
+		if logger == nil {
+			// An earlier phase failed, so there is nothing to wait for.
+			close(recorder.Log)
+			return
+		}
 		logger.Wait()
 	})
 }
@@ -36,6 +36,9 @@ type (
 		finished chan struct{}
 		// err is the final error returned from a phase that ends the resolution.
 		err error
+		// events, if not nil, publishes phase changes and resolutions as
+		// they are recorded.
+		events *ResolveEvents
 		sync.RWMutex
 	}
 
@@ -47,6 +50,11 @@ type (
 		Desc ResolutionType
 		// Error captures the error (if any) encountered during diff resolution
 		Error *ErrorWrapper
+		// Rollout records the step of a staged rollout taken, if any.
+		Rollout *RolloutProgress `json:",omitempty"`
+		// RolledBackFrom is the version which failed to deploy, when the
+		// server has rolled the deployment back to its last known-good version.
+		RolledBackFrom string `json:",omitempty"`
 	}
 
 	// ResolutionType marks the kind of a DiffResolution
@@ -65,6 +73,20 @@ const (
 	ModifyDiff = ResolutionType("updated")
 	// DeleteDiff - a deployment was active that wasn't intended at all, and was deleted.
 	DeleteDiff = ResolutionType("deleted")
+	// RollbackDiff - a staged rollout failed, and was abandoned in favour of
+	// the previous deployment, or a failed deploy was replaced in the GDM by
+	// the last known-good version.
+	RollbackDiff = ResolutionType("rolled back")
+	// HeldDiff - the deployment differed from the intended, but was not
+	// changed because its cluster is frozen.
+	HeldDiff = ResolutionType("held")
+	// BlockedDiff - the deployment differed from the intended, but was not
+	// changed because a deployment it depends on is not yet active at its
+	// intended version.
+	BlockedDiff = ResolutionType("blocked")
+	// UnverifiedDiff - the intended deployment is active, but failed its
+	// Verify probes.
+	UnverifiedDiff = ResolutionType("failed verification")
 )
 
 func (rez DiffResolution) String() string {
@@ -74,6 +96,11 @@ func (rez DiffResolution) String() string {
 // NewResolveRecorder creates a new ResolveRecorder and calls f with it as its
 // argument. It then returns that ResolveRecorder immediately.
 func NewResolveRecorder(intended Deployments, f func(*ResolveRecorder)) *ResolveRecorder {
+	return newResolveRecorder(intended, nil, f)
+}
+
+// newResolveRecorder is NewResolveRecorder, publishing to events.
+func newResolveRecorder(intended Deployments, events *ResolveEvents, f func(*ResolveRecorder)) *ResolveRecorder {
 	rr := &ResolveRecorder{
 		status: &ResolveStatus{
 			Started:  time.Now(),
@@ -83,6 +110,7 @@ func NewResolveRecorder(intended Deployments, f func(*ResolveRecorder)) *Resolve
 		},
 		Log:      make(chan DiffResolution, 10),
 		finished: make(chan struct{}),
+		events:   events,
 	}
 
 	for _, d := range intended.Snapshot() {
@@ -99,6 +127,7 @@ func NewResolveRecorder(intended Deployments, f func(*ResolveRecorder)) *Resolve
 					logging.Log.Debug.Printf("resolve error = %+v\n", rez.Error)
 				}
 			})
+			rr.events.publishResolution(rez)
 		}
 		close(rr.finished)
 	}()
@@ -106,12 +135,17 @@ func NewResolveRecorder(intended Deployments, f func(*ResolveRecorder)) *Resolve
 	// Execute the main function (f) over this resolve recorder.
 	go func() {
 		f(rr)
+		var failed bool
 		rr.write(func() {
 			rr.status.Finished = time.Now()
-			if rr.err == nil {
+			failed = rr.err != nil
+			if !failed {
 				rr.status.Phase = "finished"
 			}
 		})
+		if !failed {
+			rr.events.publishPhase("finished")
+		}
 	}()
 	return rr
 }
@@ -187,6 +221,7 @@ func (rr *ResolveRecorder) setPhase(phase string) {
 	rr.write(func() {
 		rr.status.Phase = phase
 	})
+	rr.events.publishPhase(phase)
 }
 
 // Phase returns the name of the current phase.
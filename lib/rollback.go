@@ -0,0 +1,192 @@
+package sous
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/samsalisbury/semv"
+)
+
+// RollbackPolicy describes what the Sous server should do when a deploy of a
+// manifest fails.
+type RollbackPolicy string
+
+const (
+	// RollbackNever leaves failed deploys in place, for their owners to fix.
+	// It is the default.
+	RollbackNever RollbackPolicy = ""
+	// RollbackToKnownGood writes the last version of a deployment that was
+	// seen to be active back to the GDM when a deploy of a newer version
+	// fails.
+	RollbackToKnownGood RollbackPolicy = "known-good"
+)
+
+// SystemUser is the User the Sous server attributes its own changes to the
+// GDM to.
+var SystemUser = User{Name: "Sous Server", Email: "sous-server@localhost"}
+
+// Validate returns a list of flaws with this RollbackPolicy.
+func (rp RollbackPolicy) Validate() []Flaw {
+	switch rp {
+	default:
+		return []Flaw{GenericFlaw{
+			Desc: fmt.Sprintf("RollbackPolicy %q not valid", rp),
+			RepairFunc: func() error {
+				return errors.Errorf("unable to repair invalid RollbackPolicy")
+			},
+		}}
+	case RollbackNever, RollbackToKnownGood:
+		return nil
+	}
+}
+
+type (
+	// KnownGoodVersions remembers, for each deployment, the last version seen
+	// to be DeployStatusActive, and any version since then seen to have
+	// failed. It is safe for concurrent use.
+	KnownGoodVersions struct {
+		sync.Mutex
+		active, failed map[DeploymentID]semv.Version
+		// since is when each deployment which is active now was first seen
+		// to be active at its current version.
+		since map[DeploymentID]time.Time
+	}
+
+	// A Rollback describes a deployment which should be put back to its last
+	// known-good version.
+	Rollback struct {
+		DeploymentID
+		// Failed is the version which failed to deploy.
+		Failed semv.Version
+		// KnownGood is the last version which was active.
+		KnownGood semv.Version
+	}
+
+	// A RolledBackError reports that a deploy failed, and that the Sous
+	// server has rolled the deployment back to its last known-good version.
+	RolledBackError struct {
+		Rollback
+		User User
+	}
+)
+
+// NewKnownGoodVersions returns an empty KnownGoodVersions.
+func NewKnownGoodVersions() *KnownGoodVersions {
+	return &KnownGoodVersions{
+		active: map[DeploymentID]semv.Version{},
+		failed: map[DeploymentID]semv.Version{},
+		since:  map[DeploymentID]time.Time{},
+	}
+}
+
+// KnownGood returns the last version of did seen to be active.
+func (kg *KnownGoodVersions) KnownGood(did DeploymentID) (semv.Version, bool) {
+	kg.Lock()
+	defer kg.Unlock()
+	v, ok := kg.active[did]
+	return v, ok
+}
+
+// Active returns the version of did which is active now, and when it was
+// first seen to be active. The time is no earlier than when this
+// KnownGoodVersions first observed did.
+func (kg *KnownGoodVersions) Active(did DeploymentID) (ActiveVersion, bool) {
+	kg.Lock()
+	defer kg.Unlock()
+	since, ok := kg.since[did]
+	if !ok {
+		return ActiveVersion{}, false
+	}
+	return ActiveVersion{Version: kg.active[did], Since: since}, true
+}
+
+// observe records the actual state of a deployment, as compared to its
+// intended state.
+func (kg *KnownGoodVersions) observe(pair *DeployablePair) {
+	if pair.Prior == nil || pair.Prior.Deployment == nil {
+		return
+	}
+	did := pair.ID()
+	version := pair.Prior.SourceID.Version
+	kg.Lock()
+	defer kg.Unlock()
+	if pair.Prior.Status != DeployStatusActive {
+		// Soak time only counts while a deployment stays active.
+		delete(kg.since, did)
+	}
+	switch pair.Prior.Status {
+	case DeployStatusActive:
+		if _, ok := kg.since[did]; !ok || !kg.active[did].Equals(version) {
+			kg.since[did] = time.Now()
+		}
+		kg.active[did] = version
+		delete(kg.failed, did)
+	case DeployStatusFailed:
+		// Only a failure of the version we still intend to deploy is worth
+		// rolling back: otherwise the GDM has already moved on.
+		if pair.Post == nil || pair.Post.Deployment == nil || !pair.Post.SourceID.Version.Equals(version) {
+			delete(kg.failed, did)
+			return
+		}
+		kg.failed[did] = version
+	}
+}
+
+// Rollbacks returns a Rollback for each failed deployment with a known-good
+// version different to the one which failed.
+func (kg *KnownGoodVersions) Rollbacks() []Rollback {
+	kg.Lock()
+	defer kg.Unlock()
+	var rbs []Rollback
+	for did, failed := range kg.failed {
+		good, ok := kg.active[did]
+		if !ok || good.Equals(failed) {
+			continue
+		}
+		rbs = append(rbs, Rollback{DeploymentID: did, Failed: failed, KnownGood: good})
+	}
+	return rbs
+}
+
+// forget stops tracking the failure of did, once it has been dealt with.
+func (kg *KnownGoodVersions) forget(did DeploymentID) {
+	kg.Lock()
+	defer kg.Unlock()
+	delete(kg.failed, did)
+}
+
+// knownGoodRecorder is a DeployableProcessor which records the actual
+// versions of deployments in a KnownGoodVersions.
+type knownGoodRecorder struct {
+	*KnownGoodVersions
+}
+
+func (kgr knownGoodRecorder) HandlePairs(dp *DeployablePair) (*DeployablePair, *DiffResolution) {
+	if kgr.KnownGoodVersions != nil {
+		kgr.observe(dp)
+	}
+	return dp, nil
+}
+
+// Apply writes the known-good version of rb's deployment into state, if its
+// manifest's RollbackPolicy allows it. It returns false if it didn't.
+func (rb Rollback) Apply(state *State) bool {
+	m, ok := state.Manifests.Get(rb.ManifestID)
+	if !ok || m.Rollback != RollbackToKnownGood {
+		return false
+	}
+	spec, ok := m.Deployments[rb.Cluster]
+	if !ok || !spec.Version.Equals(rb.Failed) {
+		return false
+	}
+	spec.Version = rb.KnownGood
+	m.Deployments[rb.Cluster] = spec
+	state.Manifests.Set(rb.ManifestID, m)
+	return true
+}
+
+func (e *RolledBackError) Error() string {
+	return fmt.Sprintf("Deploy of version %s failed; rolled back to version %s by %s.", e.Failed, e.KnownGood, e.User)
+}
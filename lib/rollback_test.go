@@ -0,0 +1,189 @@
+package sous
+
+import (
+	"testing"
+
+	"github.com/opentable/sous/util/logging"
+	"github.com/samsalisbury/semv"
+)
+
+func rollbackTestPair(actual, intended string, status DeployStatus) *DeployablePair {
+	did := DeploymentID{ManifestID: ManifestID{Source: project1}, Cluster: "cluster-1"}
+	deployable := func(version string) *Deployable {
+		return &Deployable{Deployment: &Deployment{
+			ClusterName: did.Cluster,
+			SourceID:    project1.SourceID(semv.MustParse(version)),
+		}}
+	}
+	pair := &DeployablePair{name: did, Prior: deployable(actual), Post: deployable(intended)}
+	pair.Prior.Status = status
+	return pair
+}
+
+func TestKnownGoodVersions(t *testing.T) {
+	kg := NewKnownGoodVersions()
+
+	kg.observe(rollbackTestPair("1.0.0", "2.0.0", DeployStatusActive))
+	kg.observe(rollbackTestPair("2.0.0", "2.0.0", DeployStatusPending))
+	if rbs := kg.Rollbacks(); len(rbs) != 0 {
+		t.Fatalf("got %d rollbacks before any failure; want 0", len(rbs))
+	}
+
+	kg.observe(rollbackTestPair("2.0.0", "2.0.0", DeployStatusFailed))
+	rbs := kg.Rollbacks()
+	if len(rbs) != 1 {
+		t.Fatalf("got %d rollbacks; want 1", len(rbs))
+	}
+	if rbs[0].Failed.String() != "2.0.0" || rbs[0].KnownGood.String() != "1.0.0" {
+		t.Errorf("got rollback from %s to %s; want from 2.0.0 to 1.0.0", rbs[0].Failed, rbs[0].KnownGood)
+	}
+
+	// Once the GDM has moved on, the failure doesn't matter any more.
+	kg.observe(rollbackTestPair("2.0.0", "3.0.0", DeployStatusFailed))
+	if rbs := kg.Rollbacks(); len(rbs) != 0 {
+		t.Errorf("got %d rollbacks after a new version was intended; want 0", len(rbs))
+	}
+
+	// The known-good version itself failing can't be rolled back.
+	kg.observe(rollbackTestPair("1.0.0", "1.0.0", DeployStatusFailed))
+	if rbs := kg.Rollbacks(); len(rbs) != 0 {
+		t.Errorf("got %d rollbacks of the known-good version; want 0", len(rbs))
+	}
+}
+
+func TestRollbackApply(t *testing.T) {
+	did := DeploymentID{ManifestID: ManifestID{Source: project1}, Cluster: "cluster-1"}
+	rb := Rollback{DeploymentID: did, Failed: semv.MustParse("1.0.0"), KnownGood: semv.MustParse("0.9.0")}
+
+	state := &State{Defs: makeTestDefs(), Manifests: makeTestManifests()}
+	if rb.Apply(state) {
+		t.Errorf("Apply rolled back a manifest with no Rollback policy")
+	}
+
+	m, _ := state.Manifests.Get(did.ManifestID)
+	m.Rollback = RollbackToKnownGood
+	if !rb.Apply(state) {
+		t.Fatalf("Apply did not roll back a manifest with RollbackToKnownGood")
+	}
+	m, _ = state.Manifests.Get(did.ManifestID)
+	if v := m.Deployments["cluster-1"].Version.String(); v != "0.9.0" {
+		t.Errorf("got version %s; want 0.9.0", v)
+	}
+	if rb.Apply(state) {
+		t.Errorf("Apply rolled back a deployment no longer at the failed version")
+	}
+}
+
+func TestAutoResolver_rollBack(t *testing.T) {
+	did := DeploymentID{ManifestID: ManifestID{Source: project1}, Cluster: "cluster-1"}
+	state := &State{Defs: makeTestDefs(), Manifests: makeTestManifests()}
+	m, _ := state.Manifests.Get(did.ManifestID)
+	m.Rollback = RollbackToKnownGood
+	sm := &DummyStateManager{State: state.Clone()}
+
+	ar := NewAutoResolver(dummyResolver(), sm, logging.SilentLogSet())
+	ar.KnownGood.observe(rollbackTestPair("0.9.0", "0.9.0", DeployStatusActive))
+	ar.KnownGood.observe(rollbackTestPair("1.0.0", "1.0.0", DeployStatusFailed))
+
+	if !ar.rollBack() {
+		t.Fatalf("rollBack returned false; want true")
+	}
+	if sm.WriteCount != 1 {
+		t.Errorf("state written %d times; want 1", sm.WriteCount)
+	}
+	written, _ := sm.State.Manifests.Get(did.ManifestID)
+	if v := written.Deployments["cluster-1"].Version.String(); v != "0.9.0" {
+		t.Errorf("got written version %s; want 0.9.0", v)
+	}
+
+	rezs := ar.rollbackResolutions()
+	if len(rezs) != 1 {
+		t.Fatalf("got %d rollback resolutions; want 1", len(rezs))
+	}
+	if rezs[0].Desc != RollbackDiff || rezs[0].RolledBackFrom != "1.0.0" {
+		t.Errorf("got resolution %v from %q; want %q from 1.0.0", rezs[0], rezs[0].RolledBackFrom, RollbackDiff)
+	}
+	if ar.rollBack() {
+		t.Errorf("rollBack rolled back the same failure twice")
+	}
+
+	// A new deploy clears the rollback from the status.
+	ar.GDM = NewDeployments()
+	if rezs := ar.rollbackResolutions(); len(rezs) != 0 {
+		t.Errorf("got %d rollback resolutions after a new deploy; want 0", len(rezs))
+	}
+}
+
+func TestAutoResolver_rollBack_keepsConcurrentDeploys(t *testing.T) {
+	did := DeploymentID{ManifestID: ManifestID{Source: project1}, Cluster: "cluster-1"}
+	sm := &DummyStateManager{State: &State{Defs: makeTestDefs(), Manifests: makeTestManifests()}}
+	m, _ := sm.State.Manifests.Get(did.ManifestID)
+	m.Rollback = RollbackToKnownGood
+
+	ar := NewAutoResolver(dummyResolver(), sm, logging.SilentLogSet())
+	ar.KnownGood.observe(rollbackTestPair("0.9.0", "0.9.0", DeployStatusActive))
+	ar.KnownGood.observe(rollbackTestPair("1.0.0", "1.0.0", DeployStatusFailed))
+
+	// Deployed to cluster-2 while the cycle which found the failure ran.
+	m = m.Clone()
+	spec := m.Deployments["cluster-2"]
+	spec.Version = semv.MustParse("2.1.0")
+	m.Deployments["cluster-2"] = spec
+	sm.State.Manifests.Set(did.ManifestID, m)
+
+	if !ar.rollBack() {
+		t.Fatalf("rollBack returned false; want true")
+	}
+	written, _ := sm.State.Manifests.Get(did.ManifestID)
+	if v := written.Deployments["cluster-1"].Version.String(); v != "0.9.0" {
+		t.Errorf("got cluster-1 version %s; want 0.9.0", v)
+	}
+	if v := written.Deployments["cluster-2"].Version.String(); v != "2.1.0" {
+		t.Errorf("got cluster-2 version %s; want the deploy made during the cycle, 2.1.0", v)
+	}
+}
+
+func TestSubPoller_rolledBack(t *testing.T) {
+	did := DeploymentID{ManifestID: ManifestID{Source: project1}, Cluster: "cluster-1"}
+	status := &ResolveStatus{Log: []DiffResolution{
+		{DeploymentID: did, Desc: StableDiff},
+		{
+			DeploymentID:   did,
+			Desc:           RollbackDiff,
+			Error:          WrapResolveError(&RolledBackError{User: SystemUser}),
+			RolledBackFrom: "1.0.0",
+		},
+	}}
+	sub := subPoller{idFilter: &ResolveFilter{
+		Repo: NewResolveFieldMatcher(project1.Repo),
+		Tag:  NewResolveFieldMatcher("1.0.0"),
+	}}
+	if sub.rolledBack(status) == nil {
+		t.Errorf("rollback from the version being polled for not found")
+	}
+	sub.idFilter.Tag = NewResolveFieldMatcher("1.1.0")
+	if rez := sub.rolledBack(status); rez != nil {
+		t.Errorf("got rollback %v for a different version", rez)
+	}
+}
+
+func TestPutbackManifests_keepsRollback(t *testing.T) {
+	defs := makeTestDefs()
+	olds := makeTestManifests()
+	for _, m := range olds.Snapshot() {
+		m.Rollback = RollbackToKnownGood
+	}
+	ds, err := olds.Deployments(defs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ms, err := ds.PutbackManifests(defs, olds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for id, m := range ms.Snapshot() {
+		if m.Rollback != RollbackToKnownGood {
+			t.Errorf("manifest %q lost its Rollback policy", id)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package sous
+
+import (
+	"fmt"
+	"sync"
+)
+
+type (
+	// A Runner launches runs of on-demand and one-off deployments on their
+	// cluster's scheduler, and reports on them.
+	Runner interface {
+		// Run launches a run of d, identified by runID, passing it args.
+		Run(d *Deployment, runID string, args []string) error
+		// RunStatus reports on the run runID of d.
+		RunStatus(d *Deployment, runID string) (*RunStatus, error)
+		// RunOutput returns what the run runID of d has written to stream,
+		// "stdout" or "stderr", from offset on.
+		RunOutput(d *Deployment, runID, stream string, offset int64) (*RunOutput, error)
+	}
+
+	// RunState is the state of a run.
+	RunState string
+
+	// RunStatus describes a run of a deployment.
+	RunStatus struct {
+		RunID string
+		// TaskID is the scheduler's ID for the task of the run, once it has
+		// been launched.
+		TaskID string `json:",omitempty"`
+		State  RunState
+		// ExitCode is the exit status of a finished run, or -1 if the
+		// scheduler didn't report it.
+		ExitCode int
+		// Message is the scheduler's description of the run's state.
+		Message string `json:",omitempty"`
+	}
+
+	// RunOutput is part of the output of a run.
+	RunOutput struct {
+		Data string
+		// NextOffset is the offset to ask for the rest of the output from.
+		NextOffset int64
+	}
+
+	// A RunRequest asks for a run of a deployment. RunID is chosen by the
+	// client, so that it can follow the run.
+	RunRequest struct {
+		RunID string
+		Args  []string `json:",omitempty"`
+	}
+
+	// DummyRunner is a Runner which records runs, and reports that they
+	// succeeded straight away.
+	DummyRunner struct {
+		sync.Mutex
+		Runs map[string]RunRequest
+	}
+)
+
+const (
+	// RunPending is the state of a run which hasn't started yet.
+	RunPending RunState = "pending"
+	// RunRunning is the state of a run which has started.
+	RunRunning RunState = "running"
+	// RunSucceeded is the state of a run which exited with status 0.
+	RunSucceeded RunState = "succeeded"
+	// RunFailed is the state of a run which exited with another status, or
+	// was killed or lost by the scheduler.
+	RunFailed RunState = "failed"
+)
+
+// Finished returns true if a run in this state won't change state again.
+func (rs RunState) Finished() bool {
+	return rs == RunSucceeded || rs == RunFailed
+}
+
+// Runnable returns true if deployments of this kind are run on demand,
+// rather than kept running or run on a schedule.
+func (mk ManifestKind) Runnable() bool {
+	return mk == ManifestKindOnDemand || mk == ManifestKindOnce
+}
+
+func (rs *RunStatus) String() string {
+	switch {
+	default:
+		return fmt.Sprintf("run %s %s", rs.RunID, rs.State)
+	case rs.State.Finished() && rs.ExitCode >= 0:
+		return fmt.Sprintf("run %s %s with exit status %d", rs.RunID, rs.State, rs.ExitCode)
+	}
+}
+
+// NewDummyRunner returns a DummyRunner with no runs.
+func NewDummyRunner() *DummyRunner {
+	return &DummyRunner{Runs: map[string]RunRequest{}}
+}
+
+// Run implements Runner on DummyRunner.
+func (dr *DummyRunner) Run(d *Deployment, runID string, args []string) error {
+	dr.Lock()
+	defer dr.Unlock()
+	dr.Runs[runID] = RunRequest{RunID: runID, Args: args}
+	return nil
+}
+
+// RunStatus implements Runner on DummyRunner.
+func (dr *DummyRunner) RunStatus(d *Deployment, runID string) (*RunStatus, error) {
+	dr.Lock()
+	defer dr.Unlock()
+	if _, ok := dr.Runs[runID]; !ok {
+		return nil, fmt.Errorf("no run %q of %s", runID, d.ID())
+	}
+	return &RunStatus{RunID: runID, TaskID: runID, State: RunSucceeded}, nil
+}
+
+// RunOutput implements Runner on DummyRunner. The runs have no output.
+func (dr *DummyRunner) RunOutput(d *Deployment, runID, stream string, offset int64) (*RunOutput, error) {
+	return &RunOutput{NextOffset: offset}, nil
+}
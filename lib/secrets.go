@@ -0,0 +1,183 @@
+package sous
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// SecretRefScheme prefixes env values which refer to a secret rather than
+// holding a value, like secret://vault/path/to/secret#key.
+const SecretRefScheme = "secret://"
+
+// SecretVarType is the Type of an EnvDef whose values must be secret
+// references.
+const SecretVarType = VarType("Secret")
+
+// SecretStoreNames are the names of the secret stores a SecretRef may name.
+var SecretStoreNames = []string{"vault", "file"}
+
+type (
+	// A SecretRef refers to a secret: the value of Key in the secret at Path
+	// in the named Store.
+	SecretRef struct {
+		Store, Path, Key string
+	}
+
+	// A SecretResolver looks up the values of secrets.
+	SecretResolver interface {
+		ResolveSecret(SecretRef) (string, error)
+	}
+
+	// A SecretStore keeps secrets, as maps of keys to values at paths.
+	SecretStore interface {
+		SecretResolver
+		// SetSecret sets key to value in the secret at path, creating the
+		// secret if need be.
+		SetSecret(path, key, value string) error
+		// SecretKeys returns the keys of the secret at path, in order.
+		SecretKeys(path string) ([]string, error)
+	}
+
+	// SecretResolvers resolves each secret with the resolver for its store.
+	SecretResolvers map[string]SecretResolver
+
+	// An UnresolvedSecretError reports that an env var's secret could not be
+	// resolved.
+	UnresolvedSecretError struct {
+		Name string
+		Ref  SecretRef
+		Err  error
+	}
+)
+
+// IsSecretRef returns true if the env value v refers to a secret.
+func IsSecretRef(v string) bool {
+	return strings.HasPrefix(v, SecretRefScheme)
+}
+
+// ParseSecretRef parses an env value of the form
+// secret://<store>/<path>#<key>.
+func ParseSecretRef(v string) (SecretRef, error) {
+	if !IsSecretRef(v) {
+		return SecretRef{}, errors.Errorf("%q is not a secret reference", v)
+	}
+	rest := strings.TrimPrefix(v, SecretRefScheme)
+	hash := strings.LastIndex(rest, "#")
+	slash := strings.Index(rest, "/")
+	if hash < 0 || slash < 0 || slash > hash {
+		return SecretRef{}, errors.Errorf("secret reference %q should look like %s<store>/<path>#<key>", v, SecretRefScheme)
+	}
+	ref := SecretRef{Store: rest[:slash], Path: rest[slash+1 : hash], Key: rest[hash+1:]}
+	if !knownSecretStore(ref.Store) {
+		return SecretRef{}, errors.Errorf("secret reference %q names unknown store %q (known stores: %s)",
+			v, ref.Store, strings.Join(SecretStoreNames, ", "))
+	}
+	if ref.Path == "" || ref.Key == "" {
+		return SecretRef{}, errors.Errorf("secret reference %q needs both a path and a key", v)
+	}
+	return ref, nil
+}
+
+func knownSecretStore(name string) bool {
+	for _, n := range SecretStoreNames {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// SecretPath returns the path of the secret holding the secrets of the
+// deployment did, as managed by `sous secrets`.
+func SecretPath(did DeploymentID) string {
+	return fmt.Sprintf("sous/%s/%s", did.ManifestID, did.Cluster)
+}
+
+func (r SecretRef) String() string {
+	return fmt.Sprintf("%s%s/%s#%s", SecretRefScheme, r.Store, r.Path, r.Key)
+}
+
+// ResolveSecret implements SecretResolver on SecretResolvers.
+func (srs SecretResolvers) ResolveSecret(ref SecretRef) (string, error) {
+	sr, ok := srs[ref.Store]
+	if !ok || sr == nil {
+		return "", errors.Errorf("no %q secret store is configured", ref.Store)
+	}
+	return sr.ResolveSecret(ref)
+}
+
+func (e *UnresolvedSecretError) Error() string {
+	return fmt.Sprintf("resolving %s (%s): %v", e.Name, e.Ref, e.Err)
+}
+
+// SecretRefs returns the secret references in e, by variable name.
+func (e Env) SecretRefs() (map[string]SecretRef, error) {
+	refs := map[string]SecretRef{}
+	for name, v := range e {
+		if !IsSecretRef(v) {
+			continue
+		}
+		ref, err := ParseSecretRef(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "env var %s", name)
+		}
+		refs[name] = ref
+	}
+	return refs, nil
+}
+
+// Resolve returns a copy of e with its secret references replaced by the
+// values of the secrets they refer to, or e itself if it has none. Resolved
+// envs must never be logged or stored.
+func (e Env) Resolve(sr SecretResolver) (Env, error) {
+	refs, err := e.SecretRefs()
+	if err != nil || len(refs) == 0 {
+		return e, err
+	}
+	resolved := make(Env, len(e))
+	for name, v := range e {
+		resolved[name] = v
+	}
+	names := make([]string, 0, len(refs))
+	for name := range refs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		ref := refs[name]
+		if sr == nil {
+			return nil, &UnresolvedSecretError{Name: name, Ref: ref, Err: errors.New("no secret stores are configured")}
+		}
+		v, err := sr.ResolveSecret(ref)
+		if err != nil {
+			return nil, &UnresolvedSecretError{Name: name, Ref: ref, Err: err}
+		}
+		resolved[name] = v
+	}
+	return resolved, nil
+}
+
+// validateSecrets checks e's use of secrets against defs: a secret
+// reference may only be the value of a variable defined with SecretVarType,
+// and such variables may only hold secret references.
+func (e Env) validateSecrets(defs EnvDefs) []Flaw {
+	secret := map[string]bool{}
+	for _, d := range defs {
+		if d.Type == SecretVarType {
+			secret[d.Name] = true
+		}
+	}
+	var flaws []Flaw
+	for name, v := range e {
+		switch {
+		case IsSecretRef(v) && !secret[name]:
+			flaws = append(flaws, FatalFlaw("env var %s refers to a secret, but is not defined with Type %s in defs", name, SecretVarType))
+		case !IsSecretRef(v) && secret[name]:
+			flaws = append(flaws, FatalFlaw("env var %s is a secret, and must be a %s reference, not a plain value", name, SecretRefScheme))
+		}
+	}
+	return flaws
+}
@@ -0,0 +1,60 @@
+package sous
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mapSecrets map[string]string
+
+func (ms mapSecrets) ResolveSecret(ref SecretRef) (string, error) {
+	v, ok := ms[ref.String()]
+	if !ok {
+		return "", fmt.Errorf("no secret %s", ref)
+	}
+	return v, nil
+}
+
+func TestParseSecretRef(t *testing.T) {
+	ref, err := ParseSecretRef("secret://vault/db/orders#password")
+	if assert.NoError(t, err) {
+		assert.Equal(t, SecretRef{Store: "vault", Path: "db/orders", Key: "password"}, ref)
+		assert.Equal(t, "secret://vault/db/orders#password", ref.String())
+	}
+
+	for _, bad := range []string{
+		"hunter2",
+		"secret://vault/db/orders",
+		"secret://vault#password",
+		"secret://vault/#password",
+		"secret://vault/db#",
+		"secret://s3/db/orders#password",
+	} {
+		_, err := ParseSecretRef(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestEnvResolve(t *testing.T) {
+	env := Env{"DB_PASSWORD": "secret://file/db#password", "PLAIN": "value"}
+	resolved, err := env.Resolve(mapSecrets{"secret://file/db#password": "hunter2"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, Env{"DB_PASSWORD": "hunter2", "PLAIN": "value"}, resolved)
+	}
+	assert.Equal(t, "secret://file/db#password", env["DB_PASSWORD"])
+
+	_, err = env.Resolve(SecretResolvers{})
+	if assert.IsType(t, &UnresolvedSecretError{}, err) {
+		assert.NotContains(t, err.Error(), "hunter2")
+	}
+}
+
+func TestEnvValidateSecrets(t *testing.T) {
+	defs := EnvDefs{{Name: "DB_PASSWORD", Type: SecretVarType}}
+
+	assert.Empty(t, Env{"DB_PASSWORD": "secret://vault/db#password", "PLAIN": "x"}.validateSecrets(defs))
+	assert.Len(t, Env{"DB_PASSWORD": "hunter2"}.validateSecrets(defs), 1)
+	assert.Len(t, Env{"API_KEY": "secret://vault/api#key"}.validateSecrets(defs), 1)
+}
@@ -15,6 +15,9 @@ type Startup struct { //                             Singularity fields
 	Timeout         int `yaml:",omitempty"` // Healthcheck.StartupTimeoutSeconds
 	ConnectInterval int `yaml:",omitempty"` // Healthcheck.StartupIntervalSeconds
 
+	// CheckReadyProtocol is the kind of check: HTTP or HTTPS to request
+	// CheckReadyURIPath, TCP to connect to the port, or COMMAND to run
+	// CheckReadyCommand in the container.
 	CheckReadyProtocol        string `yaml:",omitempty"` // Healthcheck.Protocol
 	CheckReadyURIPath         string `yaml:",omitempty"` // Healthcheck.URI
 	CheckReadyPortIndex       int    `yaml:",omitempty"` // Healthcheck.PortIndex
@@ -22,6 +25,16 @@ type Startup struct { //                             Singularity fields
 	CheckReadyURITimeout      int    `yaml:",omitempty"` // Healthcheck.ResponseTimeoutSeconds
 	CheckReadyInterval        int    `yaml:",omitempty"` // Healthcheck.IntervalSeconds
 	CheckReadyRetries         int    `yaml:",omitempty"` // Healthcheck.MaxRetries
+	// CheckReadyCommand is the command run by a COMMAND check, which passes
+	// if it exits zero.
+	CheckReadyCommand []string `yaml:",omitempty"`
+
+	// LivenessInterval is the time between checks once a task is ready. The
+	// liveness check is the same as the readiness check, and a task which
+	// fails it LivenessFailureThreshold times in a row is restarted. Zero
+	// means the task isn't checked once it is ready.
+	LivenessInterval         int `yaml:",omitempty"`
+	LivenessFailureThreshold int `yaml:",omitempty"`
 
 	// ??? We don't deploy fixed port services...
 	// ??? CheckReadyPortNumber int    `yaml:",omitempty"` // Healthcheck.PortNumber
@@ -61,14 +74,25 @@ func (s *Startup) Validate() []Flaw {
 
 		switch s.CheckReadyProtocol {
 		default:
-			flaws = append(flaws, FatalFlaw("CheckReadyProtocol must be HTTP or HTTPS, was %q.", s.CheckReadyProtocol))
-		case "https", "http":
-			flaws = append(flaws, NewFlaw(fmt.Sprintf("CheckReadyProtocol must be HTTP or HTTPS, was %q (lowercase).", s.CheckReadyProtocol),
+			flaws = append(flaws, FatalFlaw("CheckReadyProtocol must be HTTP, HTTPS, TCP or COMMAND, was %q.", s.CheckReadyProtocol))
+		case "https", "http", "tcp", "command":
+			flaws = append(flaws, NewFlaw(fmt.Sprintf("CheckReadyProtocol must be HTTP, HTTPS, TCP or COMMAND, was %q (lowercase).", s.CheckReadyProtocol),
 				func() error {
 					s.CheckReadyProtocol = strings.ToUpper(s.CheckReadyProtocol)
 					return nil
 				}))
-		case "HTTPS", "HTTP":
+		case "HTTPS", "HTTP", "TCP", "COMMAND":
+		}
+
+		if strings.EqualFold(s.CheckReadyProtocol, "COMMAND") && len(s.CheckReadyCommand) == 0 {
+			flaws = append(flaws, FatalFlaw("CheckReadyProtocol is COMMAND, but CheckReadyCommand is empty."))
+		}
+
+		if s.LivenessInterval < 0 {
+			flaws = append(flaws, FatalFlaw("LivenessInterval less than zero: %d!", s.LivenessInterval))
+		}
+		if s.LivenessFailureThreshold < 0 {
+			flaws = append(flaws, FatalFlaw("LivenessFailureThreshold less than zero: %d!", s.LivenessFailureThreshold))
 		}
 
 		for _, status := range s.CheckReadyFailureStatuses {
@@ -81,6 +105,23 @@ func (s *Startup) Validate() []Flaw {
 	return flaws
 }
 
+// validateForKind returns a flaw for each check s asks for that clusters of
+// kind can't make. Singularity only checks tasks as they start, and can't run
+// commands to do it.
+func (s Startup) validateForKind(kind string) []Flaw {
+	if s.SkipCheck || (kind != "" && kind != "singularity") {
+		return nil
+	}
+	var flaws []Flaw
+	if strings.EqualFold(s.CheckReadyProtocol, "COMMAND") {
+		flaws = append(flaws, FatalFlaw("CheckReadyProtocol is COMMAND, but Singularity clusters can't run COMMAND checks."))
+	}
+	if s.LivenessInterval != 0 {
+		flaws = append(flaws, FatalFlaw("LivenessInterval is %d, but Singularity clusters don't check liveness.", s.LivenessInterval))
+	}
+	return flaws
+}
+
 // MergeDefaults merges default values with a Startup and returns the result
 func (s Startup) MergeDefaults(base Startup) Startup {
 	n := base
@@ -130,6 +171,18 @@ func (s Startup) MergeDefaults(base Startup) Startup {
 		n.CheckReadyRetries = s.CheckReadyRetries
 	}
 
+	if len(n.CheckReadyCommand) == len(zeroStartup.CheckReadyCommand) {
+		n.CheckReadyCommand = s.CheckReadyCommand
+	}
+
+	if n.LivenessInterval == zeroStartup.LivenessInterval {
+		n.LivenessInterval = s.LivenessInterval
+	}
+
+	if n.LivenessFailureThreshold == zeroStartup.LivenessFailureThreshold {
+		n.LivenessFailureThreshold = s.LivenessFailureThreshold
+	}
+
 	return n
 }
 
@@ -203,6 +256,21 @@ func (s Startup) UnmergeDefaults(base, old Startup) Startup {
 		n.CheckReadyRetries = zeroStartup.CheckReadyRetries
 	}
 
+	if stringSlicesEqual(base.CheckReadyCommand, s.CheckReadyCommand) &&
+		len(old.CheckReadyCommand) == len(zeroStartup.CheckReadyCommand) {
+		n.CheckReadyCommand = zeroStartup.CheckReadyCommand
+	}
+
+	if base.LivenessInterval == s.LivenessInterval &&
+		old.LivenessInterval == zeroStartup.LivenessInterval {
+		n.LivenessInterval = zeroStartup.LivenessInterval
+	}
+
+	if base.LivenessFailureThreshold == s.LivenessFailureThreshold &&
+		old.LivenessFailureThreshold == zeroStartup.LivenessFailureThreshold {
+		n.LivenessFailureThreshold = zeroStartup.LivenessFailureThreshold
+	}
+
 	return n
 }
 
@@ -225,6 +293,7 @@ func (s Startup) diff(o Startup) []string {
 	if o.SkipCheck == true {
 		r = zeroStartup
 	}
+	l, r = l.checked(), r.checked()
 
 	if s.SkipCheck != o.SkipCheck {
 		diff("SkipCheck; this %v, other %v", s.SkipCheck, o.SkipCheck)
@@ -279,5 +348,41 @@ func (s Startup) diff(o Startup) []string {
 		diff("CheckReadyURITimeout; this %d, other %d", l.CheckReadyURITimeout, r.CheckReadyURITimeout)
 	}
 
+	if !stringSlicesEqual(l.CheckReadyCommand, r.CheckReadyCommand) {
+		diff("CheckReadyCommand; this %q, other %q", l.CheckReadyCommand, r.CheckReadyCommand)
+	}
+
+	if l.LivenessInterval != r.LivenessInterval {
+		diff("LivenessInterval; this %d, other %d", l.LivenessInterval, r.LivenessInterval)
+	}
+
+	if l.LivenessFailureThreshold != r.LivenessFailureThreshold {
+		diff("LivenessFailureThreshold; this %d, other %d", l.LivenessFailureThreshold, r.LivenessFailureThreshold)
+	}
+
 	return diffs
 }
+
+// checked returns a copy of s without the fields its kind of check doesn't
+// use, which may be left over from defaults, so that they aren't compared.
+// A check with neither a protocol nor a URI path is taken to be TCP: that's
+// how Singularity records TCP checks, and how it records the deploys made
+// before there were TCP checks.
+func (s Startup) checked() Startup {
+	if s.CheckReadyProtocol == "" && s.CheckReadyURIPath == "" {
+		s.CheckReadyProtocol = "TCP"
+	}
+	switch strings.ToUpper(s.CheckReadyProtocol) {
+	default:
+		s.CheckReadyCommand = nil
+	case "TCP":
+		s.CheckReadyURIPath = ""
+		s.CheckReadyFailureStatuses = nil
+		s.CheckReadyCommand = nil
+	case "COMMAND":
+		s.CheckReadyURIPath = ""
+		s.CheckReadyFailureStatuses = nil
+		s.CheckReadyPortIndex = 0
+	}
+	return s
+}
@@ -57,6 +57,12 @@ func (s *StartupTest) TestPutGet() {
 		Startup{CheckReadyURIPath: "/health", CheckReadyURITimeout: 100, Timeout: 10},
 		Startup{SkipCheck: true},
 	)
+
+	s.PutGet(
+		Startup{LivenessInterval: 30, LivenessFailureThreshold: 3},
+		Startup{CheckReadyProtocol: "COMMAND", CheckReadyCommand: []string{"check"}, LivenessInterval: 10, LivenessFailureThreshold: 3},
+		Startup{},
+	)
 }
 
 func (s *StartupTest) GetPut(defaults, base Startup) {
@@ -98,6 +104,10 @@ func (s *StartupTest) TestGetPut() {
 		},
 	)
 
+	s.GetPut(
+		Startup{CheckReadyCommand: []string{"check"}, LivenessInterval: 30},
+		Startup{CheckReadyProtocol: "COMMAND", CheckReadyCommand: []string{"other"}, LivenessFailureThreshold: 5},
+	)
 }
 
 func (s *StartupTest) TestMerge() {
@@ -107,6 +117,7 @@ func (s *StartupTest) TestMerge() {
 		SkipCheck:            true,
 		CheckReadyURITimeout: 100,
 		Timeout:              10,
+		LivenessInterval:     30,
 	}
 	right := Startup{
 		CheckReadyURIPath:         "/health",
@@ -115,6 +126,7 @@ func (s *StartupTest) TestMerge() {
 		CheckReadyPortIndex:       2,
 		CheckReadyInterval:        978,
 		CheckReadyRetries:         67,
+		LivenessFailureThreshold:  3,
 	}
 
 	merged := Startup{
@@ -129,6 +141,8 @@ func (s *StartupTest) TestMerge() {
 		CheckReadyPortIndex:       2,
 		CheckReadyInterval:        978,
 		CheckReadyRetries:         67,
+		LivenessInterval:          30,
+		LivenessFailureThreshold:  3,
 	}
 
 	s.Equal(merged, left.MergeDefaults(right))
@@ -154,3 +168,78 @@ func TestStartup_diff(t *testing.T) {
 		t.Fatalf("got diff %q; want %q", actual, expected)
 	}
 }
+
+func TestStartup_diffIgnoresUnusedFields(t *testing.T) {
+	a := Startup{CheckReadyProtocol: "TCP", CheckReadyPortIndex: 1}
+	b := Startup{CheckReadyProtocol: "TCP", CheckReadyPortIndex: 1, CheckReadyURIPath: "/health", CheckReadyFailureStatuses: []int{500}}
+	if diffs := a.diff(b); len(diffs) != 0 {
+		t.Errorf("TCP checks differed by HTTP fields: %v", diffs)
+	}
+
+	b.CheckReadyProtocol = "HTTP"
+	if diffs := a.diff(b); len(diffs) != 3 {
+		t.Errorf("got diffs %v; want protocol, path and failure statuses", diffs)
+	}
+
+	c := Startup{CheckReadyProtocol: "COMMAND", CheckReadyCommand: []string{"check"}, CheckReadyPortIndex: 2}
+	d := Startup{CheckReadyProtocol: "COMMAND", CheckReadyCommand: []string{"check"}}
+	if diffs := c.diff(d); len(diffs) != 0 {
+		t.Errorf("COMMAND checks differed by port index: %v", diffs)
+	}
+}
+
+func TestStartup_Validate(t *testing.T) {
+	valid := []Startup{
+		{CheckReadyProtocol: "TCP"},
+		{CheckReadyProtocol: "COMMAND", CheckReadyCommand: []string{"check"}},
+		{CheckReadyProtocol: "HTTP", LivenessInterval: 30, LivenessFailureThreshold: 3},
+	}
+	for _, s := range valid {
+		if flaws := s.Validate(); len(flaws) != 0 {
+			t.Errorf("%+v: got flaws %v", s, flaws)
+		}
+	}
+
+	invalid := []Startup{
+		{CheckReadyProtocol: "UDP"},
+		{CheckReadyProtocol: "COMMAND"},
+		{CheckReadyProtocol: "TCP", LivenessInterval: -1},
+		{CheckReadyProtocol: "TCP", LivenessFailureThreshold: -1},
+	}
+	for _, s := range invalid {
+		if flaws := s.Validate(); len(flaws) != 1 {
+			t.Errorf("%+v: got flaws %v; want one", s, flaws)
+		}
+	}
+
+	lower := Startup{CheckReadyProtocol: "tcp"}
+	fs, es := RepairAll(lower.Validate())
+	if len(fs) != 0 || len(es) != 0 || lower.CheckReadyProtocol != "TCP" {
+		t.Errorf("lowercase tcp not repaired: %v %v %q", fs, es, lower.CheckReadyProtocol)
+	}
+}
+
+func TestStartup_validateForKind(t *testing.T) {
+	command := Startup{CheckReadyProtocol: "command", CheckReadyCommand: []string{"check"}}
+	liveness := Startup{CheckReadyProtocol: "HTTP", LivenessInterval: 30}
+	both := Startup{CheckReadyProtocol: "COMMAND", CheckReadyCommand: []string{"check"}, LivenessInterval: 30}
+
+	for _, kind := range []string{"", "singularity"} {
+		if flaws := command.validateForKind(kind); len(flaws) != 1 {
+			t.Errorf("%q: COMMAND check: got flaws %v; want one", kind, flaws)
+		}
+		if flaws := liveness.validateForKind(kind); len(flaws) != 1 {
+			t.Errorf("%q: liveness check: got flaws %v; want one", kind, flaws)
+		}
+		if flaws := both.validateForKind(kind); len(flaws) != 2 {
+			t.Errorf("%q: both: got flaws %v; want two", kind, flaws)
+		}
+	}
+	if flaws := both.validateForKind("kubernetes"); len(flaws) != 0 {
+		t.Errorf("kubernetes: got flaws %v", flaws)
+	}
+	both.SkipCheck = true
+	if flaws := both.validateForKind("singularity"); len(flaws) != 0 {
+		t.Errorf("skipped checks: got flaws %v", flaws)
+	}
+}
@@ -33,6 +33,12 @@ type (
 		Resources FieldDefinitions
 		// Metadata contains the definitions for metadata fields
 		Metadata FieldDefinitions
+		// Promotions declares which clusters versions are promoted between by
+		// `sous promote`.
+		Promotions Promotions `yaml:",omitempty"`
+		// Freezes stop changes to deployments in some or all clusters for a
+		// window of time.
+		Freezes Freezes `yaml:",omitempty"`
 	}
 
 	// EnvDefs is a collection of EnvDef
@@ -69,8 +75,8 @@ type (
 	Cluster struct {
 		// Name is the unique name of this cluster.
 		Name string
-		// Kind is the kind of cluster. Currently the only legal value is
-		// "singularity"
+		// Kind is the kind of cluster. Legal values are "singularity" (the
+		// default, if empty) and "kubernetes".
 		Kind string
 		// BaseURL is the main entrypoint URL for interacting with this cluster.
 		BaseURL string
@@ -136,6 +142,8 @@ func (d Defs) Clone() Defs {
 	d.EnvVars = d.EnvVars.Clone()
 	d.Resources = d.Resources.Clone()
 	d.Metadata = d.Metadata.Clone()
+	d.Promotions = d.Promotions.Clone()
+	d.Freezes = d.Freezes.Clone()
 	return d
 }
 
@@ -205,9 +213,13 @@ func (s *State) BaseURLs() []string {
 func (s *State) Validate() []Flaw {
 	var flaws []Flaw
 
+	flaws = append(flaws, s.Defs.Promotions.Validate(s.Defs.Clusters)...)
+	flaws = append(flaws, s.Defs.Freezes.Validate(s.Defs.Clusters)...)
+
 	for _, m := range s.Manifests.Snapshot() {
 		flaws = append(flaws, m.Validate()...)
 	}
+	flaws = append(flaws, s.Dependencies().Validate(s.Manifests)...)
 
 	ds, err := s.Deployments()
 	if err != nil {
@@ -215,6 +227,8 @@ func (s *State) Validate() []Flaw {
 	}
 	for _, depl := range ds.Snapshot() {
 		flaws = append(flaws, depl.Validate()...)
+		flaws = append(flaws, depl.ValidateForCluster()...)
+		flaws = append(flaws, depl.Env.validateSecrets(s.Defs.EnvVars)...)
 	}
 
 	for _, f := range flaws {
@@ -74,3 +74,46 @@ func TestState_Validate(t *testing.T) {
 	}
 
 }
+
+func TestState_Validate_clusterKind(t *testing.T) {
+	mid := MustParseManifestID("github.com/user/repo")
+	spec := DeploySpec{
+		DeployConfig: DeployConfig{
+			Resources: Resources{
+				"cpus":   "1",
+				"memory": "256",
+				"ports":  "1",
+			},
+			NumInstances: 3,
+			Startup: Startup{
+				CheckReadyProtocol: "COMMAND",
+				CheckReadyCommand:  []string{"check"},
+			},
+		},
+		Version: semv.MustParse("1"),
+	}
+	state := &State{
+		Manifests: NewManifestsFromMap(map[ManifestID]*Manifest{
+			mid: &Manifest{
+				Source: mid.Source,
+				Kind:   ManifestKindService,
+				Deployments: DeploySpecs{
+					"sing-cluster": spec,
+					"kube-cluster": spec,
+				},
+			},
+		}),
+		Defs: Defs{
+			Clusters: Clusters{
+				"sing-cluster": {Kind: "singularity"},
+				"kube-cluster": {Kind: "kubernetes"},
+			},
+		},
+	}
+
+	flaws := state.Validate()
+	if len(flaws) != 1 {
+		t.Fatalf("got flaws %v; want one, for sing-cluster", flaws)
+	}
+	assert.Contains(t, flaws[0].(GenericFlaw).Desc, "Singularity")
+}
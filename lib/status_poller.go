@@ -147,7 +147,7 @@ func (sp *StatusPoller) subPollers(clusters *serverListData, deps Deployments) (
 		logging.Log.Debugf("Starting poller against %v", s)
 
 		// Kick off a separate process to issue HTTP requests against this cluster.
-		sub, err := newSubPoller(s.ClusterName, s.URL, sp.ResolveFilter, sp.User, logging.Log)
+		sub, err := newSubPoller(sp.HTTPClient, s.ClusterName, s.URL, sp.ResolveFilter, sp.User, logging.Log)
 		if err != nil {
 			return nil, err
 		}
@@ -361,6 +361,9 @@ func TestStatusPoller(t *testing.T) {
 			}
 		} else if gdmRE.MatchString(url) {
 			rw.Write(gdmJSON)
+		} else if r.URL.Path == "/events" {
+			// These servers predate /events, so the poller polls /status.
+			http.NotFound(rw, r)
 		} else {
 			t.Errorf("Bad request: %#v", r)
 			rw.WriteHeader(500)
@@ -497,6 +500,9 @@ func TestStatusPoller_OldServer2(t *testing.T) {
 			rw.Write(statusJSON)
 		} else if gdmRE.MatchString(url) {
 			rw.Write(gdmJSON)
+		} else if r.URL.Path == "/events" {
+			// These servers predate /events, so the poller polls /status.
+			http.NotFound(rw, r)
 		} else {
 			t.Errorf("Bad request: %#v", r)
 			rw.WriteHeader(500)
@@ -606,6 +612,9 @@ func TestStatusPoller_MesosFailed(t *testing.T) {
 			}
 		} else if gdmRE.MatchString(url) {
 			rw.Write(gdmJSON)
+		} else if r.URL.Path == "/events" {
+			// These servers predate /events, so the poller polls /status.
+			http.NotFound(rw, r)
 		} else {
 			t.Errorf("Bad request: %#v", r)
 			rw.WriteHeader(500)
@@ -744,6 +753,9 @@ func TestStatusPoller_NotIntended(t *testing.T) {
 			rw.Write(statusJSON)
 		} else if gdmRE.MatchString(url) {
 			rw.Write(gdmJSON)
+		} else if r.URL.Path == "/events" {
+			// These servers predate /events, so the poller polls /status.
+			http.NotFound(rw, r)
 		} else {
 			t.Errorf("Bad request: %#v", r)
 			rw.WriteHeader(500)
@@ -825,6 +837,9 @@ func TestStatusPoller_OldServer(t *testing.T) {
 		} else if statusRE.MatchString(url) {
 			rw.WriteHeader(404)
 			rw.Write([]byte{})
+		} else if r.URL.Path == "/events" {
+			// These servers predate /events, so the poller polls /status.
+			http.NotFound(rw, r)
 		} else {
 			t.Errorf("Bad request: %#v", r)
 			rw.WriteHeader(500)
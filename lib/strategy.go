@@ -0,0 +1,202 @@
+package sous
+
+import (
+	"fmt"
+	"time"
+)
+
+type (
+	// Strategy describes how a new version of a deployment is rolled out. The
+	// zero Strategy replaces every instance at once.
+	//
+	// A staged rollout starts a few instances of the new version alongside the
+	// old, and waits for them to be healthy (and to soak) before moving on to
+	// the next step. The resolver advances one step per resolve cycle at most,
+	// and the final step always brings up every instance.
+	//
+	// Strategy is not compared by DeployConfig.Diff: it governs how changes are
+	// made, rather than being something that is deployed.
+	Strategy struct {
+		// Steps are the stages of a rollout, in order.
+		Steps []RolloutStep `yaml:",omitempty"`
+	}
+
+	// RolloutStep is a single stage of a staged rollout. Exactly one of
+	// Instances and Percent should be set.
+	RolloutStep struct {
+		// Instances is an absolute number of new instances, e.g. for a canary.
+		Instances int `yaml:",omitempty"`
+		// Percent is a percentage of DeployConfig.NumInstances.
+		Percent int `yaml:",omitempty"`
+		// SoakSeconds is how long all instances of this step must have been
+		// healthy before the rollout moves on to the next step.
+		SoakSeconds int `yaml:",omitempty"`
+	}
+
+	// RolloutProgress describes where a staged rollout is up to.
+	RolloutProgress struct {
+		// Step is the current step, counting from 1, of Of steps.
+		Step, Of int
+		// TargetInstances is the number of new instances this step calls for.
+		TargetInstances int
+		// ActiveInstances is the number of new instances currently running.
+		ActiveInstances int
+		// StepComplete is true once TargetInstances are healthy.
+		StepComplete bool
+		// Updated is when the progress last changed.
+		Updated time.Time
+		// RollingBack is set when the rollout has been abandoned because the
+		// new version failed.
+		RollingBack bool
+	}
+)
+
+// IsZero returns true if this Strategy replaces all instances at once.
+func (s Strategy) IsZero() bool {
+	return len(s.Steps) == 0
+}
+
+// Clone returns a deep copy of this Strategy.
+func (s Strategy) Clone() Strategy {
+	if s.Steps == nil {
+		return Strategy{}
+	}
+	c := Strategy{Steps: make([]RolloutStep, len(s.Steps))}
+	copy(c.Steps, s.Steps)
+	return c
+}
+
+// Validate implements Flawed on Strategy.
+func (s *Strategy) Validate() []Flaw {
+	flaws := []Flaw{}
+	for n, step := range s.Steps {
+		switch {
+		case step.Instances != 0 && step.Percent != 0:
+			flaws = append(flaws, FatalFlaw("Strategy step %d sets both Instances and Percent", n+1))
+		case step.Instances == 0 && step.Percent == 0:
+			flaws = append(flaws, FatalFlaw("Strategy step %d sets neither Instances nor Percent", n+1))
+		case step.Instances < 0:
+			flaws = append(flaws, FatalFlaw("Strategy step %d Instances less than zero: %d", n+1, step.Instances))
+		case step.Percent < 0 || step.Percent > 100:
+			flaws = append(flaws, FatalFlaw("Strategy step %d Percent must be between 1 and 100, was %d", n+1, step.Percent))
+		}
+		if step.SoakSeconds < 0 {
+			flaws = append(flaws, FatalFlaw("Strategy step %d SoakSeconds less than zero: %d", n+1, step.SoakSeconds))
+		}
+	}
+	return flaws
+}
+
+// Targets returns the number of new instances called for by each step of a
+// rollout to numInstances. Steps which would not increase the number of
+// instances are skipped, and a final step of numInstances is added if the
+// steps stop short of it. The soak time of each target is returned alongside
+// it.
+func (s Strategy) Targets(numInstances int) (targets []int, soaks []time.Duration) {
+	last := 0
+	for _, step := range s.Steps {
+		n := step.Instances
+		if step.Percent != 0 {
+			n = (numInstances*step.Percent + 99) / 100
+		}
+		if n > numInstances {
+			n = numInstances
+		}
+		if n <= last {
+			continue
+		}
+		targets = append(targets, n)
+		soaks = append(soaks, time.Duration(step.SoakSeconds)*time.Second)
+		last = n
+	}
+	if last < numInstances {
+		targets = append(targets, numInstances)
+		soaks = append(soaks, 0)
+	}
+	return
+}
+
+func (rp *RolloutProgress) String() string {
+	if rp == nil {
+		return "no rollout"
+	}
+	if rp.RollingBack {
+		return fmt.Sprintf("rolling back at step %d of %d", rp.Step, rp.Of)
+	}
+	return fmt.Sprintf("step %d of %d: %d/%d new instances", rp.Step, rp.Of, rp.ActiveInstances, rp.TargetInstances)
+}
+
+// planRollout decides which step of a staged rollout pair.Post should be at,
+// and records it in pair.Post.Rollout. Rollouts only apply to deployments
+// that are already running, when their version changes or their last deploy
+// failed.
+func planRollout(pair *DeployablePair, now time.Time) {
+	if pair.Prior == nil || pair.Post == nil || pair.Post.Deployment == nil {
+		return
+	}
+	post := pair.Post
+	if post.Strategy.IsZero() {
+		return
+	}
+	targets, soaks := post.Strategy.Targets(post.NumInstances)
+	if len(targets) < 2 {
+		return
+	}
+	prior := pair.Prior.Rollout
+
+	if prior == nil {
+		changed := pair.Prior.Status == DeployStatusActive && !pair.Prior.SourceID.Equal(post.SourceID)
+		if !changed && pair.Prior.Status != DeployStatusFailed {
+			return
+		}
+		post.Rollout = &RolloutProgress{Step: 1, Of: len(targets), TargetInstances: targets[0], Updated: now}
+		return
+	}
+
+	// A rollout is in progress.
+	if !pair.Prior.SourceID.Equal(post.SourceID) {
+		// The intended version has changed under the rollout: start again.
+		post.Rollout = &RolloutProgress{Step: 1, Of: len(targets), TargetInstances: targets[0], Updated: now}
+		return
+	}
+	// Schedulers only report instance counts, so work out the step from
+	// those.
+	next := *prior
+	next.Of = len(targets)
+	next.Step = 1
+	for next.Step < len(targets) && targets[next.Step-1] < prior.TargetInstances {
+		next.Step++
+	}
+	post.Rollout = &next
+	if pair.Prior.Status == DeployStatusFailed {
+		next.RollingBack = true
+		next.Updated = now
+		return
+	}
+	if !prior.StepComplete || next.Step >= len(targets) {
+		return
+	}
+	if soak := soaks[next.Step-1]; now.Sub(prior.Updated) < soak {
+		return
+	}
+	next.TargetInstances = targets[next.Step]
+	next.Step++
+	next.StepComplete = false
+	next.Updated = now
+}
+
+// Advances returns true if rp calls for more instances than prior.
+func (rp *RolloutProgress) Advances(prior *RolloutProgress) bool {
+	return rp != nil && prior != nil && !rp.RollingBack && rp.TargetInstances > prior.TargetInstances
+}
+
+// rolloutPlanner is a DeployableProcessor which plans the next step of any
+// staged rollouts.
+type rolloutPlanner struct {
+	now time.Time
+}
+
+func (rp rolloutPlanner) HandlePairs(dp *DeployablePair) (*DeployablePair, *DiffResolution) {
+	planRollout(dp, rp.now)
+	return dp, nil
+}
@@ -0,0 +1,98 @@
+package sous
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var canaryStrategy = Strategy{Steps: []RolloutStep{
+	{Instances: 1, SoakSeconds: 60},
+	{Percent: 50, SoakSeconds: 120},
+}}
+
+func TestStrategyTargets(t *testing.T) {
+	targets, soaks := canaryStrategy.Targets(10)
+	assert.Equal(t, []int{1, 5, 10}, targets)
+	assert.Equal(t, []time.Duration{time.Minute, 2 * time.Minute, 0}, soaks)
+
+	// Steps which don't add instances are skipped.
+	targets, _ = canaryStrategy.Targets(2)
+	assert.Equal(t, []int{1, 2}, targets)
+
+	targets, _ = Strategy{}.Targets(4)
+	assert.Equal(t, []int{4}, targets)
+}
+
+func TestStrategyValidate(t *testing.T) {
+	assert.Empty(t, canaryStrategy.Validate())
+
+	bad := Strategy{Steps: []RolloutStep{
+		{Instances: 1, Percent: 10},
+		{},
+		{Percent: 101},
+		{Instances: 1, SoakSeconds: -1},
+	}}
+	assert.Len(t, bad.Validate(), 4)
+}
+
+// plannedRollout diffs an actual state at actualVersion against an intended
+// deployment of intendedVersion, as the resolver does, and returns the pair
+// after the rollout planner has seen it.
+func plannedRollout(t *testing.T, now time.Time, actualVersion, intendedVersion string, status DeployStatus, progress *RolloutProgress) *DeployablePair {
+	dep := func(version string) *Deployment {
+		return &Deployment{
+			SourceID:     MustNewSourceID("github.com/opentable/example", "", version),
+			ClusterName:  "test",
+			DeployConfig: DeployConfig{NumInstances: 10, Strategy: canaryStrategy},
+		}
+	}
+	actual := NewDeployStates(&DeployState{Deployment: *dep(actualVersion), Status: status, Rollout: progress})
+	intended := NewDeployments(dep(intendedVersion))
+
+	pairs := actual.Diff(intended).Pipeline(context.Background(), rolloutPlanner{now: now}).collect()
+	require.Len(t, pairs, 1)
+	return pairs[0]
+}
+
+func TestPlanRollout(t *testing.T) {
+	now := time.Now()
+
+	pair := plannedRollout(t, now, "1.0.0", "1.0.0", DeployStatusActive, nil)
+	assert.Nil(t, pair.Post.Rollout, "no change, no rollout")
+
+	pair = plannedRollout(t, now, "1.0.0", "2.0.0", DeployStatusActive, nil)
+	assert.Equal(t, &RolloutProgress{Step: 1, Of: 3, TargetInstances: 1, Updated: now}, pair.Post.Rollout)
+
+	// Not yet healthy: hold.
+	pair = plannedRollout(t, now, "2.0.0", "2.0.0", DeployStatusPending,
+		&RolloutProgress{TargetInstances: 1, Updated: now.Add(-time.Hour)})
+	require.NotNil(t, pair.Prior.Rollout, "the actual state's progress should reach the planner")
+	assert.Equal(t, 1, pair.Post.Rollout.Step)
+	assert.False(t, pair.Post.Rollout.Advances(pair.Prior.Rollout))
+
+	// Healthy, but still soaking: hold.
+	pair = plannedRollout(t, now, "2.0.0", "2.0.0", DeployStatusPending,
+		&RolloutProgress{TargetInstances: 1, ActiveInstances: 1, StepComplete: true, Updated: now.Add(-time.Second)})
+	assert.False(t, pair.Post.Rollout.Advances(pair.Prior.Rollout))
+
+	// Soaked: advance.
+	pair = plannedRollout(t, now, "2.0.0", "2.0.0", DeployStatusPending,
+		&RolloutProgress{TargetInstances: 1, ActiveInstances: 1, StepComplete: true, Updated: now.Add(-time.Hour)})
+	assert.Equal(t, 2, pair.Post.Rollout.Step)
+	assert.Equal(t, 5, pair.Post.Rollout.TargetInstances)
+	assert.True(t, pair.Post.Rollout.Advances(pair.Prior.Rollout))
+
+	// Failed: roll back.
+	pair = plannedRollout(t, now, "2.0.0", "2.0.0", DeployStatusFailed,
+		&RolloutProgress{TargetInstances: 5, ActiveInstances: 3})
+	assert.True(t, pair.Post.Rollout.RollingBack)
+	assert.Equal(t, 2, pair.Post.Rollout.Step)
+
+	// A failed deploy is retried as a staged rollout.
+	pair = plannedRollout(t, now, "2.0.0", "2.0.0", DeployStatusFailed, nil)
+	assert.Equal(t, 1, pair.Post.Rollout.Step)
+}
@@ -1,7 +1,9 @@
 package sous
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/opentable/sous/util/logging"
@@ -17,11 +19,37 @@ type (
 		User                     User
 		httpErrorCount           int
 		logs                     logging.LogSink
+		// eventsClient streams the server's resolve events.
+		eventsClient eventStreamer
+	}
+
+	// eventStreamer sends the long-lived GET for a server's /events.
+	eventStreamer interface {
+		Stream(ctx context.Context, urlPath string, qParms map[string]string, headers map[string]string) (*http.Response, error)
+	}
+
+	// serverClient derives clients which authenticate to other servers as it
+	// does to its own.
+	serverClient interface {
+		ForServer(serverURL string) (*restful.LiveHTTPClient, error)
 	}
 )
 
-func newSubPoller(clusterName, serverURL string, baseFilter *ResolveFilter, user User, logs logging.LogSink) (*subPoller, error) {
-	cl, err := restful.NewClient(serverURL, logs.Child("http"))
+// EventsPollTimeout is the pause between each polling request to /status
+// while the server's resolve events are streamed. Each event prompts a poll,
+// so this is only a backstop for events which are missed.
+const EventsPollTimeout = 10 * time.Second
+
+// newSubPoller returns a subPoller for the server at serverURL. Its requests
+// are signed and secured as parent's are, if parent is a serverClient.
+func newSubPoller(parent restful.HTTPClient, clusterName, serverURL string, baseFilter *ResolveFilter, user User, logs logging.LogSink) (*subPoller, error) {
+	var cl *restful.LiveHTTPClient
+	var err error
+	if sc, ok := parent.(serverClient); ok {
+		cl, err = sc.ForServer(serverURL)
+	} else {
+		cl, err = restful.NewClient(serverURL, logs.Child("http"))
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -42,28 +70,90 @@ func newSubPoller(clusterName, serverURL string, baseFilter *ResolveFilter, user
 		idFilter:       &id,
 		User:           user,
 		logs:           logs.Child(clusterName),
+		eventsClient:   cl,
 	}, nil
 }
 
-// start issues a new /status request every half second, reporting the state as computed.
-// c.f. pollOnce.
+// start reports the state as computed by pollOnce, first and then each time
+// the server publishes a resolve event for the deployment at /events. Servers
+// which don't publish events are polled every PollTimeout instead.
 func (sub *subPoller) start(rs chan pollResult, done chan struct{}) {
 	rs <- pollResult{url: sub.URL, stat: ResolveNotPolled}
+	// Subscribe before the first poll, so that no change is missed between.
+	events, err := sub.subscribe(done)
+	if err != nil {
+		logging.Log.Debugf("%s: not streaming events, polling instead: %s", sub.ClusterName, err)
+	}
 	pollResult := sub.pollOnce()
 	rs <- pollResult
-	ticker := time.NewTicker(PollTimeout)
-	defer ticker.Stop()
+	interval := PollTimeout
+	if events != nil {
+		interval = EventsPollTimeout
+	}
+	ticker := time.NewTicker(interval)
+	defer func() { ticker.Stop() }()
 	for {
 		select {
 		case <-ticker.C:
 			latest := sub.pollOnce()
 			rs <- latest
+		case _, ok := <-events:
+			if !ok {
+				logging.Log.Debugf("%s: event stream ended, polling instead", sub.ClusterName)
+				events = nil
+				ticker.Stop()
+				ticker = time.NewTicker(PollTimeout)
+			}
+			latest := sub.pollOnce()
+			rs <- latest
 		case <-done:
 			return
 		}
 	}
 }
 
+// subscribe streams the server's resolve events for the deployment, until
+// done is closed or the stream ends, when the returned channel is closed.
+func (sub *subPoller) subscribe(done chan struct{}) (<-chan ResolveEvent, error) {
+	if sub.eventsClient == nil {
+		return nil, errors.Errorf("no client to stream events from %s", sub.URL)
+	}
+	query := sub.locationFilter.QueryMap()
+	query["cluster"] = sub.ClusterName
+	headers := sub.User.HTTPHeaders()
+	headers["Accept"] = "text/event-stream"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	resp, err := sub.eventsClient.Stream(ctx, "./events", query, headers)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		cancel()
+		return nil, errors.Errorf("GET %s: %s", resp.Request.URL, resp.Status)
+	}
+
+	events := make(chan ResolveEvent)
+	go func() {
+		select {
+		case <-done:
+		case <-ctx.Done():
+		}
+		cancel()
+	}()
+	go func() {
+		defer close(events)
+		defer cancel()
+		defer resp.Body.Close()
+		if err := readResolveEvents(resp.Body, events, done); err != nil {
+			logging.Log.Debugf("%s: reading events: %s", sub.ClusterName, err)
+		}
+	}()
+	return events, nil
+}
+
 func (sub *subPoller) result(rs ResolveState, data *statusData, err error) pollResult {
 	resolveID := "<none in progress>"
 	if data.InProgress != nil {
@@ -98,6 +188,13 @@ func (sub *subPoller) pollOnce() pollResult {
 		data.InProgress.Intended = data.Deployments
 	}
 
+	// The server may have given up on the version we're waiting for, and
+	// rolled its deployment back to the last version that worked.
+	if rb := sub.rolledBack(data.Completed); rb != nil {
+		logging.Log.Warn.Printf("Deployment to %s rolled back: %s", sub.ClusterName, rb.Error)
+		return sub.result(ResolveFailed, data, rb.Error)
+	}
+
 	currentState, err := sub.computeState(sub.stateFeatures("in-progress", data.InProgress))
 
 	if currentState == ResolveNotStarted ||
@@ -136,6 +233,23 @@ func diffResolutionFor(rstat *ResolveStatus, rf *ResolveFilter) *DiffResolution
 	return nil
 }
 
+// rolledBack returns the resolution recording a rollback away from the
+// version this subPoller is waiting for, if there is one.
+func (sub *subPoller) rolledBack(rstat *ResolveStatus) *DiffResolution {
+	if rstat == nil {
+		return nil
+	}
+	for _, rez := range rstat.Log {
+		if rez.Desc != RollbackDiff || rez.RolledBackFrom == "" || rez.Error == nil {
+			continue
+		}
+		if sub.idFilter.FilterManifestID(rez.ManifestID) && sub.idFilter.matchTag(rez.RolledBackFrom) {
+			return &rez
+		}
+	}
+	return nil
+}
+
 func serverIntent(rstat *ResolveStatus, rf *ResolveFilter) *Deployment {
 	logging.Log.Debugf("Filtering with %q", rf)
 	if rstat == nil {
@@ -0,0 +1,42 @@
+package sous
+
+import "time"
+
+type (
+	// TaskInfo describes a running task of a deployment.
+	TaskInfo struct {
+		TaskAddress
+		Started time.Time
+		Health  TaskHealth
+	}
+
+	// TaskHealth is the outcome of the latest health check of a task.
+	TaskHealth string
+
+	// A FileChunk is part of a file in the sandbox of a task.
+	FileChunk struct {
+		Data string
+		// NextOffset is the offset to read from for the rest of the file.
+		NextOffset int64
+	}
+
+	// A TaskInspector reads the running tasks of deployments, and the files
+	// they write. None of its methods change anything.
+	TaskInspector interface {
+		// Tasks lists the running tasks of the current deploy of d.
+		Tasks(d *Deployment) ([]TaskInfo, error)
+		// ReadTaskFile reads the file at path in the sandbox of the task
+		// taskID of d, from offset on. The Data of the chunk returned is
+		// empty when there is nothing more to read yet.
+		ReadTaskFile(d *Deployment, taskID, path string, offset int64) (*FileChunk, error)
+	}
+)
+
+const (
+	// TaskHealthy means the latest health check of the task passed.
+	TaskHealthy = TaskHealth("healthy")
+	// TaskUnhealthy means the latest health check of the task failed.
+	TaskUnhealthy = TaskHealth("unhealthy")
+	// TaskHealthUnknown means the task hasn't been health checked.
+	TaskHealthUnknown = TaskHealth("unknown")
+)
@@ -0,0 +1,344 @@
+package sous
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/samsalisbury/semv"
+)
+
+type (
+	// A Probe is an HTTP GET request which the Sous server makes of each task
+	// of a deployment once it is active, to verify that the new version works.
+	// A deployment which fails any of its probes is reported as failed, and
+	// may be rolled back: see RollbackPolicy.
+	Probe struct {
+		// Path is the path requested, e.g. "/health".
+		Path string
+		// PortIndex is the index of the task's port to make the request of,
+		// as for Startup.CheckReadyPortIndex.
+		PortIndex int `yaml:",omitempty"`
+		// Status is the HTTP status expected. If zero, 200 is expected.
+		Status int `yaml:",omitempty"`
+		// BodyMatches, if set, is a regular expression the response body must
+		// match.
+		BodyMatches string `yaml:",omitempty"`
+		// JSONField, if set, is the dot-separated path of a field in the JSON
+		// response body, e.g. "checks.database.status", whose value must be
+		// JSONValue. Array elements are addressed by their index.
+		JSONField string `yaml:",omitempty"`
+		// JSONValue is the value expected of JSONField, as it would be written
+		// in JSON, but without quotes around strings.
+		JSONValue string `yaml:",omitempty"`
+	}
+
+	// Probes are the probes run against a deployment. They are run in order,
+	// and are not compared by DeployConfig.Diff: changing them doesn't
+	// require a new deploy.
+	Probes []Probe
+
+	// A TaskAddress is where a task of a deployment can be reached.
+	TaskAddress struct {
+		TaskID string
+		Host   string
+		// Ports are the ports allocated to the task, in order.
+		Ports []int
+	}
+
+	// A TaskAddresser lists the addresses of the running tasks of a
+	// deployment.
+	TaskAddresser interface {
+		// TaskAddresses returns the addresses of the tasks running
+		// pair.Prior, the current state of a deployment.
+		TaskAddresses(pair *DeployablePair) ([]TaskAddress, error)
+	}
+
+	// A ProbeResult is the outcome of a Probe of a single task.
+	ProbeResult struct {
+		TaskID string
+		URL    string
+		// Status is the HTTP status of the response, or zero if there was
+		// none.
+		Status int
+		// Failure describes why the probe failed, and is empty if it passed.
+		Failure string `json:",omitempty"`
+		// Output is the start of the response body of a failed probe.
+		Output string `json:",omitempty"`
+	}
+
+	// A VerificationError reports that a deployment failed its probes.
+	VerificationError struct {
+		DeploymentID
+		Version semv.Version
+		// Failures are the results of the probes which failed.
+		Failures []ProbeResult
+	}
+
+	// A Verifier runs the probes of deployments once they are active, and
+	// remembers the outcome for each deployment's current version. It is safe
+	// for concurrent use.
+	Verifier struct {
+		Tasks  TaskAddresser
+		Client *http.Client
+		sync.Mutex
+		verifications map[DeploymentID]*verification
+	}
+
+	verification struct {
+		version semv.Version
+		done    bool
+		err     *VerificationError
+	}
+)
+
+// maxProbeOutput is the most of a failed probe's response body kept in its
+// ProbeResult.
+const maxProbeOutput = 1024
+
+// Validate returns a list of flaws with these Probes.
+func (ps Probes) Validate() []Flaw {
+	var flaws []Flaw
+	for n, p := range ps {
+		if !strings.HasPrefix(p.Path, "/") {
+			flaws = append(flaws, FatalFlaw("Verify probe %d Path must begin with /, was %q", n+1, p.Path))
+		}
+		if p.PortIndex < 0 {
+			flaws = append(flaws, FatalFlaw("Verify probe %d PortIndex less than zero: %d", n+1, p.PortIndex))
+		}
+		if p.Status != 0 && (p.Status < 100 || p.Status > 599) {
+			flaws = append(flaws, FatalFlaw("Verify probe %d Status is not an HTTP status: %d", n+1, p.Status))
+		}
+		if _, err := regexp.Compile(p.BodyMatches); err != nil {
+			flaws = append(flaws, FatalFlaw("Verify probe %d BodyMatches is not a valid regular expression: %s", n+1, err))
+		}
+		if p.JSONField == "" && p.JSONValue != "" {
+			flaws = append(flaws, FatalFlaw("Verify probe %d sets JSONValue without JSONField", n+1))
+		}
+	}
+	return flaws
+}
+
+// Clone returns a copy of these Probes.
+func (ps Probes) Clone() Probes {
+	if ps == nil {
+		return nil
+	}
+	c := make(Probes, len(ps))
+	copy(c, ps)
+	return c
+}
+
+// Run makes the request described by p of task, using client. scheme is
+// "http" or "https".
+func (p Probe) Run(client *http.Client, scheme string, task TaskAddress) ProbeResult {
+	result := ProbeResult{TaskID: task.TaskID}
+	if p.PortIndex >= len(task.Ports) {
+		result.Failure = fmt.Sprintf("task has no port with index %d", p.PortIndex)
+		return result
+	}
+	result.URL = fmt.Sprintf("%s://%s:%d%s", scheme, task.Host, task.Ports[p.PortIndex], p.Path)
+	res, err := client.Get(result.URL)
+	if err != nil {
+		result.Failure = err.Error()
+		return result
+	}
+	defer res.Body.Close()
+	result.Status = res.StatusCode
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		result.Failure = fmt.Sprintf("reading response: %s", err)
+		return result
+	}
+	if result.Failure = p.check(res.StatusCode, body); result.Failure != "" {
+		result.Output = string(body)
+		if len(result.Output) > maxProbeOutput {
+			result.Output = result.Output[:maxProbeOutput] + "..."
+		}
+	}
+	return result
+}
+
+// check returns why a response with status and body fails p, or "" if it
+// passes.
+func (p Probe) check(status int, body []byte) string {
+	want := p.Status
+	if want == 0 {
+		want = http.StatusOK
+	}
+	if status != want {
+		return fmt.Sprintf("expected status %d, got %d", want, status)
+	}
+	if p.BodyMatches != "" {
+		re, err := regexp.Compile(p.BodyMatches)
+		if err != nil {
+			return fmt.Sprintf("BodyMatches: %s", err)
+		}
+		if !re.Match(body) {
+			return fmt.Sprintf("body does not match %q", p.BodyMatches)
+		}
+	}
+	if p.JSONField != "" {
+		got, err := jsonField(body, p.JSONField)
+		if err != nil {
+			return err.Error()
+		}
+		if got != p.JSONValue {
+			return fmt.Sprintf("expected %s to be %q, got %q", p.JSONField, p.JSONValue, got)
+		}
+	}
+	return ""
+}
+
+// jsonField returns the value of the field at the dot-separated path in the
+// JSON document body. Strings are returned without their quotes, and other
+// values as they are written in JSON.
+func jsonField(body []byte, path string) (string, error) {
+	dec := json.NewDecoder(strings.NewReader(string(body)))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil && err != io.EOF {
+		return "", fmt.Errorf("body is not JSON: %s", err)
+	}
+	for _, name := range strings.Split(path, ".") {
+		switch t := v.(type) {
+		default:
+			return "", fmt.Errorf("%s not found in body", path)
+		case map[string]interface{}:
+			var ok bool
+			if v, ok = t[name]; !ok {
+				return "", fmt.Errorf("%s not found in body", path)
+			}
+		case []interface{}:
+			i, err := strconv.Atoi(name)
+			if err != nil || i < 0 || i >= len(t) {
+				return "", fmt.Errorf("%s not found in body", path)
+			}
+			v = t[i]
+		}
+	}
+	switch t := v.(type) {
+	case string:
+		return t, nil
+	case nil:
+		return "null", nil
+	default:
+		b, err := json.Marshal(t)
+		return string(b), err
+	}
+}
+
+func (e *VerificationError) Error() string {
+	lines := []string{fmt.Sprintf("Verification of %s version %s failed:", e.DeploymentID, e.Version)}
+	for _, f := range e.Failures {
+		line := fmt.Sprintf("  task %s: GET %s: %s", f.TaskID, f.URL, f.Failure)
+		if f.Output != "" {
+			line += fmt.Sprintf("; response: %s", f.Output)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// NewVerifier returns a Verifier which finds the tasks of deployments using
+// tasks.
+func NewVerifier(tasks TaskAddresser) *Verifier {
+	return &Verifier{
+		Tasks:         tasks,
+		Client:        &http.Client{Timeout: 10 * time.Second},
+		verifications: map[DeploymentID]*verification{},
+	}
+}
+
+// check returns whether the probes of pair.Post have been run against the
+// current version of the deployment, pair.Prior, and the error if they
+// failed. The probes are started in the background the first time the
+// version is checked.
+func (v *Verifier) check(pair *DeployablePair) (bool, *VerificationError) {
+	did := pair.ID()
+	version := pair.Prior.SourceID.Version
+	v.Lock()
+	defer v.Unlock()
+	if ver, ok := v.verifications[did]; ok && ver.version.Equals(version) {
+		return ver.done, ver.err
+	}
+	ver := &verification{version: version}
+	v.verifications[did] = ver
+	go v.verify(pair, ver)
+	return false, nil
+}
+
+// verify runs the probes of pair.Post against the tasks of pair.Prior, and
+// records the outcome in ver. If the tasks can't be listed, ver is forgotten
+// so that the next check tries again.
+func (v *Verifier) verify(pair *DeployablePair, ver *verification) {
+	tasks, err := v.Tasks.TaskAddresses(pair)
+	if err != nil || len(tasks) == 0 {
+		v.Lock()
+		if v.verifications[pair.ID()] == ver {
+			delete(v.verifications, pair.ID())
+		}
+		v.Unlock()
+		return
+	}
+	scheme := "http"
+	if strings.EqualFold(pair.Post.Startup.CheckReadyProtocol, "https") {
+		scheme = "https"
+	}
+	verr := &VerificationError{DeploymentID: pair.ID(), Version: ver.version}
+	for _, p := range pair.Post.Verify {
+		for _, task := range tasks {
+			if result := p.Run(v.Client, scheme, task); result.Failure != "" {
+				verr.Failures = append(verr.Failures, result)
+			}
+		}
+	}
+	v.Lock()
+	defer v.Unlock()
+	ver.done = true
+	if len(verr.Failures) != 0 {
+		ver.err = verr
+	}
+}
+
+// deploymentVerifier is a DeployableProcessor which holds back deployments
+// which are active at their intended version until they pass their probes.
+// Deployments which fail them are recorded as failed in KnownGood, so that
+// they can be rolled back.
+type deploymentVerifier struct {
+	*Verifier
+	KnownGood *KnownGoodVersions
+}
+
+func (dv deploymentVerifier) HandlePairs(dp *DeployablePair) (*DeployablePair, *DiffResolution) {
+	if dv.Verifier == nil || dp.Kind() != SameKind || dp.Prior.Status != DeployStatusActive || len(dp.Post.Verify) == 0 {
+		return dp, nil
+	}
+	done, verr := dv.check(dp)
+	switch {
+	default:
+		return dp, nil
+	case !done:
+		return nil, &DiffResolution{DeploymentID: dp.ID(), Desc: ComingDiff}
+	case verr != nil:
+		if dv.KnownGood != nil {
+			failed := *dp
+			prior := *dp.Prior
+			prior.Status = DeployStatusFailed
+			failed.Prior = &prior
+			dv.KnownGood.observe(&failed)
+		}
+		return nil, &DiffResolution{
+			DeploymentID: dp.ID(),
+			Desc:         UnverifiedDiff,
+			Error:        WrapResolveError(verr),
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package sous
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeTaskAddresser struct {
+	tasks []TaskAddress
+	err   error
+}
+
+func (f fakeTaskAddresser) TaskAddresses(*DeployablePair) ([]TaskAddress, error) {
+	return f.tasks, f.err
+}
+
+func testTaskAddress(t *testing.T, url string) TaskAddress {
+	host, port, err := net.SplitHostPort(strings.TrimPrefix(url, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, _ := strconv.Atoi(port)
+	return TaskAddress{TaskID: "task-1", Host: host, Ports: []int{p}}
+}
+
+func TestProbesValidate(t *testing.T) {
+	ps := Probes{
+		{Path: "/health"},
+		{Path: "health"},
+		{Path: "/", Status: 42},
+		{Path: "/", BodyMatches: "("},
+		{Path: "/", JSONValue: "ok"},
+		{Path: "/", PortIndex: -1},
+	}
+	if flaws := ps.Validate(); len(flaws) != 5 {
+		t.Errorf("got %d flaws; want 5: %v", len(flaws), flaws)
+	}
+}
+
+func TestProbeRun(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		default:
+			http.Error(w, "broken", http.StatusInternalServerError)
+		case "/health":
+			fmt.Fprint(w, `{"status": "ok", "checks": [{"name": "db", "healthy": true}], "uptime": 12}`)
+		}
+	}))
+	defer srv.Close()
+	task := testTaskAddress(t, srv.URL)
+
+	testCases := []struct {
+		probe   Probe
+		failure string
+	}{
+		{Probe{Path: "/health"}, ""},
+		{Probe{Path: "/health", BodyMatches: `"status":\s*"ok"`}, ""},
+		{Probe{Path: "/health", JSONField: "status", JSONValue: "ok"}, ""},
+		{Probe{Path: "/health", JSONField: "checks.0.healthy", JSONValue: "true"}, ""},
+		{Probe{Path: "/health", JSONField: "uptime", JSONValue: "12"}, ""},
+		{Probe{Path: "/broken", Status: 500}, ""},
+		{Probe{Path: "/broken"}, "expected status 200, got 500"},
+		{Probe{Path: "/health", BodyMatches: "degraded"}, `body does not match "degraded"`},
+		{Probe{Path: "/health", JSONField: "status", JSONValue: "degraded"}, `expected status to be "degraded", got "ok"`},
+		{Probe{Path: "/health", JSONField: "checks.1.name"}, "checks.1.name not found in body"},
+		{Probe{Path: "/health", PortIndex: 1}, "task has no port with index 1"},
+	}
+	for _, tc := range testCases {
+		result := tc.probe.Run(http.DefaultClient, "http", task)
+		if result.Failure != tc.failure {
+			t.Errorf("%+v: got failure %q; want %q", tc.probe, result.Failure, tc.failure)
+		}
+		if tc.failure != "" && tc.probe.PortIndex == 0 && result.Output == "" {
+			t.Errorf("%+v: failed without recording the response", tc.probe)
+		}
+	}
+}
+
+func verifyTestPair(probes Probes) *DeployablePair {
+	pair := rollbackTestPair("2.0.0", "2.0.0", DeployStatusActive)
+	pair.Post.Status = DeployStatusActive
+	pair.Post.Verify = probes
+	return pair
+}
+
+// verifyUntilDone runs dv until the verification of pair finishes.
+func verifyUntilDone(t *testing.T, dv deploymentVerifier, pair *DeployablePair) (*DeployablePair, *DiffResolution) {
+	for i := 0; i < 100; i++ {
+		out, rez := dv.HandlePairs(pair)
+		if rez == nil || rez.Desc != ComingDiff {
+			return out, rez
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("verification of %s didn't finish", pair.ID())
+	return nil, nil
+}
+
+func TestDeploymentVerifier(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" {
+			http.Error(w, "broken", http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+	kg := NewKnownGoodVersions()
+	kg.observe(rollbackTestPair("1.0.0", "2.0.0", DeployStatusActive))
+	dv := deploymentVerifier{
+		Verifier:  NewVerifier(fakeTaskAddresser{tasks: []TaskAddress{testTaskAddress(t, srv.URL)}}),
+		KnownGood: kg,
+	}
+
+	unprobed := verifyTestPair(nil)
+	if out, rez := dv.HandlePairs(unprobed); out != unprobed || rez != nil {
+		t.Errorf("deployment without probes was held: %v", rez)
+	}
+
+	passing := verifyTestPair(Probes{{Path: "/health"}})
+	if _, rez := dv.HandlePairs(passing); rez == nil || rez.Desc != ComingDiff {
+		t.Errorf("got %v while verifying; want %s", rez, ComingDiff)
+	}
+	if out, rez := verifyUntilDone(t, dv, passing); out != passing || rez != nil {
+		t.Errorf("deployment which passed its probes was held: %v", rez)
+	}
+
+	failing := verifyTestPair(Probes{{Path: "/health"}, {Path: "/other"}})
+	failing.name.Cluster = "cluster-2"
+	out, rez := verifyUntilDone(t, dv, failing)
+	if out != nil || rez == nil {
+		t.Fatalf("deployment which failed its probes was passed on")
+	}
+	if rez.Desc != UnverifiedDiff {
+		t.Errorf("got %s; want %s", rez.Desc, UnverifiedDiff)
+	}
+	if IsTransientResolveError(rez.Error) {
+		t.Errorf("verification failure is transient")
+	}
+	if msg := rez.Error.Error(); !strings.Contains(msg, "/other: expected status 200, got 500; response: broken") {
+		t.Errorf("error doesn't include the probe output: %s", msg)
+	}
+}
+
+func TestDeploymentVerifier_recordsFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "broken", http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	kg := NewKnownGoodVersions()
+	kg.observe(rollbackTestPair("1.0.0", "2.0.0", DeployStatusActive))
+	dv := deploymentVerifier{
+		Verifier:  NewVerifier(fakeTaskAddresser{tasks: []TaskAddress{testTaskAddress(t, srv.URL)}}),
+		KnownGood: kg,
+	}
+
+	verifyUntilDone(t, dv, verifyTestPair(Probes{{Path: "/health"}}))
+	rbs := kg.Rollbacks()
+	if len(rbs) != 1 {
+		t.Fatalf("got %d rollbacks; want 1", len(rbs))
+	}
+	if rbs[0].Failed.String() != "2.0.0" || rbs[0].KnownGood.String() != "1.0.0" {
+		t.Errorf("got rollback from %s to %s; want from 2.0.0 to 1.0.0", rbs[0].Failed, rbs[0].KnownGood)
+	}
+}
+
+func TestVerifier_retriesWithoutTasks(t *testing.T) {
+	v := NewVerifier(fakeTaskAddresser{})
+	pair := verifyTestPair(Probes{{Path: "/health"}})
+	for i := 0; i < 10; i++ {
+		if done, _ := v.check(pair); done {
+			t.Fatalf("verification without any tasks finished")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,426 @@
+package sous
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/restful"
+	"github.com/pborman/uuid"
+	"github.com/pkg/errors"
+)
+
+type (
+	// A Webhook subscribes a URL to the deployment lifecycle events of a
+	// Sous server. Each event is POSTed to the URL as a WebhookEvent in JSON.
+	Webhook struct {
+		Name string
+		URL  string
+		// Secret, if set, signs each delivery with the Sous-Signature header,
+		// which restful.HMACAuthenticator verifies.
+		Secret string `yaml:",omitempty" json:"-"`
+		// Events are the kinds of event delivered. If empty, every kind is.
+		Events []WebhookEventKind `yaml:",omitempty"`
+		// Clusters are the clusters whose events are delivered. If empty,
+		// every cluster's are.
+		Clusters []string `yaml:",omitempty"`
+	}
+
+	// WebhookEventKind is the kind of a WebhookEvent.
+	WebhookEventKind string
+
+	// A WebhookEvent is the payload of a webhook delivery.
+	WebhookEvent struct {
+		// ID identifies the event. Retried deliveries have the same ID.
+		ID   string
+		Kind WebhookEventKind
+		Time time.Time
+		// Resolution is the resolution the event reports, for every kind but
+		// WebhookGDMWrite.
+		Resolution *DiffResolution `json:",omitempty"`
+		// GDMWrite is the write the event reports, for WebhookGDMWrite.
+		GDMWrite *GDMWrite `json:",omitempty"`
+	}
+
+	// A GDMWrite describes a change to the GDM.
+	GDMWrite struct {
+		User User
+		// Changed are the deployments created, changed or removed.
+		Changed []DeploymentID
+	}
+
+	// A WebhookDelivery records the delivery of an event to a Webhook.
+	WebhookDelivery struct {
+		Webhook  string
+		EventID  string
+		Kind     WebhookEventKind
+		Status   WebhookDeliveryStatus
+		Attempts int
+		// LastAttempt is when the event was last sent, and LastError why that
+		// attempt failed, if it did.
+		LastAttempt time.Time
+		LastError   string `json:",omitempty"`
+	}
+
+	// WebhookDeliveryStatus is the status of a WebhookDelivery.
+	WebhookDeliveryStatus string
+
+	// WebhooksStatus reports the configured Webhooks, and their most recent
+	// deliveries.
+	WebhooksStatus struct {
+		Webhooks []Webhook
+		// Deliveries are the most recent deliveries, newest first.
+		Deliveries []WebhookDelivery
+	}
+
+	// Webhooks delivers events to the Webhooks it was configured with. A
+	// nil *Webhooks delivers nothing.
+	Webhooks struct {
+		hooks []*webhookTarget
+		ls    logging.LogSink
+		// maxAttempts is the number of times a delivery is tried, and backoff
+		// the pause before the first retry, which doubles for each later one.
+		maxAttempts int
+		backoff     time.Duration
+
+		mu         sync.Mutex
+		deliveries []*WebhookDelivery
+		// recurring records the last failure or held change reported for
+		// each deployment, so that it isn't reported again every cycle.
+		recurring map[DeploymentID]string
+	}
+
+	webhookTarget struct {
+		Webhook
+		client *restful.LiveHTTPClient
+	}
+
+	// WebhookStateManager is a StateManager which delivers a WebhookGDMWrite
+	// event for each write.
+	WebhookStateManager struct {
+		StateManager
+		Webhooks *Webhooks
+	}
+
+	webhookFailedMessage struct {
+		logging.CallerInfo
+		delivery WebhookDelivery
+	}
+)
+
+const (
+	// WebhookCreated reports a deployment created.
+	WebhookCreated = WebhookEventKind("created")
+	// WebhookUpdated reports a deployment updated.
+	WebhookUpdated = WebhookEventKind("updated")
+	// WebhookDeleted reports a deployment deleted.
+	WebhookDeleted = WebhookEventKind("deleted")
+	// WebhookFailed reports a deployment which could not be resolved, or
+	// failed once deployed. Transient errors are not reported.
+	WebhookFailed = WebhookEventKind("failed")
+	// WebhookRolledBack reports a deployment rolled back.
+	WebhookRolledBack = WebhookEventKind("rolled-back")
+	// WebhookHeld reports a change held by a freeze or the deletion brake.
+	WebhookHeld = WebhookEventKind("held")
+	// WebhookGDMWrite reports a write to the GDM.
+	WebhookGDMWrite = WebhookEventKind("gdm-write")
+
+	// WebhookPending is the status of a delivery still being tried.
+	WebhookPending = WebhookDeliveryStatus("pending")
+	// WebhookDelivered is the status of a delivery the receiver accepted.
+	WebhookDelivered = WebhookDeliveryStatus("delivered")
+	// WebhookUndeliverable is the status of a delivery which failed every
+	// attempt.
+	WebhookUndeliverable = WebhookDeliveryStatus("failed")
+)
+
+// webhookHistory is the number of deliveries whose status is kept.
+const webhookHistory = 100
+
+// NewWebhooks returns a Webhooks which delivers to hooks.
+func NewWebhooks(hooks []Webhook, ls logging.LogSink) (*Webhooks, error) {
+	wh := &Webhooks{
+		ls:          ls,
+		maxAttempts: 5,
+		backoff:     time.Second,
+		recurring:   map[DeploymentID]string{},
+	}
+	for _, h := range hooks {
+		cl, err := restful.NewClient(h.URL, ls.Child("webhook"))
+		if err != nil {
+			return nil, err
+		}
+		cl.Timeout = 30 * time.Second
+		if err := cl.SetAuth(restful.AuthConfig{HMACSecret: h.Secret}); err != nil {
+			return nil, err
+		}
+		wh.hooks = append(wh.hooks, &webhookTarget{Webhook: h, client: cl})
+	}
+	return wh, nil
+}
+
+// Watch delivers the resolutions published to events, until events stops
+// publishing to it.
+func (wh *Webhooks) Watch(events *ResolveEvents) {
+	if wh == nil || len(wh.hooks) == 0 || events == nil {
+		return
+	}
+	sub := events.Subscribe(nil)
+	go func() {
+		for ev := range sub.C {
+			if ev.Resolution != nil {
+				wh.Resolution(*ev.Resolution)
+			}
+		}
+	}()
+}
+
+// Resolution delivers the event for rez, if it reports a change or a
+// failure.
+func (wh *Webhooks) Resolution(rez DiffResolution) {
+	if wh == nil {
+		return
+	}
+	kind, ok := wh.resolutionKind(rez)
+	if !ok {
+		return
+	}
+	ev := WebhookEvent{ID: uuid.New(), Kind: kind, Time: time.Now(), Resolution: &rez}
+	for _, h := range wh.hooks {
+		if h.wants(kind, rez.Cluster) {
+			go wh.deliver(h, ev)
+		}
+	}
+}
+
+// GDMWrite delivers the event for a write to the GDM by user, which changed
+// the deployments in changed.
+func (wh *Webhooks) GDMWrite(user User, changed []DeploymentID) {
+	if wh == nil || len(changed) == 0 {
+		return
+	}
+	id := uuid.New()
+	now := time.Now()
+	for _, h := range wh.hooks {
+		write := &GDMWrite{User: user}
+		for _, did := range changed {
+			if h.wants(WebhookGDMWrite, did.Cluster) {
+				write.Changed = append(write.Changed, did)
+			}
+		}
+		if len(write.Changed) != 0 {
+			go wh.deliver(h, WebhookEvent{ID: id, Kind: WebhookGDMWrite, Time: now, GDMWrite: write})
+		}
+	}
+}
+
+// resolutionKind returns the kind of event which reports rez, and false if
+// it is not reported. Failures and held changes are reported once, rather
+// than every cycle they recur.
+func (wh *Webhooks) resolutionKind(rez DiffResolution) (WebhookEventKind, bool) {
+	var kind WebhookEventKind
+	switch {
+	default:
+		kind = ""
+	case rez.Desc == HeldDiff, rez.Desc == BlockedDiff:
+		kind = WebhookHeld
+	case rez.Desc == RollbackDiff:
+		kind = WebhookRolledBack
+	case rez.Error != nil:
+		if !IsTransientResolveError(rez.Error) {
+			kind = WebhookFailed
+		}
+	case rez.Desc == CreateDiff:
+		kind = WebhookCreated
+	case rez.Desc == ModifyDiff:
+		kind = WebhookUpdated
+	case rez.Desc == DeleteDiff:
+		kind = WebhookDeleted
+	}
+
+	wh.mu.Lock()
+	defer wh.mu.Unlock()
+	if kind != WebhookHeld && kind != WebhookFailed {
+		delete(wh.recurring, rez.DeploymentID)
+		return kind, kind != ""
+	}
+	key := string(kind)
+	if rez.Error != nil {
+		key += ": " + rez.Error.String
+	}
+	if wh.recurring[rez.DeploymentID] == key {
+		return "", false
+	}
+	wh.recurring[rez.DeploymentID] = key
+	return kind, true
+}
+
+func (h *webhookTarget) wants(kind WebhookEventKind, cluster string) bool {
+	return (len(h.Events) == 0 || containsKind(h.Events, kind)) &&
+		(len(h.Clusters) == 0 || containsString(h.Clusters, cluster))
+}
+
+// deliver POSTs ev to h, retrying with exponential backoff until it is
+// accepted or maxAttempts have failed.
+func (wh *Webhooks) deliver(h *webhookTarget, ev WebhookEvent) {
+	d := wh.record(WebhookDelivery{Webhook: h.Name, EventID: ev.ID, Kind: ev.Kind, Status: WebhookPending})
+	headers := map[string]string{
+		"Content-Type":       "application/json",
+		"Sous-Webhook-Event": string(ev.Kind),
+		"Sous-Delivery":      ev.ID,
+	}
+	backoff := wh.backoff
+	for attempt := 1; ; attempt++ {
+		err := h.client.Post("", nil, ev, headers)
+		wh.locked(func() {
+			d.Attempts = attempt
+			d.LastAttempt = time.Now()
+			d.LastError = ""
+			if err != nil {
+				d.LastError = err.Error()
+			}
+			switch {
+			case err == nil:
+				d.Status = WebhookDelivered
+			case attempt >= wh.maxAttempts:
+				d.Status = WebhookUndeliverable
+			}
+		})
+		if err == nil {
+			return
+		}
+		if attempt >= wh.maxAttempts {
+			logging.Deliver(webhookFailedMessage{
+				CallerInfo: logging.GetCallerInfo(logging.NotHere()),
+				delivery:   wh.delivery(d),
+			}, wh.ls)
+			return
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// record adds d to the recent deliveries, forgetting the oldest beyond
+// webhookHistory.
+func (wh *Webhooks) record(d WebhookDelivery) *WebhookDelivery {
+	wh.mu.Lock()
+	defer wh.mu.Unlock()
+	wh.deliveries = append(wh.deliveries, &d)
+	if len(wh.deliveries) > webhookHistory {
+		wh.deliveries = wh.deliveries[len(wh.deliveries)-webhookHistory:]
+	}
+	return &d
+}
+
+func (wh *Webhooks) locked(f func()) {
+	wh.mu.Lock()
+	defer wh.mu.Unlock()
+	f()
+}
+
+func (wh *Webhooks) delivery(d *WebhookDelivery) WebhookDelivery {
+	wh.mu.Lock()
+	defer wh.mu.Unlock()
+	return *d
+}
+
+// Status returns the configured webhooks, less their secrets, and their
+// most recent deliveries.
+func (wh *Webhooks) Status() WebhooksStatus {
+	status := WebhooksStatus{Webhooks: []Webhook{}, Deliveries: []WebhookDelivery{}}
+	if wh == nil {
+		return status
+	}
+	for _, h := range wh.hooks {
+		hook := h.Webhook
+		hook.Secret = ""
+		status.Webhooks = append(status.Webhooks, hook)
+	}
+	wh.mu.Lock()
+	defer wh.mu.Unlock()
+	for i := len(wh.deliveries) - 1; i >= 0; i-- {
+		status.Deliveries = append(status.Deliveries, *wh.deliveries[i])
+	}
+	return status
+}
+
+// NewWebhookStateManager returns sm, delivering an event to wh for each
+// write.
+func NewWebhookStateManager(sm StateManager, wh *Webhooks) StateManager {
+	if wh == nil || len(wh.hooks) == 0 {
+		return sm
+	}
+	return &WebhookStateManager{StateManager: sm, Webhooks: wh}
+}
+
+// WriteState implements StateWriter on WebhookStateManager. The state is
+// read before it is written, to find which deployments the write changes.
+func (sm *WebhookStateManager) WriteState(state *State, user User) error {
+	var prior Deployments
+	if before, err := sm.StateManager.ReadState(); err == nil {
+		prior, _ = before.Deployments()
+	}
+	if err := sm.StateManager.WriteState(state, user); err != nil {
+		return err
+	}
+	after, err := state.Deployments()
+	if err != nil {
+		return nil
+	}
+	changed := changedDeployments(prior, after)
+	sort.Slice(changed, func(i, j int) bool {
+		return changed[i].String() < changed[j].String()
+	})
+	sm.Webhooks.GDMWrite(user, changed)
+	return nil
+}
+
+// ReadHistory implements HistoryReader on WebhookStateManager, reading from
+// the wrapped StateManager if it keeps history.
+func (sm *WebhookStateManager) ReadHistory(did DeploymentID) (DeploymentHistory, error) {
+	if hr, ok := sm.StateManager.(HistoryReader); ok {
+		return hr.ReadHistory(did)
+	}
+	return nil, errors.Errorf("the wrapped StateManager does not keep history")
+}
+
+func containsKind(kinds []WebhookEventKind, k WebhookEventKind) bool {
+	for _, x := range kinds {
+		if x == k {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(ss []string, s string) bool {
+	for _, x := range ss {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (msg webhookFailedMessage) DefaultLevel() logging.Level {
+	return logging.WarningLevel
+}
+
+func (msg webhookFailedMessage) Message() string {
+	return "webhook delivery failed: " + msg.delivery.LastError
+}
+
+func (msg webhookFailedMessage) MetricsTo(m logging.MetricsSink) {
+	m.IncCounter("webhook-delivery-failures", 1)
+}
+
+func (msg webhookFailedMessage) EachField(f logging.FieldReportFn) {
+	f("@loglov3-otl", "sous-generic-v1")
+	f("sous-webhook", msg.delivery.Webhook)
+	f("sous-webhook-event", string(msg.delivery.Kind))
+	f("sous-webhook-delivery", msg.delivery.EventID)
+	f("sous-webhook-attempts", msg.delivery.Attempts)
+	msg.CallerInfo.EachField(f)
+}
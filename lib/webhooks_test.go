@@ -0,0 +1,223 @@
+package sous
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/restful"
+	"github.com/samsalisbury/semv"
+)
+
+// webhookReceiver is an httptest receiver of webhook deliveries, which fails
+// the first failures of them.
+type webhookReceiver struct {
+	*httptest.Server
+	t        *testing.T
+	secret   string
+	mu       sync.Mutex
+	failures int
+	attempts int
+	events   chan WebhookEvent
+}
+
+func newWebhookReceiver(t *testing.T, secret string, failures int) *webhookReceiver {
+	wr := &webhookReceiver{t: t, secret: secret, failures: failures, events: make(chan WebhookEvent, 10)}
+	wr.Server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			t.Errorf("got %s; want POST", r.Method)
+		}
+		if _, err := (restful.HMACAuthenticator{Secret: []byte(wr.secret)}).Authenticate(r); err != nil {
+			t.Errorf("bad signature: %s", err)
+		}
+		wr.mu.Lock()
+		wr.attempts++
+		fail := wr.attempts <= wr.failures
+		wr.mu.Unlock()
+		if fail {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		ev := WebhookEvent{}
+		if err := json.NewDecoder(r.Body).Decode(&ev); err != nil {
+			t.Errorf("decoding event: %s", err)
+		}
+		if kind := r.Header.Get("Sous-Webhook-Event"); kind != string(ev.Kind) {
+			t.Errorf("got Sous-Webhook-Event %q; want %q", kind, ev.Kind)
+		}
+		wr.events <- ev
+	}))
+	return wr
+}
+
+func (wr *webhookReceiver) next() WebhookEvent {
+	select {
+	case ev := <-wr.events:
+		return ev
+	case <-time.After(2 * time.Second):
+		wr.t.Fatal("timed out waiting for a delivery")
+		return WebhookEvent{}
+	}
+}
+
+func (wr *webhookReceiver) none() {
+	select {
+	case ev := <-wr.events:
+		wr.t.Errorf("got unexpected delivery %v", ev)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func testWebhooks(t *testing.T, hooks ...Webhook) *Webhooks {
+	wh, err := NewWebhooks(hooks, logging.SilentLogSet())
+	if err != nil {
+		t.Fatal(err)
+	}
+	wh.backoff = time.Millisecond
+	wh.maxAttempts = 3
+	return wh
+}
+
+func TestWebhooks_Resolution(t *testing.T) {
+	recv := newWebhookReceiver(t, "sekrit", 0)
+	defer recv.Close()
+	wh := testWebhooks(t, Webhook{Name: "chat", URL: recv.URL + "/hooks", Secret: "sekrit", Clusters: []string{"left"}})
+
+	did := DeploymentID{ManifestID: MustParseManifestID("github.com/example/one"), Cluster: "left"}
+	failure := WrapResolveError(errors.New("no such image"))
+
+	wh.Resolution(DiffResolution{DeploymentID: did, Desc: StableDiff})
+	recv.none()
+
+	wh.Resolution(DiffResolution{DeploymentID: did, Desc: ModifyDiff})
+	if ev := recv.next(); ev.Kind != WebhookUpdated || ev.Resolution.DeploymentID != did {
+		t.Errorf("got %s of %v; want updated %s", ev.Kind, ev.Resolution, did)
+	}
+
+	// A failure is reported once, however many cycles it recurs.
+	wh.Resolution(DiffResolution{DeploymentID: did, Desc: ModifyDiff, Error: failure})
+	if ev := recv.next(); ev.Kind != WebhookFailed {
+		t.Errorf("got %s; want %s", ev.Kind, WebhookFailed)
+	}
+	wh.Resolution(DiffResolution{DeploymentID: did, Desc: ModifyDiff, Error: failure})
+	recv.none()
+
+	// Other clusters are filtered out.
+	other := did
+	other.Cluster = "right"
+	wh.Resolution(DiffResolution{DeploymentID: other, Desc: DeleteDiff})
+	recv.none()
+
+	status := wh.Status()
+	if len(status.Webhooks) != 1 || status.Webhooks[0].Secret != "" {
+		t.Errorf("got webhooks %v; want one, without its secret", status.Webhooks)
+	}
+	if len(status.Deliveries) != 2 || status.Deliveries[0].Kind != WebhookFailed {
+		t.Errorf("got deliveries %v; want the failure, then the update", status.Deliveries)
+	}
+}
+
+func TestWebhooks_retries(t *testing.T) {
+	recv := newWebhookReceiver(t, "", 2)
+	defer recv.Close()
+	wh := testWebhooks(t, Webhook{Name: "chat", URL: recv.URL})
+
+	did := DeploymentID{ManifestID: MustParseManifestID("github.com/example/one"), Cluster: "left"}
+	wh.Resolution(DiffResolution{DeploymentID: did, Desc: CreateDiff})
+	first := recv.next()
+	if first.Kind != WebhookCreated {
+		t.Errorf("got %s; want %s", first.Kind, WebhookCreated)
+	}
+
+	// The status is updated once the receiver has responded.
+	d := finishedDelivery(t, wh, WebhookCreated)
+	if d.Status != WebhookDelivered || d.Attempts != 3 || d.EventID != first.ID {
+		t.Errorf("got delivery %+v; want event %s delivered on attempt 3", d, first.ID)
+	}
+
+	// The receiver fails the next delivery every time.
+	recv.mu.Lock()
+	recv.failures = 100
+	recv.mu.Unlock()
+	wh.Resolution(DiffResolution{DeploymentID: did, Desc: DeleteDiff})
+	d = finishedDelivery(t, wh, WebhookDeleted)
+	if d.Status != WebhookUndeliverable || d.Attempts != 3 || d.LastError == "" {
+		t.Errorf("got delivery %+v; want failed after 3 attempts", d)
+	}
+}
+
+// finishedDelivery waits for the delivery of an event of kind to be
+// delivered or to fail, and returns it.
+func finishedDelivery(t *testing.T, wh *Webhooks, kind WebhookEventKind) WebhookDelivery {
+	for i := 0; i < 1000; i++ {
+		for _, d := range wh.Status().Deliveries {
+			if d.Kind == kind && d.Status != WebhookPending {
+				return d
+			}
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for the %s delivery", kind)
+	return WebhookDelivery{}
+}
+
+func TestWebhookStateManager(t *testing.T) {
+	recv := newWebhookReceiver(t, "", 0)
+	defer recv.Close()
+	wh := testWebhooks(t, Webhook{Name: "audit", URL: recv.URL, Events: []WebhookEventKind{WebhookGDMWrite}})
+
+	mid := MustParseManifestID("github.com/example/one")
+	state := &State{
+		Manifests: NewManifestsFromMap(map[ManifestID]*Manifest{
+			mid: {
+				Source: mid.Source,
+				Kind:   ManifestKindService,
+				Deployments: DeploySpecs{
+					"left": {
+						DeployConfig: DeployConfig{NumInstances: 1},
+						Version:      semv.MustParse("1.0.0"),
+					},
+					"right": {
+						DeployConfig: DeployConfig{NumInstances: 1},
+						Version:      semv.MustParse("1.0.0"),
+					},
+				},
+			},
+		}),
+		Defs: Defs{Clusters: Clusters{"left": {Name: "left"}, "right": {Name: "right"}}},
+	}
+	dummy := NewDummyStateManager()
+	dummy.State = state.Clone()
+	sm := NewWebhookStateManager(dummy, wh)
+
+	next := state.Clone()
+	m, _ := next.Manifests.Get(mid)
+	spec := m.Deployments["right"]
+	spec.Version = semv.MustParse("1.1.0")
+	m.Deployments["right"] = spec
+	user := User{Name: "Test User", Email: "test@example.com"}
+	if err := sm.WriteState(next, user); err != nil {
+		t.Fatal(err)
+	}
+
+	ev := recv.next()
+	if ev.Kind != WebhookGDMWrite || ev.GDMWrite == nil {
+		t.Fatalf("got %s %v; want a GDM write", ev.Kind, ev.GDMWrite)
+	}
+	if ev.GDMWrite.User != user {
+		t.Errorf("got user %v; want %v", ev.GDMWrite.User, user)
+	}
+	want := DeploymentID{ManifestID: mid, Cluster: "right"}
+	if len(ev.GDMWrite.Changed) != 1 || ev.GDMWrite.Changed[0] != want {
+		t.Errorf("got changed %v; want [%s]", ev.GDMWrite.Changed, want)
+	}
+
+	// Resolutions aren't delivered to a webhook only for GDM writes.
+	wh.Resolution(DiffResolution{DeploymentID: want, Desc: ModifyDiff})
+	recv.none()
+}
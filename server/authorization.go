@@ -0,0 +1,137 @@
+package server
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/restful"
+	"github.com/pkg/errors"
+)
+
+// A writeAuthorizer decides whether a client may change manifests: only
+// their owners, and members of the admin groups, may.
+type writeAuthorizer struct {
+	// enabled is true if the server authenticates its clients. Otherwise,
+	// every change is allowed.
+	enabled     bool
+	adminGroups []string
+}
+
+func (ctx ComponentLocator) writeAuthorizer() writeAuthorizer {
+	if ctx.Config == nil {
+		return writeAuthorizer{}
+	}
+	return writeAuthorizer{
+		enabled:     ctx.Config.Auth.Enabled(),
+		adminGroups: ctx.Config.AdminGroups,
+	}
+}
+
+// authorize returns an error explaining why the client which made req may
+// not make the changes from before to after. A manifest which already
+// exists is checked against its existing owners; a new one against the
+// owners it is created with.
+func (wa writeAuthorizer) authorize(req *http.Request, before, after sous.Manifests) error {
+	if !wa.enabled {
+		return nil
+	}
+	id, ok := restful.IdentityFrom(req)
+	if !ok {
+		return errors.New("the client is not authenticated")
+	}
+	if id.InGroup(wa.adminGroups...) {
+		return nil
+	}
+	for _, mid := range changedManifests(before, after) {
+		m, ok := before.Get(mid)
+		if !ok {
+			m, _ = after.Get(mid)
+		}
+		if ownedBy(m, id) {
+			continue
+		}
+		return notOwnerError(id, "change", m)
+	}
+	return nil
+}
+
+// authorizeOwner returns an error explaining why the client which made req
+// may not verb m: only its owners, and members of the admin groups, may.
+func (wa writeAuthorizer) authorizeOwner(req *http.Request, verb string, m *sous.Manifest) error {
+	if !wa.enabled {
+		return nil
+	}
+	id, ok := restful.IdentityFrom(req)
+	if !ok {
+		return errors.New("the client is not authenticated")
+	}
+	if id.InGroup(wa.adminGroups...) || ownedBy(m, id) {
+		return nil
+	}
+	return notOwnerError(id, verb, m)
+}
+
+// notOwnerError explains that id may not verb m, because it doesn't own it.
+func notOwnerError(id restful.Identity, verb string, m *sous.Manifest) error {
+	who := id.Email
+	if who == "" {
+		who = id.Name
+	}
+	if who == "" {
+		who = "an anonymous client"
+	}
+	owners := "it has no owners"
+	if len(m.Owners) != 0 {
+		owners = "its owners are " + strings.Join(m.Owners, ", ")
+	}
+	return errors.Errorf("%s may not %s %s: %s", who, verb, m.ID(), owners)
+}
+
+// authorizeAdmin returns an error explaining why the client which made req
+// may not make changes reserved to the admin groups.
+func (wa writeAuthorizer) authorizeAdmin(req *http.Request) error {
+	if !wa.enabled {
+		return nil
+	}
+	id, ok := restful.IdentityFrom(req)
+	if !ok {
+		return errors.New("the client is not authenticated")
+	}
+	if !id.InGroup(wa.adminGroups...) {
+		return errors.Errorf("only members of %s may do that", strings.Join(wa.adminGroups, ", "))
+	}
+	return nil
+}
+
+// ownedBy returns true if the name or email of id is one of m's owners.
+func ownedBy(m *sous.Manifest, id restful.Identity) bool {
+	for _, o := range m.Owners {
+		if (id.Email != "" && strings.EqualFold(o, id.Email)) || (id.Name != "" && strings.EqualFold(o, id.Name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// changedManifests returns the IDs of the manifests which differ between
+// before and after, including those only in one of them, in a stable order.
+func changedManifests(before, after sous.Manifests) []sous.ManifestID {
+	var changed []sous.ManifestID
+	for mid, b := range before.Snapshot() {
+		a, ok := after.Get(mid)
+		if !ok || !a.Equal(b) {
+			changed = append(changed, mid)
+		}
+	}
+	for mid := range after.Snapshot() {
+		if _, ok := before.Get(mid); !ok {
+			changed = append(changed, mid)
+		}
+	}
+	sort.Slice(changed, func(i, j int) bool {
+		return changed[i].String() < changed[j].String()
+	})
+	return changed
+}
@@ -10,6 +10,9 @@ type (
 	NameData struct {
 		ClusterName string
 		URL         string
+		// Leader is the URL of the server leading the cluster, if servers
+		// elect leaders.
+		Leader string `json:",omitempty"`
 	}
 
 	// ServerListData is the DTO for lists of servers
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+)
+
+// overrideFreezes rejects the change to state from the manifests in before
+// if it touches a frozen cluster, unless the request overrides the freeze.
+// Overrides are recorded in the changed manifests, and logged. The
+// overrides recorded in before are kept, whatever the client sent. If the
+// change is rejected, it returns the status to respond with, and why.
+func overrideFreezes(state *sous.State, before sous.Manifests, user ClientUser, header http.Header, ls logging.LogSink) (int, error) {
+	now := time.Now()
+	freezes := state.Defs.Freezes
+	if len(freezes) == 0 {
+		freezes.RecordOverrides(before, state.Manifests, nil, now)
+		return http.StatusOK, nil
+	}
+	beforeDs, err := (&sous.State{Defs: state.Defs, Manifests: before}).Deployments()
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	afterDs, err := state.Deployments()
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+	reason := header.Get(sous.FreezeOverrideHeader)
+	overrides, err := freezes.Override(beforeDs, afterDs, sous.User(user), reason, now)
+	if err != nil {
+		return http.StatusForbidden, err
+	}
+	freezes.RecordOverrides(before, state.Manifests, overrides, now)
+	for _, o := range overrides {
+		logging.ReportMsg(ls, logging.WarningLevel, fmt.Sprintf("%s overrode the freeze of %s to change %s: %s",
+			o.User, o.Cluster, o.ManifestID, o.Reason))
+	}
+	return http.StatusOK, nil
+}
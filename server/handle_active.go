@@ -0,0 +1,59 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/restful"
+	"github.com/pkg/errors"
+)
+
+type (
+	// ActiveResource describes the resource for the version of a deployment
+	// which is active in this server's cluster.
+	ActiveResource struct {
+		restful.QueryParser
+		context ComponentLocator
+	}
+
+	// GETActiveHandler handles GET exchanges for the active version of a
+	// deployment.
+	GETActiveHandler struct {
+		restful.QueryValues
+		KnownGood *sous.KnownGoodVersions
+	}
+)
+
+func newActiveResource(ctx ComponentLocator) *ActiveResource {
+	return &ActiveResource{context: ctx}
+}
+
+// Get implements Getable for ActiveResource
+func (ar *ActiveResource) Get(_ http.ResponseWriter, req *http.Request, _ httprouter.Params) restful.Exchanger {
+	h := &GETActiveHandler{QueryValues: ar.ParseQuery(req)}
+	if ar.context.AutoResolver != nil {
+		h.KnownGood = ar.context.AutoResolver.KnownGood
+	}
+	return h
+}
+
+// Exchange implements restful.Exchanger
+func (h *GETActiveHandler) Exchange() (interface{}, int) {
+	mid, err := manifestIDFromValues(h.QueryValues)
+	if err != nil {
+		return err, http.StatusNotFound
+	}
+	cluster, err := h.Single("cluster")
+	if err != nil {
+		return err, http.StatusNotFound
+	}
+	if h.KnownGood == nil {
+		return errors.Errorf("this server is not resolving"), http.StatusNotImplemented
+	}
+	active, ok := h.KnownGood.Active(sous.DeploymentID{ManifestID: mid, Cluster: cluster})
+	if !ok {
+		return nil, http.StatusNotFound
+	}
+	return active, http.StatusOK
+}
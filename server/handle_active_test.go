@@ -0,0 +1,26 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/restful"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlesActiveGet(t *testing.T) {
+	get := func(query string, kg *sous.KnownGoodVersions) int {
+		q, err := url.ParseQuery(query)
+		require.NoError(t, err)
+		h := &GETActiveHandler{QueryValues: restful.QueryValues{q}, KnownGood: kg}
+		_, status := h.Exchange()
+		return status
+	}
+
+	assert.Equal(t, http.StatusNotImplemented, get("repo=gh&cluster=test", nil))
+	assert.Equal(t, http.StatusNotFound, get("repo=gh&cluster=test", sous.NewKnownGoodVersions()))
+	assert.Equal(t, http.StatusNotFound, get("repo=gh", sous.NewKnownGoodVersions()))
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/restful"
+	"github.com/pkg/errors"
+)
+
+type (
+	// DeletionBrakeResource describes the resource for the deletion brake of
+	// this server's resolver, and the deletions it holds.
+	DeletionBrakeResource struct {
+		restful.QueryParser
+		context ComponentLocator
+	}
+
+	// GETDeletionBrakeHandler handles GET exchanges for the deletion brake.
+	GETDeletionBrakeHandler struct {
+		Brake *sous.DeletionBrake
+	}
+
+	// DELETEDeletionBrakeHandler handles DELETE exchanges for the deletion
+	// brake, which acknowledge the held deletions, so that the server makes
+	// them.
+	DELETEDeletionBrakeHandler struct {
+		*http.Request
+		restful.QueryValues
+		Brake      *sous.DeletionBrake
+		authorizer writeAuthorizer
+	}
+)
+
+func newDeletionBrakeResource(ctx ComponentLocator) *DeletionBrakeResource {
+	return &DeletionBrakeResource{context: ctx}
+}
+
+func (dbr *DeletionBrakeResource) brake() *sous.DeletionBrake {
+	if dbr.context.AutoResolver == nil || dbr.context.AutoResolver.Resolver == nil {
+		return nil
+	}
+	return dbr.context.AutoResolver.Resolver.DeletionBrake
+}
+
+// Get implements Getable for DeletionBrakeResource.
+func (dbr *DeletionBrakeResource) Get(http.ResponseWriter, *http.Request, httprouter.Params) restful.Exchanger {
+	return &GETDeletionBrakeHandler{Brake: dbr.brake()}
+}
+
+// Delete implements Deleteable for DeletionBrakeResource.
+func (dbr *DeletionBrakeResource) Delete(_ http.ResponseWriter, req *http.Request, _ httprouter.Params) restful.Exchanger {
+	return &DELETEDeletionBrakeHandler{
+		Request:     req,
+		QueryValues: dbr.ParseQuery(req),
+		Brake:       dbr.brake(),
+		authorizer:  dbr.context.writeAuthorizer(),
+	}
+}
+
+// Exchange implements restful.Exchanger.
+func (h *GETDeletionBrakeHandler) Exchange() (interface{}, int) {
+	return h.Brake.Status(), http.StatusOK
+}
+
+// Exchange implements restful.Exchanger.
+func (h *DELETEDeletionBrakeHandler) Exchange() (interface{}, int) {
+	if err := h.authorizer.authorizeAdmin(h.Request); err != nil {
+		return err, http.StatusForbidden
+	}
+	id, err := h.Single("id")
+	if err != nil {
+		return err, http.StatusBadRequest
+	}
+	if !h.Brake.Enabled() {
+		return errors.Errorf("this server has no deletion brake"), http.StatusNotFound
+	}
+	if err := h.Brake.Acknowledge(id); err != nil {
+		return err, http.StatusConflict
+	}
+	return nil, http.StatusNoContent
+}
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/restful"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandlesDeletionBrake(t *testing.T) {
+	brake := sous.NewDeletionBrake(sous.DeletionBrakeConfig{MaxDeletions: 1})
+	del := func(query string, wa writeAuthorizer, id *restful.Identity) int {
+		q, _ := url.ParseQuery(query)
+		req := httptest.NewRequest("DELETE", "/deletion-brake?"+query, nil)
+		if id != nil {
+			req = restful.WithIdentity(req, *id)
+		}
+		h := &DELETEDeletionBrakeHandler{Request: req, QueryValues: restful.QueryValues{q}, Brake: brake, authorizer: wa}
+		_, status := h.Exchange()
+		return status
+	}
+
+	data, status := (&GETDeletionBrakeHandler{Brake: brake}).Exchange()
+	assert.Equal(t, http.StatusOK, status)
+	assert.Nil(t, data.(sous.DeletionBrakeStatus).Held)
+	assert.Equal(t, http.StatusConflict, del("id=abc", writeAuthorizer{}, nil))
+
+	d := func(repo string) *sous.Deployment {
+		return &sous.Deployment{SourceID: sous.MustParseSourceID(repo + ",1.0.0"), ClusterName: "x", DeployConfig: sous.DeployConfig{NumInstances: 1}}
+	}
+	running := sous.NewDeployStates(
+		&sous.DeployState{Deployment: *d("github.com/ot/one"), Status: sous.DeployStatusActive},
+		&sous.DeployState{Deployment: *d("github.com/ot/two"), Status: sous.DeployStatusActive},
+	)
+	held := brake.Check(running.Diff(sous.NewDeployments()).Collect(), time.Now())
+	if !assert.NotNil(t, held) {
+		return
+	}
+
+	admins := writeAuthorizer{enabled: true, adminGroups: []string{"ops"}}
+	assert.Equal(t, http.StatusForbidden, del("id="+held.ID, admins, &restful.Identity{Email: "dev@example.com"}))
+	assert.Equal(t, http.StatusBadRequest, del("", writeAuthorizer{}, nil))
+	assert.Equal(t, http.StatusConflict, del("id=abc", writeAuthorizer{}, nil))
+	assert.Equal(t, http.StatusNoContent, del("id="+held.ID, admins, &restful.Identity{Email: "op@example.com", Groups: []string{"ops"}}))
+	assert.Nil(t, brake.Held())
+}
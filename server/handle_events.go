@@ -0,0 +1,100 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/restful"
+)
+
+type (
+	// EventsHandler streams the ResolveEvents of the server's resolver as
+	// server-sent events. It is a plain http.Handler, rather than a restful
+	// resource, because its response never ends while the client listens.
+	EventsHandler struct {
+		Events *sous.ResolveEvents
+		auth   restful.Authenticator
+		// keepAlive is the pause between comments sent to keep an idle
+		// stream open.
+		keepAlive time.Duration
+	}
+)
+
+// eventsKeepAlive is the default keepAlive of an EventsHandler.
+const eventsKeepAlive = 15 * time.Second
+
+func newEventsHandler(ctx ComponentLocator) *EventsHandler {
+	h := &EventsHandler{auth: ctx.authenticator(), keepAlive: eventsKeepAlive}
+	if ctx.AutoResolver != nil && ctx.AutoResolver.Resolver != nil {
+		h.Events = ctx.AutoResolver.Resolver.Events
+	}
+	return h
+}
+
+// ServeHTTP implements http.Handler. Each event is sent with its type as the
+// event name, and its JSON encoding as the data. The query may filter the
+// resolutions sent by repo, offset, flavor and cluster, as for /plan; phase
+// events are always sent.
+func (h *EventsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		w.Header().Set("Allow", "GET")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.auth != nil {
+		if _, err := h.auth.Authenticate(r); err != nil {
+			http.Error(w, fmt.Sprintf("authentication failed: %s", err), http.StatusUnauthorized)
+			return
+		}
+	}
+	if h.Events == nil {
+		http.Error(w, "this server is not resolving", http.StatusNotImplemented)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	rf, err := resolveFilterFromValues(restful.QueryValues{Values: r.URL.Query()})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	sub := h.Events.Subscribe(rf)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(h.keepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ":\n\n"); err != nil {
+				return
+			}
+		case ev, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(ev)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Type, data); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opentable/sous/lib"
+)
+
+func TestEventsHandler(t *testing.T) {
+	events := sous.NewResolveEvents()
+	srv := httptest.NewServer(&EventsHandler{Events: events, keepAlive: time.Hour})
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/events?repo=github.com/example/one&cluster=left")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d; want 200", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("got Content-Type %q", ct)
+	}
+
+	did := func(repo, cluster string) sous.DeploymentID {
+		return sous.DeploymentID{ManifestID: sous.MustParseManifestID(repo), Cluster: cluster}
+	}
+	// The subscription is made once the response headers are sent.
+	events.Publish(sous.ResolveEvent{Type: sous.ResolutionEvent, Resolution: &sous.DiffResolution{
+		DeploymentID: did("github.com/example/two", "left"), Desc: sous.ModifyDiff}})
+	events.Publish(sous.ResolveEvent{Type: sous.ResolutionEvent, Resolution: &sous.DiffResolution{
+		DeploymentID: did("github.com/example/one", "left"), Desc: sous.ModifyDiff}})
+	events.Publish(sous.ResolveEvent{Type: sous.PhaseEvent, Phase: "finished"})
+
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if line := scanner.Text(); line != "" {
+				lines <- line
+			}
+		}
+		close(lines)
+	}()
+	next := func() string {
+		select {
+		case line := <-lines:
+			return line
+		case <-time.After(time.Second):
+			t.Fatal("timed out reading events")
+			return ""
+		}
+	}
+
+	if line := next(); line != "event: resolution" {
+		t.Errorf("got %q; want the resolution event", line)
+	}
+	if line := next(); !strings.Contains(line, `"github.com/example/one"`) {
+		t.Errorf("got %q; want the resolution of github.com/example/one", line)
+	}
+	if line := next(); line != "event: phase" {
+		t.Errorf("got %q; want the phase event", line)
+	}
+	if line := next(); !strings.Contains(line, `"Phase":"finished"`) {
+		t.Errorf("got %q; want the finished phase", line)
+	}
+}
+
+func TestEventsHandler_notResolving(t *testing.T) {
+	rw := httptest.NewRecorder()
+	(&EventsHandler{}).ServeHTTP(rw, httptest.NewRequest("GET", "/events", nil))
+	if rw.Code != http.StatusNotImplemented {
+		t.Errorf("got status %d; want %d", rw.Code, http.StatusNotImplemented)
+	}
+
+	rw = httptest.NewRecorder()
+	h := &EventsHandler{Events: sous.NewResolveEvents()}
+	h.ServeHTTP(rw, httptest.NewRequest("GET", "/events?bogus=1", nil))
+	if rw.Code != http.StatusBadRequest {
+		t.Errorf("got status %d; want %d", rw.Code, http.StatusBadRequest)
+	}
+}
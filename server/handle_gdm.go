@@ -35,6 +35,7 @@ type (
 		GDM          *sous.State
 		StateManager sous.StateManager
 		User         ClientUser
+		authorizer   writeAuthorizer
 	}
 )
 
@@ -85,6 +86,7 @@ func (gr *GDMResource) Put(_ http.ResponseWriter, req *http.Request, _ httproute
 		GDM:          gr.context.liveState(),
 		StateManager: gr.context.StateManager,
 		User:         gr.GetUser(req),
+		authorizer:   gr.context.writeAuthorizer(),
 	}
 }
 
@@ -104,6 +106,7 @@ func (h *PUTGDMHandler) Exchange() (interface{}, int) {
 		return msg, http.StatusInternalServerError
 	}
 
+	before := state.Manifests
 	state.Manifests, err = deps.PutbackManifests(state.Defs, state.Manifests)
 	if err != nil {
 		msg := "Error getting state"
@@ -111,6 +114,16 @@ func (h *PUTGDMHandler) Exchange() (interface{}, int) {
 		return msg, http.StatusConflict
 	}
 
+	if err := h.authorizer.authorize(h.Request, before, state.Manifests); err != nil {
+		reportHandleGDMMessage("Change not authorized", nil, err, h.LogSink)
+		return err, http.StatusForbidden
+	}
+
+	if status, err := overrideFreezes(state, before, h.User, h.Header, h.LogSink); err != nil {
+		reportHandleGDMMessage("Change rejected by freeze", nil, err, h.LogSink)
+		return err, status
+	}
+
 	flaws := state.Validate()
 	if len(flaws) > 0 {
 		msg := "Invalid GDM"
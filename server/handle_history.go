@@ -0,0 +1,60 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/restful"
+	"github.com/pkg/errors"
+)
+
+type (
+	// HistoryResource describes the resource for the history of deployments.
+	HistoryResource struct {
+		restful.QueryParser
+		context ComponentLocator
+	}
+
+	// GETHistoryHandler handles GET exchanges for the history of a deployment.
+	GETHistoryHandler struct {
+		restful.QueryValues
+		StateManager sous.StateManager
+	}
+)
+
+func newHistoryResource(ctx ComponentLocator) *HistoryResource {
+	return &HistoryResource{context: ctx}
+}
+
+// Get implements Getable for HistoryResource
+func (hr *HistoryResource) Get(_ http.ResponseWriter, req *http.Request, _ httprouter.Params) restful.Exchanger {
+	return &GETHistoryHandler{
+		QueryValues:  hr.ParseQuery(req),
+		StateManager: hr.context.StateManager,
+	}
+}
+
+// Exchange implements restful.Exchanger
+func (h *GETHistoryHandler) Exchange() (interface{}, int) {
+	mid, err := manifestIDFromValues(h.QueryValues)
+	if err != nil {
+		return err, http.StatusNotFound
+	}
+	cluster, err := h.Single("cluster")
+	if err != nil {
+		return err, http.StatusNotFound
+	}
+	hr, ok := h.StateManager.(sous.HistoryReader)
+	if !ok {
+		return errors.Errorf("this server's storage does not keep history"), http.StatusNotImplemented
+	}
+	history, err := hr.ReadHistory(sous.DeploymentID{ManifestID: mid, Cluster: cluster})
+	if err != nil {
+		return err, http.StatusInternalServerError
+	}
+	if len(history) == 0 {
+		return nil, http.StatusNotFound
+	}
+	return history, http.StatusOK
+}
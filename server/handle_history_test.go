@@ -0,0 +1,85 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/restful"
+	"github.com/samsalisbury/semv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type historyStateManager struct {
+	*sous.DummyStateManager
+	histories map[sous.DeploymentID]sous.DeploymentHistory
+}
+
+func (hsm historyStateManager) ReadHistory(did sous.DeploymentID) (sous.DeploymentHistory, error) {
+	return hsm.histories[did], nil
+}
+
+func TestHandlesHistoryGet(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	did := sous.DeploymentID{
+		ManifestID: sous.ManifestID{Source: sous.SourceLocation{Repo: "gh"}},
+		Cluster:    "test",
+	}
+	history := sous.DeploymentHistory{{Version: semv.MustParse("1.0.0")}}
+	sm := historyStateManager{
+		DummyStateManager: sous.NewDummyStateManager(),
+		histories:         map[sous.DeploymentID]sous.DeploymentHistory{did: history},
+	}
+
+	get := func(query string) (interface{}, int) {
+		q, err := url.ParseQuery(query)
+		require.NoError(err)
+		h := &GETHistoryHandler{QueryValues: restful.QueryValues{q}, StateManager: sm}
+		return h.Exchange()
+	}
+
+	data, status := get("repo=gh&cluster=test")
+	assert.Equal(http.StatusOK, status)
+	assert.Equal(history, data)
+
+	_, status = get("repo=gh&cluster=other")
+	assert.Equal(http.StatusNotFound, status)
+
+	_, status = get("repo=gh")
+	assert.Equal(http.StatusNotFound, status)
+}
+
+func TestHandlesHistoryGetWithWebhooks(t *testing.T) {
+	did := sous.DeploymentID{
+		ManifestID: sous.ManifestID{Source: sous.SourceLocation{Repo: "gh"}},
+		Cluster:    "test",
+	}
+	history := sous.DeploymentHistory{{Version: semv.MustParse("1.0.0")}}
+	wh, err := sous.NewWebhooks([]sous.Webhook{{Name: "audit", URL: "http://example.com/hook"}}, logging.SilentLogSet())
+	require.NoError(t, err)
+	sm := sous.NewWebhookStateManager(historyStateManager{
+		DummyStateManager: sous.NewDummyStateManager(),
+		histories:         map[sous.DeploymentID]sous.DeploymentHistory{did: history},
+	}, wh)
+	require.IsType(t, &sous.WebhookStateManager{}, sm)
+
+	q, err := url.ParseQuery("repo=gh&cluster=test")
+	require.NoError(t, err)
+	h := &GETHistoryHandler{QueryValues: restful.QueryValues{q}, StateManager: sm}
+	data, status := h.Exchange()
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, history, data)
+}
+
+func TestHandlesHistoryGetNoHistory(t *testing.T) {
+	q, err := url.ParseQuery("repo=gh&cluster=test")
+	require.NoError(t, err)
+	h := &GETHistoryHandler{QueryValues: restful.QueryValues{q}, StateManager: sous.NewDummyStateManager()}
+	_, status := h.Exchange()
+	assert.Equal(t, http.StatusNotImplemented, status)
+}
@@ -2,6 +2,7 @@ package server
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 
 	"github.com/davecgh/go-spew/spew"
@@ -35,13 +36,16 @@ type (
 		restful.QueryValues
 		User        ClientUser
 		StateWriter sous.StateWriter
+		authorizer  writeAuthorizer
 	}
 
 	// DELETEManifestHandler handles DELETE exchanges for manifests
 	DELETEManifestHandler struct {
 		*sous.State
+		*http.Request
 		restful.QueryValues
 		StateWriter sous.StateWriter
+		authorizer  writeAuthorizer
 	}
 )
 
@@ -66,6 +70,7 @@ func (mr *ManifestResource) Put(_ http.ResponseWriter, req *http.Request, _ http
 		QueryValues: mr.ParseQuery(req),
 		User:        mr.GetUser(req),
 		StateWriter: sous.StateWriter(mr.context.StateManager),
+		authorizer:  mr.context.writeAuthorizer(),
 	}
 }
 
@@ -73,8 +78,10 @@ func (mr *ManifestResource) Put(_ http.ResponseWriter, req *http.Request, _ http
 func (mr *ManifestResource) Delete(_ http.ResponseWriter, req *http.Request, _ httprouter.Params) restful.Exchanger {
 	return &DELETEManifestHandler{
 		State:       mr.context.liveState(),
+		Request:     req,
 		QueryValues: mr.ParseQuery(req),
 		StateWriter: sous.StateWriter(mr.context.StateManager),
+		authorizer:  mr.context.writeAuthorizer(),
 	}
 }
 
@@ -101,7 +108,11 @@ func (dmh *DELETEManifestHandler) Exchange() (interface{}, int) {
 	if !there {
 		return nil, http.StatusNotFound
 	}
+	before := dmh.State.Manifests.Clone()
 	dmh.State.Manifests.Remove(mid)
+	if err := dmh.authorizer.authorize(dmh.Request, before, dmh.State.Manifests); err != nil {
+		return err, http.StatusForbidden
+	}
 
 	return nil, http.StatusNoContent
 }
@@ -122,13 +133,41 @@ func (pmh *PUTManifestHandler) Exchange() (interface{}, int) {
 		pmh.Vomitf(spew.Sdump(flaws))
 		return "Invalid manifest", http.StatusBadRequest
 	}
+	before := pmh.State.Manifests.Clone()
 	pmh.State.Manifests.Set(mid, m)
+	if flaws := clusterFlaws(pmh.State, mid); len(flaws) > 0 {
+		pmh.Vomitf("%s", spew.Sdump(flaws))
+		return fmt.Sprintf("Invalid manifest: %v", flaws), http.StatusBadRequest
+	}
+	if err := pmh.authorizer.authorize(pmh.Request, before, pmh.State.Manifests); err != nil {
+		return err, http.StatusForbidden
+	}
+	if status, err := overrideFreezes(pmh.State, before, pmh.User, pmh.Header, pmh.LogSink); err != nil {
+		return err, status
+	}
 	if err := pmh.StateWriter.WriteState(pmh.State, sous.User(pmh.User)); err != nil {
 		return errors.Wrapf(err, "state recording collision - retry"), http.StatusConflict
 	}
 	return m, http.StatusOK
 }
 
+// clusterFlaws returns the flaws of mid's deployments in state that clusters
+// of their kinds can't run. Deployments which can't be merged, e.g. for
+// clusters missing from the defs, are left to the resolver to report.
+func clusterFlaws(state *sous.State, mid sous.ManifestID) []sous.Flaw {
+	ds, err := state.Deployments()
+	if err != nil {
+		return nil
+	}
+	var flaws []sous.Flaw
+	for _, d := range ds.Snapshot() {
+		if d.ManifestID() == mid {
+			flaws = append(flaws, d.ValidateForCluster()...)
+		}
+	}
+	return flaws
+}
+
 /*
 To recap:
 
@@ -3,9 +3,11 @@ package server
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/opentable/sous/lib"
 	"github.com/opentable/sous/util/logging"
@@ -174,3 +176,153 @@ func TestHandlesManifestPut(t *testing.T) {
 	assert.Equal(changed.Owners[1], "judson")
 
 }
+
+func TestHandlesManifestPutSingularityChecks(t *testing.T) {
+	q, err := url.ParseQuery("repo=gh")
+	require.NoError(t, err)
+
+	put := func(kind string, startup sous.Startup) int {
+		state := sous.NewState()
+		state.Defs.Clusters = sous.Clusters{"ci": {Name: "ci", Kind: kind}}
+		manifest := &sous.Manifest{
+			Source: sous.SourceLocation{Repo: "gh"},
+			Kind:   sous.ManifestKindService,
+			Deployments: sous.DeploySpecs{
+				"ci": sous.DeploySpec{
+					DeployConfig: sous.DeployConfig{
+						Resources: sous.Resources{"cpus": "0.1", "memory": "100", "ports": "1"},
+						Startup:   startup,
+					},
+				},
+			},
+		}
+		buf := &bytes.Buffer{}
+		json.NewEncoder(buf).Encode(manifest)
+		req, err := http.NewRequest("PUT", "", buf)
+		require.NoError(t, err)
+		th := &PUTManifestHandler{
+			Request:     req,
+			StateWriter: &sous.DummyStateManager{State: state},
+			State:       state,
+			QueryValues: restful.QueryValues{q},
+			LogSink:     logging.SilentLogSet(),
+		}
+		_, status := th.Exchange()
+		return status
+	}
+
+	command := sous.Startup{CheckReadyProtocol: "COMMAND", CheckReadyCommand: []string{"check"}}
+	liveness := sous.Startup{CheckReadyProtocol: "HTTP", LivenessInterval: 30}
+
+	assert.Equal(t, http.StatusBadRequest, put("", command))
+	assert.Equal(t, http.StatusBadRequest, put("singularity", liveness))
+	assert.Equal(t, http.StatusOK, put("kubernetes", command))
+	assert.Equal(t, http.StatusOK, put("kubernetes", liveness))
+}
+
+func TestHandlesManifestPutFrozen(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	q, err := url.ParseQuery("repo=gh")
+	require.NoError(err)
+
+	put := func(override string) (*sous.State, int) {
+		state := sous.NewState()
+		state.Defs.Clusters = sous.Clusters{"ci": {Name: "ci"}}
+		state.Defs.Freezes = sous.Freezes{{
+			Clusters:   []string{"ci"},
+			Start:      time.Now().Add(-time.Hour),
+			Overriders: []string{"judson@example.com"},
+		}}
+		manifest := &sous.Manifest{
+			Source: sous.SourceLocation{Repo: "gh"},
+			Kind:   sous.ManifestKindService,
+			Deployments: sous.DeploySpecs{
+				"ci": sous.DeploySpec{
+					DeployConfig: sous.DeployConfig{
+						Resources:    sous.Resources{"cpus": "0.1", "memory": "100", "ports": "1"},
+						NumInstances: 1,
+					},
+				},
+			},
+		}
+		buf := &bytes.Buffer{}
+		require.NoError(json.NewEncoder(buf).Encode(manifest))
+		req, err := http.NewRequest("PUT", "", buf)
+		require.NoError(err)
+		if override != "" {
+			req.Header.Set(sous.FreezeOverrideHeader, override)
+		}
+
+		th := &PUTManifestHandler{
+			Request:     req,
+			StateWriter: &sous.DummyStateManager{State: state},
+			State:       state,
+			QueryValues: restful.QueryValues{q},
+			User:        ClientUser{Name: "Judson", Email: "judson@example.com"},
+			LogSink:     logging.SilentLogSet(),
+		}
+		_, status := th.Exchange()
+		return state, status
+	}
+
+	_, status := put("")
+	assert.Equal(http.StatusForbidden, status)
+
+	state, status := put("fixing the outage")
+	assert.Equal(http.StatusOK, status)
+	changed, found := state.Manifests.Get(sous.ManifestID{Source: sous.SourceLocation{Repo: "gh"}})
+	require.True(found)
+	if assert.Len(changed.FreezeOverrides, 1) {
+		assert.Equal("fixing the outage", changed.FreezeOverrides[0].Reason)
+		assert.Equal("ci", changed.FreezeOverrides[0].Cluster)
+	}
+}
+
+func TestHandlesManifestPutAuthorized(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	q, err := url.ParseQuery("repo=gh")
+	require.NoError(err)
+
+	put := func(id restful.Identity) (interface{}, int) {
+		state := sous.NewState()
+		state.Manifests.Add(&sous.Manifest{
+			Source: sous.SourceLocation{Repo: "gh"},
+			Owners: []string{"judson@example.com"},
+			Kind:   sous.ManifestKindService,
+		})
+		manifest := &sous.Manifest{
+			Source: sous.SourceLocation{Repo: "gh"},
+			Owners: []string{"judson@example.com", "mallory@example.com"},
+			Kind:   sous.ManifestKindService,
+		}
+		buf := &bytes.Buffer{}
+		require.NoError(json.NewEncoder(buf).Encode(manifest))
+		req, err := http.NewRequest("PUT", "", buf)
+		require.NoError(err)
+
+		th := &PUTManifestHandler{
+			Request:     restful.WithIdentity(req, id),
+			StateWriter: &sous.DummyStateManager{State: state},
+			State:       state,
+			QueryValues: restful.QueryValues{q},
+			User:        ClientUser{Name: id.Name, Email: id.Email},
+			LogSink:     logging.SilentLogSet(),
+			authorizer:  writeAuthorizer{enabled: true, adminGroups: []string{"sous-admins"}},
+		}
+		return th.Exchange()
+	}
+
+	data, status := put(restful.Identity{Email: "mallory@example.com"})
+	assert.Equal(http.StatusForbidden, status)
+	assert.Contains(fmt.Sprintf("%s", data), "its owners are judson@example.com")
+
+	_, status = put(restful.Identity{Email: "Judson@example.com"})
+	assert.Equal(http.StatusOK, status)
+
+	_, status = put(restful.Identity{Email: "root@example.com", Groups: []string{"sous-admins"}})
+	assert.Equal(http.StatusOK, status)
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/restful"
+	"github.com/pkg/errors"
+)
+
+type (
+	// PlanResource describes the resource for the changes a rectification
+	// would make to the deployments this server resolves.
+	PlanResource struct {
+		restful.QueryParser
+		context ComponentLocator
+	}
+
+	// GETPlanHandler handles GET exchanges for plans.
+	GETPlanHandler struct {
+		restful.QueryValues
+		GDM *sous.State
+		// Resolver is the server's resolver, whose ResolveFilter selects the
+		// deployments this server resolves.
+		Resolver *sous.Resolver
+	}
+)
+
+func newPlanResource(ctx ComponentLocator) *PlanResource {
+	return &PlanResource{context: ctx}
+}
+
+// Get implements Getable for PlanResource.
+func (pr *PlanResource) Get(_ http.ResponseWriter, req *http.Request, _ httprouter.Params) restful.Exchanger {
+	h := &GETPlanHandler{
+		QueryValues: pr.ParseQuery(req),
+		GDM:         pr.context.liveState(),
+	}
+	if pr.context.AutoResolver != nil {
+		h.Resolver = pr.context.AutoResolver.Resolver
+	}
+	return h
+}
+
+// Exchange implements restful.Exchanger.
+func (h *GETPlanHandler) Exchange() (interface{}, int) {
+	if h.Resolver == nil {
+		return errors.Errorf("this server is not resolving"), http.StatusNotImplemented
+	}
+	if h.GDM == nil {
+		return errors.Errorf("the GDM could not be read"), http.StatusInternalServerError
+	}
+	rf, err := resolveFilterFromValues(h.QueryValues)
+	if err != nil {
+		return err, http.StatusBadRequest
+	}
+	intended, err := h.GDM.Deployments()
+	if err != nil {
+		return err, http.StatusInternalServerError
+	}
+	// Only plan for the deployments this server would resolve.
+	intended = intended.Filter(h.Resolver.FilterDeployment)
+	clusters := h.Resolver.FilteredClusters(h.GDM.Defs.Clusters)
+
+	rez := *h.Resolver
+	rez.ResolveFilter = rf
+	plan, err := rez.Plan(intended, clusters)
+	if err != nil {
+		return err, http.StatusInternalServerError
+	}
+	return plan, http.StatusOK
+}
+
+// resolveFilterFromValues returns the ResolveFilter selected by the query
+// parameters in qv.
+func resolveFilterFromValues(qv restful.QueryValues) (*sous.ResolveFilter, error) {
+	rf := &sous.ResolveFilter{}
+	for name := range qv.Values {
+		value, err := qv.Single(name)
+		if err != nil {
+			return nil, err
+		}
+		if !rf.SetQueryField(name, value) {
+			return nil, errors.Errorf("unknown filter %q", name)
+		}
+	}
+	return rf, nil
+}
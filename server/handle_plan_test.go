@@ -0,0 +1,36 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/restful"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlesPlanGet(t *testing.T) {
+	get := func(query string, rez *sous.Resolver) (interface{}, int) {
+		q, err := url.ParseQuery(query)
+		require.NoError(t, err)
+		h := &GETPlanHandler{QueryValues: restful.QueryValues{q}, GDM: sous.NewState(), Resolver: rez}
+		return h.Exchange()
+	}
+	rez := sous.NewResolver(sous.NewDummyDeployer(), sous.NewDummyRegistry(), &sous.ResolveFilter{}, logging.SilentLogSet())
+
+	_, status := get("cluster=x", nil)
+	assert.Equal(t, http.StatusNotImplemented, status)
+	_, status = get("colour=blue", rez)
+	assert.Equal(t, http.StatusBadRequest, status)
+	_, status = get("cluster=x&cluster=y", rez)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	data, status := get("cluster=x&repo=github.com/ot/one", rez)
+	assert.Equal(t, http.StatusOK, status)
+	if assert.IsType(t, &sous.Plan{}, data) {
+		assert.True(t, data.(*sous.Plan).Empty())
+	}
+}
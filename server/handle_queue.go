@@ -0,0 +1,116 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/restful"
+	"github.com/pkg/errors"
+)
+
+type (
+	// QueueResource describes the resource listing the rectifications queued
+	// by this server's resolver.
+	QueueResource struct {
+		restful.QueryParser
+		context ComponentLocator
+	}
+
+	// GETQueueHandler handles GET exchanges for the rectification queue.
+	GETQueueHandler struct {
+		restful.QueryValues
+		QueueSet *sous.R11nQueueSet
+	}
+
+	// R11nResource describes the resource for a single queued
+	// rectification.
+	R11nResource struct {
+		context ComponentLocator
+	}
+
+	// DELETER11nHandler handles DELETE exchanges for a queued rectification,
+	// which cancel it.
+	DELETER11nHandler struct {
+		*http.Request
+		ID         sous.R11nID
+		QueueSet   *sous.R11nQueueSet
+		authorizer writeAuthorizer
+	}
+
+	// queueStatus is the response to GET /queue.
+	queueStatus struct {
+		Queued []sous.R11nQueueEntry
+	}
+)
+
+func resolverQueueSet(ctx ComponentLocator) *sous.R11nQueueSet {
+	if ctx.AutoResolver == nil || ctx.AutoResolver.Resolver == nil {
+		return nil
+	}
+	return ctx.AutoResolver.Resolver.QueueSet
+}
+
+func newQueueResource(ctx ComponentLocator) *QueueResource {
+	return &QueueResource{context: ctx}
+}
+
+// Get implements Getable for QueueResource.
+func (qr *QueueResource) Get(_ http.ResponseWriter, req *http.Request, _ httprouter.Params) restful.Exchanger {
+	return &GETQueueHandler{
+		QueryValues: qr.ParseQuery(req),
+		QueueSet:    resolverQueueSet(qr.context),
+	}
+}
+
+// Exchange implements restful.Exchanger. The entries may be filtered by the
+// repo, offset, flavor and cluster parameters, as for /plan.
+func (h *GETQueueHandler) Exchange() (interface{}, int) {
+	if h.QueueSet == nil {
+		return errors.Errorf("this server has no rectification queue"), http.StatusNotImplemented
+	}
+	rf, err := resolveFilterFromValues(h.QueryValues)
+	if err != nil {
+		return err, http.StatusBadRequest
+	}
+	status := queueStatus{Queued: []sous.R11nQueueEntry{}}
+	for _, e := range h.QueueSet.Entries() {
+		if rf.FilterManifestID(e.DeploymentID.ManifestID) && rf.FilterClusterName(e.DeploymentID.Cluster) {
+			status.Queued = append(status.Queued, e)
+		}
+	}
+	return status, http.StatusOK
+}
+
+func newR11nResource(ctx ComponentLocator) *R11nResource {
+	return &R11nResource{context: ctx}
+}
+
+// Delete implements Deleteable for R11nResource.
+func (rr *R11nResource) Delete(_ http.ResponseWriter, req *http.Request, ps httprouter.Params) restful.Exchanger {
+	return &DELETER11nHandler{
+		Request:    req,
+		ID:         sous.R11nID(ps.ByName("r11nID")),
+		QueueSet:   resolverQueueSet(rr.context),
+		authorizer: rr.context.writeAuthorizer(),
+	}
+}
+
+// Exchange implements restful.Exchanger. Only rectifications which have not
+// yet begun can be cancelled.
+func (h *DELETER11nHandler) Exchange() (interface{}, int) {
+	if err := h.authorizer.authorizeAdmin(h.Request); err != nil {
+		return err, http.StatusForbidden
+	}
+	if h.QueueSet == nil {
+		return errors.Errorf("this server has no rectification queue"), http.StatusNotImplemented
+	}
+	cancelled, err := h.QueueSet.Cancel(h.ID)
+	if err != nil {
+		return err, http.StatusConflict
+	}
+	if !cancelled {
+		return errors.Errorf("no rectification %s is queued", h.ID), http.StatusNotFound
+	}
+	return nil, http.StatusNoContent
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/restful"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlesQueue(t *testing.T) {
+	proceed := make(chan struct{})
+	defer close(proceed)
+	begun := make(chan struct{}, 2)
+	qs := sous.NewR11nQueueSet(
+		sous.R11nQueuePersistTo(sous.NewMemoryR11nQueueStore(), "", logging.SilentLogSet()),
+		sous.R11nQueueStartWithHandler(func(*sous.QueuedR11n) sous.DiffResolution {
+			begun <- struct{}{}
+			<-proceed
+			return sous.DiffResolution{}
+		}))
+
+	d := func(repo string) *sous.Deployment {
+		return &sous.Deployment{SourceID: sous.MustParseSourceID(repo + ",1.0.0"), ClusterName: "x", DeployConfig: sous.DeployConfig{NumInstances: 1}}
+	}
+	running := sous.NewDeployStates(
+		&sous.DeployState{Deployment: *d("github.com/ot/one"), Status: sous.DeployStatusActive},
+		&sous.DeployState{Deployment: *d("github.com/ot/two"), Status: sous.DeployStatusActive},
+	)
+	var one *sous.QueuedR11n
+	for _, pair := range running.Diff(sous.NewDeployments()).Collect() {
+		qr, ok := qs.PushIfEmpty(sous.NewRectification(*pair))
+		require.True(t, ok)
+		if pair.ID().ManifestID.Source.Repo == "github.com/ot/one" {
+			one = qr
+		}
+	}
+
+	get := func(query string, qs *sous.R11nQueueSet) (interface{}, int) {
+		q, err := url.ParseQuery(query)
+		require.NoError(t, err)
+		return (&GETQueueHandler{QueryValues: restful.QueryValues{q}, QueueSet: qs}).Exchange()
+	}
+	_, status := get("", nil)
+	assert.Equal(t, http.StatusNotImplemented, status)
+	_, status = get("colour=blue", qs)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	data, status := get("", qs)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Len(t, data.(queueStatus).Queued, 2)
+
+	data, _ = get("repo=github.com/ot/one", qs)
+	if queued := data.(queueStatus).Queued; assert.Len(t, queued, 1) {
+		assert.Equal(t, one.ID, queued[0].ID)
+		assert.Equal(t, sous.DeleteDiff, queued[0].Change)
+	}
+
+	del := func(id sous.R11nID, wa writeAuthorizer, ident *restful.Identity) int {
+		req := httptest.NewRequest("DELETE", "/queue/"+string(id), nil)
+		if ident != nil {
+			req = restful.WithIdentity(req, *ident)
+		}
+		_, status := (&DELETER11nHandler{Request: req, ID: id, QueueSet: qs, authorizer: wa}).Exchange()
+		return status
+	}
+	<-begun
+	<-begun
+	admins := writeAuthorizer{enabled: true, adminGroups: []string{"ops"}}
+	assert.Equal(t, http.StatusForbidden, del(one.ID, admins, &restful.Identity{Email: "dev@example.com"}))
+	assert.Equal(t, http.StatusNotFound, del("no-such-r11n", writeAuthorizer{}, nil))
+	assert.Equal(t, http.StatusConflict, del(one.ID, writeAuthorizer{}, nil), "it has already begun")
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/restful"
+	"github.com/pkg/errors"
+)
+
+type (
+	// ResolveResource describes the resource which starts resolving some
+	// deployments straight away, rather than in the server's next cycle.
+	ResolveResource struct {
+		restful.QueryParser
+		context ComponentLocator
+	}
+
+	// POSTResolveHandler handles POST exchanges for targeted resolves.
+	POSTResolveHandler struct {
+		restful.QueryValues
+		AutoResolver *sous.AutoResolver
+	}
+
+	// resolveStarted is the response to a targeted resolve.
+	resolveStarted struct {
+		Filter  string
+		Started time.Time
+	}
+)
+
+func newResolveResource(ctx ComponentLocator) *ResolveResource {
+	return &ResolveResource{context: ctx}
+}
+
+// Post implements Postable for ResolveResource.
+func (rr *ResolveResource) Post(_ http.ResponseWriter, req *http.Request, _ httprouter.Params) restful.Exchanger {
+	return &POSTResolveHandler{
+		QueryValues:  rr.ParseQuery(req),
+		AutoResolver: rr.context.AutoResolver,
+	}
+}
+
+// Exchange implements restful.Exchanger. The deployments are selected by
+// the repo, offset, flavor and cluster parameters, as for /plan. The resolve
+// is started, and the response sent, without waiting for it to finish.
+func (h *POSTResolveHandler) Exchange() (interface{}, int) {
+	if h.AutoResolver == nil {
+		return errors.Errorf("this server is not resolving"), http.StatusNotImplemented
+	}
+	rf, err := resolveFilterFromValues(h.QueryValues)
+	if err != nil {
+		return err, http.StatusBadRequest
+	}
+	if _, ok := h.AutoResolver.ResolveFilter.Narrowed(rf); !ok {
+		return errors.Errorf("%s matches nothing resolved by this server", rf), http.StatusBadRequest
+	}
+	recorder, err := h.AutoResolver.ResolveNow(rf)
+	if err != nil {
+		return err, http.StatusInternalServerError
+	}
+	return resolveStarted{Filter: rf.String(), Started: recorder.CurrentStatus().Started}, http.StatusAccepted
+}
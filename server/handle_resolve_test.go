@@ -0,0 +1,39 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/restful"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlesResolvePost(t *testing.T) {
+	post := func(query string, ar *sous.AutoResolver) (interface{}, int) {
+		q, err := url.ParseQuery(query)
+		require.NoError(t, err)
+		h := &POSTResolveHandler{QueryValues: restful.QueryValues{q}, AutoResolver: ar}
+		return h.Exchange()
+	}
+	ls := logging.SilentLogSet()
+	rez := sous.NewResolver(sous.NewDummyDeployer(), sous.NewDummyRegistry(),
+		&sous.ResolveFilter{Cluster: sous.NewResolveFieldMatcher("x")}, ls)
+	ar := sous.NewAutoResolver(rez, &sous.DummyStateManager{State: sous.NewState()}, ls)
+
+	_, status := post("cluster=x", nil)
+	assert.Equal(t, http.StatusNotImplemented, status)
+	_, status = post("colour=blue", ar)
+	assert.Equal(t, http.StatusBadRequest, status)
+	_, status = post("cluster=y", ar)
+	assert.Equal(t, http.StatusBadRequest, status, "cluster y isn't resolved by this server")
+
+	data, status := post("cluster=x&repo=github.com/ot/one", ar)
+	assert.Equal(t, http.StatusAccepted, status)
+	if assert.IsType(t, resolveStarted{}, data) {
+		assert.Contains(t, data.(resolveStarted).Filter, "github.com/ot/one")
+	}
+}
@@ -0,0 +1,212 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/restful"
+	"github.com/pkg/errors"
+)
+
+type (
+	// RunResource describes the resource for runs of on-demand and one-off
+	// deployments.
+	RunResource struct {
+		restful.QueryParser
+		context ComponentLocator
+	}
+
+	// RunOutputResource describes the resource for the output of runs.
+	RunOutputResource struct {
+		restful.QueryParser
+		context ComponentLocator
+	}
+
+	// POSTRunHandler handles POST exchanges for runs, launching them.
+	POSTRunHandler struct {
+		*sous.State
+		*http.Request
+		restful.QueryValues
+		Runner     sous.Runner
+		authorizer writeAuthorizer
+	}
+
+	// GETRunHandler handles GET exchanges for runs, reporting their status.
+	GETRunHandler struct {
+		*sous.State
+		restful.QueryValues
+		Runner sous.Runner
+	}
+
+	// GETRunOutputHandler handles GET exchanges for the output of runs.
+	GETRunOutputHandler struct {
+		*sous.State
+		*http.Request
+		restful.QueryValues
+		Runner     sous.Runner
+		authorizer writeAuthorizer
+	}
+)
+
+func newRunResource(ctx ComponentLocator) *RunResource {
+	return &RunResource{context: ctx}
+}
+
+func newRunOutputResource(ctx ComponentLocator) *RunOutputResource {
+	return &RunOutputResource{context: ctx}
+}
+
+// Post implements Postable for RunResource.
+func (rr *RunResource) Post(_ http.ResponseWriter, req *http.Request, _ httprouter.Params) restful.Exchanger {
+	return &POSTRunHandler{
+		State:       rr.context.liveState(),
+		Request:     req,
+		QueryValues: rr.ParseQuery(req),
+		Runner:      rr.context.Runner,
+		authorizer:  rr.context.writeAuthorizer(),
+	}
+}
+
+// Get implements Getable for RunResource.
+func (rr *RunResource) Get(_ http.ResponseWriter, req *http.Request, _ httprouter.Params) restful.Exchanger {
+	return &GETRunHandler{
+		State:       rr.context.liveState(),
+		QueryValues: rr.ParseQuery(req),
+		Runner:      rr.context.Runner,
+	}
+}
+
+// Get implements Getable for RunOutputResource.
+func (ror *RunOutputResource) Get(_ http.ResponseWriter, req *http.Request, _ httprouter.Params) restful.Exchanger {
+	return &GETRunOutputHandler{
+		State:       ror.context.liveState(),
+		Request:     req,
+		QueryValues: ror.ParseQuery(req),
+		Runner:      ror.context.Runner,
+		authorizer:  ror.context.writeAuthorizer(),
+	}
+}
+
+// runnableDeployment returns the deployment selected by the repo, offset,
+// flavor and cluster parameters in qv, and its manifest, with the HTTP status
+// to respond with if it can't be run.
+func runnableDeployment(state *sous.State, qv restful.QueryValues) (*sous.Deployment, *sous.Manifest, int, error) {
+	if state == nil {
+		return nil, nil, http.StatusInternalServerError, errors.Errorf("unable to read state")
+	}
+	mid, err := manifestIDFromValues(qv)
+	if err != nil {
+		return nil, nil, http.StatusNotFound, err
+	}
+	cluster, err := qv.Single("cluster")
+	if err != nil {
+		return nil, nil, http.StatusNotFound, err
+	}
+	m, ok := state.Manifests.Get(mid)
+	if !ok {
+		return nil, nil, http.StatusNotFound, errors.Errorf("no manifest %s", mid)
+	}
+	if !m.Kind.Runnable() {
+		return nil, nil, http.StatusBadRequest, errors.Errorf("%s is a %s; only %s and %s manifests can be run", mid, m.Kind, sous.ManifestKindOnDemand, sous.ManifestKindOnce)
+	}
+	deployments, err := state.Deployments()
+	if err != nil {
+		return nil, nil, http.StatusInternalServerError, err
+	}
+	d, ok := deployments.Get(sous.DeploymentID{ManifestID: mid, Cluster: cluster})
+	if !ok {
+		return nil, nil, http.StatusNotFound, errors.Errorf("%s is not deployed to %s", mid, cluster)
+	}
+	return d, m, http.StatusOK, nil
+}
+
+// Exchange implements restful.Exchanger. The body is a sous.RunRequest, and
+// only the owners of the manifest, and members of the admin groups, may run
+// it.
+func (h *POSTRunHandler) Exchange() (interface{}, int) {
+	if h.Runner == nil {
+		return errors.Errorf("this server can't run deployments"), http.StatusNotImplemented
+	}
+	d, m, status, err := runnableDeployment(h.State, h.QueryValues)
+	if err != nil {
+		return err, status
+	}
+	if err := h.authorizer.authorizeOwner(h.Request, "run", m); err != nil {
+		return err, http.StatusForbidden
+	}
+	var rr sous.RunRequest
+	if err := json.NewDecoder(h.Request.Body).Decode(&rr); err != nil {
+		return errors.Wrapf(err, "decoding run request"), http.StatusBadRequest
+	}
+	if rr.RunID == "" {
+		return errors.Errorf("the run request has no RunID"), http.StatusBadRequest
+	}
+	if err := h.Runner.Run(d, rr.RunID, rr.Args); err != nil {
+		return err, http.StatusBadGateway
+	}
+	return &sous.RunStatus{RunID: rr.RunID, State: sous.RunPending, ExitCode: -1}, http.StatusCreated
+}
+
+// Exchange implements restful.Exchanger, reporting on the run given by the
+// run parameter.
+func (h *GETRunHandler) Exchange() (interface{}, int) {
+	if h.Runner == nil {
+		return errors.Errorf("this server can't run deployments"), http.StatusNotImplemented
+	}
+	d, _, status, err := runnableDeployment(h.State, h.QueryValues)
+	if err != nil {
+		return err, status
+	}
+	runID, err := h.Single("run")
+	if err != nil {
+		return err, http.StatusNotFound
+	}
+	rs, err := h.Runner.RunStatus(d, runID)
+	if err != nil {
+		return err, http.StatusBadGateway
+	}
+	return rs, http.StatusOK
+}
+
+// Exchange implements restful.Exchanger, returning the output of the run
+// given by the run parameter, on the stream given by the stream parameter
+// (stdout by default), from the offset given by the from parameter. (The
+// offset parameter is the offset of the manifest's source.) As with running
+// it, only the owners of the manifest, and members of the admin groups, may
+// read it.
+func (h *GETRunOutputHandler) Exchange() (interface{}, int) {
+	if h.Runner == nil {
+		return errors.Errorf("this server can't run deployments"), http.StatusNotImplemented
+	}
+	d, m, status, err := runnableDeployment(h.State, h.QueryValues)
+	if err != nil {
+		return err, status
+	}
+	if err := h.authorizer.authorizeOwner(h.Request, "read the output of", m); err != nil {
+		return err, http.StatusForbidden
+	}
+	runID, err := h.Single("run")
+	if err != nil {
+		return err, http.StatusNotFound
+	}
+	stream, err := h.Single("stream", "stdout")
+	if err != nil {
+		return err, http.StatusBadRequest
+	}
+	offsetParam, err := h.Single("from", "0")
+	if err != nil {
+		return err, http.StatusBadRequest
+	}
+	offset, err := strconv.ParseInt(offsetParam, 10, 64)
+	if err != nil || offset < 0 {
+		return errors.Errorf("from must be a non-negative number, was %q", offsetParam), http.StatusBadRequest
+	}
+	out, err := h.Runner.RunOutput(d, runID, stream, offset)
+	if err != nil {
+		return err, http.StatusBadGateway
+	}
+	return out, http.StatusOK
+}
@@ -0,0 +1,125 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/restful"
+	"github.com/samsalisbury/semv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runTestState(kind sous.ManifestKind) *sous.State {
+	state := sous.NewState()
+	state.Defs.Clusters = sous.Clusters{"left": {Name: "left", BaseURL: "http://left.example.com"}}
+	state.Manifests.Add(&sous.Manifest{
+		Source: sous.SourceLocation{Repo: "gh"},
+		Owners: []string{"judson@example.com"},
+		Kind:   kind,
+		Deployments: sous.DeploySpecs{"left": {
+			Version:      semv.MustParse("1.0.0"),
+			DeployConfig: sous.DeployConfig{Resources: sous.Resources{"cpus": "0.1", "memory": "32", "ports": "1"}},
+		}},
+	})
+	return state
+}
+
+func postRun(t *testing.T, state *sous.State, query string, id *restful.Identity, runner sous.Runner) (interface{}, int) {
+	q, err := url.ParseQuery(query)
+	require.NoError(t, err)
+	buf := &bytes.Buffer{}
+	require.NoError(t, json.NewEncoder(buf).Encode(sous.RunRequest{RunID: "run-1", Args: []string{"-v"}}))
+	req, err := http.NewRequest("POST", "", buf)
+	require.NoError(t, err)
+	if id != nil {
+		req = restful.WithIdentity(req, *id)
+	}
+	h := &POSTRunHandler{
+		State:       state,
+		Request:     req,
+		QueryValues: restful.QueryValues{q},
+		Runner:      runner,
+		authorizer:  writeAuthorizer{enabled: true, adminGroups: []string{"sous-admins"}},
+	}
+	return h.Exchange()
+}
+
+func getRunOutput(t *testing.T, state *sous.State, q url.Values, id *restful.Identity, runner sous.Runner) (interface{}, int) {
+	req, err := http.NewRequest("GET", "", nil)
+	require.NoError(t, err)
+	if id != nil {
+		req = restful.WithIdentity(req, *id)
+	}
+	h := &GETRunOutputHandler{
+		State:       state,
+		Request:     req,
+		QueryValues: restful.QueryValues{q},
+		Runner:      runner,
+		authorizer:  writeAuthorizer{enabled: true, adminGroups: []string{"sous-admins"}},
+	}
+	return h.Exchange()
+}
+
+func TestPOSTRunHandler(t *testing.T) {
+	owner := &restful.Identity{Email: "judson@example.com"}
+
+	runner := sous.NewDummyRunner()
+	_, status := postRun(t, runTestState(sous.ManifestKindOnDemand), "repo=gh&cluster=left", owner, runner)
+	assert.Equal(t, http.StatusCreated, status)
+	assert.Equal(t, []string{"-v"}, runner.Runs["run-1"].Args)
+
+	runner = sous.NewDummyRunner()
+	data, status := postRun(t, runTestState(sous.ManifestKindOnDemand), "repo=gh&cluster=left", &restful.Identity{Email: "mallory@example.com"}, runner)
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Contains(t, fmt.Sprint(data), "may not run")
+	assert.Empty(t, runner.Runs)
+
+	_, status = postRun(t, runTestState(sous.ManifestKindOnDemand), "repo=gh&cluster=left", &restful.Identity{Email: "root@example.com", Groups: []string{"sous-admins"}}, runner)
+	assert.Equal(t, http.StatusCreated, status)
+
+	_, status = postRun(t, runTestState(sous.ManifestKindService), "repo=gh&cluster=left", owner, runner)
+	assert.Equal(t, http.StatusBadRequest, status, "services can't be run")
+
+	_, status = postRun(t, runTestState(sous.ManifestKindOnce), "repo=gh&cluster=right", owner, runner)
+	assert.Equal(t, http.StatusNotFound, status, "not deployed to the cluster")
+}
+
+func TestGETRunHandlers(t *testing.T) {
+	runner := sous.NewDummyRunner()
+	state := runTestState(sous.ManifestKindOnce)
+	_, status := postRun(t, state, "repo=gh&cluster=left", nil, runner)
+	require.Equal(t, http.StatusForbidden, status, "unauthenticated clients can't run")
+	_, status = postRun(t, state, "repo=gh&cluster=left", &restful.Identity{Email: "judson@example.com"}, runner)
+	require.Equal(t, http.StatusCreated, status)
+
+	q, err := url.ParseQuery("repo=gh&cluster=left&run=run-1")
+	require.NoError(t, err)
+	data, status := (&GETRunHandler{State: state, QueryValues: restful.QueryValues{q}, Runner: runner}).Exchange()
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, sous.RunSucceeded, data.(*sous.RunStatus).State)
+
+	owner := &restful.Identity{Email: "judson@example.com"}
+	q.Set("from", "-1")
+	data, status = getRunOutput(t, state, q, owner, runner)
+	assert.Equal(t, http.StatusBadRequest, status, "%v", data)
+
+	q.Set("from", "12")
+	data, status = getRunOutput(t, state, q, owner, runner)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, int64(12), data.(*sous.RunOutput).NextOffset)
+
+	_, status = getRunOutput(t, state, q, nil, runner)
+	assert.Equal(t, http.StatusForbidden, status, "unauthenticated clients can't read the output")
+	data, status = getRunOutput(t, state, q, &restful.Identity{Email: "mallory@example.com"}, runner)
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Contains(t, fmt.Sprint(data), "may not read the output of")
+
+	_, status = (&GETRunHandler{State: state, QueryValues: restful.QueryValues{q}}).Exchange()
+	assert.Equal(t, http.StatusNotImplemented, status)
+}
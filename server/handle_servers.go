@@ -7,8 +7,10 @@ import (
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/opentable/sous/config"
+	"github.com/opentable/sous/lib"
 	"github.com/opentable/sous/util/logging"
 	"github.com/opentable/sous/util/restful"
+	"github.com/pkg/errors"
 )
 
 type (
@@ -19,7 +21,9 @@ type (
 
 	// ServerListHandler handles GET for /servers
 	ServerListHandler struct {
-		Config *config.Config
+		Config     *config.Config
+		Leadership *sous.Leadership
+		Log        logging.LogSink
 	}
 
 	// ServerListUpdater handles PUT for /servers
@@ -37,7 +41,9 @@ func newServerListResource(context ComponentLocator) *ServerListResource {
 // Get implements Getable on ServerListResource, which marks it as accepting GET requests
 func (slr *ServerListResource) Get(http.ResponseWriter, *http.Request, httprouter.Params) restful.Exchanger {
 	return &ServerListHandler{
-		Config: slr.context.Config,
+		Config:     slr.context.Config,
+		Leadership: slr.context.Leadership,
+		Log:        slr.context.LogSink,
 	}
 }
 
@@ -54,7 +60,15 @@ func (slr *ServerListResource) Put(_ http.ResponseWriter, req *http.Request, _ h
 func (slh *ServerListHandler) Exchange() (interface{}, int) {
 	data := ServerListData{Servers: []NameData{}}
 	for name, url := range slh.Config.SiblingURLs {
-		data.Servers = append(data.Servers, NameData{ClusterName: name, URL: url})
+		nd := NameData{ClusterName: name, URL: url}
+		if slh.Leadership != nil {
+			leader, err := slh.Leadership.LeaderOf(name)
+			if err != nil {
+				logging.ReportError(slh.Log, errors.Wrapf(err, "finding the leader of %s", name))
+			}
+			nd.Leader = leader
+		}
+		data.Servers = append(data.Servers, nd)
 	}
 	return data, 200
 }
@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/opentable/sous/config"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -34,3 +36,22 @@ func TestHandleServerList_Get(t *testing.T) {
 	assert.Equal(list.Servers[0].ClusterName, "left")
 	assert.Equal(list.Servers[1].ClusterName, "right")
 }
+
+func TestHandleServerList_Get_leaders(t *testing.T) {
+	election := sous.NewMemoryLeaderElection()
+	election.Elector("https://left-2.sous.com").Campaign("left")
+	h := &ServerListHandler{
+		Config: &config.Config{
+			SiblingURLs: map[string]string{"left": "https://left.sous.com", "right": "https://right.sous.com"},
+		},
+		Leadership: sous.NewLeadership(election.Elector("https://left-1.sous.com"), "left", logging.SilentLogSet()),
+	}
+
+	rez, stat := h.Exchange()
+	assert.Equal(t, 200, stat)
+	leaders := map[string]string{}
+	for _, s := range rez.(ServerListData).Servers {
+		leaders[s.ClusterName] = s.Leader
+	}
+	assert.Equal(t, map[string]string{"left": "https://left-2.sous.com", "right": ""}, leaders)
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/opentable/sous/lib"
+)
+
+type (
+	// SingularityWebhookHandler receives Singularity's request, deploy and
+	// task webhooks, and invalidates the deploy states cached for the
+	// request each concerns, so that the next poll assembles it afresh. It
+	// is a plain http.Handler, rather than a restful resource, because
+	// Singularity can't authenticate itself; since it only causes extra
+	// polling, it needn't.
+	SingularityWebhookHandler struct {
+		Invalidator sous.StateInvalidator
+	}
+
+	// singularityWebhook holds the parts of the bodies of Singularity's
+	// webhooks which identify the request they concern.
+	singularityWebhook struct {
+		// Request is set in request webhooks.
+		Request *struct {
+			ID string `json:"id"`
+		} `json:"request"`
+		// DeployMarker is set in deploy webhooks.
+		DeployMarker *struct {
+			RequestID string `json:"requestId"`
+		} `json:"deployMarker"`
+		// TaskID is set in task webhooks.
+		TaskID *struct {
+			RequestID string `json:"requestId"`
+		} `json:"taskId"`
+	}
+)
+
+func newSingularityWebhookHandler(ctx ComponentLocator) *SingularityWebhookHandler {
+	return &SingularityWebhookHandler{Invalidator: ctx.StateInvalidator}
+}
+
+func (wh singularityWebhook) requestID() string {
+	switch {
+	case wh.Request != nil && wh.Request.ID != "":
+		return wh.Request.ID
+	case wh.DeployMarker != nil && wh.DeployMarker.RequestID != "":
+		return wh.DeployMarker.RequestID
+	case wh.TaskID != nil:
+		return wh.TaskID.RequestID
+	}
+	return ""
+}
+
+// ServeHTTP implements http.Handler.
+func (h *SingularityWebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.Invalidator == nil {
+		http.Error(w, "this server doesn't cache Singularity's deploy states", http.StatusNotImplemented)
+		return
+	}
+	var wh singularityWebhook
+	if err := json.NewDecoder(r.Body).Decode(&wh); err != nil {
+		http.Error(w, fmt.Sprintf("decoding webhook: %s", err), http.StatusBadRequest)
+		return
+	}
+	id := wh.requestID()
+	if id == "" {
+		http.Error(w, "the webhook names no request", http.StatusBadRequest)
+		return
+	}
+	h.Invalidator.InvalidateRequest(id)
+	w.WriteHeader(http.StatusNoContent)
+}
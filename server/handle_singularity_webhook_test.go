@@ -0,0 +1,42 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingInvalidator []string
+
+func (ri *recordingInvalidator) InvalidateRequest(requestID string) {
+	*ri = append(*ri, requestID)
+}
+
+func TestSingularityWebhookHandler(t *testing.T) {
+	invalidated := &recordingInvalidator{}
+	h := &SingularityWebhookHandler{Invalidator: invalidated}
+
+	post := func(body string) int {
+		rw := httptest.NewRecorder()
+		h.ServeHTTP(rw, httptest.NewRequest("POST", "/webhooks/singularity", strings.NewReader(body)))
+		return rw.Code
+	}
+
+	assert.Equal(t, http.StatusNoContent, post(`{"request": {"id": "request-1"}, "eventType": "UPDATED"}`))
+	assert.Equal(t, http.StatusNoContent, post(`{"deployMarker": {"requestId": "request-2", "deployId": "deploy-1"}}`))
+	assert.Equal(t, http.StatusNoContent, post(`{"taskId": {"requestId": "request-3", "deployId": "deploy-1"}}`))
+	assert.Equal(t, http.StatusBadRequest, post(`{"eventType": "UPDATED"}`))
+	assert.Equal(t, http.StatusBadRequest, post(`not json`))
+	assert.Equal(t, []string{"request-1", "request-2", "request-3"}, []string(*invalidated))
+
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest("GET", "/webhooks/singularity", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rw.Code)
+
+	rw = httptest.NewRecorder()
+	(&SingularityWebhookHandler{}).ServeHTTP(rw, httptest.NewRequest("POST", "/webhooks/singularity", strings.NewReader(`{"request": {"id": "request-1"}}`)))
+	assert.Equal(t, http.StatusNotImplemented, rw.Code)
+}
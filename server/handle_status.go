@@ -18,11 +18,15 @@ type (
 	StatusHandler struct {
 		AutoResolver *sous.AutoResolver
 		*sous.ResolveFilter
+		Leadership *sous.Leadership
 	}
 
 	statusData struct {
 		Deployments           []*sous.Deployment
 		Completed, InProgress *sous.ResolveStatus
+		// Leadership reports the leader of this server's cluster, if servers
+		// elect leaders.
+		Leadership *sous.LeadershipStatus `json:",omitempty"`
 	}
 )
 
@@ -35,6 +39,7 @@ func (sr *StatusResource) Get(http.ResponseWriter, *http.Request, httprouter.Par
 	return &StatusHandler{
 		AutoResolver:  sr.context.AutoResolver,
 		ResolveFilter: sr.context.ResolveFilter,
+		Leadership:    sr.context.Leadership,
 	}
 }
 
@@ -45,5 +50,10 @@ func (h *StatusHandler) Exchange() (interface{}, int) {
 		status.Deployments = append(status.Deployments, d)
 	}
 	status.Completed, status.InProgress = h.AutoResolver.Statuses()
+	if h.Leadership != nil {
+		// A leader which cannot be found is reported as none.
+		ls, _ := h.Leadership.Status()
+		status.Leadership = &ls
+	}
 	return status, http.StatusOK
 }
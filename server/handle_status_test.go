@@ -39,3 +39,18 @@ func TestHandlesStatusGet(t *testing.T) {
 	assert.Equal(status, 200)
 	assert.Len(data.(statusData).Deployments, 0)
 }
+
+func TestHandlesStatusGet_leadership(t *testing.T) {
+	election := sous.NewMemoryLeaderElection()
+	election.Elector("http://leader").Campaign("cluster-1")
+	th := &StatusHandler{
+		AutoResolver: &sous.AutoResolver{
+			GDM:     sous.NewDeployments(),
+			LogSink: logging.SilentLogSet(),
+		},
+		Leadership: sous.NewLeadership(election.Elector("http://follower"), "cluster-1", logging.SilentLogSet()),
+	}
+	data, status := th.Exchange()
+	assert.Equal(t, 200, status)
+	assert.Equal(t, &sous.LeadershipStatus{Cluster: "cluster-1", Leader: "http://leader"}, data.(statusData).Leadership)
+}
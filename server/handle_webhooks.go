@@ -0,0 +1,36 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/restful"
+)
+
+type (
+	// WebhooksResource describes the resource for the webhooks of this
+	// server, and the status of their recent deliveries.
+	WebhooksResource struct {
+		context ComponentLocator
+	}
+
+	// GETWebhooksHandler handles GET exchanges for webhooks.
+	GETWebhooksHandler struct {
+		Webhooks *sous.Webhooks
+	}
+)
+
+func newWebhooksResource(ctx ComponentLocator) *WebhooksResource {
+	return &WebhooksResource{context: ctx}
+}
+
+// Get implements Getable for WebhooksResource.
+func (wr *WebhooksResource) Get(http.ResponseWriter, *http.Request, httprouter.Params) restful.Exchanger {
+	return &GETWebhooksHandler{Webhooks: wr.context.Webhooks}
+}
+
+// Exchange implements restful.Exchanger.
+func (h *GETWebhooksHandler) Exchange() (interface{}, int) {
+	return h.Webhooks.Status(), http.StatusOK
+}
@@ -0,0 +1,29 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+)
+
+func TestGETWebhooksHandler(t *testing.T) {
+	data, status := (&GETWebhooksHandler{}).Exchange()
+	if status != http.StatusOK {
+		t.Errorf("got status %d; want 200", status)
+	}
+	if ws := data.(sous.WebhooksStatus); len(ws.Webhooks) != 0 || len(ws.Deliveries) != 0 {
+		t.Errorf("got %v; want no webhooks", ws)
+	}
+
+	wh, err := sous.NewWebhooks([]sous.Webhook{{Name: "chat", URL: "http://chat.example.com", Secret: "sekrit"}}, logging.SilentLogSet())
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ = (&GETWebhooksHandler{Webhooks: wh}).Exchange()
+	ws := data.(sous.WebhooksStatus)
+	if len(ws.Webhooks) != 1 || ws.Webhooks[0].Name != "chat" || ws.Webhooks[0].Secret != "" {
+		t.Errorf("got webhooks %v; want chat, without its secret", ws.Webhooks)
+	}
+}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/restful"
+	"github.com/pkg/errors"
+)
+
+// forwardedByHeader marks requests forwarded from a follower to its leader,
+// so that they are never forwarded again.
+const forwardedByHeader = "Sous-Forwarded-By"
+
+// leaderReadPaths are the resources whose reads are answered from the state
+// of the server resolving the cluster, rather than from shared storage. A
+// follower's answers would be empty, so it forwards these reads as well.
+var leaderReadPaths = []string{
+	"/active",
+	"/deletion-brake",
+	"/events",
+	"/plan",
+	"/queue",
+	"/status",
+	"/webhooks",
+}
+
+// forwardsToLeader returns true if a follower forwards req to the leader.
+func forwardsToLeader(req *http.Request) bool {
+	switch req.Method {
+	case "PUT", "POST", "DELETE":
+		return true
+	case "GET", "HEAD":
+		for _, p := range leaderReadPaths {
+			if req.URL.Path == p || strings.HasPrefix(req.URL.Path, p+"/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// forwardToLeader wraps h so that, while this server follows another, writes
+// and reads of leaderReadPaths are forwarded to the leader; other reads are
+// served by h. If no leader is known, every request is served by h.
+//
+// Header credentials, i.e. signatures and bearer tokens, are forwarded as
+// they are. Client certificates can't be: requests authenticated only by one
+// are signed as the identity it proves, with auth's HMACSecret, which the
+// leader must share. Groups from the certificate are not passed on; the
+// leader derives the identity's groups from its own Groups.
+func forwardToLeader(l *sous.Leadership, auth restful.AuthConfig, h http.Handler, ls logging.LogSink) http.Handler {
+	if l == nil {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !forwardsToLeader(req) || l.Leading() || req.Header.Get(forwardedByHeader) != "" {
+			h.ServeHTTP(w, req)
+			return
+		}
+		leader, err := l.LeaderOf(l.Cluster)
+		if err != nil {
+			logging.ReportError(ls, errors.Wrapf(err, "finding the leader to forward %s %s to", req.Method, req.URL.Path))
+		}
+		if leader == "" {
+			h.ServeHTTP(w, req)
+			return
+		}
+		target, err := url.Parse(leader)
+		if err != nil {
+			logging.ReportError(ls, errors.Wrapf(err, "parsing leader URL %q", leader))
+			h.ServeHTTP(w, req)
+			return
+		}
+		if err := passOnClientCert(req, auth); err != nil {
+			logging.ReportError(ls, err)
+			http.Error(w, err.Error(), http.StatusMisdirectedRequest)
+			return
+		}
+		logging.ReportMsg(ls, logging.DebugLevel, fmt.Sprintf("Forwarding %s %s to leader %s", req.Method, req.URL.Path, leader))
+		req.Header.Set(forwardedByHeader, "follower")
+		proxy := httputil.NewSingleHostReverseProxy(target)
+		// Flush each write, so that /events streams through.
+		proxy.FlushInterval = -1
+		proxy.ServeHTTP(w, req)
+	})
+}
+
+// passOnClientCert signs req as the identity proven by its client
+// certificate, if it carries no other credentials.
+func passOnClientCert(req *http.Request, auth restful.AuthConfig) error {
+	if req.Header.Get("Authorization") != "" || req.Header.Get(restful.SignatureHeader) != "" {
+		return nil
+	}
+	id, err := restful.ClientCertAuthenticator{}.Authenticate(req)
+	if err != nil || id == nil {
+		return err
+	}
+	return errors.Wrapf(auth.SignAs(req, *id), "forwarding %s %s to the leader as %s", req.Method, req.URL.Path, id.Name)
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/logging"
+	"github.com/opentable/sous/util/restful"
+	"github.com/stretchr/testify/assert"
+)
+
+func servedBy(name string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Served-By", name)
+		w.Header().Set("Forwarded-By", req.Header.Get(forwardedByHeader))
+	})
+}
+
+func TestForwardToLeader(t *testing.T) {
+	leader := httptest.NewServer(servedBy("leader"))
+	defer leader.Close()
+
+	election := sous.NewMemoryLeaderElection()
+	election.Elector(leader.URL).Campaign("*")
+	follower := sous.NewLeadership(election.Elector("http://follower"), "*", logging.SilentLogSet())
+	h := forwardToLeader(follower, restful.AuthConfig{}, servedBy("follower"), logging.SilentLogSet())
+
+	for method, want := range map[string]string{
+		"GET":    "follower",
+		"PUT":    "leader",
+		"POST":   "leader",
+		"DELETE": "leader",
+	} {
+		rw := httptest.NewRecorder()
+		h.ServeHTTP(rw, httptest.NewRequest(method, "/gdm", nil))
+		assert.Equal(t, want, rw.Header().Get("Served-By"), method)
+	}
+	for path, want := range map[string]string{
+		"/active?repo=x&cluster=c": "leader",
+		"/status":                  "leader",
+		"/queue/abc":               "leader",
+		"/events":                  "leader",
+		"/manifest?repo=x":         "follower",
+		"/statusquo":               "follower",
+	} {
+		rw := httptest.NewRecorder()
+		h.ServeHTTP(rw, httptest.NewRequest("GET", path, nil))
+		assert.Equal(t, want, rw.Header().Get("Served-By"), "GET %s", path)
+	}
+
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest("PUT", "/gdm", nil))
+	assert.Equal(t, "follower", rw.Header().Get("Forwarded-By"), "forwarded requests are marked")
+
+	req := httptest.NewRequest("PUT", "/gdm", nil)
+	req.Header.Set(forwardedByHeader, "follower")
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, req)
+	assert.Equal(t, "follower", rw.Header().Get("Served-By"), "forwarded requests are not forwarded again")
+}
+
+func TestForwardToLeader_noLeader(t *testing.T) {
+	election := sous.NewMemoryLeaderElection()
+	follower := sous.NewLeadership(election.Elector("http://follower"), "*", logging.SilentLogSet())
+	h := forwardToLeader(follower, restful.AuthConfig{}, servedBy("follower"), logging.SilentLogSet())
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest("PUT", "/gdm", nil))
+	assert.Equal(t, "follower", rw.Header().Get("Served-By"))
+}
+
+func TestForwardToLeader_clientCert(t *testing.T) {
+	auth := restful.AuthConfig{HMACSecret: "secret"}
+	leader := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		id, err := auth.Authenticator().Authenticate(req)
+		if err != nil || id == nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Authenticated-As", id.Email)
+	}))
+	defer leader.Close()
+
+	election := sous.NewMemoryLeaderElection()
+	election.Elector(leader.URL).Campaign("*")
+	follower := sous.NewLeadership(election.Elector("http://follower"), "*", logging.SilentLogSet())
+
+	certRequest := func() *http.Request {
+		req := httptest.NewRequest("PUT", "/gdm", strings.NewReader("{}"))
+		cert := &x509.Certificate{Subject: pkix.Name{CommonName: "Judson"}, EmailAddresses: []string{"judson@example.com"}}
+		req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+		return req
+	}
+
+	rw := httptest.NewRecorder()
+	forwardToLeader(follower, auth, servedBy("follower"), logging.SilentLogSet()).ServeHTTP(rw, certRequest())
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, "judson@example.com", rw.Header().Get("Authenticated-As"), "the certificate's identity is passed on")
+
+	rw = httptest.NewRecorder()
+	forwardToLeader(follower, restful.AuthConfig{}, servedBy("follower"), logging.SilentLogSet()).ServeHTTP(rw, certRequest())
+	assert.Equal(t, http.StatusMisdirectedRequest, rw.Code, "without an HMACSecret, the identity can't be passed on")
+}
@@ -34,8 +34,36 @@ func TestSousRoutes(t *testing.T) {
 		restful.KV{"repo", "github.com/opentable/sous"},
 		restful.KV{"offset", "alt"},
 	)
+	test(
+		"/history?cluster=left&repo=github.com%2Fopentable%2Fsous",
+
+		"history",
+		restful.KV{"repo", "github.com/opentable/sous"},
+		restful.KV{"cluster", "left"},
+	)
+	test(
+		"/active?cluster=left&repo=github.com%2Fopentable%2Fsous",
+
+		"active",
+		restful.KV{"repo", "github.com/opentable/sous"},
+		restful.KV{"cluster", "left"},
+	)
+	test(
+		"/plan?cluster=left",
+
+		"plan",
+		restful.KV{"cluster", "left"},
+	)
 	test(
 		"/status",
 		"status",
 	)
+	test(
+		"/run/output?cluster=left&repo=github.com%2Fopentable%2Fsous&run=r1",
+
+		"run-output",
+		restful.KV{"repo", "github.com/opentable/sous"},
+		restful.KV{"cluster", "left"},
+		restful.KV{"run", "r1"},
+	)
 }
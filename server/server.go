@@ -1,6 +1,7 @@
 package server
 
 import (
+	"crypto/tls"
 	"net/http"
 	"net/http/pprof"
 	"os"
@@ -27,9 +28,34 @@ type (
 		sous.StateManager
 		ResolveFilter *sous.ResolveFilter
 		*sous.AutoResolver
+		// Webhooks are the server's webhooks, whose deliveries /webhooks
+		// reports.
+		Webhooks *sous.Webhooks
+		// Leadership, if not nil, elects the one of several servers for the
+		// same cluster which resolves it; the others forward writes to it.
+		Leadership *sous.Leadership
+		// Runner launches runs of on-demand and one-off deployments.
+		Runner sous.Runner
+		// StateInvalidator, if not nil, is told which requests Singularity's
+		// webhooks report have changed.
+		StateInvalidator sous.StateInvalidator
 	}
 )
 
+// authConfig returns the server's auth config.
+func (ctx ComponentLocator) authConfig() restful.AuthConfig {
+	if ctx.Config == nil {
+		return restful.AuthConfig{}
+	}
+	return ctx.Config.Auth
+}
+
+// authenticator returns the restful.Authenticator for the server's clients,
+// or nil if it does not authenticate them.
+func (ctx ComponentLocator) authenticator() restful.Authenticator {
+	return ctx.authConfig().Authenticator()
+}
+
 func (ctx ComponentLocator) liveState() *sous.State {
 	state, err := ctx.StateManager.ReadState()
 	if os.IsNotExist(errors.Cause(err)) || storage.IsGSMError(err) {
@@ -43,7 +69,12 @@ func (ctx ComponentLocator) liveState() *sous.State {
 	return state
 }
 
+// GetUser returns the user making req: the authenticated identity, if there
+// is one, and otherwise the user the client claims to be.
 func (userExtractor) GetUser(req *http.Request) ClientUser {
+	if id, ok := restful.IdentityFrom(req); ok {
+		return ClientUser{Name: id.Name, Email: id.Email}
+	}
 	return ClientUser{
 		Name:  req.Header.Get("Sous-User-Name"),
 		Email: req.Header.Get("Sous-User-Email"),
@@ -56,6 +87,12 @@ func Run(laddr string, handler http.Handler) error {
 	return s.ListenAndServe()
 }
 
+// RunTLS starts a server up, serving HTTPS with the certificates in tc.
+func RunTLS(laddr string, handler http.Handler, tc *tls.Config) error {
+	s := &http.Server{Addr: laddr, Handler: handler, TLSConfig: tc}
+	return s.ListenAndServeTLS("", "")
+}
+
 // Handler builds the http.Handler for the Sous server httprouter.
 func Handler(sc ComponentLocator, metrics http.Handler, ls logging.LogSink) http.Handler {
 	handler := mux(sc, ls)
@@ -72,10 +109,13 @@ func ProfilingHandler(sc ComponentLocator, metrics http.Handler, ls logging.LogS
 }
 
 func mux(sc ComponentLocator, ls logging.LogSink) *http.ServeMux {
-	router := routemap(sc).BuildRouter(ls)
+	router := routemap(sc).BuildAuthenticatedRouter(ls, sc.authenticator())
 
 	handler := http.NewServeMux()
-	handler.Handle("/", router)
+	auth := sc.authConfig()
+	handler.Handle("/", forwardToLeader(sc.Leadership, auth, router, ls))
+	handler.Handle("/events", forwardToLeader(sc.Leadership, auth, newEventsHandler(sc), ls))
+	handler.Handle("/webhooks/singularity", forwardToLeader(sc.Leadership, auth, newSingularityWebhookHandler(sc), ls))
 	return handler
 }
 
@@ -84,9 +124,19 @@ func routemap(context ComponentLocator) *restful.RouteMap {
 		{"gdm", "/gdm", newGDMResource(context)},
 		{"defs", "/defs", newStateDefResource(context)},
 		{"manifest", "/manifest", newManifestResource(context)},
+		{"history", "/history", newHistoryResource(context)},
+		{"active", "/active", newActiveResource(context)},
 		{"artifact", "/artifact", newArtifactResource(context)},
 		{"status", "/status", newStatusResource(context)},
 		{"servers", "/servers", newServerListResource(context)},
+		{"plan", "/plan", newPlanResource(context)},
+		{"deletion-brake", "/deletion-brake", newDeletionBrakeResource(context)},
+		{"webhooks", "/webhooks", newWebhooksResource(context)},
+		{"resolve", "/resolve", newResolveResource(context)},
+		{"queue", "/queue", newQueueResource(context)},
+		{"r11n", "/queue/:r11nID", newR11nResource(context)},
+		{"run", "/run", newRunResource(context)},
+		{"run-output", "/run/output", newRunOutputResource(context)},
 	}
 }
 
@@ -42,6 +42,12 @@ type (
 	// UnknownErr is the error of last resort, only to be used if none of the
 	// other error types is applicable.
 	UnknownErr struct{ *cliErr }
+	// ExitErr signifies a failure which should be reported with a particular
+	// exit code, such as that of a process run on the user's behalf.
+	ExitErr struct {
+		*cliErr
+		Code int
+	}
 )
 
 // EnsureErrorResult takes an error, and if it is not already also a Result,
@@ -82,11 +88,17 @@ func UnknownErrorf(format string, v ...interface{}) UnknownErr {
 	return UnknownErr{newError(format, v...)}
 }
 
+// ExitErrorf returns an ExitErr, which exits with code.
+func ExitErrorf(code int, format string, v ...interface{}) ExitErr {
+	return ExitErr{newError(format, v...), code}
+}
+
 func (e InternalErr) ExitCode() int { return EX_SOFTWARE }
 func (e UsageErr) ExitCode() int    { return EX_USAGE }
 func (e OSErr) ExitCode() int       { return EX_OSERR }
 func (e IOErr) ExitCode() int       { return EX_IOERR }
 func (e UnknownErr) ExitCode() int  { return 255 }
+func (e ExitErr) ExitCode() int     { return e.Code }
 func (e *cliErr) ExitCode() int     { return 255 }
 
 func (e *cliErr) UserTip() string { return e.Tip }
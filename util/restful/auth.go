@@ -0,0 +1,382 @@
+package restful
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// SignatureHeader carries the HMAC signature of a request.
+	SignatureHeader = "Sous-Signature"
+	// UserNameHeader and UserEmailHeader identify the user making a request.
+	// They are only trusted when the request is signed.
+	UserNameHeader  = "Sous-User-Name"
+	UserEmailHeader = "Sous-User-Email"
+
+	// maxClockSkew is how far the Date of a signed request may be from the
+	// server's clock.
+	maxClockSkew = 5 * time.Minute
+)
+
+type (
+	// An Identity is the verified identity of the client making a request.
+	Identity struct {
+		Name, Email string
+		Groups      []string `yaml:",omitempty"`
+	}
+
+	// An Authenticator verifies the identity of the client making a request.
+	// It returns nil and no error if the request carries no credentials it
+	// knows about, and an error if the credentials are invalid.
+	Authenticator interface {
+		Authenticate(*http.Request) (*Identity, error)
+	}
+
+	// Authenticators tries each of its Authenticators in turn, returning the
+	// first identity or error.
+	Authenticators []Authenticator
+
+	// HMACAuthenticator authenticates requests signed with a secret shared by
+	// the server and its clients. The identity is taken from the signed user
+	// headers.
+	HMACAuthenticator struct {
+		Secret []byte
+		// now is the time requests are checked against; if nil, time.Now.
+		now func() time.Time
+	}
+
+	// ClientCertAuthenticator authenticates requests by the client
+	// certificate verified during the TLS handshake. The identity is taken
+	// from the certificate's common name, email addresses and organizational
+	// units, which are used as groups.
+	ClientCertAuthenticator struct{}
+
+	// BearerTokenAuthenticator authenticates requests by the bearer token in
+	// their Authorization header.
+	BearerTokenAuthenticator struct {
+		Tokens map[string]Identity
+	}
+
+	// AuthConfig configures how a Sous server authenticates its clients, and
+	// how clients authenticate to it.
+	AuthConfig struct {
+		// HMACSecret is shared by the server and its clients, and used to
+		// sign requests.
+		HMACSecret string `env:"SOUS_AUTH_HMAC_SECRET"`
+		// Token is the bearer token a client sends.
+		Token string `env:"SOUS_AUTH_TOKEN"`
+		// Tokens maps the bearer tokens a server accepts to the identities
+		// they authenticate.
+		Tokens map[string]Identity `yaml:",omitempty"`
+		// ClientCert and ClientKey are PEM files holding the certificate a
+		// client presents to the server.
+		ClientCert string `env:"SOUS_AUTH_CLIENT_CERT"`
+		ClientKey  string `env:"SOUS_AUTH_CLIENT_KEY"`
+		// ServerCA is a PEM file holding the CAs a client trusts to sign the
+		// server's certificate. If it is empty, the system roots are used.
+		ServerCA string `env:"SOUS_AUTH_SERVER_CA"`
+		// ServerCert and ServerKey are PEM files holding the certificate a
+		// server serves TLS with.
+		ServerCert string `env:"SOUS_AUTH_SERVER_CERT"`
+		ServerKey  string `env:"SOUS_AUTH_SERVER_KEY"`
+		// ClientCA is a PEM file holding the CAs a server trusts to sign
+		// client certificates.
+		ClientCA string `env:"SOUS_AUTH_CLIENT_CA"`
+		// Groups maps group names to the emails of their members, in
+		// addition to the groups named in client certificates.
+		Groups map[string][]string `yaml:",omitempty"`
+	}
+
+	// groupAuthenticator adds configured groups to the identities found by
+	// its Authenticator.
+	groupAuthenticator struct {
+		Authenticator
+		groups map[string][]string
+	}
+
+	// requestSigner adds credentials to the requests a client sends.
+	requestSigner struct {
+		secret []byte
+		token  string
+	}
+
+	identityKey struct{}
+)
+
+// WithIdentity returns a copy of r carrying id.
+func WithIdentity(r *http.Request, id Identity) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), identityKey{}, id))
+}
+
+// IdentityFrom returns the identity authenticated for r, if any.
+func IdentityFrom(r *http.Request) (Identity, bool) {
+	id, ok := r.Context().Value(identityKey{}).(Identity)
+	return id, ok
+}
+
+// InGroup returns true if id is a member of any of groups.
+func (id Identity) InGroup(groups ...string) bool {
+	for _, g := range groups {
+		for _, mine := range id.Groups {
+			if g == mine {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Authenticate implements Authenticator on Authenticators.
+func (as Authenticators) Authenticate(r *http.Request) (*Identity, error) {
+	for _, a := range as {
+		id, err := a.Authenticate(r)
+		if id != nil || err != nil {
+			return id, err
+		}
+	}
+	return nil, nil
+}
+
+// Authenticate implements Authenticator on HMACAuthenticator.
+func (a HMACAuthenticator) Authenticate(r *http.Request) (*Identity, error) {
+	sig := r.Header.Get(SignatureHeader)
+	if sig == "" {
+		return nil, nil
+	}
+	given, err := base64.StdEncoding.DecodeString(sig)
+	if err != nil {
+		return nil, errors.Wrapf(err, "malformed %s", SignatureHeader)
+	}
+	date, err := http.ParseTime(r.Header.Get("Date"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "signed request has a bad Date")
+	}
+	now := time.Now
+	if a.now != nil {
+		now = a.now
+	}
+	if skew := now().Sub(date); skew > maxClockSkew || skew < -maxClockSkew {
+		return nil, errors.Errorf("signed request Date %s is too far from the server's clock", r.Header.Get("Date"))
+	}
+	body, err := readBody(r)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(given, signature(a.Secret, r.Method, requestURI(r), r.Header, body)) {
+		return nil, errors.New("request signature does not match")
+	}
+	return &Identity{
+		Name:  r.Header.Get(UserNameHeader),
+		Email: r.Header.Get(UserEmailHeader),
+	}, nil
+}
+
+// Authenticate implements Authenticator on ClientCertAuthenticator.
+func (ClientCertAuthenticator) Authenticate(r *http.Request) (*Identity, error) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil, nil
+	}
+	cert := r.TLS.VerifiedChains[0][0]
+	id := &Identity{
+		Name:   cert.Subject.CommonName,
+		Groups: append([]string(nil), cert.Subject.OrganizationalUnit...),
+	}
+	if len(cert.EmailAddresses) > 0 {
+		id.Email = cert.EmailAddresses[0]
+	}
+	return id, nil
+}
+
+// Authenticate implements Authenticator on BearerTokenAuthenticator.
+func (a BearerTokenAuthenticator) Authenticate(r *http.Request) (*Identity, error) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return nil, nil
+	}
+	given := []byte(strings.TrimPrefix(auth, "Bearer "))
+	for token, id := range a.Tokens {
+		if subtle.ConstantTimeCompare(given, []byte(token)) == 1 {
+			id := id
+			return &id, nil
+		}
+	}
+	return nil, errors.New("unknown bearer token")
+}
+
+func (a groupAuthenticator) Authenticate(r *http.Request) (*Identity, error) {
+	id, err := a.Authenticator.Authenticate(r)
+	if id == nil || err != nil {
+		return id, err
+	}
+	for group, members := range a.groups {
+		for _, m := range members {
+			if id.Email != "" && strings.EqualFold(m, id.Email) && !id.InGroup(group) {
+				id.Groups = append(id.Groups, group)
+			}
+		}
+	}
+	return id, nil
+}
+
+// Enabled returns true if a server with this config authenticates clients.
+func (c AuthConfig) Enabled() bool {
+	return c.HMACSecret != "" || len(c.Tokens) != 0 || c.ClientCA != ""
+}
+
+// Authenticator returns the Authenticator a server with this config uses,
+// or nil if it does not authenticate clients.
+func (c AuthConfig) Authenticator() Authenticator {
+	if !c.Enabled() {
+		return nil
+	}
+	var as Authenticators
+	if c.ClientCA != "" {
+		as = append(as, ClientCertAuthenticator{})
+	}
+	if len(c.Tokens) != 0 {
+		as = append(as, BearerTokenAuthenticator{Tokens: c.Tokens})
+	}
+	if c.HMACSecret != "" {
+		as = append(as, HMACAuthenticator{Secret: []byte(c.HMACSecret)})
+	}
+	return groupAuthenticator{Authenticator: as, groups: c.Groups}
+}
+
+// ServerTLSConfig returns the TLS config a server with this config serves
+// with, or nil if it serves plain HTTP. Client certificates are verified if
+// they are presented, so that clients may authenticate in other ways.
+func (c AuthConfig) ServerTLSConfig() (*tls.Config, error) {
+	if c.ServerCert == "" {
+		return nil, nil
+	}
+	cert, err := tls.LoadX509KeyPair(c.ServerCert, c.ServerKey)
+	if err != nil {
+		return nil, errors.Wrapf(err, "loading server certificate")
+	}
+	tc := &tls.Config{Certificates: []tls.Certificate{cert}}
+	if c.ClientCA != "" {
+		pool, err := loadCertPool(c.ClientCA)
+		if err != nil {
+			return nil, err
+		}
+		tc.ClientCAs = pool
+		tc.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return tc, nil
+}
+
+// clientTLSConfig returns the TLS config a client with this config uses, or
+// nil if the defaults will do.
+func (c AuthConfig) clientTLSConfig() (*tls.Config, error) {
+	if c.ClientCert == "" && c.ServerCA == "" {
+		return nil, nil
+	}
+	tc := &tls.Config{}
+	if c.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(c.ClientCert, c.ClientKey)
+		if err != nil {
+			return nil, errors.Wrapf(err, "loading client certificate")
+		}
+		tc.Certificates = []tls.Certificate{cert}
+	}
+	if c.ServerCA != "" {
+		pool, err := loadCertPool(c.ServerCA)
+		if err != nil {
+			return nil, err
+		}
+		tc.RootCAs = pool
+	}
+	return tc, nil
+}
+
+func loadCertPool(path string) (*x509.CertPool, error) {
+	pem, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading CA certificates")
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.Errorf("no CA certificates in %s", path)
+	}
+	return pool, nil
+}
+
+// sign adds the signer's credentials to rq, whose body is body.
+func (s requestSigner) sign(rq *http.Request, body []byte) {
+	if s.token != "" {
+		rq.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	if len(s.secret) == 0 {
+		return
+	}
+	rq.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	sig := signature(s.secret, rq.Method, rq.URL.RequestURI(), rq.Header, body)
+	rq.Header.Set(SignatureHeader, base64.StdEncoding.EncodeToString(sig))
+}
+
+// SignAs signs r as made by id, with c's HMACSecret, so that servers sharing
+// the secret authenticate it as id. Servers use it to pass on requests they
+// authenticated in other ways. Only id's name and email are passed on.
+func (c AuthConfig) SignAs(r *http.Request, id Identity) error {
+	if c.HMACSecret == "" {
+		return errors.New("no HMACSecret is configured to sign with")
+	}
+	body, err := readBody(r)
+	if err != nil {
+		return err
+	}
+	r.Header.Set(UserNameHeader, id.Name)
+	r.Header.Set(UserEmailHeader, id.Email)
+	requestSigner{secret: []byte(c.HMACSecret)}.sign(r, body)
+	return nil
+}
+
+// signature computes the HMAC of the parts of a request which identify what
+// it does and who is doing it.
+func signature(secret []byte, method, uri string, header http.Header, body []byte) []byte {
+	sum := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strings.Join([]string{
+		method,
+		uri,
+		header.Get("Date"),
+		header.Get(UserNameHeader),
+		header.Get(UserEmailHeader),
+		hex.EncodeToString(sum[:]),
+	}, "\n")))
+	return mac.Sum(nil)
+}
+
+func requestURI(r *http.Request) string {
+	if r.RequestURI != "" {
+		return r.RequestURI
+	}
+	return r.URL.RequestURI()
+}
+
+// readBody reads r's body, replacing it so that it can be read again.
+func readBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil {
+		return nil, nil
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading request body")
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
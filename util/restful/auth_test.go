@@ -0,0 +1,138 @@
+package restful
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/opentable/sous/util/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func authTestServer(auth AuthConfig) *httptest.Server {
+	return httptest.NewServer(testRouteMap().BuildAuthenticatedRouter(logging.SilentLogSet(), auth.Authenticator()))
+}
+
+func authTestClient(t *testing.T, url string, auth AuthConfig) *LiveHTTPClient {
+	cl, err := NewClient(url, logging.SilentLogSet())
+	require.NoError(t, err)
+	require.NoError(t, cl.SetAuth(auth))
+	return cl
+}
+
+func TestHMACSignedWrites(t *testing.T) {
+	srv := authTestServer(AuthConfig{HMACSecret: "sekrit"})
+	defer srv.Close()
+	user := map[string]string{UserNameHeader: "Judson", UserEmailHeader: "jlester@example.com"}
+
+	signed := authTestClient(t, srv.URL, AuthConfig{HMACSecret: "sekrit"})
+	assert.NoError(t, signed.Create("/test/missing", map[string]string{"extra": "two"}, TestData{"new", "missing", "two"}, user))
+
+	unsigned := authTestClient(t, srv.URL, AuthConfig{})
+	err := unsigned.Create("/test/missing", nil, TestData{"new", "missing", ""}, user)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "401")
+		assert.Contains(t, err.Error(), "authentication required")
+	}
+
+	forged := authTestClient(t, srv.URL, AuthConfig{HMACSecret: "guess"})
+	err = forged.Create("/test/missing", nil, TestData{"new", "missing", ""}, user)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "signature does not match")
+	}
+
+	assert.NoError(t, signed.Post("/test/one", nil, nil, user))
+	err = unsigned.Post("/test/one", nil, nil, user)
+	if assert.Error(t, err, "POSTs are writes") {
+		assert.Contains(t, err.Error(), "401")
+	}
+
+	td := TestData{}
+	_, err = unsigned.Retrieve("/test/one", nil, &td, nil)
+	assert.NoError(t, err, "reads need no credentials")
+	assert.Equal(t, "one", td.Name)
+}
+
+func TestHMACIdentity(t *testing.T) {
+	body := []byte(`{"Data":"x"}`)
+	rq := httptest.NewRequest("PUT", "/test/one?extra=two", nil)
+	rq.Header.Set(UserNameHeader, "Judson")
+	rq.Header.Set(UserEmailHeader, "jlester@example.com")
+	requestSigner{secret: []byte("sekrit")}.sign(rq, body)
+	rq.Body = justBytes(body, nil)
+
+	a := HMACAuthenticator{Secret: []byte("sekrit")}
+	id, err := a.Authenticate(rq)
+	require.NoError(t, err)
+	assert.Equal(t, &Identity{Name: "Judson", Email: "jlester@example.com"}, id)
+
+	rq.Body = justBytes(body, nil)
+	rq.Header.Set(UserEmailHeader, "admin@example.com")
+	_, err = a.Authenticate(rq)
+	assert.Error(t, err, "user headers are signed")
+
+	rq.Body = justBytes(body, nil)
+	rq.Header.Set(UserEmailHeader, "jlester@example.com")
+	a.now = func() time.Time { return time.Now().Add(time.Hour) }
+	_, err = a.Authenticate(rq)
+	assert.Error(t, err, "stale signatures are rejected")
+}
+
+func TestBearerTokenAuthentication(t *testing.T) {
+	auth := AuthConfig{
+		Tokens: map[string]Identity{"t0k3n": {Name: "deploy-bot", Email: "bot@example.com"}},
+		Groups: map[string][]string{"admins": {"BOT@example.com"}},
+	}
+	a := auth.Authenticator()
+
+	rq := httptest.NewRequest("GET", "/", nil)
+	id, err := a.Authenticate(rq)
+	assert.NoError(t, err)
+	assert.Nil(t, id)
+
+	rq.Header.Set("Authorization", "Bearer t0k3n")
+	id, err = a.Authenticate(rq)
+	require.NoError(t, err)
+	assert.Equal(t, "deploy-bot", id.Name)
+	assert.True(t, id.InGroup("admins"))
+
+	rq.Header.Set("Authorization", "Bearer nope")
+	_, err = a.Authenticate(rq)
+	assert.Error(t, err)
+}
+
+func TestClientCertAuthentication(t *testing.T) {
+	cert := &x509.Certificate{
+		Subject: pkix.Name{
+			CommonName:         "jlester",
+			OrganizationalUnit: []string{"platform"},
+		},
+		EmailAddresses: []string{"jlester@example.com"},
+	}
+	rq := httptest.NewRequest("GET", "/", nil)
+	rq.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+
+	id, err := ClientCertAuthenticator{}.Authenticate(rq)
+	require.NoError(t, err)
+	assert.Equal(t, &Identity{Name: "jlester", Email: "jlester@example.com", Groups: []string{"platform"}}, id)
+
+	rq.TLS = nil
+	id, err = ClientCertAuthenticator{}.Authenticate(rq)
+	assert.NoError(t, err)
+	assert.Nil(t, id)
+}
+
+func TestIdentityOnRequest(t *testing.T) {
+	rq := httptest.NewRequest("GET", "/", nil)
+	_, ok := IdentityFrom(rq)
+	assert.False(t, ok)
+
+	rq = WithIdentity(rq, Identity{Email: "jlester@example.com"})
+	id, ok := IdentityFrom(rq)
+	assert.True(t, ok)
+	assert.Equal(t, "jlester@example.com", id.Email)
+}
@@ -2,6 +2,7 @@ package restful
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -23,6 +24,7 @@ type (
 		http.Client
 		logSet
 		commonHeaders http.Header
+		signer        requestSigner
 	}
 
 	resourceState struct {
@@ -118,6 +120,46 @@ func NewClient(serverURL string, ls logSet, headers ...map[string]string) (*Live
 	return client, errors.Wrapf(err, "new Sous REST client")
 }
 
+// SetAuth configures client to authenticate to the server as described by
+// c: signing its requests, sending a bearer token, or presenting a client
+// certificate.
+func (client *LiveHTTPClient) SetAuth(c AuthConfig) error {
+	client.signer = requestSigner{secret: []byte(c.HMACSecret), token: c.Token}
+	tc, err := c.clientTLSConfig()
+	if err != nil || tc == nil {
+		return err
+	}
+	transport, ok := client.Client.Transport.(*http.Transport)
+	if !ok {
+		return errors.Errorf("cannot configure TLS on a %T", client.Client.Transport)
+	}
+	transport.TLSClientConfig = tc
+	return nil
+}
+
+// ForServer returns a client for serverURL which authenticates to it as
+// client does, sharing client's transport, signer and common headers.
+func (client *LiveHTTPClient) ForServer(serverURL string) (*LiveHTTPClient, error) {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "new Sous REST client")
+	}
+	other := *client
+	other.serverURL = u
+	return &other, nil
+}
+
+// Stream sends a signed GET for urlPath and returns the response, whose body
+// the caller must close. The request is cancelled with ctx.
+func (client *LiveHTTPClient) Stream(ctx context.Context, urlPath string, qParms map[string]string, headers map[string]string) (*http.Response, error) {
+	url, err := client.buildURL(urlPath, qParms)
+	rq, err := client.buildRequest("GET", url, headers, nil, nil, err)
+	if err != nil {
+		return nil, err
+	}
+	return client.sendRequest(rq.WithContext(ctx), nil)
+}
+
 // NewInMemoryClient wraps a MemoryListener in a restful.Client
 func NewInMemoryClient(handler http.Handler, ls logSet, headers ...map[string]string) (HTTPClient, error) {
 	u, err := url.Parse("http://in.memory.server")
@@ -179,6 +221,18 @@ func (client *LiveHTTPClient) Create(urlPath string, qParms map[string]string, q
 	}(), "Create %s %v", urlPath, qParms)
 }
 
+// Post sends qBody to urlPath/qParms with a POST, for endpoints which aren't
+// resources, such as webhook receivers. Any response body is discarded.
+func (client *LiveHTTPClient) Post(urlPath string, qParms map[string]string, qBody interface{}, headers map[string]string) error {
+	return errors.Wrapf(func() error {
+		url, err := client.buildURL(urlPath, qParms)
+		rq, err := client.buildRequest("POST", url, headers, nil, qBody, err)
+		rz, err := client.sendRequest(rq, err)
+		_, err = client.getBody(rz, nil, err)
+		return err
+	}(), "Post %s %v", urlPath, qParms)
+}
+
 func (client *LiveHTTPClient) deelete(urlPath string, qParms map[string]string, from *resourceState, headers map[string]string) error {
 	return errors.Wrapf(func() error {
 		url, err := client.buildURL(urlPath, qParms)
@@ -286,6 +340,9 @@ func (client *LiveHTTPClient) buildRequest(method, url string, headers map[strin
 	*/
 
 	client.updateHeaders(rq, headers)
+	if err == nil {
+		client.signer.sign(rq, JSON.Bytes())
+	}
 
 	return rq, err
 }
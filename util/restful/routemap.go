@@ -53,10 +53,13 @@ type (
 	Optionsable interface {
 		Options(http.ResponseWriter, *http.Request, httprouter.Params) Exchanger
 	}
+	// Postable tags ResourceFamilies that respond to POST, for actions which
+	// aren't the replacement of a resource, and so have no preconditions.
+	Postable interface {
+		Post(http.ResponseWriter, *http.Request, httprouter.Params) Exchanger
+	}
+
 	/*
-		Postable interface {
-			Post() Exchanger
-		}
 		// also consider Headable or Patchable
 		// which maybe should be named "SpecializedHead" or something
 		// Note that Patchable and SpecialPatch should be separate
@@ -72,11 +75,12 @@ type (
 	}
 )
 
-func (rm *RouteMap) buildMetaHandler(r *httprouter.Router, ls logging.LogSink) *MetaHandler {
+func (rm *RouteMap) buildMetaHandler(r *httprouter.Router, ls logging.LogSink, auth Authenticator) *MetaHandler {
 	ph := &StatusMiddleware{logSet: ls, gatelatch: os.Getenv("GATELATCH")}
 	mh := &MetaHandler{
 		router:        r,
 		statusHandler: ph,
+		auth:          auth,
 		LogSink:       ls,
 	}
 	mh.InstallPanicHandler()
@@ -86,13 +90,22 @@ func (rm *RouteMap) buildMetaHandler(r *httprouter.Router, ls logging.LogSink) *
 
 // BuildRouter builds a returns an http.Handler based on some constant configuration
 func (rm *RouteMap) BuildRouter(ls logging.LogSink) http.Handler {
+	return rm.BuildAuthenticatedRouter(ls, nil)
+}
+
+// BuildAuthenticatedRouter is like BuildRouter, but the handler it returns
+// authenticates clients with auth: PUTs, DELETEs and POSTs must carry valid
+// credentials, and other requests may. If auth is nil, it does not
+// authenticate clients.
+func (rm *RouteMap) BuildAuthenticatedRouter(ls logging.LogSink, auth Authenticator) http.Handler {
 	r := httprouter.New()
-	mh := rm.buildMetaHandler(r, ls)
+	mh := rm.buildMetaHandler(r, ls, auth)
 
 	for _, e := range *rm {
 		get, canGet := e.Resource.(Getable)
 		put, canPut := e.Resource.(Putable)
 		del, canDel := e.Resource.(Deleteable)
+		post, canPost := e.Resource.(Postable)
 		opt, canOpt := e.Resource.(Optionsable)
 
 		if canGet {
@@ -105,6 +118,9 @@ func (rm *RouteMap) BuildRouter(ls logging.LogSink) http.Handler {
 		if canDel {
 			r.Handle("DELETE", e.Path, mh.DeleteHandling(e.Name, del.Delete))
 		}
+		if canPost {
+			r.Handle("POST", e.Path, mh.PostHandling(e.Name, post.Post))
+		}
 		if canOpt {
 			r.Handle("OPTIONS", e.Path, mh.OptionsHandling(e.Name, opt.Options))
 		} else {
@@ -127,6 +143,9 @@ func defaultOptions(res Resource) ExchangeFactory {
 	if _, can := res.(Deleteable); can {
 		ex.methods = append(ex.methods, "DELETE")
 	}
+	if _, can := res.(Postable); can {
+		ex.methods = append(ex.methods, "POST")
+	}
 
 	return func(http.ResponseWriter, *http.Request, httprouter.Params) Exchanger {
 		return ex
@@ -144,7 +163,7 @@ func (rm *RouteMap) SingleExchanger(factory ExchangeFactory, gf func() Injector,
 	w := httptest.NewRecorder()
 	rq := httptest.NewRequest("GET", "/", nil)
 
-	mh := rm.buildMetaHandler(r, ls)
+	mh := rm.buildMetaHandler(r, ls, nil)
 
 	return mh.injectedHandler(factory, w, rq, httprouter.Params{})
 }
@@ -25,6 +25,8 @@ type (
 	MetaHandler struct {
 		router        *httprouter.Router
 		statusHandler *StatusMiddleware
+		// auth, if not nil, authenticates clients.
+		auth Authenticator
 		logging.LogSink
 	}
 
@@ -101,6 +103,10 @@ func (mh *MetaHandler) wrapResponseWriter(resName string, rq *http.Request, rw h
 func (mh *MetaHandler) GetHandling(resName string, factory ExchangeFactory) httprouter.Handle {
 	return func(rw http.ResponseWriter, r *http.Request, p httprouter.Params) {
 		w := mh.wrapResponseWriter(resName, r, rw)
+		r, ok := mh.authenticate(w, r, false)
+		if !ok {
+			return
+		}
 		h := mh.injectedHandler(factory, w, r, p)
 		data, status := h.Exchange()
 		w.Header().Add("Access-Control-Allow-Origin", "*") //XXX configurable by app
@@ -112,16 +118,39 @@ func (mh *MetaHandler) GetHandling(resName string, factory ExchangeFactory) http
 func (mh *MetaHandler) DeleteHandling(resName string, factory ExchangeFactory) httprouter.Handle {
 	return func(rw http.ResponseWriter, r *http.Request, p httprouter.Params) {
 		w := mh.wrapResponseWriter(resName, r, rw)
+		r, ok := mh.authenticate(w, r, true)
+		if !ok {
+			return
+		}
 		h := mh.injectedHandler(factory, w, r, p)
 		_, status := h.Exchange()
 		mh.renderData(status, w, r, nil)
 	}
 }
 
+// PostHandling handles POST requests. Clients must be authenticated, as for
+// other writes.
+func (mh *MetaHandler) PostHandling(resName string, factory ExchangeFactory) httprouter.Handle {
+	return func(rw http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		w := mh.wrapResponseWriter(resName, r, rw)
+		r, ok := mh.authenticate(w, r, true)
+		if !ok {
+			return
+		}
+		h := mh.injectedHandler(factory, w, r, p)
+		data, status := h.Exchange()
+		mh.renderData(status, w, r, data)
+	}
+}
+
 // HeadHandling handles Head requests.
 func (mh *MetaHandler) HeadHandling(resName string, factory ExchangeFactory) httprouter.Handle {
 	return func(rw http.ResponseWriter, r *http.Request, p httprouter.Params) {
 		w := mh.wrapResponseWriter(resName, r, rw)
+		r, ok := mh.authenticate(w, r, false)
+		if !ok {
+			return
+		}
 		h := mh.injectedHandler(factory, w, r, p)
 		_, status := h.Exchange()
 		mh.writeHeaders(status, w, r, nil)
@@ -148,6 +177,10 @@ func (mh *MetaHandler) OptionsHandling(resName string, factory ExchangeFactory)
 func (mh *MetaHandler) PutHandling(resName string, factory ExchangeFactory) httprouter.Handle {
 	return func(rw http.ResponseWriter, r *http.Request, p httprouter.Params) {
 		w := mh.wrapResponseWriter(resName, r, rw)
+		r, ok := mh.authenticate(w, r, true)
+		if !ok {
+			return
+		}
 		if r.Header.Get("If-Match") == "" && r.Header.Get("If-None-Match") == "" {
 			mh.writeHeaders(http.StatusPreconditionRequired, w, r, "PUT requires If-Match or If-None-Match")
 			return
@@ -182,6 +215,32 @@ func (mh *MetaHandler) PutHandling(resName string, factory ExchangeFactory) http
 	}
 }
 
+// authenticate returns r carrying the identity of the client, if it has
+// one. If the MetaHandler authenticates clients, requests with bad
+// credentials are rejected, as are writes without any.
+func (mh *MetaHandler) authenticate(w http.ResponseWriter, r *http.Request, write bool) (*http.Request, bool) {
+	if mh.auth == nil {
+		return r, true
+	}
+	if _, already := IdentityFrom(r); already {
+		// e.g. the GET synthesized to check a PUT's preconditions.
+		return r, true
+	}
+	id, err := mh.auth.Authenticate(r)
+	if err != nil {
+		mh.writeHeaders(http.StatusUnauthorized, w, r, fmt.Sprintf("authentication failed: %s", err))
+		return r, false
+	}
+	if id == nil {
+		if write {
+			mh.writeHeaders(http.StatusUnauthorized, w, r, "authentication required")
+			return r, false
+		}
+		return r, true
+	}
+	return WithIdentity(r, *id), true
+}
+
 // InstallPanicHandler installs an panic handler into the router.
 func (mh *MetaHandler) InstallPanicHandler() {
 	mh.router.PanicHandler = func(w http.ResponseWriter, r *http.Request, recovered interface{}) {
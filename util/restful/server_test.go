@@ -36,6 +36,10 @@ type (
 		QueryValues
 	}
 
+	TestPostExchanger struct {
+		httprouter.Params
+	}
+
 	TestData struct {
 		Data, Name, Extra string
 	}
@@ -61,6 +65,10 @@ func (tr *TestResource) Put(write http.ResponseWriter, req *http.Request, ps htt
 	}
 }
 
+func (tr *TestResource) Post(write http.ResponseWriter, req *http.Request, ps httprouter.Params) Exchanger {
+	return &TestPostExchanger{Params: ps}
+}
+
 func (ge *TestGetExchanger) Exchange() (interface{}, int) {
 	p := ge.Params.ByName("param")
 	if p == "missing" {
@@ -84,6 +92,10 @@ func (ge *TestPutExchanger) Exchange() (interface{}, int) {
 	}, 200
 }
 
+func (pe *TestPostExchanger) Exchange() (interface{}, int) {
+	return TestData{Name: pe.Params.ByName("param")}, http.StatusAccepted
+}
+
 func testRouteMap() *RouteMap {
 	return &RouteMap{
 		{"test", "/test/:param", newTestResource("base")},